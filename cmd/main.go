@@ -2,15 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"users-app/internal/auth"
+	grpccontroller "users-app/internal/controller/grpc"
 	"users-app/internal/controller/restAPI"
+	"users-app/internal/entity"
+	"users-app/internal/events"
+	"users-app/internal/idempotency"
+	"users-app/internal/policy"
+	"users-app/internal/ratelimit"
+	"users-app/internal/rates"
 	"users-app/internal/repository"
 	"users-app/internal/service"
+	"users-app/internal/webhook"
+	"users-app/internal/worker"
 	"users-app/pkg/config"
+	"users-app/pkg/i18n"
 	"users-app/pkg/logger"
 	"users-app/pkg/postgres"
 )
@@ -31,6 +44,12 @@ func main() {
 		return
 	}
 
+	catalog, err := i18n.New(cfg.I18N.DefaultLanguage)
+	if err != nil {
+		log.ErrorF("failed to load message catalog: %s", err.Error())
+		return
+	}
+
 	pool, err := postgres.Connect(ctx, cfg.Postgres.DSN, cfg.Postgres.MaxConns)
 	if err != nil {
 		log.ErrorF("failed to connect to database: %s", err.Error())
@@ -43,11 +62,77 @@ func main() {
 	}
 
 	userRepo := repository.New(pool)
-	userService := service.New(userRepo)
-	restController := restapi.New(cfg, log, userService)
+
+	authz, err := newAuthorizer(log, cfg.Auth)
+	if err != nil {
+		log.ErrorF("failed to load access policy: %s", err.Error())
+		return
+	}
+
+	if _, ok := entity.LookupCurrency(cfg.Currency.Base); !ok {
+		log.ErrorF("unknown base currency %q", cfg.Currency.Base)
+		return
+	}
+
+	userService := service.New(userRepo, authz, newRiskEngine(log, cfg.Risk), cfg.Currency.Base)
+
+	if err := loadExchangeRates(ctx, log, cfg.Currency.RatesFile, userService); err != nil {
+		log.ErrorF("failed to load exchange rates: %s", err.Error())
+		return
+	}
+	stream := events.NewBroker(cfg.Stream.ReplaySize, cfg.Stream.ClientBuffer, cfg.Stream.Heartbeat)
+
+	verifier, err := newVerifier(ctx, log, cfg.Auth)
+	if err != nil {
+		log.ErrorF("failed to create token verifier: %s", err.Error())
+		return
+	}
+
+	limiter, err := newLimiter(log, cfg.RateLimit, userRepo)
+	if err != nil {
+		log.ErrorF("failed to create rate limiter: %s", err.Error())
+		return
+	}
+
+	sinks, closeSinks, err := newSinks(cfg.Outbox)
+	if err != nil {
+		log.ErrorF("failed to create event sinks: %s", err.Error())
+		return
+	}
+	defer closeSinks()
+
+	keeper := idempotency.New(userRepo, idempotency.Options{
+		TTL:         cfg.Idempotency.TTL,
+		Wait:        cfg.Idempotency.Wait,
+		LockTimeout: cfg.Idempotency.LockTimeout,
+	})
+
+	restController, err := restapi.New(ctx, cfg, log, catalog, verifier, limiter, keeper, userService, stream)
+	if err != nil {
+		log.ErrorF("failed to create rest controller: %s", err.Error())
+		return
+	}
+
+	grpcController := grpccontroller.New(cfg, log, verifier, userService)
 
 	wg := &sync.WaitGroup{}
 
+	if verifier != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			verifier.Run(ctx, cfg.Auth.ReloadInterval)
+		}()
+	}
+
+	if limiter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.Run(ctx, cfg.RateLimit.SweepInterval)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -57,7 +142,77 @@ func main() {
 		}
 	}()
 
-	log.InfoF("server started on port %d", cfg.HTTP.Port)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := grpcController.Run(); err != nil {
+			log.ErrorF("failed to run grpc server: %s", err.Error())
+			return
+		}
+	}()
+
+	purge := worker.NewPurge(log, userService, cfg.Purge.Retention, cfg.Purge.Interval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		purge.Run(ctx)
+	}()
+
+	holdExpiry := worker.NewHoldExpiry(log, userService, cfg.Holds.ExpiryBatchSize, cfg.Holds.ExpiryInterval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		holdExpiry.Run(ctx)
+	}()
+
+	idempotencyKeys := worker.NewIdempotencyKeys(log, keeper, cfg.Idempotency.PurgeInterval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		idempotencyKeys.Run(ctx)
+	}()
+
+	relay := worker.NewRelay(log, userService, sinks,
+		entity.RetryPolicy{
+			MaxAttempts: cfg.Outbox.MaxAttempts,
+			BaseDelay:   cfg.Outbox.RetryBaseDelay,
+			MaxDelay:    cfg.Outbox.RetryMaxDelay,
+		},
+		cfg.Outbox.BatchSize, cfg.Outbox.Lease, cfg.Outbox.Interval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relay.Run(ctx)
+	}()
+
+	webhooks := worker.NewWebhooks(log, userService,
+		webhook.NewSender(webhook.NewClient(cfg.Webhooks.Timeout)),
+		entity.RetryPolicy{
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			BaseDelay:   cfg.Webhooks.RetryBaseDelay,
+			MaxDelay:    cfg.Webhooks.RetryMaxDelay,
+		},
+		cfg.Webhooks.BatchSize, cfg.Webhooks.Lease, cfg.Webhooks.Interval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		webhooks.Run(ctx)
+	}()
+
+	userChanges := worker.NewUserChanges(log, postgres.NewListener(cfg.Postgres.DSN), stream, cfg.Stream.RetryDelay)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		userChanges.Run(ctx)
+	}()
+
+	log.InfoF("server started on port %d, grpc on port %d", cfg.HTTP.Port, cfg.GRPC.Port)
 
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
@@ -65,10 +220,151 @@ func main() {
 
 	log.InfoF("got OS signal: %s\n", sig)
 
-	if err := restController.Stop(ctx); err != nil {
-		log.ErrorF("failed to stop server: %s", err.Error())
-		return
-	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+	defer shutdownCancel()
+
+	stopWg := &sync.WaitGroup{}
 
+	stopWg.Add(1)
+	go func() {
+		defer stopWg.Done()
+		if err := restController.Stop(shutdownCtx); err != nil {
+			log.ErrorF("failed to stop server: %s", err.Error())
+		}
+	}()
+
+	stopWg.Add(1)
+	go func() {
+		defer stopWg.Done()
+		if err := grpcController.Stop(shutdownCtx); err != nil {
+			log.ErrorF("failed to stop grpc server: %s", err.Error())
+		}
+	}()
+
+	stopWg.Wait()
+
+	cancel()
 	wg.Wait()
 }
+
+// newVerifier returns nil when authentication is disabled.
+func newVerifier(ctx context.Context, log logger.Logger, cfg config.Auth) (*auth.Verifier, error) {
+	if !cfg.Enabled {
+		log.WarnF("authentication is disabled, every request is accepted")
+		return nil, nil
+	}
+
+	var source auth.KeySource
+
+	switch {
+	case cfg.JWKSFile != "":
+		source = auth.NewJWKSFile(cfg.JWKSFile)
+	case cfg.PEMFile != "":
+		source = auth.NewPEMFile(cfg.PEMFile, cfg.PEMKeyID)
+	default:
+		return nil, errors.New("authentication is enabled but neither AUTH_JWKS_FILE nor AUTH_PEM_FILE is set")
+	}
+
+	return auth.NewVerifier(ctx, log, source, auth.Options{
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+		Leeway:   cfg.Leeway,
+	})
+}
+
+// newAuthorizer returns nil when authentication is disabled: without a
+// verified caller there is nothing to check roles against.
+func newAuthorizer(log logger.Logger, cfg config.Auth) (service.Authorizer, error) {
+	if !cfg.Enabled {
+		log.WarnF("authorization is disabled, every caller may do anything")
+		return nil, nil
+	}
+
+	return policy.Load(cfg.PolicyFile)
+}
+
+// newRiskEngine returns nil when risk screening is disabled.
+func newRiskEngine(log logger.Logger, cfg config.Risk) *service.RiskEngine {
+	if !cfg.Enabled {
+		log.WarnF("risk screening is disabled, every balance operation is allowed")
+		return nil
+	}
+
+	return service.NewRiskEngine(
+		service.AmountRule{Review: cfg.ReviewAmount, Deny: cfg.DenyAmount},
+		service.VelocityRule{Window: cfg.VelocityWindow, Max: cfg.VelocityMax},
+		service.NewAccountRule{Age: cfg.NewAccountAge, Amount: cfg.NewAccountAmount},
+		service.FanOutRule{Window: cfg.FanOutWindow, Max: cfg.FanOutMax},
+	)
+}
+
+// newLimiter returns nil when rate limiting is disabled.
+func newLimiter(log logger.Logger, cfg config.RateLimit, repo *repository.Repository) (*ratelimit.Limiter, error) {
+	if !cfg.Enabled {
+		log.WarnF("rate limiting is disabled")
+		return nil, nil
+	}
+
+	var store ratelimit.Store
+
+	switch cfg.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = ratelimit.NewPostgresStore(repo)
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+
+	return ratelimit.NewLimiter(log, store, cfg.Default, cfg.Address, cfg.Routes)
+}
+
+// loadExchangeRates imports the rates in the CSV file at path, if any.
+func loadExchangeRates(ctx context.Context, log logger.Logger, path string, svc *service.Service) error {
+	if path == "" {
+		return nil
+	}
+
+	loaded, err := rates.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.ImportExchangeRates(ctx, loaded); err != nil {
+		return err
+	}
+
+	log.InfoF("loaded %d exchange rates from %s", len(loaded), path)
+
+	return nil
+}
+
+func newSinks(cfg config.Outbox) ([]worker.Sink, func(), error) {
+	var (
+		sinks  []worker.Sink
+		closer = func() {}
+	)
+
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "stdout":
+			sinks = append(sinks, events.NewNDJSONSink(os.Stdout))
+		case "file":
+			f, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				closer()
+				return nil, nil, fmt.Errorf("failed to open outbox file: %w", err)
+			}
+
+			sinks = append(sinks, events.NewNDJSONSink(f))
+			closer = func() { _ = f.Close() }
+		case "webhook":
+			sinks = append(sinks, events.NewWebhookSink(cfg.WebhookURL, &http.Client{Timeout: cfg.WebhookTimeout}))
+		default:
+			closer()
+			return nil, nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+
+	return sinks, closer, nil
+}
@@ -0,0 +1,369 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"users-app/internal/auth"
+	"users-app/internal/entity"
+	"users-app/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+	secret []byte
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return testKeys{rsa: rsaKey, ec: ecKey, secret: []byte("0123456789abcdef0123456789abcdef")}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// jwks renders the public halves of keys as a JWKS document.
+func (k testKeys) jwks() []byte {
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(k.ec.X.Bytes()), "y": b64(k.ec.Y.Bytes())},
+		{"kty": "oct", "kid": "hmac-1", "alg": "HS256", "k": b64(k.secret)},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": b64(k.rsa.N.Bytes()), "e": "AQAB"},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+	}}
+
+	data, _ := json.Marshal(doc)
+
+	return data
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"iss":   "https://issuer.example.com",
+		"aud":   "users-app",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"roles": []string{"admin"},
+		"scope": "users:read users:write",
+	}
+}
+
+func newVerifier(t *testing.T, source auth.KeySource) *auth.Verifier {
+	t.Helper()
+
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	v, err := auth.NewVerifier(context.Background(), log, source, auth.Options{
+		Issuer:   "https://issuer.example.com",
+		Audience: "users-app",
+		Leeway:   time.Second,
+	})
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newVerifier(t, auth.NewJWKSFile(writeFile(t, "jwks.json", keys.jwks())))
+
+	with := func(change func(c jwt.MapClaims)) jwt.MapClaims {
+		c := validClaims()
+		change(c)
+
+		return c
+	}
+
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	require.NoError(t, err)
+
+	otherRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "rs256",
+			token: sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims()),
+		},
+		{
+			name:  "es256",
+			token: sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims()),
+		},
+		{
+			name:  "hs256",
+			token: sign(t, jwt.SigningMethodHS256, "hmac-1", keys.secret, validClaims()),
+		},
+		{
+			name:  "no kid with a single key of the algorithm",
+			token: sign(t, jwt.SigningMethodES256, "", keys.ec, validClaims()),
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "key named by kid has another algorithm",
+			token:   sign(t, jwt.SigningMethodHS256, "rsa-1", keys.secret, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "rsa public key used as hmac secret",
+			token:   sign(t, jwt.SigningMethodHS256, "", rsaPublic, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "signed by another key",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", otherRSA, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "encryption key",
+			token:   sign(t, jwt.SigningMethodRS256, "enc-1", keys.rsa, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "unsigned",
+			token:   sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() })),
+			wantErr: true,
+		},
+		{
+			name:    "no expiry",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(func(c jwt.MapClaims) { delete(c, "exp") })),
+			wantErr: true,
+		},
+		{
+			name:    "other issuer",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" })),
+			wantErr: true,
+		},
+		{
+			name:    "other audience",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(func(c jwt.MapClaims) { c["aud"] = "billing" })),
+			wantErr: true,
+		},
+		{
+			name:    "no subject",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(func(c jwt.MapClaims) { delete(c, "sub") })),
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   "not.a.token",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			claims, err := v.Verify(tt.token)
+			if tt.wantErr {
+				r.ErrorIs(err, entity.ErrUnauthenticated)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal("user-1", claims.Subject)
+			r.Equal("https://issuer.example.com", claims.Issuer)
+			r.Equal([]string{"users-app"}, claims.Audience)
+			r.Equal([]string{"admin"}, claims.Roles)
+			r.Equal([]string{"users:read", "users:write"}, claims.Scopes)
+			r.True(claims.HasRole("admin"))
+			r.WithinDuration(time.Now().Add(time.Hour), claims.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestVerifier_PEM(t *testing.T) {
+	r := require.New(t)
+	keys := newTestKeys(t)
+
+	der, err := x509.MarshalPKIXPublicKey(&keys.ec.PublicKey)
+	r.NoError(err)
+
+	path := writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	v := newVerifier(t, auth.NewPEMFile(path, "ec-1"))
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims()))
+	r.NoError(err)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, "ec-2", keys.ec, validClaims()))
+	r.ErrorIs(err, entity.ErrUnauthenticated)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodRS256, "ec-1", keys.rsa, validClaims()))
+	r.ErrorIs(err, entity.ErrUnauthenticated)
+}
+
+type fakeSource struct {
+	keys *auth.KeySet
+	err  error
+}
+
+func (s *fakeSource) Load(_ context.Context) (*auth.KeySet, error) {
+	return s.keys, s.err
+}
+
+func TestVerifier_Reload(t *testing.T) {
+	r := require.New(t)
+	keys := newTestKeys(t)
+
+	source := &fakeSource{keys: auth.NewKeySet(auth.Key{ID: "hmac-1", Algorithm: auth.HS256, Public: keys.secret})}
+	v := newVerifier(t, source)
+
+	oldToken := sign(t, jwt.SigningMethodHS256, "hmac-1", keys.secret, validClaims())
+	newToken := sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims())
+
+	_, err := v.Verify(newToken)
+	r.ErrorIs(err, entity.ErrUnauthenticated)
+
+	// A failed reload keeps the keys in use.
+	source.err = errors.New("unavailable")
+	r.Error(v.Reload(context.Background()))
+
+	_, err = v.Verify(oldToken)
+	r.NoError(err)
+
+	// Rotated keys replace the old ones.
+	source.keys = auth.NewKeySet(auth.Key{ID: "rsa-1", Algorithm: auth.RS256, Public: &keys.rsa.PublicKey})
+	source.err = nil
+	r.NoError(v.Reload(context.Background()))
+
+	_, err = v.Verify(newToken)
+	r.NoError(err)
+
+	_, err = v.Verify(oldToken)
+	r.ErrorIs(err, entity.ErrUnauthenticated)
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+
+	tests := []struct {
+		name    string
+		data    string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "unusable keys are skipped",
+			data:    string(keys.jwks()),
+			wantLen: 3,
+		},
+		{
+			name:    "no usable keys",
+			data:    `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AA"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "algorithm contradicts the key type",
+			data:    `{"keys":[{"kty":"oct","alg":"RS256","k":"c2VjcmV0"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "point off the curve",
+			data:    `{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			data:    `keys`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			set, err := auth.ParseJWKS([]byte(tt.data))
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.wantLen, set.Len())
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	r := require.New(t)
+
+	token, ok := auth.BearerToken("Bearer abc.def.ghi")
+	r.True(ok)
+	r.Equal("abc.def.ghi", token)
+
+	token, ok = auth.BearerToken("bearer abc")
+	r.True(ok)
+	r.Equal("abc", token)
+
+	_, ok = auth.BearerToken("Basic dXNlcjpwYXNz")
+	r.False(ok)
+
+	_, ok = auth.BearerToken("Bearer ")
+	r.False(ok)
+
+	_, ok = auth.BearerToken("")
+	r.False(ok)
+}
@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Supported signing algorithms.
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+	HS256 = "HS256"
+)
+
+// Key verifies the signatures of one algorithm. Public is an *rsa.PublicKey
+// for RS256, an *ecdsa.PublicKey for ES256 and the shared secret as []byte
+// for HS256.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    any
+}
+
+type KeySet struct {
+	keys []Key
+}
+
+func NewKeySet(keys ...Key) *KeySet {
+	return &KeySet{keys: keys}
+}
+
+func (s *KeySet) Len() int {
+	return len(s.keys)
+}
+
+// Lookup finds the key a token signed with alg refers to. A token without a
+// key ID matches when exactly one key has the algorithm.
+func (s *KeySet) Lookup(kid, alg string) (Key, error) {
+	var found []Key
+
+	for _, k := range s.keys {
+		if k.Algorithm != alg || (kid != "" && k.ID != kid) {
+			continue
+		}
+
+		found = append(found, k)
+	}
+
+	switch len(found) {
+	case 0:
+		return Key{}, fmt.Errorf("no %s key with id %q", alg, kid)
+	case 1:
+		return found[0], nil
+	default:
+		return Key{}, fmt.Errorf("%d %s keys match id %q", len(found), alg, kid)
+	}
+}
+
+// KeySource loads the verification keys. It is called again on every reload,
+// so rotated keys are picked up without a restart.
+type KeySource interface {
+	Load(ctx context.Context) (*KeySet, error)
+}
+
+// JWKSFile reads an RFC 7517 JSON Web Key Set. RSA, P-256 EC and symmetric
+// ("oct") keys are used; keys for encryption or of other types are skipped.
+type JWKSFile struct {
+	path string
+}
+
+func NewJWKSFile(path string) *JWKSFile {
+	return &JWKSFile{path: path}
+}
+
+func (f *JWKSFile) Load(_ context.Context) (*KeySet, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	return ParseJWKS(data)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.key()
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d (%q): %w", i, k.Kid, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no usable signing keys")
+	}
+
+	return NewKeySet(keys...), nil
+}
+
+var errUnsupportedKey = errors.New("unsupported key")
+
+func (k jwk) key() (Key, error) {
+	key := Key{ID: k.Kid, Algorithm: k.Alg}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return Key{}, errors.New("invalid exponent")
+		}
+
+		key.Public = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		key.Algorithm = defaultAlg(k.Alg, RS256)
+	case "EC":
+		if k.Crv != "P-256" {
+			return Key{}, errUnsupportedKey
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+
+		if x.BitLen() > 256 || y.BitLen() > 256 {
+			return Key{}, errors.New("invalid ec point")
+		}
+
+		// Reject points off the curve before they reach the verifier.
+		point := make([]byte, 65)
+		point[0] = 4
+		x.FillBytes(point[1:33])
+		y.FillBytes(point[33:])
+
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return Key{}, fmt.Errorf("invalid ec point: %w", err)
+		}
+
+		key.Public = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		key.Algorithm = defaultAlg(k.Alg, ES256)
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return Key{}, errors.New("invalid secret")
+		}
+
+		key.Public = secret
+		key.Algorithm = defaultAlg(k.Alg, HS256)
+	default:
+		return Key{}, errUnsupportedKey
+	}
+
+	if !keyMatchesAlg(key) {
+		return Key{}, errUnsupportedKey
+	}
+
+	return key, nil
+}
+
+// PEMFile reads a PEM encoded RSA or P-256 EC public key, or a certificate
+// holding one. The algorithm follows from the key type.
+type PEMFile struct {
+	path string
+	kid  string
+}
+
+// NewPEMFile returns a source for the key at path. kid may be empty, in which
+// case tokens must not name a key ID other than empty.
+func NewPEMFile(path, kid string) *PEMFile {
+	return &PEMFile{path: path, kid: kid}
+}
+
+func (f *PEMFile) Load(_ context.Context) (*KeySet, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pem file: %w", err)
+	}
+
+	key, err := ParsePEM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key.ID = f.kid
+
+	return NewKeySet(key), nil
+}
+
+func ParsePEM(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no pem block found")
+	}
+
+	var (
+		pub any
+		err error
+	)
+
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			pub = cert.PublicKey
+		}
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse pem key: %w", err)
+	}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return Key{Algorithm: RS256, Public: pub}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return Key{}, errors.New("only P-256 ec keys are supported")
+		}
+
+		return Key{Algorithm: ES256, Public: pub}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported pem key type %T", pub)
+	}
+}
+
+func keyMatchesAlg(k Key) bool {
+	switch k.Public.(type) {
+	case *rsa.PublicKey:
+		return k.Algorithm == RS256
+	case *ecdsa.PublicKey:
+		return k.Algorithm == ES256
+	case []byte:
+		return k.Algorithm == HS256
+	default:
+		return false
+	}
+}
+
+func defaultAlg(alg, def string) string {
+	if alg == "" {
+		return def
+	}
+
+	return alg
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("not a base64url encoded integer")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,159 @@
+// Package auth verifies the JWT bearer tokens callers authenticate with.
+//
+// Tokens must be signed with RS256, ES256 or HS256 by a key from the
+// configured KeySource, and carry an expiry. The algorithm a token claims is
+// only accepted when it is the algorithm of the key it names, so a public key
+// can never be used as an HMAC secret.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"users-app/internal/entity"
+	"users-app/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Options struct {
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+type Verifier struct {
+	log    logger.Logger
+	source KeySource
+	parser *jwt.Parser
+	keys   atomic.Pointer[KeySet]
+}
+
+// NewVerifier loads the keys once; the verifier is unusable without them.
+func NewVerifier(ctx context.Context, log logger.Logger, source KeySource, opts Options) (*Verifier, error) {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{RS256, ES256, HS256}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	v := &Verifier{
+		log:    log,
+		source: source,
+		parser: jwt.NewParser(parserOpts...),
+	}
+
+	if err := v.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Reload replaces the keys with those the source returns now. On failure the
+// previous keys stay in use.
+func (v *Verifier) Reload(ctx context.Context) error {
+	keys, err := v.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load verification keys: %w", err)
+	}
+
+	v.keys.Store(keys)
+
+	return nil
+}
+
+// Run reloads the keys every interval until ctx is done.
+func (v *Verifier) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.Reload(ctx); err != nil {
+				v.log.ErrorF("failed to reload auth keys, keeping the previous ones: %s", err.Error())
+			}
+		}
+	}
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+
+	Roles []string `json:"roles,omitempty"`
+	Scope string   `json:"scope,omitempty"`
+}
+
+// Verify checks the token and returns its claims. Every error matches
+// entity.ErrUnauthenticated.
+func (v *Verifier) Verify(token string) (entity.Claims, error) {
+	var claims tokenClaims
+
+	_, err := v.parser.ParseWithClaims(token, &claims, v.keyFunc)
+	if err != nil {
+		return entity.Claims{}, fmt.Errorf("%w: %w", entity.ErrUnauthenticated, err)
+	}
+
+	if claims.Subject == "" {
+		return entity.Claims{}, fmt.Errorf("%w: token has no subject", entity.ErrUnauthenticated)
+	}
+
+	result := entity.Claims{
+		Subject:  claims.Subject,
+		Issuer:   claims.Issuer,
+		Audience: claims.Audience,
+		Roles:    claims.Roles,
+		Scopes:   strings.Fields(claims.Scope),
+	}
+
+	if claims.ExpiresAt != nil {
+		result.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return result, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := v.keys.Load().Lookup(kid, token.Method.Alg())
+	if err != nil {
+		return nil, err
+	}
+
+	if !keyMatchesAlg(key) {
+		return nil, errors.New("key does not match the token algorithm")
+	}
+
+	return key.Public, nil
+}
+
+// BearerToken extracts the token from an Authorization header value. ok is
+// false when the header is missing or uses another scheme.
+func BearerToken(header string) (token string, ok bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"users-app/internal/auth"
+	"users-app/internal/controller/grpc/pb"
+	"users-app/pkg/config"
+	"users-app/pkg/logger"
+
+	"google.golang.org/grpc"
+)
+
+type Controller struct {
+	cfg *config.Config
+	log logger.Logger
+	srv *grpc.Server
+}
+
+func New(cfg *config.Config, log logger.Logger, verifier *auth.Verifier, userService UserService) *Controller {
+	i := NewInterceptor(log, verifier, userService)
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(i.RequestID, i.Recover, i.Log, i.Actor, i.APIKey, i.Authenticate))
+
+	pb.RegisterUserServiceServer(server, NewServer(userService))
+
+	return &Controller{
+		cfg: cfg,
+		log: log,
+		srv: server,
+	}
+}
+
+func (c *Controller) Run() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", c.cfg.GRPC.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	return c.srv.Serve(lis)
+}
+
+// Stop waits for pending calls to finish, and cancels them once ctx is done.
+func (c *Controller) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		c.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		c.srv.Stop()
+		<-done
+
+		return ctx.Err()
+	}
+}
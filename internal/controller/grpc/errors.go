@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"errors"
+	"strconv"
+	"users-app/internal/entity"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type statusType struct {
+	err  error
+	code codes.Code
+	msg  string
+}
+
+// statusTypes maps errors to status codes the same way problemTypes does for
+// the REST API. The first entry matching with errors.Is wins.
+var statusTypes = []statusType{
+	{entity.ErrUnauthenticated, codes.Unauthenticated, "unauthenticated"},
+	{entity.ErrForbidden, codes.PermissionDenied, "permission denied"},
+	{entity.ErrValidation, codes.InvalidArgument, "validation failed"},
+	{entity.ErrNotFound, codes.NotFound, "user not found"},
+	{entity.ErrAlreadyExists, codes.AlreadyExists, "user already exists"},
+	{entity.ErrConflict, codes.Aborted, "version conflict"},
+	{entity.ErrInvalidCursor, codes.InvalidArgument, "invalid cursor"},
+	{entity.ErrInvalidPatch, codes.InvalidArgument, "invalid patch"},
+	{entity.ErrInvalidTransfer, codes.InvalidArgument, "invalid transfer"},
+	{entity.ErrInsufficientFunds, codes.FailedPrecondition, "insufficient funds"},
+	{entity.ErrHoldNotActive, codes.FailedPrecondition, "hold not active"},
+	{entity.ErrNoExchangeRate, codes.FailedPrecondition, "no exchange rate"},
+	{entity.ErrLimitExceeded, codes.FailedPrecondition, "limit exceeded"},
+	{entity.ErrOperationDenied, codes.FailedPrecondition, "operation denied"},
+	{entity.ErrPendingReview, codes.FailedPrecondition, "operation pending review"},
+}
+
+var internalStatus = statusType{nil, codes.Internal, "internal error"}
+
+func lookupStatus(err error) statusType {
+	for _, s := range statusTypes {
+		if errors.Is(err, s.err) {
+			return s
+		}
+	}
+
+	return internalStatus
+}
+
+// toStatus converts a service error to a gRPC status error. As with problem
+// details, the underlying error text is only logged, never sent to the client;
+// validation errors carry their fields as a BadRequest detail, exceeded limits
+// what is left of them as a QuotaFailure detail, and operations held for
+// review the id of their review as an ErrorInfo detail.
+func toStatus(err error) error {
+	st := lookupStatus(err)
+	s := status.New(st.code, st.msg)
+
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) {
+		details := &errdetails.BadRequest{
+			FieldViolations: make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErr.Fields)),
+		}
+
+		for _, f := range validationErr.Fields {
+			details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+				Reason:      f.Code,
+			})
+		}
+
+		if withDetails, detailsErr := s.WithDetails(details); detailsErr == nil {
+			s = withDetails
+		}
+	}
+
+	var limitErr *entity.LimitError
+	if errors.As(err, &limitErr) {
+		details := &errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{
+				Subject:     string(limitErr.Limit),
+				Description: limitErr.Remaining.String() + " " + limitErr.Currency + " left",
+			}},
+		}
+
+		if withDetails, detailsErr := s.WithDetails(details); detailsErr == nil {
+			s = withDetails
+		}
+	}
+
+	var reviewErr *entity.ReviewError
+	if errors.As(err, &reviewErr) {
+		details := &errdetails.ErrorInfo{
+			Reason:   "PENDING_REVIEW",
+			Domain:   "users-app",
+			Metadata: map[string]string{"review_id": strconv.FormatInt(reviewErr.Review.ID, 10)},
+		}
+
+		if withDetails, detailsErr := s.WithDetails(details); detailsErr == nil {
+			s = withDetails
+		}
+	}
+
+	return &statusError{status: s, err: err}
+}
+
+// statusError keeps the service error next to the status sent to the client
+// so that the logging interceptor can report what actually went wrong.
+type statusError struct {
+	status *status.Status
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func (e *statusError) GRPCStatus() *status.Status {
+	return e.status
+}
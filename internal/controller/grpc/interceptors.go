@@ -0,0 +1,184 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"users-app/internal/auth"
+	"users-app/internal/entity"
+	"users-app/pkg/logger"
+
+	"github.com/gofrs/uuid/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDKey = "x-request-id"
+	apiKeyKey    = "x-api-key"
+)
+
+// APIKeyAuthenticator resolves the keys sent in the x-api-key metadata.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (entity.APIKey, error)
+}
+
+// Interceptor is the gRPC counterpart of middlewares.Middleware.
+type Interceptor struct {
+	log      logger.Logger
+	verifier *auth.Verifier
+	apiKeys  APIKeyAuthenticator
+}
+
+// NewInterceptor returns the interceptors. A nil verifier disables bearer
+// token authentication.
+func NewInterceptor(log logger.Logger, verifier *auth.Verifier, apiKeys APIKeyAuthenticator) *Interceptor {
+	return &Interceptor{
+		log:      log,
+		verifier: verifier,
+		apiKeys:  apiKeys,
+	}
+}
+
+// RequestID takes the request ID from the x-request-id metadata, generating
+// one when the caller did not send it, and echoes it back in the header.
+func (i *Interceptor) RequestID(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	var requestID string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+
+	if requestID == "" {
+		requestID = uuid.Must(uuid.NewV4()).String()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	return handler(entity.WithRequestID(ctx, requestID), req)
+}
+
+// Recover turns a panic in a handler into an Internal status instead of
+// bringing the server down.
+func (i *Interceptor) Recover(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			i.log.ErrorF("panic in %s: %v\n%s", info.FullMethod, p, debug.Stack())
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func (i *Interceptor) Log(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	var headers string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, v := range md {
+			if k == "authorization" || k == "cookie" || k == apiKeyKey {
+				continue
+			}
+
+			headers += fmt.Sprintf("%s: %s,\n", k, v)
+		}
+	}
+
+	i.log.InfoF("incoming call: method = %s, metadata = %s, user_ip = %s",
+		info.FullMethod, headers, peerAddr(ctx))
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		i.log.ErrorF("grpc error: %s, code = %s, request_id = %s",
+			err.Error(), status.Code(err), entity.RequestIDFromContext(ctx))
+	}
+
+	return resp, err
+}
+
+// Actor attributes the call to its peer, like middlewares.Middleware.Actor.
+func (i *Interceptor) Actor(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	ctx = entity.WithActor(ctx, entity.Actor{Kind: entity.ActorAnonymous, ID: peerAddr(ctx)})
+
+	return handler(ctx, req)
+}
+
+// APIKey authenticates calls carrying x-api-key metadata, like
+// middlewares.Middleware.APIKey. It must run after Actor.
+func (i *Interceptor) APIKey(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	var plain string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(apiKeyKey); len(values) > 0 {
+			plain = values[0]
+		}
+	}
+
+	if plain == "" {
+		return handler(ctx, req)
+	}
+
+	key, err := i.apiKeys.AuthenticateAPIKey(ctx, plain)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	i.log.InfoF("call authenticated with api key: method = %s, key_id = %s, prefix = %s, name = %s, request_id = %s",
+		info.FullMethod, key.ID, key.Prefix, key.Name, entity.RequestIDFromContext(ctx))
+
+	ctx = entity.WithClaims(ctx, key.Claims())
+	ctx = entity.WithActor(ctx, key.Actor())
+
+	return handler(ctx, req)
+}
+
+// Authenticate requires a valid bearer token in the authorization metadata,
+// like middlewares.Middleware.Authenticate. Calls already authenticated by
+// APIKey are passed through. It must run after Actor.
+func (i *Interceptor) Authenticate(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	if _, ok := entity.ClaimsFromContext(ctx); ok || i.verifier == nil {
+		return handler(ctx, req)
+	}
+
+	var token string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token, _ = auth.BearerToken(values[0])
+		}
+	}
+
+	if token == "" {
+		return nil, toStatus(fmt.Errorf("%w: no bearer token", entity.ErrUnauthenticated))
+	}
+
+	claims, err := i.verifier.Verify(token)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	ctx = entity.WithClaims(ctx, claims)
+	ctx = entity.WithActor(ctx, entity.Actor{Kind: entity.ActorUser, ID: claims.Subject})
+
+	return handler(ctx, req)
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	return p.Addr.String()
+}
@@ -0,0 +1,5 @@
+// Package pb holds the protobuf definitions of the gRPC API and the code
+// generated from them.
+package pb
+
+//go:generate buf generate --template buf.gen.yaml
@@ -0,0 +1,316 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"users-app/internal/controller/grpc/pb"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+//go:generate go run go.uber.org/mock/mockgen@latest -source=server.go -destination=../../mocks/grpc.go -package=mocks -typed -mock_names=UserService=MockGRPCUserService
+type UserService interface {
+	GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error)
+	GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error)
+	CreateUser(ctx context.Context, user entity.User) error
+	UpdateUser(ctx context.Context, user entity.User) (entity.User, error)
+	PatchUser(ctx context.Context, id uuid.UUID, patch entity.UserPatch) (entity.User, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	ListUsers(ctx context.Context, params entity.ListUsersParams) (entity.UserPage, error)
+	Transfer(ctx context.Context, t entity.Transfer) (entity.Transfer, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (entity.APIKey, error)
+}
+
+// Server implements pb.UserServiceServer on top of the same service as the
+// REST handler.
+type Server struct {
+	pb.UnimplementedUserServiceServer
+
+	userService UserService
+}
+
+func NewServer(userService UserService) *Server {
+	return &Server{
+		userService: userService,
+	}
+}
+
+func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	getUser := s.userService.GetUserByID
+	if req.GetIncludeDeleted() {
+		getUser = s.userService.GetUserByIDWithDeleted
+	}
+
+	user, err := getUser(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPBUser(user), nil
+}
+
+func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if req.GetId() != "" {
+		if id, err = parseID("id", req.GetId()); err != nil {
+			return nil, err
+		}
+	}
+
+	balance, err := parseDecimal("balance", req.GetBalance())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.CreateUser(ctx, entity.User{
+		ID:      id,
+		Name:    req.GetName(),
+		Email:   req.GetEmail(),
+		Age:     int(req.GetAge()),
+		Balance: balance,
+	}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	user, err := s.userService.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPBUser(user), nil
+}
+
+// UpdateUser replaces the user. An omitted balance is kept, by patching the
+// other fields under the same row lock as a replacement, so that a balance
+// changed meanwhile is not overwritten.
+func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Balance == nil {
+		patch, err := json.Marshal(map[string]any{"name": req.GetName(), "email": req.GetEmail(), "age": req.GetAge()})
+		if err != nil {
+			return nil, toStatus(fmt.Errorf("failed to marshal user patch: %w", err))
+		}
+
+		user, err := s.userService.PatchUser(ctx, id, entity.UserPatch{
+			Format:   entity.MergePatch,
+			Document: patch,
+			Version:  req.GetVersion(),
+		})
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		return toPBUser(user), nil
+	}
+
+	balance, err := parseDecimal("balance", req.GetBalance())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.UpdateUser(ctx, entity.User{
+		ID:      id,
+		Name:    req.GetName(),
+		Email:   req.GetEmail(),
+		Age:     int(req.GetAge()),
+		Balance: balance,
+		Version: req.GetVersion(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toPBUser(user), nil
+}
+
+func (s *Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.DeleteUser(ctx, id); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DeleteUserResponse{}, nil
+}
+
+func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	params, err := toListUsersParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.userService.ListUsers(ctx, params)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.ListUsersResponse{
+		Items:      make([]*pb.User, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, user := range page.Items {
+		resp.Items = append(resp.Items, toPBUser(user))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) Transfer(ctx context.Context, req *pb.TransferRequest) (*pb.TransferResponse, error) {
+	from, err := parseID("from_user_id", req.GetFromUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := parseID("to_user_id", req.GetToUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseDecimal("amount", req.GetAmount())
+	if err != nil {
+		return nil, err
+	}
+
+	// The protocol has no currencies yet: transfers stay in the base one.
+	transfer, err := s.userService.Transfer(ctx, entity.Transfer{FromUserID: from, ToUserID: to, Amount: amount})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.TransferResponse{
+		Id:         transfer.ID.String(),
+		FromUserId: transfer.FromUserID.String(),
+		ToUserId:   transfer.ToUserID.String(),
+		Amount:     transfer.Amount.String(),
+		CreatedAt:  timestamppb.New(transfer.CreatedAt),
+	}, nil
+}
+
+func toListUsersParams(req *pb.ListUsersRequest) (entity.ListUsersParams, error) {
+	params := entity.ListUsersParams{
+		Filter: entity.UserFilter{
+			NamePrefix:     req.GetNamePrefix(),
+			EmailDomain:    req.GetEmailDomain(),
+			IncludeDeleted: req.GetIncludeDeleted(),
+		},
+		Sort:   entity.UserSort{Field: entity.SortByName},
+		Cursor: req.GetCursor(),
+		Limit:  defaultPageLimit,
+	}
+
+	if sort := req.GetSort(); sort != "" {
+		field, desc := strings.CutPrefix(sort, "-")
+
+		params.Sort = entity.UserSort{Field: entity.SortField(field), Desc: desc}
+		if !params.Sort.Field.Valid() {
+			return params, status.Errorf(codes.InvalidArgument, "sorting by %s is not supported", field)
+		}
+	}
+
+	if limit := req.GetLimit(); limit != 0 {
+		if limit < 1 || limit > maxPageLimit {
+			return params, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxPageLimit)
+		}
+
+		params.Limit = int(limit)
+	}
+
+	if req.MinAge != nil {
+		minAge := int(req.GetMinAge())
+		params.Filter.MinAge = &minAge
+	}
+
+	if req.MaxAge != nil {
+		maxAge := int(req.GetMaxAge())
+		params.Filter.MaxAge = &maxAge
+	}
+
+	if req.MinBalance != nil {
+		minBalance, err := parseDecimal("min_balance", req.GetMinBalance())
+		if err != nil {
+			return params, err
+		}
+
+		params.Filter.MinBalance = &minBalance
+	}
+
+	if req.MaxBalance != nil {
+		maxBalance, err := parseDecimal("max_balance", req.GetMaxBalance())
+		if err != nil {
+			return params, err
+		}
+
+		params.Filter.MaxBalance = &maxBalance
+	}
+
+	return params, nil
+}
+
+func toPBUser(user entity.User) *pb.User {
+	u := &pb.User{
+		Id:      user.ID.String(),
+		Name:    user.Name,
+		Email:   user.Email,
+		Age:     int32(user.Age),
+		Balance: user.Balance.String(),
+		Version: user.Version,
+	}
+
+	if user.DeletedAt != nil {
+		u.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
+
+	return u
+}
+
+func parseID(field, id string) (uuid.UUID, error) {
+	parsed, err := uuid.FromString(id)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "%s %q is not a valid id", field, id)
+	}
+
+	return parsed, nil
+}
+
+// parseDecimal parses a decimal string; an empty string is zero.
+func parseDecimal(field, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Decimal{}, status.Errorf(codes.InvalidArgument, "%s %q is not a valid decimal", field, value)
+	}
+
+	return d, nil
+}
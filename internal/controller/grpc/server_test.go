@@ -0,0 +1,346 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+	"users-app/internal/auth"
+	"users-app/internal/controller/grpc/pb"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/pkg/logger"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, userService UserService) pb.UserServiceClient {
+	t.Helper()
+
+	return newAuthTestClient(t, userService, nil)
+}
+
+func newAuthTestClient(t *testing.T, userService UserService, verifier *auth.Verifier) pb.UserServiceClient {
+	t.Helper()
+
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	i := NewInterceptor(log, verifier, userService)
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(i.RequestID, i.Recover, i.Log, i.Actor, i.APIKey, i.Authenticate))
+	pb.RegisterUserServiceServer(srv, NewServer(userService))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewUserServiceClient(conn)
+}
+
+func TestServer_GetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockGRPCUserService(ctrl)
+	client := newTestClient(t, mockUserService)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name         string
+		id           string
+		mockBehavior func()
+		expectedCode codes.Code
+	}{
+		{
+			name: "success",
+			id:   userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+					Return(entity.User{ID: userID, Name: "test", Balance: decimal.NewFromInt(10), Version: 2}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "invalid id",
+			id:           "not-a-uuid",
+			mockBehavior: func() {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "not found",
+			id:   userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(entity.User{}, entity.ErrNotFound)
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "panic",
+			id:   userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+					DoAndReturn(func(context.Context, uuid.UUID) (entity.User, error) { panic("boom") })
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			user, err := client.GetUser(context.Background(), &pb.GetUserRequest{Id: tt.id})
+			r.Equal(tt.expectedCode, status.Code(err))
+
+			if tt.expectedCode == codes.OK {
+				r.Equal(userID.String(), user.GetId())
+				r.Equal("10", user.GetBalance())
+				r.Equal(int64(2), user.GetVersion())
+			}
+		})
+	}
+}
+
+func TestServer_CreateUser_Validation(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockGRPCUserService(ctrl)
+	client := newTestClient(t, mockUserService)
+
+	mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(&entity.ValidationError{
+		Fields: []entity.FieldError{{Field: "email", Code: "email", Message: "must be a valid email"}},
+	})
+
+	_, err := client.CreateUser(context.Background(), &pb.CreateUserRequest{Name: "test", Email: "bad"})
+
+	st := status.Convert(err)
+	r.Equal(codes.InvalidArgument, st.Code())
+	r.Equal("validation failed", st.Message())
+	r.Len(st.Details(), 1)
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	r.True(ok)
+	r.Equal("email", badRequest.GetFieldViolations()[0].GetField())
+	r.Equal("email", badRequest.GetFieldViolations()[0].GetReason())
+}
+
+func TestServer_UpdateUser(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockGRPCUserService(ctrl)
+	client := newTestClient(t, mockUserService)
+
+	userID := uuid.Must(uuid.NewV4())
+	updated := entity.User{ID: userID, Name: "new", Email: "new@example.com", Age: 31,
+		Balance: decimal.NewFromInt(10), Version: 4}
+
+	// An omitted balance is kept.
+	mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, patch entity.UserPatch) (entity.User, error) {
+			r.Equal(entity.MergePatch, patch.Format)
+			r.JSONEq(`{"name":"new","email":"new@example.com","age":31}`, string(patch.Document))
+			r.Equal(int64(3), patch.Version)
+
+			return updated, nil
+		})
+
+	user, err := client.UpdateUser(context.Background(), &pb.UpdateUserRequest{
+		Id: userID.String(), Name: "new", Email: "new@example.com", Age: 31, Version: 3,
+	})
+	r.NoError(err)
+	r.Equal("10", user.GetBalance())
+
+	balance := "0"
+
+	mockUserService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, user entity.User) (entity.User, error) {
+			r.True(user.Balance.IsZero())
+			r.Equal(int64(3), user.Version)
+
+			return updated, nil
+		})
+
+	_, err = client.UpdateUser(context.Background(), &pb.UpdateUserRequest{
+		Id: userID.String(), Name: "new", Email: "new@example.com", Age: 31, Balance: &balance, Version: 3,
+	})
+	r.NoError(err)
+}
+
+func TestServer_Transfer(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockGRPCUserService(ctrl)
+	client := newTestClient(t, mockUserService)
+
+	from, to := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	mockUserService.EXPECT().Transfer(gomock.Any(), entity.Transfer{FromUserID: from, ToUserID: to, Amount: decimal.RequireFromString("5.5")}).
+		DoAndReturn(func(ctx context.Context, _ entity.Transfer) (entity.Transfer, error) {
+			r.NotEmpty(entity.RequestIDFromContext(ctx))
+			r.Equal(entity.ActorAnonymous, entity.ActorFromContext(ctx).Kind)
+
+			return entity.Transfer{}, entity.ErrInsufficientFunds
+		})
+
+	var header metadata.MD
+
+	_, err := client.Transfer(context.Background(), &pb.TransferRequest{
+		FromUserId: from.String(),
+		ToUserId:   to.String(),
+		Amount:     "5.5",
+	}, grpc.Header(&header))
+	r.Equal(codes.FailedPrecondition, status.Code(err))
+	r.NotEmpty(header.Get(requestIDKey))
+
+	_, err = client.Transfer(context.Background(), &pb.TransferRequest{
+		FromUserId: from.String(),
+		ToUserId:   to.String(),
+		Amount:     "a lot",
+	})
+	r.Equal(codes.InvalidArgument, status.Code(err))
+
+	mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{},
+		&entity.LimitError{Limit: entity.LimitMonthly, Remaining: decimal.NewFromInt(20), Currency: "USD"})
+
+	_, err = client.Transfer(context.Background(), &pb.TransferRequest{
+		FromUserId: from.String(),
+		ToUserId:   to.String(),
+		Amount:     "50",
+	})
+
+	st := status.Convert(err)
+	r.Equal(codes.FailedPrecondition, st.Code())
+	r.Len(st.Details(), 1)
+
+	quota, ok := st.Details()[0].(*errdetails.QuotaFailure)
+	r.True(ok)
+	r.Equal("monthly", quota.GetViolations()[0].GetSubject())
+	r.Equal("20 USD left", quota.GetViolations()[0].GetDescription())
+
+	mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{},
+		&entity.ReviewError{Review: entity.RiskReview{ID: 7, Status: entity.ReviewPending}})
+
+	_, err = client.Transfer(context.Background(), &pb.TransferRequest{
+		FromUserId: from.String(),
+		ToUserId:   to.String(),
+		Amount:     "50000",
+	})
+
+	st = status.Convert(err)
+	r.Equal(codes.FailedPrecondition, st.Code())
+	r.Len(st.Details(), 1)
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	r.True(ok)
+	r.Equal("PENDING_REVIEW", info.GetReason())
+	r.Equal("7", info.GetMetadata()["review_id"])
+}
+
+func TestInterceptor_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	verifier, err := auth.NewVerifier(context.Background(), log,
+		staticKeys{auth.NewKeySet(auth.Key{Algorithm: auth.HS256, Public: secret})}, auth.Options{})
+	require.NoError(t, err)
+
+	mockUserService := mocks.NewMockGRPCUserService(ctrl)
+	client := newAuthTestClient(t, mockUserService, verifier)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+		DoAndReturn(func(ctx context.Context, id uuid.UUID) (entity.User, error) {
+			require.Equal(t, entity.Actor{Kind: entity.ActorUser, ID: "user-1"}, entity.ActorFromContext(ctx))
+			return entity.User{ID: id}, nil
+		})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(secret)
+	require.NoError(t, err)
+
+	_, err = client.GetUser(context.Background(), &pb.GetUserRequest{Id: userID.String()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token+"x")
+	_, err = client.GetUser(ctx, &pb.GetUserRequest{Id: userID.String()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	_, err = client.GetUser(ctx, &pb.GetUserRequest{Id: userID.String()})
+	require.NoError(t, err)
+}
+
+type staticKeys struct {
+	keys *auth.KeySet
+}
+
+func (s staticKeys) Load(_ context.Context) (*auth.KeySet, error) {
+	return s.keys, nil
+}
+
+func TestInterceptor_APIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockGRPCUserService(ctrl)
+	client := newTestClient(t, mockUserService)
+
+	userID := uuid.Must(uuid.NewV4())
+	key := entity.APIKey{ID: uuid.Must(uuid.NewV4()), Scopes: []entity.Permission{entity.PermUsersRead}}
+
+	mockUserService.EXPECT().AuthenticateAPIKey(gomock.Any(), "ua_good").Return(key, nil)
+	mockUserService.EXPECT().AuthenticateAPIKey(gomock.Any(), "ua_bad").
+		Return(entity.APIKey{}, entity.ErrUnauthenticated)
+	mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+		DoAndReturn(func(ctx context.Context, id uuid.UUID) (entity.User, error) {
+			require.Equal(t, key.Actor(), entity.ActorFromContext(ctx))
+			return entity.User{ID: id}, nil
+		})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "ua_good")
+	_, err := client.GetUser(ctx, &pb.GetUserRequest{Id: userID.String()})
+	require.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "ua_bad")
+	_, err = client.GetUser(ctx, &pb.GetUserRequest{Id: userID.String()})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -4,11 +4,20 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"users-app/internal/auth"
+	"users-app/internal/controller/restAPI/graphql"
 	"users-app/internal/controller/restAPI/handler"
 	"users-app/internal/controller/restAPI/middlewares"
+	"users-app/internal/controller/restAPI/openapi"
 	"users-app/internal/controller/restAPI/router"
+	"users-app/internal/events"
+	"users-app/internal/idempotency"
+	"users-app/internal/ratelimit"
 	"users-app/pkg/config"
+	"users-app/pkg/i18n"
 	"users-app/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type Controller struct {
@@ -18,10 +27,27 @@ type Controller struct {
 	srv         *http.Server
 }
 
-func New(cfg *config.Config, log logger.Logger, userService handler.UserService) *Controller {
-	mw := middlewares.New(log)
-	h := handler.New(log, userService)
-	r := router.New(mw, h)
+func New(ctx context.Context, cfg *config.Config, log logger.Logger, catalog *i18n.Catalog,
+	verifier *auth.Verifier, limiter *ratelimit.Limiter, keeper *idempotency.Keeper,
+	userService handler.UserService, stream *events.Broker) (*Controller, error) {
+	h := handler.New(log, catalog, userService, stream)
+	mw := middlewares.New(log, verifier, userService, limiter, keeper, h.SendErr)
+	gql := graphql.New(log, userService)
+
+	// Responses are only checked in development: a mismatch is logged, and
+	// buffering every response costs too much in production.
+	v, err := openapi.NewValidator(ctx, log, cfg.Dev(), h.SendErr)
+	if err != nil {
+		return nil, err
+	}
+
+	r := router.New(mw, h, gql, v, cfg.HTTP.LegacyRoutes)
+
+	if limiter != nil {
+		if err := checkLimitedRoutes(r, limiter.Routes()); err != nil {
+			return nil, err
+		}
+	}
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.HTTP.Port),
@@ -30,12 +56,38 @@ func New(cfg *config.Config, log logger.Logger, userService handler.UserService)
 		WriteTimeout: cfg.HTTP.WriteTimeout,
 	}
 
+	// Event streams never go idle, so they are ended before Shutdown waits
+	// for open connections.
+	server.RegisterOnShutdown(stream.Close)
+
 	return &Controller{
 		cfg:         cfg,
 		log:         log,
 		userService: userService,
 		srv:         server,
+	}, nil
+}
+
+// checkLimitedRoutes fails on a rate limited route the router does not have,
+// so that a mistyped one is not silently left unlimited.
+func checkLimitedRoutes(r chi.Routes, limited []string) error {
+	routed := make(map[string]bool)
+
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routed[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk routes: %w", err)
 	}
+
+	for _, route := range limited {
+		if !routed[route] {
+			return fmt.Errorf("rate limited route %q does not exist", route)
+		}
+	}
+
+	return nil
 }
 
 func (c *Controller) Run() error {
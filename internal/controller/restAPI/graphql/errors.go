@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"errors"
+	"users-app/internal/entity"
+)
+
+// Errors raised by the resolvers themselves, before the service is reached.
+var (
+	errInvalidID       = errors.New("invalid id")
+	errInvalidArgument = errors.New("invalid argument")
+)
+
+type errorType struct {
+	err  error
+	code string
+	msg  string
+}
+
+// errorTypes maps errors to the codes reported in the extensions of a GraphQL
+// error. The codes are the ones of the REST problem types. The first entry
+// matching with errors.Is wins.
+var errorTypes = []errorType{
+	{entity.ErrUnauthenticated, "unauthorized", "unauthenticated"},
+	{entity.ErrForbidden, "forbidden", "permission denied"},
+	{entity.ErrValidation, "validation_failed", "validation failed"},
+	{entity.ErrNotFound, "not_found", "user not found"},
+	{entity.ErrAlreadyExists, "already_exists", "user already exists"},
+	{entity.ErrConflict, "version_conflict", "version conflict"},
+	{entity.ErrInvalidCursor, "invalid_cursor", "invalid cursor"},
+	{errInvalidID, "invalid_id", "invalid id"},
+	{errInvalidArgument, "invalid_argument", "invalid argument"},
+}
+
+var internalError = errorType{nil, "internal_error", "internal error"}
+
+func lookupError(err error) errorType {
+	for _, t := range errorTypes {
+		if errors.Is(err, t.err) {
+			return t
+		}
+	}
+
+	return internalError
+}
+
+// resolverError is what resolvers return. Only the message and extensions are
+// sent to the client; the wrapped error is logged by the handler.
+type resolverError struct {
+	typ errorType
+	err error
+}
+
+func newResolverError(err error) error {
+	return &resolverError{typ: lookupError(err), err: err}
+}
+
+func (e *resolverError) Error() string {
+	return e.typ.msg
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.err
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.typ.code}
+
+	var validationErr *entity.ValidationError
+	if errors.As(e.err, &validationErr) {
+		ext["errors"] = validationErr.Fields
+	}
+
+	return ext
+}
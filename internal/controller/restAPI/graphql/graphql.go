@@ -0,0 +1,83 @@
+// Package graphql serves POST /api/graphql on top of handler.UserService.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"users-app/internal/controller/restAPI/handler"
+	"users-app/pkg/logger"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	gqllog "github.com/graph-gophers/graphql-go/log"
+)
+
+const maxQueryDepth = 10
+
+//go:embed schema.graphql
+var schemaString string
+
+type Handler struct {
+	log         logger.Logger
+	userService handler.UserService
+	schema      *graphqlgo.Schema
+}
+
+func New(log logger.Logger, userService handler.UserService) *Handler {
+	schema := graphqlgo.MustParseSchema(schemaString, &resolver{userService: userService},
+		graphqlgo.UseStringDescriptions(),
+		graphqlgo.MaxDepth(maxQueryDepth),
+		graphqlgo.Logger(gqllog.LoggerFunc(func(_ context.Context, value any) {
+			log.ErrorF("graphql resolver panic: %v", value)
+		})),
+	)
+
+	return &Handler{
+		log:         log,
+		userService: userService,
+		schema:      schema,
+	}
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendJSON(w, http.StatusBadRequest, &graphqlgo.Response{
+			Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("invalid request body")},
+		})
+
+		return
+	}
+
+	ctx := withUserLoader(r.Context(), newUserLoader(r.Context(), h.userService.GetUsersByIDs, loaderWait, loaderMaxBatch))
+
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	for _, qe := range resp.Errors {
+		var resolverErr *resolverError
+		if errors.As(qe.ResolverError, &resolverErr) {
+			h.log.ErrorF("graphql error: %s, path = %v", resolverErr.err.Error(), qe.Path)
+		}
+	}
+
+	h.sendJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, code int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.log.ErrorF("failed to send json: %s", err.Error())
+	}
+}
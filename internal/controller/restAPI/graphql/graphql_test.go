@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/pkg/logger"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/mock/gomock"
+
+	"github.com/stretchr/testify/require"
+)
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newTestHandler(t *testing.T, userService *mocks.MockUserService) *Handler {
+	t.Helper()
+
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	return New(log, userService)
+}
+
+func exec(t *testing.T, h *Handler, query string, variables map[string]any) response {
+	t.Helper()
+
+	body, err := json.Marshal(request{Query: query, Variables: variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp response
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	return resp
+}
+
+func TestHandler_UserQueriesAreBatched(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newTestHandler(t, mockUserService)
+
+	first, second, missing := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	mockUserService.EXPECT().GetUsersByIDs(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ids []uuid.UUID) ([]entity.User, error) {
+			r.ElementsMatch([]uuid.UUID{first, second, missing}, ids)
+
+			return []entity.User{
+				{ID: first, Name: "first", Balance: decimal.NewFromInt(1)},
+				{ID: second, Name: "second", Balance: decimal.NewFromInt(2)},
+			}, nil
+		}).Times(1)
+
+	resp := exec(t, h, `query($a: ID!, $b: ID!, $c: ID!) {
+		a: user(id: $a) { name balance }
+		b: user(id: $b) { name }
+		c: user(id: $c) { name }
+		again: user(id: $a) { id }
+	}`, map[string]any{"a": first.String(), "b": second.String(), "c": missing.String()})
+
+	r.Empty(resp.Errors)
+	r.JSONEq(`{"name":"first","balance":"1"}`, string(resp.Data["a"]))
+	r.JSONEq(`{"name":"second"}`, string(resp.Data["b"]))
+	r.JSONEq(`null`, string(resp.Data["c"]))
+	r.JSONEq(`{"id":"`+first.String()+`"}`, string(resp.Data["again"]))
+}
+
+func TestHandler_Users(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newTestHandler(t, mockUserService)
+
+	minAge := 18
+	mockUserService.EXPECT().ListUsers(gomock.Any(), entity.ListUsersParams{
+		Filter: entity.UserFilter{NamePrefix: "a", MinAge: &minAge},
+		Sort:   entity.UserSort{Field: entity.SortByAge, Desc: true},
+		Limit:  2,
+	}).Return(entity.UserPage{
+		Items:      []entity.User{{Name: "a1"}, {Name: "a2"}},
+		NextCursor: "next",
+	}, nil)
+
+	resp := exec(t, h, `{ users(filter: {namePrefix: "a", minAge: 18}, sort: "-age", limit: 2) { items { name } nextCursor } }`, nil)
+	r.Empty(resp.Errors)
+	r.JSONEq(`{"items":[{"name":"a1"},{"name":"a2"}],"nextCursor":"next"}`, string(resp.Data["users"]))
+
+	resp = exec(t, h, `{ users(sort: "password") { nextCursor } }`, nil)
+	r.Len(resp.Errors, 1)
+	r.Equal("invalid_argument", resp.Errors[0].Extensions["code"])
+}
+
+func TestHandler_CreateUser(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newTestHandler(t, mockUserService)
+
+	userID := uuid.Must(uuid.NewV4())
+	user := entity.User{ID: userID, Name: "test", Email: "test@example.com", Age: 20, Balance: decimal.RequireFromString("1.5"), Version: 1}
+
+	mockUserService.EXPECT().CreateUser(gomock.Any(), entity.User{
+		ID: userID, Name: "test", Email: "test@example.com", Age: 20, Balance: decimal.RequireFromString("1.5"),
+	}).Return(nil)
+	mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(user, nil)
+
+	mutation := `mutation($input: CreateUserInput!) { createUser(input: $input) { id version } }`
+
+	resp := exec(t, h, mutation, map[string]any{"input": map[string]any{
+		"id": userID.String(), "name": "test", "email": "test@example.com", "age": 20, "balance": "1.5",
+	}})
+	r.Empty(resp.Errors)
+	r.JSONEq(`{"id":"`+userID.String()+`","version":1}`, string(resp.Data["createUser"]))
+
+	mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(&entity.ValidationError{
+		Fields: []entity.FieldError{{Field: "email", Code: "email"}},
+	})
+
+	resp = exec(t, h, mutation, map[string]any{"input": map[string]any{"name": "test", "email": "bad", "age": 20}})
+	r.Len(resp.Errors, 1)
+	r.Equal("validation failed", resp.Errors[0].Message)
+	r.Equal("validation_failed", resp.Errors[0].Extensions["code"])
+	r.NotEmpty(resp.Errors[0].Extensions["errors"])
+}
+
+func TestUserLoader_MaxBatch(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		mu      sync.Mutex
+		batches [][]uuid.UUID
+	)
+
+	fetch := func(_ context.Context, ids []uuid.UUID) ([]entity.User, error) {
+		mu.Lock()
+		batches = append(batches, ids)
+		mu.Unlock()
+
+		users := make([]entity.User, 0, len(ids))
+		for _, id := range ids {
+			users = append(users, entity.User{ID: id})
+		}
+
+		return users, nil
+	}
+
+	l := newUserLoader(context.Background(), fetch, 50*time.Millisecond, 2)
+
+	wg := &sync.WaitGroup{}
+
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			id := uuid.Must(uuid.NewV4())
+			user, err := l.Load(context.Background(), id)
+			r.NoError(err)
+			r.Equal(id, user.ID)
+		}()
+	}
+
+	wg.Wait()
+
+	r.Len(batches, 2)
+	r.Len(batches[0], 2)
+	r.Len(batches[1], 1)
+}
@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	loaderWait     = 2 * time.Millisecond
+	loaderMaxBatch = 100
+)
+
+// userLoader batches the GetUserByID lookups of one request: ids requested
+// within the wait window are fetched together with a single GetUsersByIDs
+// call. Results are cached for the rest of the request.
+type userLoader struct {
+	ctx      context.Context
+	fetch    func(ctx context.Context, ids []uuid.UUID) ([]entity.User, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *userBatch
+	cache   map[uuid.UUID]*userBatch
+}
+
+type userBatch struct {
+	ids   []uuid.UUID
+	users map[uuid.UUID]entity.User
+	err   error
+	done  chan struct{}
+}
+
+func newUserLoader(ctx context.Context, fetch func(ctx context.Context, ids []uuid.UUID) ([]entity.User, error),
+	wait time.Duration, maxBatch int) *userLoader {
+	return &userLoader{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[uuid.UUID]*userBatch),
+	}
+}
+
+func (l *userLoader) Load(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	l.mu.Lock()
+
+	b, ok := l.cache[id]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &userBatch{done: make(chan struct{})}
+
+			batch := l.pending
+			time.AfterFunc(l.wait, func() { l.dispatch(batch) })
+		}
+
+		b = l.pending
+		b.ids = append(b.ids, id)
+		l.cache[id] = b
+
+		if len(b.ids) >= l.maxBatch {
+			l.pending = nil
+			go l.run(b)
+		}
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return entity.User{}, ctx.Err()
+	}
+
+	if b.err != nil {
+		return entity.User{}, b.err
+	}
+
+	user, ok := b.users[id]
+	if !ok {
+		return entity.User{}, fmt.Errorf("user with id %s %w", id, entity.ErrNotFound)
+	}
+
+	return user, nil
+}
+
+// Clear drops id from the cache, so that the next Load sees a change made by
+// a mutation.
+func (l *userLoader) Clear(id uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.cache, id)
+}
+
+// dispatch runs batch unless it was already started for being full.
+func (l *userLoader) dispatch(batch *userBatch) {
+	l.mu.Lock()
+
+	if l.pending != batch {
+		l.mu.Unlock()
+		return
+	}
+
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.run(batch)
+}
+
+func (l *userLoader) run(b *userBatch) {
+	defer close(b.done)
+
+	users, err := l.fetch(l.ctx, b.ids)
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	b.users = make(map[uuid.UUID]entity.User, len(users))
+
+	for _, user := range users {
+		b.users[user.ID] = user
+	}
+}
+
+type loaderKey struct{}
+
+func withUserLoader(ctx context.Context, l *userLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func userLoaderFromContext(ctx context.Context) (*userLoader, bool) {
+	l, ok := ctx.Value(loaderKey{}).(*userLoader)
+	return l, ok
+}
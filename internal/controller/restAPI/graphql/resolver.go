@@ -0,0 +1,363 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"users-app/internal/controller/restAPI/handler"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+type resolver struct {
+	userService handler.UserService
+}
+
+func (r *resolver) User(ctx context.Context, args struct {
+	ID             graphqlgo.ID
+	IncludeDeleted bool
+}) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user entity.User
+
+	if args.IncludeDeleted {
+		user, err = r.userService.GetUserByIDWithDeleted(ctx, id)
+	} else {
+		user, err = r.loadUser(ctx, id)
+	}
+
+	return resolveUser(user, err)
+}
+
+func (r *resolver) UserByEmail(ctx context.Context, args struct{ Email string }) (*userResolver, error) {
+	return resolveUser(r.userService.GetUserByEmail(ctx, args.Email))
+}
+
+type userFilterInput struct {
+	NamePrefix     *string
+	EmailDomain    *string
+	MinAge         *int32
+	MaxAge         *int32
+	MinBalance     *string
+	MaxBalance     *string
+	IncludeDeleted *bool
+}
+
+func (r *resolver) Users(ctx context.Context, args struct {
+	Filter *userFilterInput
+	Sort   *string
+	Limit  *int32
+	Cursor *string
+}) (*userPageResolver, error) {
+	params := entity.ListUsersParams{
+		Sort:  entity.UserSort{Field: entity.SortByName},
+		Limit: defaultPageLimit,
+	}
+
+	if args.Cursor != nil {
+		params.Cursor = *args.Cursor
+	}
+
+	if args.Sort != nil && *args.Sort != "" {
+		field, desc := strings.CutPrefix(*args.Sort, "-")
+
+		params.Sort = entity.UserSort{Field: entity.SortField(field), Desc: desc}
+		if !params.Sort.Field.Valid() {
+			return nil, newResolverError(fmt.Errorf("sorting by %s is not supported: %w", field, errInvalidArgument))
+		}
+	}
+
+	if args.Limit != nil {
+		if *args.Limit < 1 || *args.Limit > maxPageLimit {
+			return nil, newResolverError(fmt.Errorf("limit %d is out of range: %w", *args.Limit, errInvalidArgument))
+		}
+
+		params.Limit = int(*args.Limit)
+	}
+
+	if args.Filter != nil {
+		filter, err := toUserFilter(*args.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Filter = filter
+	}
+
+	page, err := r.userService.ListUsers(ctx, params)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	return &userPageResolver{page: page}, nil
+}
+
+type createUserInput struct {
+	ID      *graphqlgo.ID
+	Name    string
+	Email   string
+	Age     int32
+	Balance *string
+}
+
+func (r *resolver) CreateUser(ctx context.Context, args struct{ Input createUserInput }) (*userResolver, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	if args.Input.ID != nil {
+		if id, err = parseID(*args.Input.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	balance, err := parseDecimal("balance", args.Input.Balance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.userService.CreateUser(ctx, entity.User{
+		ID:      id,
+		Name:    args.Input.Name,
+		Email:   args.Input.Email,
+		Age:     int(args.Input.Age),
+		Balance: balance,
+	}); err != nil {
+		return nil, newResolverError(err)
+	}
+
+	user, err := r.userService.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	return &userResolver{user: user}, nil
+}
+
+type updateUserInput struct {
+	ID      graphqlgo.ID
+	Name    string
+	Email   string
+	Age     int32
+	Balance *string
+	Version *int32
+}
+
+func (r *resolver) UpdateUser(ctx context.Context, args struct{ Input updateUserInput }) (*userResolver, error) {
+	id, err := parseID(args.Input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := parseDecimal("balance", args.Input.Balance)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.User{
+		ID:      id,
+		Name:    args.Input.Name,
+		Email:   args.Input.Email,
+		Age:     int(args.Input.Age),
+		Balance: balance,
+	}
+
+	if args.Input.Version != nil {
+		user.Version = int64(*args.Input.Version)
+	}
+
+	r.clearUser(ctx, id)
+
+	user, err = r.userService.UpdateUser(ctx, user)
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	return &userResolver{user: user}, nil
+}
+
+func (r *resolver) DeleteUser(ctx context.Context, args struct{ ID graphqlgo.ID }) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	r.clearUser(ctx, id)
+
+	if err := r.userService.DeleteUser(ctx, id); err != nil {
+		return false, newResolverError(err)
+	}
+
+	return true, nil
+}
+
+// loadUser goes through the request's loader so that lookups made by one
+// query are batched.
+func (r *resolver) loadUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	if l, ok := userLoaderFromContext(ctx); ok {
+		return l.Load(ctx, id)
+	}
+
+	return r.userService.GetUserByID(ctx, id)
+}
+
+func (r *resolver) clearUser(ctx context.Context, id uuid.UUID) {
+	if l, ok := userLoaderFromContext(ctx); ok {
+		l.Clear(id)
+	}
+}
+
+// resolveUser resolves a nullable user field: a missing user is null rather
+// than an error.
+func resolveUser(user entity.User, err error) (*userResolver, error) {
+	if errors.Is(err, entity.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, newResolverError(err)
+	}
+
+	return &userResolver{user: user}, nil
+}
+
+type userResolver struct {
+	user entity.User
+}
+
+func (r *userResolver) ID() graphqlgo.ID {
+	return graphqlgo.ID(r.user.ID.String())
+}
+
+func (r *userResolver) Name() string {
+	return r.user.Name
+}
+
+func (r *userResolver) Email() string {
+	return r.user.Email
+}
+
+func (r *userResolver) Age() int32 {
+	return int32(r.user.Age)
+}
+
+func (r *userResolver) Balance() string {
+	return r.user.Balance.String()
+}
+
+func (r *userResolver) Version() int32 {
+	return int32(r.user.Version)
+}
+
+func (r *userResolver) DeletedAt() *graphqlgo.Time {
+	if r.user.DeletedAt == nil {
+		return nil
+	}
+
+	return &graphqlgo.Time{Time: *r.user.DeletedAt}
+}
+
+type userPageResolver struct {
+	page entity.UserPage
+}
+
+func (r *userPageResolver) Items() []*userResolver {
+	items := make([]*userResolver, 0, len(r.page.Items))
+
+	for _, user := range r.page.Items {
+		items = append(items, &userResolver{user: user})
+	}
+
+	return items
+}
+
+func (r *userPageResolver) NextCursor() *string {
+	if r.page.NextCursor == "" {
+		return nil
+	}
+
+	return &r.page.NextCursor
+}
+
+func toUserFilter(in userFilterInput) (entity.UserFilter, error) {
+	var filter entity.UserFilter
+
+	if in.NamePrefix != nil {
+		filter.NamePrefix = *in.NamePrefix
+	}
+
+	if in.EmailDomain != nil {
+		filter.EmailDomain = *in.EmailDomain
+	}
+
+	if in.IncludeDeleted != nil {
+		filter.IncludeDeleted = *in.IncludeDeleted
+	}
+
+	if in.MinAge != nil {
+		minAge := int(*in.MinAge)
+		filter.MinAge = &minAge
+	}
+
+	if in.MaxAge != nil {
+		maxAge := int(*in.MaxAge)
+		filter.MaxAge = &maxAge
+	}
+
+	if in.MinBalance != nil {
+		minBalance, err := parseDecimal("minBalance", in.MinBalance)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.MinBalance = &minBalance
+	}
+
+	if in.MaxBalance != nil {
+		maxBalance, err := parseDecimal("maxBalance", in.MaxBalance)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.MaxBalance = &maxBalance
+	}
+
+	return filter, nil
+}
+
+func parseID(id graphqlgo.ID) (uuid.UUID, error) {
+	parsed, err := uuid.FromString(string(id))
+	if err != nil {
+		return uuid.Nil, newResolverError(fmt.Errorf("id %q: %w", id, errInvalidID))
+	}
+
+	return parsed, nil
+}
+
+// parseDecimal parses an optional decimal string; a missing value is zero.
+func parseDecimal(field string, value *string) (decimal.Decimal, error) {
+	if value == nil || *value == "" {
+		return decimal.Zero, nil
+	}
+
+	d, err := decimal.NewFromString(*value)
+	if err != nil {
+		return decimal.Decimal{}, newResolverError(fmt.Errorf("%s %q is not a valid decimal: %w", field, *value, errInvalidArgument))
+	}
+
+	return d, nil
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+)
+
+type apiKeyRequest struct {
+	Name      string              `json:"name"`
+	Scopes    []entity.Permission `json:"scopes"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+}
+
+// CreateAPIKey answers with the full key. It is not stored and cannot be
+// shown again.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req apiKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	key, err := h.userService.CreateAPIKey(ctx, entity.APIKey{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		h.sendAPIKeyErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.sendJSON(w, http.StatusCreated, key)
+}
+
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.userService.ListAPIKeys(r.Context())
+	if err != nil {
+		h.sendAPIKeyErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, keys)
+}
+
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.FromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidID, "detail.api_key_id_invalid",
+			map[string]any{"id": chi.URLParam(r, "id")}))
+		return
+	}
+
+	key, err := h.userService.RevokeAPIKey(ctx, id)
+	if err != nil {
+		h.sendAPIKeyErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, key)
+}
+
+// sendAPIKeyErr is sendErr with a not-found detail that names API keys
+// rather than users.
+func (h *Handler) sendAPIKeyErr(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, entity.ErrNotFound) {
+		err = entity.NewDetailError(err, "detail.api_key_not_found", nil)
+	}
+
+	h.sendErr(w, r, err)
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+type amountRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// limitsRequest replaces the tier and the overrides of a user. A missing or
+// null limit keeps the one of the tier.
+type limitsRequest struct {
+	Tier           string           `json:"tier"`
+	PerTransaction *decimal.Decimal `json:"per_transaction"`
+	Daily          *decimal.Decimal `json:"daily"`
+	Weekly         *decimal.Decimal `json:"weekly"`
+	Monthly        *decimal.Decimal `json:"monthly"`
+}
+
+type holdRequest struct {
+	Amount    decimal.Decimal `json:"amount"`
+	ExpiresAt *time.Time      `json:"expires_at"`
+}
+
+func (h *Handler) CreateDeposit(w http.ResponseWriter, r *http.Request) {
+	h.createMovement(w, r, h.userService.Deposit)
+}
+
+func (h *Handler) CreateWithdrawal(w http.ResponseWriter, r *http.Request) {
+	h.createMovement(w, r, h.userService.Withdraw)
+}
+
+func (h *Handler) createMovement(w http.ResponseWriter, r *http.Request,
+	move func(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error)) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	var req amountRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	movement, err := move(ctx, userID, req.Amount)
+	if err != nil {
+		h.sendOperationErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusCreated, movement)
+}
+
+// GetBalances serves the wallets of a user, with their total in the currency
+// of the currency query parameter when it is given.
+func (h *Handler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	balances, err := h.userService.Balances(ctx, userID, r.URL.Query().Get("currency"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, balances)
+}
+
+func (h *Handler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	limits, err := h.userService.GetLimits(ctx, userID)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, limits)
+}
+
+func (h *Handler) SetLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	var req limitsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	limits, err := h.userService.SetLimits(ctx, userID, req.Tier, entity.SpendingLimits{
+		PerTransaction: req.PerTransaction,
+		Daily:          req.Daily,
+		Weekly:         req.Weekly,
+		Monthly:        req.Monthly,
+	})
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, limits)
+}
+
+func (h *Handler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	var req holdRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	hold := entity.Hold{UserID: userID, Amount: req.Amount}
+	if req.ExpiresAt != nil {
+		hold.ExpiresAt = *req.ExpiresAt
+	}
+
+	hold, err = h.userService.PlaceHold(ctx, hold)
+	if err != nil {
+		h.sendOperationErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", holdLocation(hold))
+	h.sendJSON(w, http.StatusCreated, hold)
+}
+
+func (h *Handler) GetHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, holdID, err := parseHoldPath(r)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	hold, err := h.userService.GetHold(ctx, userID, holdID)
+	if err != nil {
+		h.sendHoldErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, hold)
+}
+
+// CaptureHold captures the amount in the body, or the whole hold when the
+// body or its amount is left out.
+func (h *Handler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, holdID, err := parseHoldPath(r)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	var req amountRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	hold, err := h.userService.CaptureHold(ctx, userID, holdID, req.Amount)
+	if err != nil {
+		h.sendHoldErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, hold)
+}
+
+func (h *Handler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, holdID, err := parseHoldPath(r)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	hold, err := h.userService.ReleaseHold(ctx, userID, holdID)
+	if err != nil {
+		h.sendHoldErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, hold)
+}
+
+// sendHoldErr is sendErr with a not-found detail that names holds as well as
+// users, since either may be missing.
+func (h *Handler) sendHoldErr(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, entity.ErrNotFound) {
+		err = entity.NewDetailError(err, "detail.hold_not_found", nil)
+	}
+
+	h.sendErr(w, r, err)
+}
+
+func parseHoldPath(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	id := chi.URLParam(r, "hold_id")
+
+	holdID, err := uuid.FromString(id)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, entity.NewDetailError(errInvalidID, "detail.hold_id_invalid", map[string]any{"id": id})
+	}
+
+	return userID, holdID, nil
+}
+
+func holdLocation(hold entity.Hold) string {
+	return userLocation(hold.UserID) + "/holds/" + hold.ID.String()
+}
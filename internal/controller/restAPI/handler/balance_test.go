@@ -0,0 +1,721 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/pkg/i18n"
+	"users-app/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/mock/gomock"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CreateMovement(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+	pending := &entity.ReviewError{Review: entity.RiskReview{ID: 7, Status: entity.ReviewPending}}
+
+	tests := []struct {
+		name             string
+		withdrawal       bool
+		userID           string
+		body             string
+		mockBehavior     func()
+		expectedStatus   int
+		expectedCode     string
+		expectedLocation string
+	}{
+		{
+			name:   "deposit",
+			userID: userID.String(),
+			body:   `{"amount": "25.50"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Deposit(gomock.Any(), userID, decimal.RequireFromString("25.50")).
+					Return(entity.Movement{ID: uuid.Must(uuid.NewV4()), UserID: userID, Kind: entity.OperationDeposit}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid user id",
+			userID:         "invalid-id",
+			body:           `{"amount": "25.50"}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_id",
+		},
+		{
+			name:           "malformed body",
+			userID:         userID.String(),
+			body:           `{"amount": `,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_body",
+		},
+		{
+			name:   "amount not positive",
+			userID: userID.String(),
+			body:   `{"amount": "0"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Deposit(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{},
+					&entity.ValidationError{Fields: []entity.FieldError{{Field: "amount", Code: "not_positive"}}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:   "deposit held for review",
+			userID: userID.String(),
+			body:   `{"amount": "20000"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Deposit(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{}, pending)
+			},
+			expectedStatus:   http.StatusAccepted,
+			expectedLocation: "/api/v1/reviews/7",
+		},
+		{
+			name:       "withdrawal",
+			withdrawal: true,
+			userID:     userID.String(),
+			body:       `{"amount": "10"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Withdraw(gomock.Any(), userID, decimal.RequireFromString("10")).
+					Return(entity.Movement{ID: uuid.Must(uuid.NewV4()), UserID: userID, Kind: entity.OperationWithdrawal}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:       "insufficient funds",
+			withdrawal: true,
+			userID:     userID.String(),
+			body:       `{"amount": "10"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Withdraw(gomock.Any(), userID, gomock.Any()).
+					Return(entity.Movement{}, entity.ErrInsufficientFunds)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "insufficient_funds",
+		},
+		{
+			name:       "user not found",
+			withdrawal: true,
+			userID:     userID.String(),
+			body:       `{"amount": "10"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Withdraw(gomock.Any(), userID, gomock.Any()).
+					Return(entity.Movement{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+		},
+		{
+			name:       "internal server error",
+			withdrawal: true,
+			userID:     userID.String(),
+			body:       `{"amount": "10"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Withdraw(gomock.Any(), userID, gomock.Any()).
+					Return(entity.Movement{}, errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/users/"+tt.userID+"/deposits",
+				strings.NewReader(tt.body))
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			if tt.withdrawal {
+				handler.CreateWithdrawal(rr, req)
+			} else {
+				handler.CreateDeposit(rr, req)
+			}
+
+			r.Equal(tt.expectedStatus, rr.Code)
+			r.Equal(tt.expectedLocation, rr.Header().Get("Location"))
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_CreateHold(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+	holdID := uuid.Must(uuid.NewV4())
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		body             string
+		mockBehavior     func()
+		expectedStatus   int
+		expectedCode     string
+		expectedLocation string
+	}{
+		{
+			name: "success",
+			body: `{"amount": "40", "expires_at": "2030-01-02T03:04:05Z"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PlaceHold(gomock.Any(), entity.Hold{
+					UserID:    userID,
+					Amount:    decimal.RequireFromString("40"),
+					ExpiresAt: expiresAt,
+				}).Return(entity.Hold{ID: holdID, UserID: userID, Status: entity.HoldActive}, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/api/v1/users/" + userID.String() + "/holds/" + holdID.String(),
+		},
+		{
+			name: "default expiry",
+			body: `{"amount": "40"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PlaceHold(gomock.Any(), entity.Hold{
+					UserID: userID,
+					Amount: decimal.RequireFromString("40"),
+				}).Return(entity.Hold{ID: holdID, UserID: userID, Status: entity.HoldActive}, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/api/v1/users/" + userID.String() + "/holds/" + holdID.String(),
+		},
+		{
+			name:           "malformed body",
+			body:           `{"amount": "40"`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_body",
+		},
+		{
+			name: "insufficient funds",
+			body: `{"amount": "40"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Return(entity.Hold{}, entity.ErrInsufficientFunds)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "insufficient_funds",
+		},
+		{
+			name: "expiry in the past",
+			body: `{"amount": "40", "expires_at": "2000-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Return(entity.Hold{},
+					&entity.ValidationError{Fields: []entity.FieldError{{Field: "expires_at", Code: "in_past"}}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name: "held for review",
+			body: `{"amount": "40"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Return(entity.Hold{},
+					&entity.ReviewError{Review: entity.RiskReview{ID: 9, Status: entity.ReviewPending}})
+			},
+			expectedStatus:   http.StatusAccepted,
+			expectedLocation: "/api/v1/reviews/9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/holds",
+				strings.NewReader(tt.body))
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", userID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.CreateHold(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+			r.Equal(tt.expectedLocation, rr.Header().Get("Location"))
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_SettleHold(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+	holdID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		release        bool
+		holdID         string
+		body           string
+		mockBehavior   func()
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+	}{
+		{
+			name:   "capture part",
+			holdID: holdID.String(),
+			body:   `{"amount": "15"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CaptureHold(gomock.Any(), userID, holdID, decimal.RequireFromString("15")).
+					Return(entity.Hold{ID: holdID, Status: entity.HoldCaptured}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "capture all without a body",
+			holdID: holdID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().CaptureHold(gomock.Any(), userID, holdID, decimal.Decimal{}).
+					Return(entity.Hold{ID: holdID, Status: entity.HoldCaptured}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "malformed body",
+			holdID:         holdID.String(),
+			body:           `{"amount": `,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_body",
+		},
+		{
+			name:           "invalid hold id",
+			holdID:         "invalid-id",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_id",
+		},
+		{
+			name:   "capture a settled hold",
+			holdID: holdID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().CaptureHold(gomock.Any(), userID, holdID, gomock.Any()).
+					Return(entity.Hold{}, entity.ErrHoldNotActive)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "hold_not_active",
+		},
+		{
+			name:   "capture more than held",
+			holdID: holdID.String(),
+			body:   `{"amount": "100"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CaptureHold(gomock.Any(), userID, holdID, gomock.Any()).Return(entity.Hold{},
+					&entity.ValidationError{Fields: []entity.FieldError{{Field: "amount", Code: "out_of_range"}}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:   "capture over the limit",
+			holdID: holdID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().CaptureHold(gomock.Any(), userID, holdID, gomock.Any()).Return(entity.Hold{},
+					&entity.LimitError{Limit: entity.LimitDaily, Remaining: decimal.Zero, Currency: "USD"})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "limit_exceeded",
+		},
+		{
+			name:    "release",
+			release: true,
+			holdID:  holdID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().ReleaseHold(gomock.Any(), userID, holdID).
+					Return(entity.Hold{ID: holdID, Status: entity.HoldReleased}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "release a settled hold",
+			release: true,
+			holdID:  holdID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().ReleaseHold(gomock.Any(), userID, holdID).Return(entity.Hold{}, entity.ErrHoldNotActive)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "hold_not_active",
+		},
+		{
+			name:    "release a missing hold",
+			release: true,
+			holdID:  holdID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().ReleaseHold(gomock.Any(), userID, holdID).Return(entity.Hold{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+			expectedDetail: catalog.Message("en", "detail.hold_not_found", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/holds/"+tt.holdID,
+				strings.NewReader(tt.body))
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", userID.String())
+			rctx.URLParams.Add("hold_id", tt.holdID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			if tt.release {
+				handler.ReleaseHold(rr, req)
+			} else {
+				handler.CaptureHold(rr, req)
+			}
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+
+				if tt.expectedDetail != "" {
+					r.Equal(tt.expectedDetail, resp.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_GetBalances(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		userID         string
+		query          string
+		mockBehavior   func()
+		expectedStatus int
+		expectedCode   string
+		expectedBody   string
+	}{
+		{
+			name:   "wallets",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().Balances(gomock.Any(), userID, "").Return(entity.Balances{
+					UserID: userID,
+					Wallets: []entity.Wallet{
+						{Currency: "USD", Balance: decimal.RequireFromString("10"), HeldBalance: decimal.Zero},
+						{Currency: "EUR", Balance: decimal.RequireFromString("5"), HeldBalance: decimal.Zero},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"user_id":"` + userID.String() + `","wallets":[` +
+				`{"currency":"USD","balance":"10","held_balance":"0"},` +
+				`{"currency":"EUR","balance":"5","held_balance":"0"}]}`,
+		},
+		{
+			name:   "total in a currency",
+			userID: userID.String(),
+			query:  "?currency=EUR",
+			mockBehavior: func() {
+				mockUserService.EXPECT().Balances(gomock.Any(), userID, "EUR").Return(entity.Balances{
+					UserID:  userID,
+					Wallets: []entity.Wallet{{Currency: "USD", Balance: decimal.RequireFromString("10"), HeldBalance: decimal.Zero}},
+					Total:   &entity.Money{Currency: "EUR", Amount: decimal.RequireFromString("9.2")},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"user_id":"` + userID.String() + `","wallets":[` +
+				`{"currency":"USD","balance":"10","held_balance":"0"}],"total":{"currency":"EUR","amount":"9.2"}}`,
+		},
+		{
+			name:   "unknown currency",
+			userID: userID.String(),
+			query:  "?currency=XXX",
+			mockBehavior: func() {
+				mockUserService.EXPECT().Balances(gomock.Any(), userID, "XXX").Return(entity.Balances{},
+					&entity.ValidationError{Fields: []entity.FieldError{{Field: "currency", Code: "unsupported"}}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:   "no exchange rate",
+			userID: userID.String(),
+			query:  "?currency=JPY",
+			mockBehavior: func() {
+				mockUserService.EXPECT().Balances(gomock.Any(), userID, "JPY").Return(entity.Balances{}, entity.ErrNoExchangeRate)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "exchange_rate_unavailable",
+		},
+		{
+			name:           "invalid user id",
+			userID:         "invalid-id",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_id",
+		},
+		{
+			name:   "user not found",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().Balances(gomock.Any(), userID, "").Return(entity.Balances{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/users/"+tt.userID+"/balances"+tt.query, nil)
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.GetBalances(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			if tt.expectedBody != "" {
+				r.JSONEq(tt.expectedBody, rr.Body.String())
+			}
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_Limits(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+	daily := decimal.RequireFromString("500")
+
+	tests := []struct {
+		name           string
+		set            bool
+		userID         string
+		body           string
+		mockBehavior   func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:   "get",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetLimits(gomock.Any(), userID).
+					Return(entity.UserLimits{UserID: userID, Tier: "standard", Currency: "USD"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "get with invalid user id",
+			userID:         "invalid-id",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_id",
+		},
+		{
+			name:   "get for a missing user",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetLimits(gomock.Any(), userID).Return(entity.UserLimits{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+		},
+		{
+			name:   "set",
+			set:    true,
+			userID: userID.String(),
+			body:   `{"tier": "premium", "daily": "500", "weekly": null}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().SetLimits(gomock.Any(), userID, "premium", entity.SpendingLimits{Daily: &daily}).
+					Return(entity.UserLimits{UserID: userID, Tier: "premium", Currency: "USD"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "set with malformed body",
+			set:            true,
+			userID:         userID.String(),
+			body:           `{"tier": `,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_body",
+		},
+		{
+			name:   "set an unknown tier",
+			set:    true,
+			userID: userID.String(),
+			body:   `{"tier": "gold"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().SetLimits(gomock.Any(), userID, "gold", entity.SpendingLimits{}).
+					Return(entity.UserLimits{}, &entity.ValidationError{
+						Fields: []entity.FieldError{{Field: "tier", Code: "unsupported"}},
+					})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "validation_failed",
+		},
+		{
+			name:   "set without permission",
+			set:    true,
+			userID: userID.String(),
+			body:   `{"tier": "premium"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().SetLimits(gomock.Any(), userID, "premium", entity.SpendingLimits{}).
+					Return(entity.UserLimits{}, entity.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			method := http.MethodGet
+			if tt.set {
+				method = http.MethodPut
+			}
+
+			req, err := http.NewRequest(method, "/api/v1/users/"+tt.userID+"/limits", strings.NewReader(tt.body))
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			if tt.set {
+				handler.SetLimits(rr, req)
+			} else {
+				handler.GetLimits(rr, req)
+			}
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
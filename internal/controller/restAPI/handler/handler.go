@@ -3,59 +3,114 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"users-app/internal/entity"
+	"users-app/internal/events"
+	"users-app/pkg/i18n"
 	"users-app/pkg/logger"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
 )
 
 //go:generate go run go.uber.org/mock/mockgen@latest -source=handler.go -destination=../../../mocks/handler.go -package=mocks -typed
 type UserService interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error)
+	GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error)
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
 	CreateUser(ctx context.Context, user entity.User) error
-	UpdateUser(ctx context.Context, user entity.User) error
+	UpdateUser(ctx context.Context, user entity.User) (entity.User, error)
+	PatchUser(ctx context.Context, id uuid.UUID, patch entity.UserPatch) (entity.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) (entity.User, error)
+	ListUsers(ctx context.Context, params entity.ListUsersParams) (entity.UserPage, error)
+	Transfer(ctx context.Context, t entity.Transfer) (entity.Transfer, error)
+	Balances(ctx context.Context, userID uuid.UUID, currency string) (entity.Balances, error)
+	Deposit(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error)
+	Withdraw(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error)
+	PlaceHold(ctx context.Context, hold entity.Hold) (entity.Hold, error)
+	GetHold(ctx context.Context, userID, holdID uuid.UUID) (entity.Hold, error)
+	CaptureHold(ctx context.Context, userID, holdID uuid.UUID, amount decimal.Decimal) (entity.Hold, error)
+	ReleaseHold(ctx context.Context, userID, holdID uuid.UUID) (entity.Hold, error)
+	GetLimits(ctx context.Context, userID uuid.UUID) (entity.UserLimits, error)
+	SetLimits(ctx context.Context, userID uuid.UUID, tier string, overrides entity.SpendingLimits) (entity.UserLimits, error)
+	ListReviews(ctx context.Context, status entity.ReviewStatus, cursor string, limit int) (entity.RiskReviewPage, error)
+	GetReview(ctx context.Context, id int64) (entity.RiskReview, error)
+	ApproveReview(ctx context.Context, id int64) (entity.RiskReview, error)
+	RejectReview(ctx context.Context, id int64) (entity.RiskReview, error)
+	GetUserHistory(ctx context.Context, id uuid.UUID, cursor string, limit int) (entity.AuditPage, error)
+	CreateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, cursor string, limit int) (entity.WebhookDeliveryPage, error)
+	RedeliverWebhook(ctx context.Context, subscriptionID uuid.UUID, deliveryID int64) (entity.WebhookDelivery, error)
+	AuthorizeUserEvents(ctx context.Context, ids []uuid.UUID) error
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (entity.APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (entity.APIKey, error)
 }
 
 type Handler struct {
 	log         logger.Logger
+	catalog     *i18n.Catalog
 	userService UserService
+	stream      *events.Broker
 }
 
-func New(log logger.Logger, userService UserService) *Handler {
+func New(log logger.Logger, catalog *i18n.Catalog, userService UserService, stream *events.Broker) *Handler {
 	return &Handler{
 		log:         log,
+		catalog:     catalog,
 		userService: userService,
+		stream:      stream,
 	}
 }
 
+// GetUsers serves GET /api/users: a lookup by id when the id parameter is
+// present, a paginated listing otherwise.
+func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("id") {
+		h.GetUserByID(w, r)
+		return
+	}
+
+	h.ListUsers(w, r)
+}
+
 func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.sendErr(w, http.StatusBadRequest, errors.New("id is empty"), "id is empty")
+	userID, err := parseUserID(userIDParam(r))
+	if err != nil {
+		h.sendErr(w, r, err)
 		return
 	}
 
-	userID, err := uuid.FromString(id)
+	includeDeleted, err := parseBoolParam(r.URL.Query(), "include_deleted")
 	if err != nil {
-		h.sendErr(w, http.StatusBadRequest, err, "невалидный id пользователя: "+id)
+		h.sendErr(w, r, err)
 		return
 	}
 
-	user, err := h.userService.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, entity.ErrNotFound) {
-			h.sendErr(w, http.StatusNotFound, err, "user not found")
-		}
+	getUser := h.userService.GetUserByID
+	if includeDeleted {
+		getUser = h.userService.GetUserByIDWithDeleted
+	}
 
-		h.sendErr(w, http.StatusInternalServerError, err, "failed to get user")
+	user, err := getUser(ctx, userID)
+	if err != nil {
+		h.sendErr(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", etag(user.Version))
 	h.sendJSON(w, http.StatusOK, user)
 }
 
@@ -65,70 +120,264 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var user entity.User
 
 	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		h.sendErr(w, http.StatusBadRequest, err, "failed to decode request body")
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
 		return
 	}
 
-	if err := h.userService.CreateUser(ctx, user); err != nil {
-		if errors.Is(err, entity.ErrAlreadyExists) {
-			h.sendErr(w, http.StatusConflict, err, "user with email "+user.Email+" already exists")
+	if user.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			h.sendErr(w, r, err)
 			return
 		}
 
-		h.sendErr(w, http.StatusInternalServerError, err, "failed to create user")
+		user.ID = id
+	}
+
+	if err := h.userService.CreateUser(ctx, user); err != nil {
+		h.sendErr(w, r, err)
 		return
 	}
 
+	w.Header().Set("Location", userLocation(user.ID))
 	h.sendJSON(w, http.StatusCreated, user)
 }
 
+// updateUserRequest replaces a user. A missing or null balance keeps the
+// balance, as over gRPC, rather than zeroing it.
+type updateUserRequest struct {
+	ID      uuid.UUID        `json:"id"`
+	Name    string           `json:"name"`
+	Email   string           `json:"email"`
+	Age     int              `json:"age"`
+	Balance *decimal.Decimal `json:"balance"`
+}
+
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var user entity.User
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		h.sendErr(w, r, entity.NewDetailError(errPreconditionRequired, "detail.if_match_required", nil))
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		h.sendErr(w, http.StatusBadRequest, err, "failed to decode request body")
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	var req updateUserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
 		return
 	}
 
-	if err := h.userService.UpdateUser(ctx, user); err != nil {
-		if errors.Is(err, entity.ErrNotFound) {
-			h.sendErr(w, http.StatusNotFound, err, "user not found")
+	// The versioned route names the user in the path; the body may then
+	// omit the id but must not contradict it.
+	if id := chi.URLParam(r, "id"); id != "" {
+		userID, err := parseUserID(id)
+		if err != nil {
+			h.sendErr(w, r, err)
+			return
+		}
+
+		if req.ID != uuid.Nil && req.ID != userID {
+			h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.id_mismatch", nil))
+			return
+		}
+
+		req.ID = userID
+	}
+
+	var updated entity.User
+
+	if req.Balance == nil {
+		patch, err := json.Marshal(map[string]any{"name": req.Name, "email": req.Email, "age": req.Age})
+		if err != nil {
+			h.sendErr(w, r, err)
 			return
 		}
 
-		h.sendErr(w, http.StatusInternalServerError, err, "failed to update user")
+		updated, err = h.userService.PatchUser(ctx, req.ID, entity.UserPatch{
+			Format:   entity.MergePatch,
+			Document: patch,
+			Version:  version,
+		})
+	} else {
+		updated, err = h.userService.UpdateUser(ctx, entity.User{
+			ID:      req.ID,
+			Name:    req.Name,
+			Email:   req.Email,
+			Age:     req.Age,
+			Balance: *req.Balance,
+			Version: version,
+		})
+	}
+
+	if err != nil {
+		h.sendErr(w, r, err)
 		return
 	}
 
-	h.sendJSON(w, http.StatusOK, "user updated")
+	w.Header().Set("ETag", etag(updated.Version))
+	h.sendJSON(w, http.StatusOK, h.message(w, r, "message.user_updated"))
 }
 
-func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) PatchUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		h.sendErr(w, http.StatusBadRequest, errors.New("id is empty"), "id is empty")
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
 		return
 	}
 
-	userID, err := uuid.FromString(id)
-	if err != nil {
-		h.sendErr(w, http.StatusBadRequest, err, "невалидный id пользователя: "+id)
+	patch := entity.UserPatch{Format: entity.MergePatch}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch entity.PatchFormat(mediaType) {
+	case entity.MergePatch, "application/json", "":
+	case entity.JSONPatch:
+		patch.Format = entity.JSONPatch
+	default:
+		w.Header().Set("Accept-Patch", string(entity.MergePatch)+", "+string(entity.JSONPatch))
+		h.sendErr(w, r, entity.NewDetailError(errUnsupportedMediaType, "detail.media_type_unsupported",
+			map[string]any{"type": mediaType}))
 		return
 	}
 
-	if err := h.userService.DeleteUser(ctx, userID); err != nil {
-		if errors.Is(err, entity.ErrNotFound) {
-			h.sendErr(w, http.StatusNotFound, err, "user not found")
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if patch.Version, err = parseIfMatch(ifMatch); err != nil {
+			h.sendErr(w, r, err)
 			return
 		}
+	}
+
+	if patch.Document, err = io.ReadAll(r.Body); err != nil || !json.Valid(patch.Document) {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	user, err := h.userService.PatchUser(ctx, userID, patch)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(user.Version))
+	h.sendJSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(userIDParam(r))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	if err := h.userService.DeleteUser(ctx, userID); err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.message(w, r, "message.user_deleted"))
+}
+
+func (h *Handler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	user, err := h.userService.RestoreUser(ctx, userID)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(user.Version))
+	h.sendJSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params, err := parseListUsersParams(r.URL.Query())
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	page, err := h.userService.ListUsers(ctx, params)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, page)
+}
 
-		h.sendErr(w, http.StatusInternalServerError, err, "failed to delete user")
+func (h *Handler) GetUserHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := parseUserID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	limit, err := parsePageLimit(r.URL.Query())
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	page, err := h.userService.GetUserHistory(ctx, userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, page)
+}
+
+type transferRequest struct {
+	FromUserID uuid.UUID       `json:"from_user_id"`
+	ToUserID   uuid.UUID       `json:"to_user_id"`
+	Amount     decimal.Decimal `json:"amount"`
+	Currency   string          `json:"currency"`
+	ToCurrency string          `json:"to_currency"`
+}
+
+func (h *Handler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req transferRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	transfer, err := h.userService.Transfer(ctx, entity.Transfer{
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		ToCurrency: req.ToCurrency,
+	})
+	if err != nil {
+		h.sendOperationErr(w, r, err)
 		return
 	}
 
-	h.sendJSON(w, http.StatusOK, "user deleted")
+	h.sendJSON(w, http.StatusCreated, transfer)
 }
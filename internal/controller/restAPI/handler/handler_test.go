@@ -1,17 +1,24 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 	"users-app/internal/entity"
 	"users-app/internal/mocks"
+	"users-app/pkg/i18n"
 	"users-app/pkg/logger"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
 	"go.uber.org/mock/gomock"
 
 	"github.com/stretchr/testify/require"
@@ -28,7 +35,10 @@ func TestHandler_GetUserByID(t *testing.T) {
 	log, err := logger.New("mock")
 	r.NoError(err)
 
-	handler := New(log, mockUserService)
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
 
 	tests := []struct {
 		name           string
@@ -40,7 +50,7 @@ func TestHandler_GetUserByID(t *testing.T) {
 			name:   "success",
 			userID: uuid.Must(uuid.NewV4()).String(),
 			mockBehavior: func(userID uuid.UUID) {
-				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(entity.User{ID: userID, Name: "test"}, nil)
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(entity.User{ID: userID, Name: "test", Version: 2}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -104,7 +114,10 @@ func TestHandler_CreateUser(t *testing.T) {
 	log, err := logger.New("mock")
 	r.NoError(err)
 
-	handler := New(log, mockUserService)
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
 
 	tests := []struct {
 		name           string
@@ -114,21 +127,49 @@ func TestHandler_CreateUser(t *testing.T) {
 	}{
 		{
 			name:        "success",
-			requestBody: `{"name": "Test", "email": "test@example.com"}`,
+			requestBody: `{"id": "0190b7a2-5c1e-7d3a-9f00-4c8e2a1b6d55", "name": "Test", "email": "test@example.com"}`,
 			mockBehavior: func(user entity.User) {
 				mockUserService.EXPECT().CreateUser(gomock.Any(), user).Return(nil)
 			},
 			expectedStatus: http.StatusCreated,
 		},
+		{
+			name:        "generated id",
+			requestBody: `{"name": "Test", "email": "test@example.com"}`,
+			mockBehavior: func(_ entity.User) {
+				mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, user entity.User) error {
+						r.NotEqual(uuid.Nil, user.ID)
+						r.Equal("Test", user.Name)
+
+						return nil
+					},
+				)
+			},
+			expectedStatus: http.StatusCreated,
+		},
 		{
 			name:           "invalid request body",
 			requestBody:    `{"name": "Test", "email": "test@example.com"`,
 			mockBehavior:   func(user entity.User) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:        "validation failed",
+			requestBody: `{"id": "0190b7a2-5c1e-7d3a-9f00-4c8e2a1b6d55", "name": "", "email": "test"}`,
+			mockBehavior: func(user entity.User) {
+				mockUserService.EXPECT().CreateUser(gomock.Any(), user).Return(&entity.ValidationError{
+					Fields: []entity.FieldError{
+						{Field: "name", Code: "required", Message: "name is required"},
+						{Field: "email", Code: "invalid_format", Message: "email must be a valid address"},
+					},
+				})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
 		{
 			name:        "user already exists",
-			requestBody: `{"name": "test", "email": "test@example.com"}`,
+			requestBody: `{"id": "0190b7a2-5c1e-7d3a-9f00-4c8e2a1b6d55", "name": "test", "email": "test@example.com"}`,
 			mockBehavior: func(user entity.User) {
 				mockUserService.EXPECT().CreateUser(gomock.Any(), user).Return(entity.ErrAlreadyExists)
 			},
@@ -136,7 +177,7 @@ func TestHandler_CreateUser(t *testing.T) {
 		},
 		{
 			name:        "internal server error",
-			requestBody: `{"name": "test", "email": "test@example.com"}`,
+			requestBody: `{"id": "0190b7a2-5c1e-7d3a-9f00-4c8e2a1b6d55", "name": "test", "email": "test@example.com"}`,
 			mockBehavior: func(user entity.User) {
 				mockUserService.EXPECT().CreateUser(gomock.Any(), user).Return(errors.New("some error"))
 			},
@@ -175,41 +216,102 @@ func TestHandler_UpdateUser(t *testing.T) {
 	log, err := logger.New("mock")
 	r.NoError(err)
 
-	handler := New(log, mockUserService)
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
 
 	tests := []struct {
 		name           string
 		requestBody    string
+		ifMatch        string
 		mockBehavior   func(user entity.User)
 		expectedStatus int
+		expectedETag   string
 	}{
 		{
 			name:        "success",
-			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com"}`,
+			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			ifMatch:     `"3"`,
+			mockBehavior: func(user entity.User) {
+				updated := user
+				updated.Version = 4
+				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(updated, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:        "missing balance is kept",
+			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "age": 30}`,
+			ifMatch:     `"3"`,
 			mockBehavior: func(user entity.User) {
-				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(nil)
+				updated := user
+				updated.Version = 4
+				mockUserService.EXPECT().PatchUser(gomock.Any(), user.ID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ uuid.UUID, patch entity.UserPatch) (entity.User, error) {
+						r.Equal(entity.MergePatch, patch.Format)
+						r.Equal(int64(3), patch.Version)
+						r.JSONEq(`{"name": "Updated Name", "email": "updated@example.com", "age": 30}`, string(patch.Document))
+
+						return updated, nil
+					},
+				)
 			},
 			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "missing if-match",
+			requestBody:    `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			mockBehavior:   func(user entity.User) {},
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "malformed if-match",
+			requestBody:    `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			ifMatch:        `3`,
+			mockBehavior:   func(user entity.User) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "weak if-match",
+			requestBody:    `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			ifMatch:        `W/"3"`,
+			mockBehavior:   func(user entity.User) {},
+			expectedStatus: http.StatusPreconditionFailed,
 		},
 		{
 			name:           "invalid request body",
-			requestBody:    `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com"`,
+			requestBody:    `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"`,
+			ifMatch:        `"3"`,
 			mockBehavior:   func(user entity.User) {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:        "version conflict",
+			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			ifMatch:     `"3"`,
+			mockBehavior: func(user entity.User) {
+				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(entity.User{}, entity.ErrConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
 		{
 			name:        "user not found",
-			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com"}`,
+			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			ifMatch:     `"3"`,
 			mockBehavior: func(user entity.User) {
-				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(entity.ErrNotFound)
+				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(entity.User{}, entity.ErrNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 		},
 		{
 			name:        "internal server error",
-			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com"}`,
+			requestBody: `{"id": "d290f1ee-6c54-4b01-90e6-d701748f0851", "name": "Updated Name", "email": "updated@example.com", "balance": "10"}`,
+			ifMatch:     `"3"`,
 			mockBehavior: func(user entity.User) {
-				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(errors.New("some error"))
+				mockUserService.EXPECT().UpdateUser(gomock.Any(), user).Return(entity.User{}, errors.New("some error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -218,19 +320,23 @@ func TestHandler_UpdateUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var user entity.User
-			if tt.name != "invalid request body" {
-				err := json.Unmarshal([]byte(tt.requestBody), &user)
-				r.NoError(err)
+			if json.Unmarshal([]byte(tt.requestBody), &user) == nil {
+				user.Version = 3
 				tt.mockBehavior(user)
 			}
 
 			req, err := http.NewRequest(http.MethodPut, "/user", strings.NewReader(tt.requestBody))
 			r.NoError(err)
 
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
 			rr := httptest.NewRecorder()
 			handler.UpdateUser(rr, req)
 
 			r.Equal(tt.expectedStatus, rr.Code)
+			r.Equal(tt.expectedETag, rr.Header().Get("ETag"))
 		})
 	}
 }
@@ -246,7 +352,10 @@ func TestHandler_DeleteUser(t *testing.T) {
 	log, err := logger.New("mock")
 	r.NoError(err)
 
-	handler := New(log, mockUserService)
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
 
 	tests := []struct {
 		name           string
@@ -310,3 +419,906 @@ func TestHandler_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ListUsers(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	minAge := 18
+
+	tests := []struct {
+		name           string
+		query          string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:  "success",
+			query: "?name_prefix=A&min_age=18&sort=-balance&limit=10&cursor=abc",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListUsers(gomock.Any(), entity.ListUsersParams{
+					Filter: entity.UserFilter{NamePrefix: "A", MinAge: &minAge},
+					Sort:   entity.UserSort{Field: entity.SortByBalance, Desc: true},
+					Cursor: "abc",
+					Limit:  10,
+				}).Return(entity.UserPage{Items: []entity.User{{Name: "A test"}}, NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unsupported sort field",
+			query:          "?sort=password",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit out of range",
+			query:          "?limit=100000",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid balance filter",
+			query:          "?min_balance=abc",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=broken",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(entity.UserPage{}, entity.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "internal server error",
+			query: "",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(entity.UserPage{}, errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			r.NoError(err)
+
+			rr := httptest.NewRecorder()
+			handler.GetUsers(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_CreateTransfer(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	from := uuid.Must(uuid.NewV4())
+	to := uuid.Must(uuid.NewV4())
+	body := `{"from_user_id": "` + from.String() + `", "to_user_id": "` + to.String() + `", "amount": "10.5"}`
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:        "success",
+			requestBody: body,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), entity.Transfer{FromUserID: from, ToUserID: to, Amount: decimal.RequireFromString("10.5")}).
+					Return(entity.Transfer{ID: uuid.Must(uuid.NewV4())}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid request body",
+			requestBody:    `{"amount": 1`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid transfer",
+			requestBody: body,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, entity.ErrInvalidTransfer)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "user not found",
+			requestBody: body,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:        "insufficient funds",
+			requestBody: body,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, entity.ErrInsufficientFunds)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "between currencies",
+			requestBody: `{"from_user_id": "` + from.String() + `", "to_user_id": "` + to.String() +
+				`", "amount": "10.5", "currency": "EUR", "to_currency": "USD"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), entity.Transfer{FromUserID: from, ToUserID: to,
+					Amount: decimal.RequireFromString("10.5"), Currency: "EUR", ToCurrency: "USD"}).
+					Return(entity.Transfer{ID: uuid.Must(uuid.NewV4())}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:        "no exchange rate",
+			requestBody: body,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, entity.ErrNoExchangeRate)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "internal server error",
+			requestBody: body,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, errors.New("some error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/transfers", strings.NewReader(tt.requestBody))
+			r.NoError(err)
+
+			rr := httptest.NewRecorder()
+			handler.CreateTransfer(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_PatchUser(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		userID         string
+		contentType    string
+		ifMatch        string
+		requestBody    string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:        "merge patch",
+			userID:      userID.String(),
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"2"`,
+			requestBody: `{"name": "new"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, entity.UserPatch{
+					Format:   entity.MergePatch,
+					Document: []byte(`{"name": "new"}`),
+					Version:  2,
+				}).Return(entity.User{ID: userID, Name: "new", Version: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "json patch",
+			userID:      userID.String(),
+			contentType: "application/json-patch+json",
+			requestBody: `[{"op": "replace", "path": "/name", "value": "new"}]`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, entity.UserPatch{
+					Format:   entity.JSONPatch,
+					Document: []byte(`[{"op": "replace", "path": "/name", "value": "new"}]`),
+				}).Return(entity.User{ID: userID, Name: "new", Version: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "if-match list with a weak tag",
+			userID:      userID.String(),
+			ifMatch:     `W/"1", "2"`,
+			requestBody: `{"name": "new"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, entity.UserPatch{
+					Format:   entity.MergePatch,
+					Document: []byte(`{"name": "new"}`),
+					Version:  2,
+				}).Return(entity.User{ID: userID, Name: "new", Version: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "weak if-match",
+			userID:         userID.String(),
+			ifMatch:        `W/"2"`,
+			requestBody:    `{"name": "new"}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "if-match list of versions",
+			userID:         userID.String(),
+			ifMatch:        `"1", "2"`,
+			requestBody:    `{"name": "new"}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed if-match",
+			userID:         userID.String(),
+			ifMatch:        `2`,
+			requestBody:    `{"name": "new"}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid id",
+			userID:         "invalid-id",
+			requestBody:    `{}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported media type",
+			userID:         userID.String(),
+			contentType:    "text/plain",
+			requestBody:    `{}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "malformed body",
+			userID:         userID.String(),
+			requestBody:    `{"name": `,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid patch",
+			userID:      userID.String(),
+			requestBody: `{"balance": "1000"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).Return(entity.User{}, entity.ErrInvalidPatch)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "version conflict",
+			userID:      userID.String(),
+			ifMatch:     `"1"`,
+			requestBody: `{"name": "new"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).Return(entity.User{}, entity.ErrConflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:        "user not found",
+			userID:      userID.String(),
+			requestBody: `{"name": "new"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).Return(entity.User{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPatch, "/users/"+tt.userID, strings.NewReader(tt.requestBody))
+			r.NoError(err)
+
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.PatchUser(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_ValidationErrorBody(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	fields := []entity.FieldError{
+		{Field: "name", Code: "required", Message: "name is required"},
+		{Field: "age", Code: "out_of_range", Message: "age must be between 0 and 150",
+			Params: map[string]any{"min": 0, "max": 150}},
+	}
+
+	mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(&entity.ValidationError{Fields: fields})
+
+	req, err := http.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"age": 200}`))
+	r.NoError(err)
+
+	rr := httptest.NewRecorder()
+	handler.CreateUser(rr, req)
+
+	r.Equal(http.StatusUnprocessableEntity, rr.Code)
+
+	var resp Problem
+	r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+	r.Equal("validation_failed", resp.Code)
+	r.Len(resp.Errors, 2)
+	r.Equal("name is required", resp.Errors[0].Message)
+	r.Equal("age must be between 0 and 150", resp.Errors[1].Message)
+}
+
+func TestHandler_LimitExceededBody(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	mockUserService.EXPECT().Withdraw(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{},
+		fmt.Errorf("user with id %s cannot spend 500: %w", userID,
+			&entity.LimitError{Limit: entity.LimitDaily, Remaining: decimal.RequireFromString("120.5"), Currency: "USD"}))
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/withdrawals",
+		strings.NewReader(`{"amount": "500"}`))
+	r.NoError(err)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", userID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	handler.CreateWithdrawal(rr, req)
+
+	r.Equal(http.StatusUnprocessableEntity, rr.Code)
+
+	var resp Problem
+	r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+	r.Equal("limit_exceeded", resp.Code)
+	r.Equal(entity.LimitDaily, resp.Limit)
+	r.Equal("120.5", resp.Remaining.String())
+	r.Equal("USD", resp.Currency)
+	r.Equal("The operation exceeds the daily limit, 120.5 USD is left.", resp.Detail)
+}
+
+func TestHandler_DetailErrorBody(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+	}{
+		{
+			name: "immutable field",
+			err: entity.NewDetailError(fmt.Errorf("field %q is immutable: %w", "balance", entity.ErrInvalidPatch),
+				"detail.field_immutable", map[string]any{"field": "balance"}),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_patch",
+			expectedDetail: "Field balance cannot be changed.",
+		},
+		{
+			name:           "immutable field in russian",
+			acceptLanguage: "ru",
+			err: entity.NewDetailError(fmt.Errorf("field %q is immutable: %w", "balance", entity.ErrInvalidPatch),
+				"detail.field_immutable", map[string]any{"field": "balance"}),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_patch",
+			expectedDetail: "Поле balance нельзя изменить.",
+		},
+		{
+			name: "version conflict",
+			err: entity.NewDetailError(fmt.Errorf("user is at version 4, not 3: %w", entity.ErrConflict),
+				"detail.version_mismatch", map[string]any{"version": int64(4)}),
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedCode:   "version_conflict",
+			expectedDetail: "The user is at version 4.",
+		},
+		{
+			name:           "without a reason",
+			err:            entity.ErrInvalidPatch,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_patch",
+			expectedDetail: "The patch is malformed, fails a test operation or changes an immutable field.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).Return(entity.User{}, tt.err)
+
+			req, err := http.NewRequest(http.MethodPatch, "/api/v1/users/"+userID.String(),
+				strings.NewReader(`{"balance": "1000"}`))
+			r.NoError(err)
+
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", userID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.PatchUser(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			var resp Problem
+			r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+			r.Equal(tt.expectedCode, resp.Code)
+			r.Equal(tt.expectedDetail, resp.Detail)
+		})
+	}
+}
+
+func TestHandler_PendingReviewBody(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	mockUserService.EXPECT().Withdraw(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{},
+		&entity.ReviewError{Review: entity.RiskReview{ID: 42, Rule: "new_account_withdrawal", Status: entity.ReviewPending}})
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/withdrawals",
+		strings.NewReader(`{"amount": "5000"}`))
+	r.NoError(err)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", userID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	handler.CreateWithdrawal(rr, req)
+
+	r.Equal(http.StatusAccepted, rr.Code)
+	r.Equal("/api/v1/reviews/42", rr.Header().Get("Location"))
+	r.JSONEq(`{"review_id":42,"status":"pending"}`, rr.Body.String())
+}
+
+func TestHandler_ProblemDetails(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name            string
+		query           string
+		acceptLanguage  string
+		mockBehavior    func()
+		expectedProblem Problem
+	}{
+		{
+			name:         "invalid id",
+			query:        "?id=abc",
+			mockBehavior: func() {},
+			expectedProblem: Problem{
+				Type:      "urn:users-app:problem:invalid_id",
+				Title:     "Invalid identifier",
+				Status:    http.StatusBadRequest,
+				Detail:    "abc is not a valid user id.",
+				Instance:  "/api/users",
+				Code:      "invalid_id",
+				RequestID: "req-1",
+			},
+		},
+		{
+			name:  "not found",
+			query: "?id=" + userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+					Return(entity.User{}, fmt.Errorf("user with id %s %w", userID, entity.ErrNotFound))
+			},
+			expectedProblem: Problem{
+				Type:      "urn:users-app:problem:not_found",
+				Title:     "Resource not found",
+				Status:    http.StatusNotFound,
+				Detail:    "The requested user does not exist.",
+				Instance:  "/api/users",
+				Code:      "not_found",
+				RequestID: "req-1",
+			},
+		},
+		{
+			name:  "internal error hides details",
+			query: "?id=" + userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(entity.User{}, errors.New("connection refused"))
+			},
+			expectedProblem: Problem{
+				Type:      "urn:users-app:problem:internal_error",
+				Title:     "Internal server error",
+				Status:    http.StatusInternalServerError,
+				Detail:    "Something went wrong. Please try again later.",
+				Instance:  "/api/users",
+				Code:      "internal_error",
+				RequestID: "req-1",
+			},
+		},
+		{
+			name:           "russian",
+			query:          "?id=abc",
+			acceptLanguage: "de;q=0.9, ru-RU;q=0.8, en;q=0.1",
+			mockBehavior:   func() {},
+			expectedProblem: Problem{
+				Type:      "urn:users-app:problem:invalid_id",
+				Title:     "Неверный идентификатор",
+				Status:    http.StatusBadRequest,
+				Detail:    "abc не является корректным идентификатором пользователя.",
+				Instance:  "/api/users",
+				Code:      "invalid_id",
+				RequestID: "req-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodGet, "/api/users"+tt.query, nil)
+			r.NoError(err)
+
+			req = req.WithContext(context.WithValue(req.Context(), middleware.RequestIDKey, "req-1"))
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			rr := httptest.NewRecorder()
+			handler.GetUserByID(rr, req)
+
+			r.Equal(tt.expectedProblem.Status, rr.Code)
+			r.Equal("application/problem+json", rr.Header().Get("Content-Type"))
+
+			var problem Problem
+			r.NoError(json.NewDecoder(rr.Body).Decode(&problem))
+			r.Equal(tt.expectedProblem, problem)
+		})
+	}
+}
+
+func TestHandler_ProblemTitlesTranslated(t *testing.T) {
+	r := require.New(t)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	for _, pt := range append(problemTypes, internalProblem) {
+		key := "problem." + pt.code + ".title"
+		r.NotEqual(key, catalog.Message("en", key, nil), "missing title for %s", pt.code)
+	}
+}
+
+func TestHandler_RestoreUser(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		userID         string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:   "success",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().RestoreUser(gomock.Any(), userID).Return(entity.User{ID: userID, Version: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid id",
+			userID:         "invalid-id",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "not deleted",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().RestoreUser(gomock.Any(), userID).Return(entity.User{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "email taken",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().RestoreUser(gomock.Any(), userID).Return(entity.User{}, entity.ErrAlreadyExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/users/"+tt.userID+"/restore", nil)
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.RestoreUser(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_GetUserByIDIncludeDeleted(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+	deletedAt := time.Now()
+
+	mockUserService.EXPECT().GetUserByIDWithDeleted(gomock.Any(), userID).
+		Return(entity.User{ID: userID, DeletedAt: &deletedAt}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/users?include_deleted=true&id="+userID.String(), nil)
+	r.NoError(err)
+
+	rr := httptest.NewRecorder()
+	handler.GetUsers(rr, req)
+
+	r.Equal(http.StatusOK, rr.Code)
+
+	req, err = http.NewRequest(http.MethodGet, "/users?include_deleted=maybe&id="+userID.String(), nil)
+	r.NoError(err)
+
+	rr = httptest.NewRecorder()
+	handler.GetUsers(rr, req)
+
+	r.Equal(http.StatusBadRequest, rr.Code)
+}
+
+func TestHandler_GetUserHistory(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		userID         string
+		query          string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:   "success",
+			userID: userID.String(),
+			query:  "?limit=10&cursor=abc",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserHistory(gomock.Any(), userID, "abc", 10).Return(entity.AuditPage{
+					Items: []entity.AuditRecord{{ID: 1, UserID: userID, Action: entity.AuditCreated}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "default limit",
+			userID: userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserHistory(gomock.Any(), userID, "", defaultPageLimit).Return(entity.AuditPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid id",
+			userID:         "invalid-id",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			userID:         userID.String(),
+			query:          "?limit=0",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid cursor",
+			userID: userID.String(),
+			query:  "?cursor=bad",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserHistory(gomock.Any(), userID, "bad", defaultPageLimit).
+					Return(entity.AuditPage{}, entity.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodGet, "/users/"+tt.userID+"/history"+tt.query, nil)
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.GetUserHistory(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
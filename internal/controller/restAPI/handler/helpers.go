@@ -3,22 +3,31 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"users-app/internal/entity"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
 )
 
-type ResponseError struct {
-	Message string `json:"message"`
-}
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
 
-func (h *Handler) sendErr(w http.ResponseWriter, code int, err error, msg string) {
-	h.log.ErrorF("api error: %s, code = %d", err.Error(), code)
+// lang negotiates the response language and announces it to the client.
+func (h *Handler) lang(w http.ResponseWriter, r *http.Request) string {
+	lang := h.catalog.Negotiate(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", lang)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
+	return lang
+}
 
-	if err := json.NewEncoder(w).Encode(ResponseError{Message: msg}); err != nil {
-		h.log.ErrorF("failed to send error: %s", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+func (h *Handler) message(w http.ResponseWriter, r *http.Request, key string) string {
+	return h.catalog.Message(h.lang(w, r), key, nil)
 }
 
 type emptyJSON struct{}
@@ -36,3 +45,219 @@ func (h *Handler) sendJSON(w http.ResponseWriter, code int, data any) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func parseListUsersParams(query url.Values) (entity.ListUsersParams, error) {
+	params := entity.ListUsersParams{
+		Filter: entity.UserFilter{
+			NamePrefix:  query.Get("name_prefix"),
+			EmailDomain: query.Get("email_domain"),
+		},
+		Sort:   entity.UserSort{Field: entity.SortByName},
+		Cursor: query.Get("cursor"),
+		Limit:  defaultPageLimit,
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		field, desc := strings.CutPrefix(sort, "-")
+
+		params.Sort = entity.UserSort{Field: entity.SortField(field), Desc: desc}
+		if !params.Sort.Field.Valid() {
+			return params, entity.NewDetailError(errInvalidQuery, "detail.sort_unsupported", map[string]any{"field": field})
+		}
+	}
+
+	var err error
+
+	if params.Limit, err = parsePageLimit(query); err != nil {
+		return params, err
+	}
+
+	if params.Filter.IncludeDeleted, err = parseBoolParam(query, "include_deleted"); err != nil {
+		return params, err
+	}
+
+	if params.Filter.MinAge, err = parseIntParam(query, "min_age"); err != nil {
+		return params, err
+	}
+
+	if params.Filter.MaxAge, err = parseIntParam(query, "max_age"); err != nil {
+		return params, err
+	}
+
+	if params.Filter.MinBalance, err = parseDecimalParam(query, "min_balance"); err != nil {
+		return params, err
+	}
+
+	if params.Filter.MaxBalance, err = parseDecimalParam(query, "max_balance"); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+func parsePageLimit(query url.Values) (int, error) {
+	limit := query.Get("limit")
+	if limit == "" {
+		return defaultPageLimit, nil
+	}
+
+	n, err := strconv.Atoi(limit)
+	if err != nil || n < 1 || n > maxPageLimit {
+		return 0, entity.NewDetailError(errInvalidQuery, "detail.limit_out_of_range", map[string]any{"max": maxPageLimit})
+	}
+
+	return n, nil
+}
+
+func parseIntParam(query url.Values, name string) (*int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, entity.NewDetailError(errInvalidQuery, "detail.param_not_number", map[string]any{"param": name, "value": v})
+	}
+
+	return &n, nil
+}
+
+func parseBoolParam(query url.Values, name string) (bool, error) {
+	v := query.Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, entity.NewDetailError(errInvalidQuery, "detail.param_not_boolean", map[string]any{"param": name, "value": v})
+	}
+
+	return b, nil
+}
+
+func parseDecimalParam(query url.Values, name string) (*decimal.Decimal, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return nil, entity.NewDetailError(errInvalidQuery, "detail.param_not_number", map[string]any{"param": name, "value": v})
+	}
+
+	return &d, nil
+}
+
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the version referenced by an If-Match header. "*"
+// matches any version and is returned as zero. If-Match compares entity tags
+// strongly, so a weak tag never matches: a header of weak tags only fails the
+// precondition, and weak tags next to a strong one are ignored. A list may
+// name one version only, the one the client saw last.
+func parseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, nil
+	}
+
+	invalid := entity.NewDetailError(errInvalidPrecondition, "detail.if_match_invalid", map[string]any{"tag": header})
+
+	var (
+		version int64
+		weak    bool
+	)
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		if weakTag, ok := strings.CutPrefix(tag, "W/"); ok {
+			if _, ok := unquoteTag(weakTag); !ok {
+				return 0, invalid
+			}
+
+			weak = true
+
+			continue
+		}
+
+		opaque, ok := unquoteTag(tag)
+		if !ok {
+			return 0, invalid
+		}
+
+		v, err := strconv.ParseInt(opaque, 10, 64)
+		if err != nil || v < 1 {
+			return 0, invalid
+		}
+
+		if version != 0 && v != version {
+			return 0, entity.NewDetailError(errInvalidPrecondition, "detail.if_match_list", nil)
+		}
+
+		version = v
+	}
+
+	switch {
+	case version != 0:
+		return version, nil
+	case weak:
+		return 0, entity.NewDetailError(errPreconditionFailed, "detail.if_match_weak", nil)
+	default:
+		return 0, invalid
+	}
+}
+
+// unquoteTag returns the opaque part of a quoted entity tag.
+func unquoteTag(tag string) (string, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' || strings.Contains(tag[1:len(tag)-1], `"`) {
+		return "", false
+	}
+
+	return tag[1 : len(tag)-1], true
+}
+
+// userIDParam returns the user id from the path of a versioned route, or from
+// the id query parameter of a legacy one.
+func userIDParam(r *http.Request) string {
+	if id := chi.URLParam(r, "id"); id != "" {
+		return id
+	}
+
+	return r.URL.Query().Get("id")
+}
+
+// userLocation is the canonical URL of a user, sent in the Location header
+// whichever route created it.
+func userLocation(id uuid.UUID) string {
+	return "/api/v1/users/" + id.String()
+}
+
+func webhookLocation(id uuid.UUID) string {
+	return "/api/v1/webhooks/" + id.String()
+}
+
+func reviewLocation(id int64) string {
+	return "/api/v1/reviews/" + strconv.FormatInt(id, 10)
+}
+
+func parseUserID(id string) (uuid.UUID, error) {
+	if id == "" {
+		return uuid.Nil, entity.NewDetailError(errInvalidID, "detail.id_empty", nil)
+	}
+
+	userID, err := uuid.FromString(id)
+	if err != nil {
+		return uuid.Nil, entity.NewDetailError(errInvalidID, "detail.id_invalid", map[string]any{"id": id})
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"users-app/internal/controller/restAPI/openapi"
+	"users-app/internal/entity"
+	"users-app/internal/idempotency"
+	"users-app/internal/ratelimit"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/shopspring/decimal"
+)
+
+const problemTypePrefix = "urn:users-app:problem:"
+
+// Errors raised by the transport itself, before the service is reached.
+var (
+	errInvalidID            = errors.New("invalid id")
+	errInvalidBody          = errors.New("invalid request body")
+	errInvalidQuery         = errors.New("invalid query parameter")
+	errPreconditionRequired = errors.New("precondition required")
+	errInvalidPrecondition  = errors.New("invalid precondition")
+	errPreconditionFailed   = errors.New("precondition failed")
+	errUnsupportedMediaType = errors.New("unsupported media type")
+	errInternal             = errors.New("internal error")
+)
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []entity.FieldError `json:"errors,omitempty"`
+
+	// Limit, Remaining and Currency tell which spending limit an operation
+	// would exceed and what is left of it.
+	Limit     entity.LimitKind `json:"limit,omitempty"`
+	Remaining *decimal.Decimal `json:"remaining,omitempty"`
+	Currency  string           `json:"currency,omitempty"`
+}
+
+type problemType struct {
+	err    error
+	status int
+	code   string
+}
+
+// problemTypes maps errors to problem types. The first entry matching with
+// errors.Is wins; a new domain error only needs a line here and a title in
+// the locales to get a stable code and status across every endpoint.
+var problemTypes = []problemType{
+	{entity.ErrUnauthenticated, http.StatusUnauthorized, "unauthorized"},
+	{entity.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{entity.ErrValidation, http.StatusUnprocessableEntity, "validation_failed"},
+	{entity.ErrNotFound, http.StatusNotFound, "not_found"},
+	{entity.ErrAlreadyExists, http.StatusConflict, "already_exists"},
+	{entity.ErrConflict, http.StatusPreconditionFailed, "version_conflict"},
+	{entity.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{entity.ErrInvalidPatch, http.StatusUnprocessableEntity, "invalid_patch"},
+	{entity.ErrInvalidTransfer, http.StatusBadRequest, "invalid_transfer"},
+	{entity.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds"},
+	{entity.ErrHoldNotActive, http.StatusConflict, "hold_not_active"},
+	{entity.ErrNoExchangeRate, http.StatusUnprocessableEntity, "exchange_rate_unavailable"},
+	{entity.ErrLimitExceeded, http.StatusUnprocessableEntity, "limit_exceeded"},
+	{entity.ErrOperationDenied, http.StatusUnprocessableEntity, "operation_denied"},
+	{entity.ErrReviewNotPending, http.StatusConflict, "review_not_pending"},
+	{errInvalidID, http.StatusBadRequest, "invalid_id"},
+	{errInvalidBody, http.StatusBadRequest, "invalid_body"},
+	{errInvalidQuery, http.StatusBadRequest, "invalid_query"},
+	{errPreconditionRequired, http.StatusPreconditionRequired, "precondition_required"},
+	{errInvalidPrecondition, http.StatusBadRequest, "invalid_precondition"},
+	{errPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{errUnsupportedMediaType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{openapi.ErrInvalidRequest, http.StatusBadRequest, "invalid_request"},
+	{ratelimit.ErrLimited, http.StatusTooManyRequests, "rate_limited"},
+	{idempotency.ErrInvalidKey, http.StatusBadRequest, "invalid_idempotency_key"},
+	{idempotency.ErrInUse, http.StatusConflict, "idempotency_key_in_use"},
+	{idempotency.ErrReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+}
+
+var internalProblem = problemType{errInternal, http.StatusInternalServerError, "internal_error"}
+
+func lookupProblem(err error) problemType {
+	for _, p := range problemTypes {
+		if errors.Is(err, p.err) {
+			return p
+		}
+	}
+
+	return internalProblem
+}
+
+// newProblem builds the problem details for err in lang. The underlying error
+// text is only logged, never sent to the client.
+func (h *Handler) newProblem(r *http.Request, err error, lang string) Problem {
+	pt := lookupProblem(err)
+
+	problem := Problem{
+		Type:      problemTypePrefix + pt.code,
+		Title:     h.catalog.Message(lang, "problem."+pt.code+".title", nil),
+		Status:    pt.status,
+		Detail:    h.catalog.Message(lang, "problem."+pt.code+".detail", nil),
+		Instance:  r.URL.Path,
+		Code:      pt.code,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+
+	var detailErr *entity.DetailError
+	if errors.As(err, &detailErr) {
+		problem.Detail = h.catalog.Message(lang, detailErr.Key, detailErr.Args)
+	}
+
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) {
+		problem.Errors = make([]entity.FieldError, 0, len(validationErr.Fields))
+
+		for _, f := range validationErr.Fields {
+			args := map[string]any{"field": f.Field}
+			for k, v := range f.Params {
+				args[k] = v
+			}
+
+			f.Message = h.catalog.Message(lang, "validation."+f.Code, args)
+			problem.Errors = append(problem.Errors, f)
+		}
+	}
+
+	var limitErr *entity.LimitError
+	if errors.As(err, &limitErr) {
+		problem.Limit = limitErr.Limit
+		problem.Remaining = &limitErr.Remaining
+		problem.Currency = limitErr.Currency
+		problem.Detail = h.catalog.Message(lang, "problem.limit_exceeded.detail", map[string]any{
+			"limit":     h.catalog.Message(lang, "limit."+string(limitErr.Limit), nil),
+			"remaining": limitErr.Remaining.String(),
+			"currency":  limitErr.Currency,
+		})
+	}
+
+	var requestErr *openapi.RequestError
+	if errors.As(err, &requestErr) {
+		problem.Errors = []entity.FieldError{{Field: requestErr.Field, Code: "schema_mismatch", Message: requestErr.Reason}}
+	}
+
+	return problem
+}
+
+// SendErr renders err as problem details, so that middlewares report errors
+// the same way as the handlers.
+func (h *Handler) SendErr(w http.ResponseWriter, r *http.Request, err error) {
+	h.sendErr(w, r, err)
+}
+
+func (h *Handler) sendErr(w http.ResponseWriter, r *http.Request, err error) {
+	lang := h.lang(w, r)
+	problem := h.newProblem(r, err, lang)
+
+	h.log.ErrorF("api error: %s, code = %d, request_id = %s", err.Error(), problem.Status, problem.RequestID)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		h.log.ErrorF("failed to send error: %s", err.Error())
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"users-app/internal/entity"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// pendingOperation answers a balance operation held for review. It leaves out
+// the rule that fired, which only admins get to see.
+type pendingOperation struct {
+	ReviewID int64               `json:"review_id"`
+	Status   entity.ReviewStatus `json:"status"`
+}
+
+func (h *Handler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit, err := parsePageLimit(r.URL.Query())
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	page, err := h.userService.ListReviews(ctx, entity.ReviewStatus(r.URL.Query().Get("status")),
+		r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) GetReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseReviewID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	review, err := h.userService.GetReview(ctx, id)
+	if err != nil {
+		h.sendReviewErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, review)
+}
+
+func (h *Handler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseReviewID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	review, err := h.userService.ApproveReview(ctx, id)
+	if err != nil {
+		h.sendReviewErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, review)
+}
+
+func (h *Handler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseReviewID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	review, err := h.userService.RejectReview(ctx, id)
+	if err != nil {
+		h.sendReviewErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, review)
+}
+
+// sendOperationErr is sendErr for deposits, withdrawals, transfers and holds:
+// an operation held for review is accepted with 202 and the location of its
+// review.
+func (h *Handler) sendOperationErr(w http.ResponseWriter, r *http.Request, err error) {
+	var reviewErr *entity.ReviewError
+
+	if errors.As(err, &reviewErr) {
+		w.Header().Set("Location", reviewLocation(reviewErr.Review.ID))
+		h.sendJSON(w, http.StatusAccepted, pendingOperation{
+			ReviewID: reviewErr.Review.ID,
+			Status:   reviewErr.Review.Status,
+		})
+
+		return
+	}
+
+	h.sendErr(w, r, err)
+}
+
+// sendReviewErr is sendErr with a not-found detail that names reviews rather
+// than users.
+func (h *Handler) sendReviewErr(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, entity.ErrNotFound) {
+		err = entity.NewDetailError(err, "detail.review_not_found", nil)
+	}
+
+	h.sendErr(w, r, err)
+}
+
+func parseReviewID(id string) (int64, error) {
+	reviewID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || reviewID < 1 {
+		return 0, entity.NewDetailError(errInvalidID, "detail.review_id_invalid", map[string]any{"id": id})
+	}
+
+	return reviewID, nil
+}
@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/pkg/i18n"
+	"users-app/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/mock/gomock"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ListReviews(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	tests := []struct {
+		name           string
+		query          string
+		mockBehavior   func()
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "first page",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListReviews(gomock.Any(), entity.ReviewStatus(""), "", defaultPageLimit).
+					Return(entity.RiskReviewPage{Items: []entity.RiskReview{{ID: 1, Status: entity.ReviewPending}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "pending only",
+			query: "?status=pending&cursor=abc&limit=10",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListReviews(gomock.Any(), entity.ReviewPending, "abc", 10).
+					Return(entity.RiskReviewPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "limit out of range",
+			query:          "?limit=0",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_query",
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=bad",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListReviews(gomock.Any(), entity.ReviewStatus(""), "bad", defaultPageLimit).
+					Return(entity.RiskReviewPage{}, entity.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_cursor",
+		},
+		{
+			name: "without permission",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListReviews(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(entity.RiskReviewPage{}, entity.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/reviews"+tt.query, nil)
+			r.NoError(err)
+
+			rr := httptest.NewRecorder()
+			handler.ListReviews(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_DecideReview(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	tests := []struct {
+		name           string
+		action         string
+		reviewID       string
+		mockBehavior   func()
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+	}{
+		{
+			name:     "get",
+			action:   "get",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetReview(gomock.Any(), int64(42)).
+					Return(entity.RiskReview{ID: 42, Status: entity.ReviewPending}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "get with invalid id",
+			action:         "get",
+			reviewID:       "abc",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_id",
+		},
+		{
+			name:           "get with id below one",
+			action:         "get",
+			reviewID:       "0",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_id",
+		},
+		{
+			name:     "get a missing review",
+			action:   "get",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetReview(gomock.Any(), int64(42)).Return(entity.RiskReview{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+			expectedDetail: catalog.Message("en", "detail.review_not_found", nil),
+		},
+		{
+			name:     "approve",
+			action:   "approve",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ApproveReview(gomock.Any(), int64(42)).
+					Return(entity.RiskReview{ID: 42, Status: entity.ReviewApproved}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "approve a decided review",
+			action:   "approve",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ApproveReview(gomock.Any(), int64(42)).
+					Return(entity.RiskReview{}, entity.ErrReviewNotPending)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "review_not_pending",
+		},
+		{
+			name:     "approve an operation that fails",
+			action:   "approve",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ApproveReview(gomock.Any(), int64(42)).
+					Return(entity.RiskReview{}, entity.ErrInsufficientFunds)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "insufficient_funds",
+		},
+		{
+			name:     "reject",
+			action:   "reject",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RejectReview(gomock.Any(), int64(42)).
+					Return(entity.RiskReview{ID: 42, Status: entity.ReviewRejected}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "reject a decided review",
+			action:   "reject",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RejectReview(gomock.Any(), int64(42)).
+					Return(entity.RiskReview{}, entity.ErrReviewNotPending)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "review_not_pending",
+		},
+		{
+			name:     "reject a missing review",
+			action:   "reject",
+			reviewID: "42",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RejectReview(gomock.Any(), int64(42)).Return(entity.RiskReview{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+			expectedDetail: catalog.Message("en", "detail.review_not_found", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/reviews/"+tt.reviewID, nil)
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.reviewID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			switch tt.action {
+			case "get":
+				handler.GetReview(rr, req)
+			case "approve":
+				handler.ApproveReview(rr, req)
+			case "reject":
+				handler.RejectReview(rr, req)
+			}
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+
+				if tt.expectedDetail != "" {
+					r.Equal(tt.expectedDetail, resp.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_ScreenedTransfer(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	from := uuid.Must(uuid.NewV4())
+	to := uuid.Must(uuid.NewV4())
+	body := `{"from_user_id": "` + from.String() + `", "to_user_id": "` + to.String() + `", "amount": "50000"}`
+
+	tests := []struct {
+		name             string
+		err              error
+		expectedStatus   int
+		expectedCode     string
+		expectedLocation string
+		expectedBody     string
+	}{
+		{
+			name: "held for review",
+			err: &entity.ReviewError{Review: entity.RiskReview{
+				ID: 12, Rule: "amount_threshold", Reason: "amount over 10000", Status: entity.ReviewPending,
+			}},
+			expectedStatus:   http.StatusAccepted,
+			expectedLocation: "/api/v1/reviews/12",
+			expectedBody:     `{"review_id":12,"status":"pending"}`,
+		},
+		{
+			name:           "denied",
+			err:            entity.ErrOperationDenied,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "operation_denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, tt.err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/transfers", strings.NewReader(body))
+			r.NoError(err)
+
+			rr := httptest.NewRecorder()
+			handler.CreateTransfer(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+			r.Equal(tt.expectedLocation, rr.Header().Get("Location"))
+
+			if tt.expectedBody != "" {
+				// the rule that fired is only shown to admins
+				r.JSONEq(tt.expectedBody, rr.Body.String())
+			}
+
+			if tt.expectedCode != "" {
+				var resp Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+				r.Equal(tt.expectedCode, resp.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/events"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// StreamUserEvents serves GET /api/users/events as a Server-Sent Events
+// stream. Clients can narrow it with user_id and type parameters, given
+// repeatedly or comma-separated, and resume after a reconnect by sending
+// the Last-Event-ID header.
+func (h *Handler) StreamUserEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	if err := h.userService.AuthorizeUserEvents(ctx, slices.Collect(maps.Keys(filter.UserIDs))); err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	// A malformed Last-Event-ID is treated like a fresh connection.
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	sub, missed := h.stream.Subscribe(lastID, filter)
+	defer h.stream.Unsubscribe(sub)
+
+	rc := http.NewResponseController(w)
+
+	// The stream outlives the server write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, msg := range missed {
+		if err := writeEvent(w, msg); err != nil {
+			return
+		}
+	}
+
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.stream.Heartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return
+			}
+
+			if err := writeEvent(w, msg); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, msg events.Message) error {
+	data, err := json.Marshal(msg.Event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event.Type, data)
+
+	return err
+}
+
+func parseEventFilter(query url.Values) (events.Filter, error) {
+	filter := events.Filter{
+		UserIDs: make(map[uuid.UUID]struct{}),
+		Types:   make(map[entity.EventType]struct{}),
+	}
+
+	for _, id := range splitParam(query, "user_id") {
+		userID, err := parseUserID(id)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.UserIDs[userID] = struct{}{}
+	}
+
+	for _, t := range splitParam(query, "type") {
+		eventType := entity.EventType(t)
+		if !eventType.Valid() {
+			return filter, entity.NewDetailError(errInvalidQuery, "detail.event_type_unsupported", map[string]any{"type": t})
+		}
+
+		filter.Types[eventType] = struct{}{}
+	}
+
+	return filter, nil
+}
+
+// splitParam returns every value of a parameter that may be repeated or
+// comma-separated.
+func splitParam(query url.Values, name string) []string {
+	var values []string
+
+	for _, v := range query[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
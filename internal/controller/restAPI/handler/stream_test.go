@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/events"
+	"users-app/internal/mocks"
+	"users-app/pkg/i18n"
+	"users-app/pkg/logger"
+
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/mock/gomock"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_StreamUserEvents(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	stream := events.NewBroker(10, 8, time.Hour)
+	mockUserService := mocks.NewMockUserService(ctrl)
+	handler := New(log, catalog, mockUserService, stream)
+
+	watched := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+
+	mockUserService.EXPECT().AuthorizeUserEvents(gomock.Any(), []uuid.UUID{watched}).Return(nil)
+
+	// Published before the client connects and replayed through Last-Event-ID.
+	stream.Publish(entity.Event{Type: entity.EventUserCreated, UserID: watched})
+	stream.Publish(entity.Event{Type: entity.EventUserCreated, UserID: other})
+
+	srv := httptest.NewServer(http.HandlerFunc(handler.StreamUserEvents))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"?user_id="+watched.String(), nil)
+	r.NoError(err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := srv.Client().Do(req)
+	r.NoError(err)
+	defer resp.Body.Close()
+
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	nextEvent := func() string {
+		for lines.Scan() {
+			if eventType, ok := strings.CutPrefix(lines.Text(), "event: "); ok {
+				return eventType
+			}
+		}
+
+		return ""
+	}
+
+	r.Equal(string(entity.EventUserCreated), nextEvent())
+
+	stream.Publish(entity.Event{Type: entity.EventUserDeleted, UserID: other})
+	stream.Publish(entity.Event{Type: entity.EventUserBalanceChanged, UserID: watched})
+
+	r.Equal(string(entity.EventUserBalanceChanged), nextEvent())
+
+	// Shutting the broker down ends the stream.
+	stream.Close()
+	r.Empty(nextEvent())
+}
+
+func TestHandler_StreamUserEventsInvalidFilter(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mocks.NewMockUserService(ctrl), events.NewBroker(10, 8, time.Hour))
+
+	for _, query := range []string{"?user_id=abc", "?type=user.renamed", "?type=user.created,bogus"} {
+		req, err := http.NewRequest(http.MethodGet, "/users/events"+query, nil)
+		r.NoError(err)
+
+		rr := httptest.NewRecorder()
+		handler.StreamUserEvents(rr, req)
+
+		r.Equal(http.StatusBadRequest, rr.Code, query)
+	}
+}
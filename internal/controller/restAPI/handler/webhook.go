@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"users-app/internal/entity"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+)
+
+type webhookRequest struct {
+	URL    string             `json:"url"`
+	Events []entity.EventType `json:"events"`
+	Active *bool              `json:"active"`
+}
+
+// subscription converts the request to a subscription; it is active unless
+// the client says otherwise.
+func (req webhookRequest) subscription(id uuid.UUID) entity.WebhookSubscription {
+	active := req.Active == nil || *req.Active
+
+	return entity.WebhookSubscription{
+		ID:     id,
+		URL:    req.URL,
+		Events: req.Events,
+		Active: active,
+	}
+}
+
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req webhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	sub, err := h.userService.CreateWebhook(ctx, req.subscription(uuid.Nil))
+	if err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", webhookLocation(sub.ID))
+	h.sendJSON(w, http.StatusCreated, sub)
+}
+
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.userService.ListWebhooks(r.Context())
+	if err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, subs)
+}
+
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseWebhookID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	sub, err := h.userService.GetWebhook(ctx, id)
+	if err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, sub)
+}
+
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseWebhookID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	var req webhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidBody, "detail.body_malformed", nil))
+		return
+	}
+
+	sub, err := h.userService.UpdateWebhook(ctx, req.subscription(id))
+	if err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, sub)
+}
+
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseWebhookID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	if err := h.userService.DeleteWebhook(ctx, id); err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, h.message(w, r, "message.webhook_deleted"))
+}
+
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseWebhookID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	limit, err := parsePageLimit(r.URL.Query())
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	page, err := h.userService.ListWebhookDeliveries(ctx, id, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseWebhookID(chi.URLParam(r, "id"))
+	if err != nil {
+		h.sendErr(w, r, err)
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "delivery_id"), 10, 64)
+	if err != nil || deliveryID < 1 {
+		h.sendErr(w, r, entity.NewDetailError(errInvalidID, "detail.delivery_id_invalid",
+			map[string]any{"id": chi.URLParam(r, "delivery_id")}))
+		return
+	}
+
+	delivery, err := h.userService.RedeliverWebhook(ctx, id, deliveryID)
+	if err != nil {
+		h.sendWebhookErr(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, http.StatusAccepted, delivery)
+}
+
+// sendWebhookErr is sendErr with a not-found detail that names webhooks
+// rather than users.
+func (h *Handler) sendWebhookErr(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, entity.ErrNotFound) {
+		err = entity.NewDetailError(err, "detail.webhook_not_found", nil)
+	}
+
+	h.sendErr(w, r, err)
+}
+
+func parseWebhookID(id string) (uuid.UUID, error) {
+	webhookID, err := uuid.FromString(id)
+	if err != nil {
+		return uuid.Nil, entity.NewDetailError(errInvalidID, "detail.webhook_id_invalid", map[string]any{"id": id})
+	}
+
+	return webhookID, nil
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/pkg/i18n"
+	"users-app/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/mock/gomock"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: `{"url": "https://example.com/hook", "events": ["user.created"]}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateWebhook(gomock.Any(), entity.WebhookSubscription{
+					URL:    "https://example.com/hook",
+					Events: []entity.EventType{entity.EventUserCreated},
+					Active: true,
+				}).Return(entity.WebhookSubscription{ID: uuid.Must(uuid.NewV4()), Secret: "whsec_x"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "inactive",
+			body: `{"url": "https://example.com/hook", "active": false}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateWebhook(gomock.Any(), entity.WebhookSubscription{
+					URL: "https://example.com/hook",
+				}).Return(entity.WebhookSubscription{}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "malformed body",
+			body:           `{`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid url",
+			body: `{"url": "not a url"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(entity.WebhookSubscription{},
+					&entity.ValidationError{Fields: []entity.FieldError{{Field: "url", Code: "invalid_format"}}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.body))
+			r.NoError(err)
+
+			rr := httptest.NewRecorder()
+			handler.CreateWebhook(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_GetWebhook(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	webhookID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		id             string
+		mockBehavior   func()
+		expectedStatus int
+		expectedDetail string
+	}{
+		{
+			name: "success",
+			id:   webhookID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetWebhook(gomock.Any(), webhookID).Return(entity.WebhookSubscription{ID: webhookID}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: "abc is not a valid webhook id.",
+		},
+		{
+			name: "not found",
+			id:   webhookID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetWebhook(gomock.Any(), webhookID).Return(entity.WebhookSubscription{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedDetail: "The requested webhook or delivery does not exist.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodGet, "/webhooks/"+tt.id, nil)
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.GetWebhook(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+
+			if tt.expectedDetail != "" {
+				var problem Problem
+				r.NoError(json.NewDecoder(rr.Body).Decode(&problem))
+				r.Equal(tt.expectedDetail, problem.Detail)
+			}
+		})
+	}
+}
+
+func TestHandler_RedeliverWebhook(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	handler := New(log, catalog, mockUserService, nil)
+
+	webhookID := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name           string
+		deliveryID     string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:       "success",
+			deliveryID: "7",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RedeliverWebhook(gomock.Any(), webhookID, int64(7)).
+					Return(entity.WebhookDelivery{ID: 7, Status: entity.DeliveryPending}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "invalid delivery id",
+			deliveryID:     "x",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			deliveryID: "8",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RedeliverWebhook(gomock.Any(), webhookID, int64(8)).
+					Return(entity.WebhookDelivery{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req, err := http.NewRequest(http.MethodPost, "/webhooks/"+webhookID.String()+"/deliveries/"+tt.deliveryID+"/redeliver", nil)
+			r.NoError(err)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", webhookID.String())
+			rctx.URLParams.Add("delivery_id", tt.deliveryID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.RedeliverWebhook(rr, req)
+
+			r.Equal(tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -1,18 +1,53 @@
 package middlewares
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"users-app/internal/auth"
+	"users-app/internal/entity"
+	"users-app/internal/idempotency"
+	"users-app/internal/ratelimit"
 	"users-app/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
+// APIKeyAuthenticator resolves the keys sent in the X-API-Key header.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (entity.APIKey, error)
+}
+
 type Middleware struct {
-	log logger.Logger
+	log      logger.Logger
+	verifier *auth.Verifier
+	apiKeys  APIKeyAuthenticator
+	limiter  *ratelimit.Limiter
+	keeper   *idempotency.Keeper
+	sendErr  func(w http.ResponseWriter, r *http.Request, err error)
 }
 
-func New(log logger.Logger) *Middleware {
+// New returns the middlewares. Errors are written with sendErr so that they
+// share the handlers' response format. A nil verifier disables bearer token
+// authentication, a nil limiter rate limiting and a nil keeper idempotency
+// keys.
+func New(log logger.Logger, verifier *auth.Verifier, apiKeys APIKeyAuthenticator, limiter *ratelimit.Limiter,
+	keeper *idempotency.Keeper, sendErr func(w http.ResponseWriter, r *http.Request, err error)) *Middleware {
 	return &Middleware{
-		log: log,
+		log:      log,
+		verifier: verifier,
+		apiKeys:  apiKeys,
+		limiter:  limiter,
+		keeper:   keeper,
+		sendErr:  sendErr,
 	}
 }
 
@@ -21,7 +56,7 @@ func (m *Middleware) Log(next http.Handler) http.Handler {
 		var headers string
 
 		for k, v := range r.Header {
-			if k == "authorization" || k == "Cookie" {
+			if k == "Authorization" || k == "Cookie" || k == "X-Api-Key" {
 				continue
 			}
 
@@ -34,3 +69,331 @@ func (m *Middleware) Log(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(r.Context()))
 	})
 }
+
+// Actor attributes the request to its caller and request ID so that the
+// service layer can record who made a change.
+func (m *Middleware) Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := entity.WithActor(r.Context(), entity.Actor{Kind: entity.ActorAnonymous, ID: r.RemoteAddr})
+		ctx = entity.WithRequestID(ctx, middleware.GetReqID(ctx))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// APIKey authenticates requests carrying an X-API-Key header and attributes
+// them to the key. Requests without the header are passed on to
+// Authenticate. It must run after Actor.
+func (m *Middleware) APIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plain := r.Header.Get("X-API-Key")
+		if plain == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := m.apiKeys.AuthenticateAPIKey(r.Context(), plain)
+		if err != nil {
+			m.sendErr(w, r, err)
+			return
+		}
+
+		m.log.InfoF("request authenticated with api key: key_id = %s, prefix = %s, name = %s, request_id = %s",
+			key.ID, key.Prefix, key.Name, middleware.GetReqID(r.Context()))
+
+		ctx := entity.WithClaims(r.Context(), key.Claims())
+		ctx = entity.WithActor(ctx, key.Actor())
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Authenticate requires a valid bearer token, puts its claims into the
+// context and attributes the request to the token's subject. Requests
+// already authenticated by APIKey are passed through. It must run after
+// Actor.
+func (m *Middleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := entity.ClaimsFromContext(r.Context()); ok || m.verifier == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := auth.BearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="users-app"`)
+			m.sendErr(w, r, fmt.Errorf("%w: no bearer token", entity.ErrUnauthenticated))
+
+			return
+		}
+
+		claims, err := m.verifier.Verify(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="users-app", error="invalid_token"`)
+			m.sendErr(w, r, err)
+
+			return
+		}
+
+		ctx := entity.WithClaims(r.Context(), claims)
+		ctx = entity.WithActor(ctx, entity.Actor{Kind: entity.ActorUser, ID: claims.Subject})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RateLimitAddress takes a token from the bucket of the client address and
+// rejects the request with 429 when none is left. It runs before APIKey and
+// Authenticate, so that requests failing authentication are limited too.
+// Requests are let through when the store fails.
+func (m *Middleware) RateLimitAddress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		addr := clientAddr(r)
+
+		res, err := m.limiter.TakeAddress(r.Context(), addr)
+		if m.limited(w, r, "ip:"+addr, res, err) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RateLimit takes a token from the caller's bucket for the route and rejects
+// the request with 429 when none is left. Callers are told apart by API key,
+// token subject or, when anonymous, address, so it must run after APIKey and
+// Authenticate. Requests are let through when the store fails.
+func (m *Middleware) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The route is not matched yet, so its pattern is looked up from the
+		// root router.
+		var pattern string
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.Routes != nil {
+			pattern = rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+		}
+
+		client := clientID(r)
+
+		res, err := m.limiter.Take(r.Context(), client, r.Method, pattern)
+		if m.limited(w, r, client, res, err) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limited describes the bucket of client in the response headers and, when
+// the request took no token, answers it with 429. It reports whether the
+// request was answered.
+func (m *Middleware) limited(w http.ResponseWriter, r *http.Request, client string, res ratelimit.Result,
+	err error) bool {
+	if err != nil {
+		m.log.ErrorF("failed to check rate limit, letting the request through: %s, request_id = %s",
+			err.Error(), middleware.GetReqID(r.Context()))
+
+		return false
+	}
+
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", res.Limit.Requests, ceilSeconds(res.Limit.Period)))
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit.Requests))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+	if res.Allowed {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+	m.sendErr(w, r, fmt.Errorf("%w: %s on %s %s", ratelimit.ErrLimited, client, r.Method, r.URL.Path))
+
+	return true
+}
+
+// clientID tells callers apart by API key, token subject or, when anonymous,
+// address.
+func clientID(r *http.Request) string {
+	switch actor := entity.ActorFromContext(r.Context()); actor.Kind {
+	case entity.ActorAPIKey:
+		return "api_key:" + actor.ID
+	case entity.ActorUser:
+		return "user:" + actor.ID
+	}
+
+	return "ip:" + clientAddr(r)
+}
+
+// clientAddr returns the address of the client. RealIP has replaced it with
+// the client's when the request came through a proxy.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return host
+}
+
+// Idempotency makes the mutating requests sent with an Idempotency-Key
+// header safe to retry: the first response is stored, and retries get it
+// back marked with Idempotent-Replayed instead of being served again. Keys
+// are scoped to the caller, so it must run after APIKey and Authenticate.
+// Server errors are not stored, so that the request can be retried.
+func (m *Middleware) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || m.keeper == nil || !mutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := idempotency.ValidateKey(key); err != nil {
+			m.sendErr(w, r, err)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			m.sendErr(w, r, fmt.Errorf("failed to read request body: %w", err))
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		scope := clientID(r)
+
+		stored, err := m.keeper.Begin(ctx, scope, key, idempotency.Fingerprint(r.Method, r.URL.RequestURI(), body))
+		if err != nil {
+			m.sendErr(w, r, err)
+			return
+		}
+
+		if stored != nil {
+			for k, v := range stored.Header {
+				w.Header()[k] = v
+			}
+
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+
+			if _, err := w.Write(stored.Body); err != nil {
+				m.log.ErrorF("failed to replay response: %s", err.Error())
+			}
+
+			return
+		}
+
+		// The key is released if the handler panics, and the outcome is
+		// recorded even if the client has gone.
+		done := context.WithoutCancel(ctx)
+		completed := false
+
+		defer func() {
+			if completed {
+				return
+			}
+
+			if err := m.keeper.Release(done, scope, key); err != nil {
+				m.log.ErrorF("failed to release idempotency key: %s, request_id = %s",
+					err.Error(), middleware.GetReqID(ctx))
+			}
+		}()
+
+		before := w.Header().Clone()
+
+		var buf bytes.Buffer
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&buf)
+
+		// A retry may take the key over once the lock lapses, so the request
+		// must not run on past it.
+		lockCtx, cancel := context.WithTimeout(ctx, m.keeper.LockTimeout())
+		defer cancel()
+
+		next.ServeHTTP(ww, r.WithContext(lockCtx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		err = m.keeper.Complete(done, scope, key, idempotency.Response{
+			Status: status,
+			Header: addedHeader(before, w.Header()),
+			Body:   buf.Bytes(),
+		})
+		if err != nil {
+			m.log.ErrorF("failed to store idempotent response: %s, request_id = %s",
+				err.Error(), middleware.GetReqID(ctx))
+			return
+		}
+
+		completed = true
+	})
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// addedHeader returns the header fields the handler set, leaving out those
+// of earlier middlewares, such as the rate limit, that describe the request
+// at hand rather than its response.
+func addedHeader(before, after http.Header) http.Header {
+	added := make(http.Header)
+
+	for k, v := range after {
+		if !slices.Equal(before[k], v) {
+			added[k] = v
+		}
+	}
+
+	return added
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// Deprecated marks the responses of legacy routes with the Deprecation (RFC
+// 9745) and Sunset (RFC 8594) headers and links them to the successor of the
+// request.
+func (m *Middleware) Deprecated(deprecatedAt, sunsetAt time.Time,
+	successor func(r *http.Request) string) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunset := sunsetAt.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunset)
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor(r)))
+
+			m.log.WarnF("deprecated route called: method = %s, url = %s, user_ip = %s",
+				r.Method, r.URL.String(), r.RemoteAddr)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package openapi holds the OpenAPI document of the REST API, serves it
+// together with a browsable reference, and validates traffic against it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	//go:embed openapi.json
+	spec []byte
+
+	//go:embed docs.html
+	docs []byte
+)
+
+// ErrInvalidRequest is matched by every RequestError.
+var ErrInvalidRequest = errors.New("request does not match the api specification")
+
+// RequestError describes where a request departs from the document. Field is
+// a parameter name or a JSON pointer into the body.
+type RequestError struct {
+	Field  string
+	Reason string
+}
+
+func (e *RequestError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", ErrInvalidRequest, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidRequest, e.Field, e.Reason)
+}
+
+func (e *RequestError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// Load parses and validates the document.
+func Load(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %w", err)
+	}
+
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// ServeSpec serves GET /api/openapi.json.
+func ServeSpec(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(spec)
+}
+
+// ServeDocs serves GET /api/docs, a Redoc page rendering the document.
+func ServeDocs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(docs)
+}
@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"users-app/pkg/logger"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Validator checks requests, and optionally responses, against the document.
+type Validator struct {
+	log               logger.Logger
+	router            routers.Router
+	validateResponses bool
+	sendErr           func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// NewValidator returns a validator rejecting invalid requests through
+// sendErr. With validateResponses, responses are checked too and mismatches
+// are logged; the response is sent unchanged.
+func NewValidator(ctx context.Context, log logger.Logger, validateResponses bool,
+	sendErr func(w http.ResponseWriter, r *http.Request, err error)) (*Validator, error) {
+	doc, err := Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build openapi router: %w", err)
+	}
+
+	return &Validator{
+		log:               log,
+		router:            router,
+		validateResponses: validateResponses,
+		sendErr:           sendErr,
+	}, nil
+}
+
+// Validate is the validating middleware. Requests to routes missing from the
+// document are passed through untouched.
+func (v *Validator) Validate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		}
+
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			v.sendErr(w, r, toRequestError(err))
+			return
+		}
+
+		if !v.validateResponses || streams(route.Operation) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if err := openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rec.status,
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+			Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+		}); err != nil {
+			v.log.ErrorF("response does not match the api specification: method = %s, url = %s, error = %s",
+				r.Method, r.URL.String(), err.Error())
+		}
+
+		rec.flush()
+	})
+}
+
+// streams reports whether the operation answers with an event stream, which
+// must not be buffered.
+func streams(op *openapi3.Operation) bool {
+	for _, resp := range op.Responses.Map() {
+		if resp.Value != nil && resp.Value.Content.Get("text/event-stream") != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toRequestError(err error) *RequestError {
+	reqErr := &RequestError{Reason: err.Error()}
+
+	var filterErr *openapi3filter.RequestError
+	if errors.As(err, &filterErr) {
+		reqErr.Reason = filterErr.Reason
+
+		if filterErr.Parameter != nil {
+			reqErr.Field = filterErr.Parameter.Name
+		}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		reqErr.Reason = schemaErr.Reason
+
+		if reqErr.Field == "" {
+			reqErr.Field = "/" + strings.Join(schemaErr.JSONPointer(), "/")
+		}
+	}
+
+	if reqErr.Reason == "" {
+		reqErr.Reason = err.Error()
+	}
+
+	return reqErr
+}
+
+// recorder holds the response back until it has been validated.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *recorder) flush() {
+	r.ResponseWriter.WriteHeader(r.status)
+	_, _ = r.ResponseWriter.Write(r.body.Bytes())
+}
@@ -1,24 +1,133 @@
 package router
 
 import (
+	"net/http"
+	"net/url"
+	"strings"
+	"users-app/internal/controller/restAPI/graphql"
 	"users-app/internal/controller/restAPI/handler"
 	"users-app/internal/controller/restAPI/middlewares"
+	"users-app/internal/controller/restAPI/openapi"
+	"users-app/pkg/config"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-func New(mw *middlewares.Middleware, h *handler.Handler) chi.Router {
+// New mounts every API version under /api. A new version gets its own
+// subtree, e.g. r.Route("/v2", v2Routes(h2)), next to /v1 so that both are
+// served at once.
+func New(mw *middlewares.Middleware, h *handler.Handler, gql *graphql.Handler, v *openapi.Validator,
+	legacy config.LegacyRoutes) chi.Router {
 	r := chi.NewRouter()
 
 	r.Route("/api", func(r chi.Router) {
-		r.Use(middleware.RealIP, middleware.Recoverer, mw.Log)
+		r.Use(middleware.RequestID, middleware.RealIP, middleware.Recoverer, mw.Log, mw.Actor)
 
-		r.Get("/users", h.GetUserByID)
+		// The API description is public so that clients can learn how to
+		// authenticate.
+		r.Group(func(r chi.Router) {
+			r.Use(v.Validate)
+
+			r.Get("/openapi.json", openapi.ServeSpec)
+			r.Get("/docs", openapi.ServeDocs)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RateLimitAddress, mw.APIKey, mw.Authenticate, mw.RateLimit, v.Validate, mw.Idempotency)
+
+			r.Method(http.MethodPost, "/graphql", gql)
+
+			r.Route("/v1", v1Routes(h))
+
+			r.Group(func(r chi.Router) {
+				r.Use(mw.Deprecated(legacy.DeprecatedAt, legacy.SunsetAt, legacySuccessor))
+				legacyRoutes(h)(r)
+			})
+		})
+	})
+
+	return r
+}
+
+func v1Routes(h *handler.Handler) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/users", h.ListUsers)
+		r.Post("/users", h.CreateUser)
+		r.Get("/users/events", h.StreamUserEvents)
+		r.Get("/users/{id}", h.GetUserByID)
+		r.Put("/users/{id}", h.UpdateUser)
+		r.Patch("/users/{id}", h.PatchUser)
+		r.Delete("/users/{id}", h.DeleteUser)
+		r.Post("/users/{id}/restore", h.RestoreUser)
+		r.Get("/users/{id}/history", h.GetUserHistory)
+
+		r.Post("/transfers", h.CreateTransfer)
+
+		r.Get("/users/{id}/balances", h.GetBalances)
+		r.Post("/users/{id}/deposits", h.CreateDeposit)
+		r.Post("/users/{id}/withdrawals", h.CreateWithdrawal)
+		r.Post("/users/{id}/holds", h.CreateHold)
+		r.Get("/users/{id}/holds/{hold_id}", h.GetHold)
+		r.Post("/users/{id}/holds/{hold_id}/capture", h.CaptureHold)
+		r.Post("/users/{id}/holds/{hold_id}/release", h.ReleaseHold)
+		r.Get("/users/{id}/limits", h.GetLimits)
+		r.Put("/users/{id}/limits", h.SetLimits)
+
+		r.Get("/reviews", h.ListReviews)
+		r.Get("/reviews/{id}", h.GetReview)
+		r.Post("/reviews/{id}/approve", h.ApproveReview)
+		r.Post("/reviews/{id}/reject", h.RejectReview)
+
+		r.Post("/webhooks", h.CreateWebhook)
+		r.Get("/webhooks", h.ListWebhooks)
+		r.Get("/webhooks/{id}", h.GetWebhook)
+		r.Put("/webhooks/{id}", h.UpdateWebhook)
+		r.Delete("/webhooks/{id}", h.DeleteWebhook)
+		r.Get("/webhooks/{id}/deliveries", h.ListWebhookDeliveries)
+		r.Post("/webhooks/{id}/deliveries/{delivery_id}/redeliver", h.RedeliverWebhook)
+
+		r.Post("/api-keys", h.CreateAPIKey)
+		r.Get("/api-keys", h.ListAPIKeys)
+		r.Delete("/api-keys/{id}", h.RevokeAPIKey)
+	}
+}
+
+// legacyRoutes are the unversioned routes, kept until their sunset. Users are
+// addressed by the id query parameter, and PUT takes the id from the body.
+// legacySuccessor maps a legacy route to its /api/v1 counterpart: the same
+// path, except for the legacy routes that take the user id as a query
+// parameter, whose successors take it in the path.
+func legacySuccessor(r *http.Request) string {
+	path := "/api/v1" + strings.TrimPrefix(r.URL.Path, "/api")
+
+	id := r.URL.Query().Get("id")
+	if path == "/api/v1/users" && id != "" && (r.Method == http.MethodGet || r.Method == http.MethodDelete) {
+		path += "/" + url.PathEscape(id)
+	}
+
+	return path
+}
+
+func legacyRoutes(h *handler.Handler) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/users", h.GetUsers)
+		r.Get("/users/events", h.StreamUserEvents)
 		r.Post("/users", h.CreateUser)
 		r.Put("/users", h.UpdateUser)
+		r.Patch("/users/{id}", h.PatchUser)
+		r.Post("/users/{id}/restore", h.RestoreUser)
+		r.Get("/users/{id}/history", h.GetUserHistory)
 		r.Delete("/users", h.DeleteUser)
-	})
 
-	return r
+		r.Post("/transfers", h.CreateTransfer)
+
+		r.Post("/webhooks", h.CreateWebhook)
+		r.Get("/webhooks", h.ListWebhooks)
+		r.Get("/webhooks/{id}", h.GetWebhook)
+		r.Put("/webhooks/{id}", h.UpdateWebhook)
+		r.Delete("/webhooks/{id}", h.DeleteWebhook)
+		r.Get("/webhooks/{id}/deliveries", h.ListWebhookDeliveries)
+		r.Post("/webhooks/{id}/deliveries/{delivery_id}/redeliver", h.RedeliverWebhook)
+	}
 }
@@ -0,0 +1,1089 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/auth"
+	"users-app/internal/controller/restAPI/graphql"
+	"users-app/internal/controller/restAPI/handler"
+	"users-app/internal/controller/restAPI/middlewares"
+	"users-app/internal/controller/restAPI/openapi"
+	"users-app/internal/controller/restAPI/router"
+	"users-app/internal/entity"
+	"users-app/internal/events"
+	"users-app/internal/idempotency"
+	"users-app/internal/mocks"
+	"users-app/internal/ratelimit"
+	"users-app/pkg/config"
+	"users-app/pkg/i18n"
+	"users-app/pkg/logger"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/mock/gomock"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T, userService handler.UserService) chi.Router {
+	t.Helper()
+
+	return newAuthRouter(t, userService, nil)
+}
+
+// newAuthRouter returns a router authenticating requests with verifier.
+func newAuthRouter(t *testing.T, userService handler.UserService, verifier *auth.Verifier) chi.Router {
+	t.Helper()
+
+	return newRouterWith(t, userService, middlewareDeps{verifier: verifier})
+}
+
+// middlewareDeps enable the middlewares that are off in tests by default.
+type middlewareDeps struct {
+	verifier *auth.Verifier
+	limiter  *ratelimit.Limiter
+	keeper   *idempotency.Keeper
+}
+
+func newRouterWith(t *testing.T, userService handler.UserService, deps middlewareDeps) chi.Router {
+	t.Helper()
+
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	catalog, err := i18n.New("en")
+	require.NoError(t, err)
+
+	h := handler.New(log, catalog, userService, events.NewBroker(10, 10, time.Minute))
+
+	v, err := openapi.NewValidator(context.Background(), log, false, h.SendErr)
+	require.NoError(t, err)
+
+	legacy := config.LegacyRoutes{
+		DeprecatedAt: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		SunsetAt:     time.Date(2027, 4, 17, 0, 0, 0, 0, time.UTC),
+	}
+
+	return router.New(middlewares.New(log, deps.verifier, userService, deps.limiter, deps.keeper, h.SendErr), h, graphql.New(log, userService), v, legacy)
+}
+
+// TestRouter_RoutesMatchSpec fails when a route is added to the router but
+// not to the document, or the other way round.
+func TestRouter_RoutesMatchSpec(t *testing.T) {
+	r := require.New(t)
+
+	doc, err := openapi.Load(context.Background())
+	r.NoError(err)
+
+	var documented []string
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	var routed []string
+
+	err = chi.Walk(newRouter(t, nil), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routed = append(routed, method+" "+route)
+		return nil
+	})
+	r.NoError(err)
+
+	r.ElementsMatch(documented, routed)
+}
+
+// TestRouter_ResponsesMatchSpec sends a request to every operation and checks
+// the response against the document.
+func TestRouter_ResponsesMatchSpec(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newRouter(t, mockUserService)
+
+	doc, err := openapi.Load(context.Background())
+	require.NoError(t, err)
+
+	specRouter, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	userID, otherID, webhookID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	user := entity.User{ID: userID, Name: "test", Email: "test@example.com", Age: 30, Balance: decimal.RequireFromString("10.5"), Version: 3}
+	deleted := user
+	deleted.DeletedAt = &now
+	sub := entity.WebhookSubscription{ID: webhookID, URL: "https://example.com/hook", Events: []entity.EventType{entity.EventUserCreated}, Active: true, CreatedAt: now, UpdatedAt: now}
+	delivery := entity.WebhookDelivery{ID: 7, SubscriptionID: webhookID, EventID: uuid.Must(uuid.NewV4()), EventType: entity.EventUserCreated,
+		Payload: json.RawMessage(`{"id":"x"}`), Status: entity.DeliveryPending, Attempts: 1, NextAttemptAt: now, CreatedAt: now}
+	apiKey := entity.APIKey{ID: uuid.Must(uuid.NewV4()), Name: "nightly export", Prefix: "ua_0a1b2c3d",
+		Scopes: []entity.Permission{entity.PermUsersRead}, CreatedBy: entity.Actor{Kind: entity.ActorUser, ID: "admin"}, CreatedAt: now}
+	transfer := entity.Transfer{ID: uuid.Must(uuid.NewV4()), FromUserID: userID, ToUserID: otherID, Amount: decimal.NewFromInt(5),
+		Currency: "USD", ToAmount: decimal.NewFromInt(5), ToCurrency: "USD", CreatedAt: now}
+	hold := entity.Hold{ID: uuid.Must(uuid.NewV4()), UserID: userID, Amount: decimal.NewFromInt(5), Status: entity.HoldActive,
+		ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	daily, weekly := decimal.NewFromInt(500), decimal.NewFromInt(2000)
+	limits := entity.UserLimits{UserID: userID, Tier: entity.DefaultLimitTier, Currency: "USD",
+		Overrides: entity.SpendingLimits{Daily: &daily},
+		Limits:    entity.SpendingLimits{Daily: &daily, Weekly: &weekly},
+		Spent:     entity.Spending{Daily: decimal.NewFromInt(100), Weekly: decimal.NewFromInt(100), Monthly: decimal.NewFromInt(100)},
+		Remaining: entity.SpendingLimits{PerTransaction: &daily, Daily: &daily, Weekly: &weekly}}
+	review := entity.RiskReview{ID: 12, RiskOperation: entity.RiskOperation{Kind: entity.OperationTransfer, UserID: userID,
+		CounterpartyID: &otherID, Amount: decimal.NewFromInt(20000), Currency: "USD", ToCurrency: "USD", Value: decimal.NewFromInt(20000)},
+		Rule: "amount_threshold", Reason: "transfer worth 20000 reaches 10000", Status: entity.ReviewPending, CreatedAt: now}
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		contentType    string
+		header         map[string]string
+		body           string
+		mockBehavior   func()
+		expectedStatus int
+	}{
+		{
+			name:   "get user",
+			method: http.MethodGet, target: "/api/users?id=" + userID.String() + "&include_deleted=true",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByIDWithDeleted(gomock.Any(), userID).Return(deleted, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get user v1",
+			method: http.MethodGet, target: "/api/v1/users/" + userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "list users v1",
+			method: http.MethodGet, target: "/api/v1/users?name_prefix=t",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(entity.UserPage{Items: []entity.User{user}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "create user v1",
+			method: http.MethodPost, target: "/api/v1/users", contentType: "application/json",
+			body: `{"id":"` + userID.String() + `","name":"test","email":"test@example.com","age":30}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "update user v1",
+			method: http.MethodPut, target: "/api/v1/users/" + userID.String(), contentType: "application/json",
+			header: map[string]string{"If-Match": "*"},
+			body:   `{"name":"test","email":"test@example.com","age":31,"balance":"10"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update user v1 without balance",
+			method: http.MethodPut, target: "/api/v1/users/" + userID.String(), contentType: "application/json",
+			header: map[string]string{"If-Match": "*"},
+			body:   `{"name":"test","email":"test@example.com","age":31}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update user v1 with mismatching id",
+			method: http.MethodPut, target: "/api/v1/users/" + userID.String(), contentType: "application/json",
+			header:         map[string]string{"If-Match": "*"},
+			body:           `{"id":"` + otherID.String() + `","name":"test","email":"test@example.com","age":31}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "delete user v1",
+			method: http.MethodDelete, target: "/api/v1/users/" + userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().DeleteUser(gomock.Any(), userID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "transfer v1",
+			method: http.MethodPost, target: "/api/v1/transfers", contentType: "application/json",
+			body: `{"from_user_id":"` + userID.String() + `","to_user_id":"` + otherID.String() + `","amount":5}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(transfer, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "balances v1",
+			method: http.MethodGet, target: "/api/v1/users/" + userID.String() + "/balances?currency=EUR",
+			mockBehavior: func() {
+				mockUserService.EXPECT().Balances(gomock.Any(), userID, "EUR").Return(entity.Balances{
+					UserID: userID,
+					Wallets: []entity.Wallet{
+						{Currency: "USD", Balance: user.Balance, HeldBalance: decimal.Zero},
+						{Currency: "EUR", Balance: decimal.NewFromInt(3), HeldBalance: decimal.Zero},
+					},
+					Total: &entity.Money{Currency: "EUR", Amount: decimal.RequireFromString("12.72")},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "balances v1 with invalid currency",
+			method: http.MethodGet, target: "/api/v1/users/" + userID.String() + "/balances?currency=euro",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "deposit v1",
+			method: http.MethodPost, target: "/api/v1/users/" + userID.String() + "/deposits", contentType: "application/json",
+			body: `{"amount":"5"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Deposit(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{
+					ID: uuid.Must(uuid.NewV4()), UserID: userID, Kind: entity.OperationDeposit,
+					Amount: decimal.NewFromInt(5), Balance: decimal.RequireFromString("15.5"), CreatedAt: now,
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "create hold v1",
+			method: http.MethodPost, target: "/api/v1/users/" + userID.String() + "/holds", contentType: "application/json",
+			body: `{"amount":5}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Return(hold, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "capture hold v1 without body",
+			method: http.MethodPost, target: "/api/v1/users/" + userID.String() + "/holds/" + hold.ID.String() + "/capture",
+			mockBehavior: func() {
+				captured := hold
+				captured.Status, captured.CapturedAmount, captured.SettledAt = entity.HoldCaptured, hold.Amount, &now
+				mockUserService.EXPECT().CaptureHold(gomock.Any(), userID, hold.ID, decimal.Decimal{}).Return(captured, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "release inactive hold v1",
+			method: http.MethodPost, target: "/api/v1/users/" + userID.String() + "/holds/" + hold.ID.String() + "/release",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ReleaseHold(gomock.Any(), userID, hold.ID).Return(entity.Hold{}, entity.ErrHoldNotActive)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "withdraw v1 over limit",
+			method: http.MethodPost, target: "/api/v1/users/" + userID.String() + "/withdrawals", contentType: "application/json",
+			body: `{"amount":"500"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Withdraw(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{},
+					&entity.LimitError{Limit: entity.LimitDaily, Remaining: decimal.NewFromInt(120), Currency: "USD"})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "get limits v1",
+			method: http.MethodGet, target: "/api/v1/users/" + userID.String() + "/limits",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetLimits(gomock.Any(), userID).Return(limits, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "set limits v1",
+			method: http.MethodPut, target: "/api/v1/users/" + userID.String() + "/limits", contentType: "application/json",
+			body: `{"tier":"standard","daily":500,"monthly":null}`,
+			mockBehavior: func() {
+				daily := decimal.NewFromInt(500)
+				mockUserService.EXPECT().SetLimits(gomock.Any(), userID, "standard", entity.SpendingLimits{Daily: &daily}).
+					Return(limits, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "deposit v1 held for review",
+			method: http.MethodPost, target: "/api/v1/users/" + userID.String() + "/deposits", contentType: "application/json",
+			body: `{"amount":"20000"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Deposit(gomock.Any(), userID, gomock.Any()).Return(entity.Movement{},
+					&entity.ReviewError{Review: review})
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "transfer v1 denied",
+			method: http.MethodPost, target: "/api/v1/transfers", contentType: "application/json",
+			body: `{"from_user_id":"` + userID.String() + `","to_user_id":"` + otherID.String() + `","amount":"500000"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(entity.Transfer{}, entity.ErrOperationDenied)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "list reviews v1",
+			method: http.MethodGet, target: "/api/v1/reviews?status=pending&limit=10",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListReviews(gomock.Any(), entity.ReviewPending, "", 10).
+					Return(entity.RiskReviewPage{Items: []entity.RiskReview{review}, NextCursor: "MTI"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "approve review v1",
+			method: http.MethodPost, target: "/api/v1/reviews/12/approve",
+			mockBehavior: func() {
+				approved := review
+				approved.Status, approved.OperationID, approved.DecidedAt = entity.ReviewApproved, &transfer.ID, &now
+				approved.DecidedBy = &entity.Actor{Kind: entity.ActorUser, ID: "admin"}
+				mockUserService.EXPECT().ApproveReview(gomock.Any(), int64(12)).Return(approved, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "reject decided review v1",
+			method: http.MethodPost, target: "/api/v1/reviews/12/reject",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RejectReview(gomock.Any(), int64(12)).Return(entity.RiskReview{}, entity.ErrReviewNotPending)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "get missing review v1",
+			method: http.MethodGet, target: "/api/v1/reviews/13",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetReview(gomock.Any(), int64(13)).Return(entity.RiskReview{}, entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "create webhook v1",
+			method: http.MethodPost, target: "/api/v1/webhooks", contentType: "application/json",
+			body: `{"url":"https://example.com/hook"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(sub, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "create api key v1",
+			method: http.MethodPost, target: "/api/v1/api-keys", contentType: "application/json",
+			body: `{"name":"nightly export","scopes":["users:read"],"expires_at":"2030-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				created := apiKey
+				created.Key = "ua_0a1b2c3d_" + strings.Repeat("f", 64)
+				mockUserService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(created, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "list api keys v1",
+			method: http.MethodGet, target: "/api/v1/api-keys",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListAPIKeys(gomock.Any()).Return([]entity.APIKey{apiKey}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "revoke api key v1",
+			method: http.MethodDelete, target: "/api/v1/api-keys/" + apiKey.ID.String(),
+			mockBehavior: func() {
+				revoked := apiKey
+				revoked.RevokedAt = &now
+				mockUserService.EXPECT().RevokeAPIKey(gomock.Any(), apiKey.ID).Return(revoked, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "list users",
+			method: http.MethodGet, target: "/api/users?sort=-age&limit=10&min_balance=1.5",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).
+					Return(entity.UserPage{Items: []entity.User{user}, NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "list users with invalid limit",
+			method: http.MethodGet, target: "/api/users?limit=1000",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create user",
+			method: http.MethodPost, target: "/api/users", contentType: "application/json",
+			body: `{"id":"` + userID.String() + `","name":"test","email":"test@example.com","age":30,"balance":10.5}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "create user with wrong field type",
+			method: http.MethodPost, target: "/api/users", contentType: "application/json",
+			body:           `{"name":"test","email":"test@example.com","age":"thirty"}`,
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "create user failing validation",
+			method: http.MethodPost, target: "/api/users", contentType: "application/json",
+			body: `{"name":"","email":"test@example.com","age":30}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).
+					Return(&entity.ValidationError{Fields: []entity.FieldError{{Field: "name", Code: "required"}}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "update user",
+			method: http.MethodPut, target: "/api/users", contentType: "application/json",
+			header: map[string]string{"If-Match": `"3"`},
+			body:   `{"id":"` + userID.String() + `","name":"test","email":"test@example.com","age":31,"balance":"10.5"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "patch user",
+			method: http.MethodPatch, target: "/api/users/" + userID.String(), contentType: "application/json-patch+json",
+			body: `[{"op":"replace","path":"/name","value":"new"}]`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().PatchUser(gomock.Any(), userID, gomock.Any()).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete missing user",
+			method: http.MethodDelete, target: "/api/users?id=" + userID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().DeleteUser(gomock.Any(), userID).Return(entity.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "restore user",
+			method: http.MethodPost, target: "/api/users/" + userID.String() + "/restore",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RestoreUser(gomock.Any(), userID).Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "user history",
+			method: http.MethodGet, target: "/api/users/" + userID.String() + "/history",
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserHistory(gomock.Any(), userID, "", 50).Return(entity.AuditPage{Items: []entity.AuditRecord{{
+					ID: 1, UserID: userID, Action: entity.AuditUpdated, Actor: entity.SystemActor,
+					Changes: map[string]entity.FieldChange{"name": {Before: "a", After: "b"}}, CreatedAt: now,
+				}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "transfer",
+			method: http.MethodPost, target: "/api/transfers", contentType: "application/json",
+			body: `{"from_user_id":"` + userID.String() + `","to_user_id":"` + otherID.String() + `","amount":"5"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().Transfer(gomock.Any(), gomock.Any()).Return(transfer, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "graphql",
+			method: http.MethodPost, target: "/api/graphql", contentType: "application/json",
+			body: `{"query":"{ userByEmail(email: \"test@example.com\") { id } }"}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetUserByEmail(gomock.Any(), "test@example.com").Return(user, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "create webhook",
+			method: http.MethodPost, target: "/api/webhooks", contentType: "application/json",
+			body: `{"url":"https://example.com/hook","events":["user.created"]}`,
+			mockBehavior: func() {
+				created := sub
+				created.Secret = "secret"
+				mockUserService.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(created, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "list webhooks",
+			method: http.MethodGet, target: "/api/webhooks",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListWebhooks(gomock.Any()).Return([]entity.WebhookSubscription{sub}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get webhook",
+			method: http.MethodGet, target: "/api/webhooks/" + webhookID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().GetWebhook(gomock.Any(), webhookID).Return(sub, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "update webhook",
+			method: http.MethodPut, target: "/api/webhooks/" + webhookID.String(), contentType: "application/json",
+			body: `{"url":"https://example.com/hook","active":false}`,
+			mockBehavior: func() {
+				mockUserService.EXPECT().UpdateWebhook(gomock.Any(), gomock.Any()).Return(sub, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "delete webhook",
+			method: http.MethodDelete, target: "/api/webhooks/" + webhookID.String(),
+			mockBehavior: func() {
+				mockUserService.EXPECT().DeleteWebhook(gomock.Any(), webhookID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "list webhook deliveries",
+			method: http.MethodGet, target: "/api/webhooks/" + webhookID.String() + "/deliveries?limit=5",
+			mockBehavior: func() {
+				mockUserService.EXPECT().ListWebhookDeliveries(gomock.Any(), webhookID, "", 5).
+					Return(entity.WebhookDeliveryPage{Items: []entity.WebhookDelivery{delivery}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "redeliver webhook",
+			method: http.MethodPost, target: "/api/webhooks/" + webhookID.String() + "/deliveries/7/redeliver",
+			mockBehavior: func() {
+				mockUserService.EXPECT().RedeliverWebhook(gomock.Any(), webhookID, int64(7)).Return(delivery, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "openapi document",
+			method:         http.MethodGet,
+			target:         "/api/openapi.json",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "docs",
+			method:         http.MethodGet,
+			target:         "/api/docs",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			r.Equal(tt.expectedStatus, w.Code, w.Body.String())
+
+			specReq := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			route, pathParams, err := specRouter.FindRoute(specReq)
+			r.NoError(err)
+
+			err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    specReq,
+					PathParams: pathParams,
+					Route:      route,
+				},
+				Status:  w.Code,
+				Header:  w.Header(),
+				Body:    io.NopCloser(bytes.NewReader(w.Body.Bytes())),
+				Options: &openapi3filter.Options{IncludeResponseStatus: true},
+			})
+			r.NoError(err)
+		})
+	}
+}
+
+func TestRouter_LegacyRoutesAreDeprecated(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newRouter(t, mockUserService)
+
+	userID := uuid.Must(uuid.NewV4())
+	user := entity.User{ID: userID, Name: "test", Email: "test@example.com"}
+
+	mockUserService.EXPECT().CreateUser(gomock.Any(), user).Return(nil).Times(2)
+
+	body := `{"id":"` + userID.String() + `","name":"test","email":"test@example.com"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	r.Equal(http.StatusCreated, w.Code)
+	r.Equal("/api/v1/users/"+userID.String(), w.Header().Get("Location"))
+	r.Equal("@1792195200", w.Header().Get("Deprecation"))
+	r.Equal("Sat, 17 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	r.Equal(`</api/v1/users>; rel="successor-version"`, w.Header().Get("Link"))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	r.Equal(http.StatusCreated, w.Code)
+	r.Equal("/api/v1/users/"+userID.String(), w.Header().Get("Location"))
+	r.Empty(w.Header().Get("Deprecation"))
+	r.Empty(w.Header().Get("Sunset"))
+
+	// the legacy routes taking the id as a query parameter link to the user
+	mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).Return(user, nil)
+	mockUserService.EXPECT().DeleteUser(gomock.Any(), userID).Return(nil)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		req = httptest.NewRequest(method, "/api/users?id="+userID.String(), nil)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		r.Equal(http.StatusOK, w.Code, method)
+		r.Equal(`</api/v1/users/`+userID.String()+`>; rel="successor-version"`, w.Header().Get("Link"), method)
+	}
+}
+
+func TestRouter_RequiresBearerToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	verifier, err := auth.NewVerifier(context.Background(), log,
+		auth.NewJWKSFile(writeJWKS(t, `{"keys":[{"kty":"oct","kid":"test","k":"`+
+			base64.RawURLEncoding.EncodeToString(secret)+`"}]}`)),
+		auth.Options{Audience: "users-app"})
+	require.NoError(t, err)
+
+	mint := func(key []byte, exp time.Time) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "user-1",
+			"aud": "users-app",
+			"exp": exp.Unix(),
+		})
+		token.Header["kid"] = "test"
+
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		return signed
+	}
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newAuthRouter(t, mockUserService, verifier)
+
+	userID := uuid.Must(uuid.NewV4())
+	userPath := "/api/v1/users/" + userID.String()
+
+	mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+		DoAndReturn(func(ctx context.Context, id uuid.UUID) (entity.User, error) {
+			claims, ok := entity.ClaimsFromContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, "user-1", claims.Subject)
+			require.Equal(t, entity.Actor{Kind: entity.ActorUser, ID: "user-1"}, entity.ActorFromContext(ctx))
+
+			return entity.User{ID: id, Name: "test", Email: "test@example.com"}, nil
+		})
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{
+			name:          "valid token",
+			path:          userPath,
+			wantStatus:    http.StatusOK,
+			authorization: "Bearer " + mint(secret, time.Now().Add(time.Hour)),
+		},
+		{
+			name:          "no token",
+			path:          userPath,
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="users-app"`,
+		},
+		{
+			name:          "other scheme",
+			path:          userPath,
+			authorization: "Basic dXNlcjpwYXNz",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="users-app"`,
+		},
+		{
+			name:          "expired token",
+			path:          userPath,
+			authorization: "Bearer " + mint(secret, time.Now().Add(-time.Hour)),
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="users-app", error="invalid_token"`,
+		},
+		{
+			name:          "token signed by another key",
+			path:          userPath,
+			authorization: "Bearer " + mint([]byte("another secret of thirty-two bytes"), time.Now().Add(time.Hour)),
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="users-app", error="invalid_token"`,
+		},
+		{
+			name:          "graphql",
+			path:          "/api/graphql",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer realm="users-app"`,
+		},
+		{
+			name:       "openapi document is public",
+			path:       "/api/openapi.json",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			method := http.MethodGet
+			if tt.path == "/api/graphql" {
+				method = http.MethodPost
+			}
+
+			req := httptest.NewRequest(method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			r.Equal(tt.wantStatus, w.Code)
+			r.Equal(tt.wantChallenge, w.Header().Get("WWW-Authenticate"))
+
+			if tt.wantStatus == http.StatusUnauthorized {
+				var problem handler.Problem
+				r.NoError(json.NewDecoder(w.Body).Decode(&problem))
+				r.Equal("urn:users-app:problem:unauthorized", problem.Type)
+				r.Equal(http.StatusUnauthorized, problem.Status)
+			}
+		})
+	}
+}
+
+func writeJWKS(t *testing.T, jwks string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(jwks), 0o600))
+
+	return path
+}
+
+func TestRouter_APIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newRouter(t, mockUserService)
+
+	userID := uuid.Must(uuid.NewV4())
+	key := entity.APIKey{ID: uuid.Must(uuid.NewV4()), Prefix: "ua_0a1b2c3d", Scopes: []entity.Permission{entity.PermUsersRead}}
+	plain := key.Prefix + "_" + strings.Repeat("f", 64)
+
+	tests := []struct {
+		name         string
+		key          string
+		mockBehavior func()
+		wantStatus   int
+	}{
+		{
+			name: "valid key",
+			key:  plain,
+			mockBehavior: func() {
+				mockUserService.EXPECT().AuthenticateAPIKey(gomock.Any(), plain).Return(key, nil)
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+					DoAndReturn(func(ctx context.Context, id uuid.UUID) (entity.User, error) {
+						require.Equal(t, entity.Actor{Kind: entity.ActorAPIKey, ID: key.ID.String()}, entity.ActorFromContext(ctx))
+
+						claims, ok := entity.ClaimsFromContext(ctx)
+						require.True(t, ok)
+						require.Equal(t, []string{"users:read"}, claims.Scopes)
+
+						return entity.User{ID: id, Name: "test", Email: "test@example.com"}, nil
+					})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "revoked key",
+			key:  plain,
+			mockBehavior: func() {
+				mockUserService.EXPECT().AuthenticateAPIKey(gomock.Any(), plain).
+					Return(entity.APIKey{}, fmt.Errorf("revoked: %w", entity.ErrUnauthenticated))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userID.String(), nil)
+			req.Header.Set("X-API-Key", tt.key)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRouter_RateLimit(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	limiter, err := ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "100/h", "100/h",
+		map[string]string{"GET /api/v1/users/{id}": "1/h"})
+	r.NoError(err)
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newRouterWith(t, mockUserService, middlewareDeps{limiter: limiter})
+
+	userID := uuid.Must(uuid.NewV4())
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userID.String(), nil)
+		req.Header.Set("X-Real-IP", ip)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		return w
+	}
+
+	mockUserService.EXPECT().GetUserByID(gomock.Any(), userID).
+		Return(entity.User{ID: userID, Name: "test", Email: "test@example.com"}, nil).Times(2)
+
+	w := send("203.0.113.1")
+	r.Equal(http.StatusOK, w.Code)
+	r.Equal("1", w.Header().Get("RateLimit-Limit"))
+	r.Equal("0", w.Header().Get("RateLimit-Remaining"))
+	r.Equal("3600", w.Header().Get("RateLimit-Reset"))
+	r.Equal("1;w=3600", w.Header().Get("RateLimit-Policy"))
+	r.Empty(w.Header().Get("Retry-After"))
+
+	w = send("203.0.113.1")
+	r.Equal(http.StatusTooManyRequests, w.Code)
+	r.Equal("3600", w.Header().Get("Retry-After"))
+
+	var problem handler.Problem
+	r.NoError(json.Unmarshal(w.Body.Bytes(), &problem))
+	r.Equal("rate_limited", problem.Code)
+
+	// Another client has its own bucket, and the other routes share the
+	// default one.
+	w = send("203.0.113.2")
+	r.Equal(http.StatusOK, w.Code)
+
+	mockUserService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(entity.UserPage{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	req.Header.Set("X-Real-IP", "203.0.113.1")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	r.Equal(http.StatusOK, w.Code)
+	r.Equal("100", w.Header().Get("RateLimit-Limit"))
+	r.Equal("99", w.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRouter_RateLimitAddress(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	limiter, err := ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "100/h", "2/h", nil)
+	r.NoError(err)
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	h := newRouterWith(t, mockUserService, middlewareDeps{limiter: limiter})
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.Header.Set("X-Real-IP", ip)
+		req.Header.Set("X-API-Key", "ua_guess")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		return w
+	}
+
+	// Failed authentication counts against the address, so guessing keys
+	// is limited before the keys are looked up.
+	mockUserService.EXPECT().AuthenticateAPIKey(gomock.Any(), "ua_guess").
+		Return(entity.APIKey{}, entity.ErrUnauthenticated).Times(3)
+
+	r.Equal(http.StatusUnauthorized, send("203.0.113.1").Code)
+	r.Equal(http.StatusUnauthorized, send("203.0.113.1").Code)
+
+	w := send("203.0.113.1")
+	r.Equal(http.StatusTooManyRequests, w.Code)
+	r.Equal("2", w.Header().Get("RateLimit-Limit"))
+	r.NotEmpty(w.Header().Get("Retry-After"))
+
+	r.Equal(http.StatusUnauthorized, send("203.0.113.2").Code)
+}
+
+func TestRouter_Idempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+	keeper := idempotency.New(mockRepo, idempotency.Options{TTL: time.Hour, LockTimeout: time.Minute})
+	h := newRouterWith(t, mockUserService, middlewareDeps{keeper: keeper})
+
+	userID := uuid.Must(uuid.NewV4())
+	body := `{"id":"` + userID.String() + `","name":"test","email":"test@example.com","age":30}`
+	fingerprint := idempotency.Fingerprint(http.MethodPost, "/api/v1/users", []byte(body))
+
+	// stored is what the first request left behind.
+	var stored entity.IdempotencyRecord
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("first request is stored", func(t *testing.T) {
+		r := require.New(t)
+
+		mockRepo.EXPECT().ClaimIdempotencyKey(gomock.Any(), "ip:192.0.2.1", "k1", fingerprint, time.Hour, time.Minute).
+			Return(entity.IdempotencyRecord{}, true, nil)
+		mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ entity.User) error {
+				// the request may not outlive its lock on the key
+				deadline, ok := ctx.Deadline()
+				r.True(ok)
+				r.WithinDuration(time.Now().Add(time.Minute), deadline, time.Second)
+
+				return nil
+			},
+		)
+		mockRepo.EXPECT().CompleteIdempotencyKey(gomock.Any(), "ip:192.0.2.1", "k1", http.StatusCreated, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, status int, header map[string][]string, body []byte) error {
+				now := time.Now()
+				stored = entity.IdempotencyRecord{Fingerprint: fingerprint, Status: status, Header: header, Body: body, CompletedAt: &now}
+
+				return nil
+			})
+
+		w := send("k1", body)
+		r.Equal(http.StatusCreated, w.Code)
+		r.Empty(w.Header().Get("Idempotent-Replayed"))
+		r.Contains(stored.Header, "Location")
+		r.JSONEq(w.Body.String(), string(stored.Body))
+	})
+
+	t.Run("retry is replayed", func(t *testing.T) {
+		r := require.New(t)
+
+		mockRepo.EXPECT().ClaimIdempotencyKey(gomock.Any(), "ip:192.0.2.1", "k1", fingerprint, time.Hour, time.Minute).
+			Return(stored, false, nil)
+
+		w := send("k1", body)
+		r.Equal(http.StatusCreated, w.Code)
+		r.Equal("true", w.Header().Get("Idempotent-Replayed"))
+		r.Equal(stored.Header["Location"], w.Header().Values("Location"))
+		r.JSONEq(string(stored.Body), w.Body.String())
+	})
+
+	t.Run("key reused for another request", func(t *testing.T) {
+		r := require.New(t)
+
+		mockRepo.EXPECT().ClaimIdempotencyKey(gomock.Any(), "ip:192.0.2.1", "k1", gomock.Any(), time.Hour, time.Minute).
+			Return(stored, false, nil)
+
+		w := send("k1", strings.Replace(body, "test@", "other@", 1))
+		r.Equal(http.StatusUnprocessableEntity, w.Code)
+		r.Contains(w.Body.String(), "idempotency_key_reused")
+	})
+
+	t.Run("server error releases the key", func(t *testing.T) {
+		r := require.New(t)
+
+		mockRepo.EXPECT().ClaimIdempotencyKey(gomock.Any(), "ip:192.0.2.1", "k2", fingerprint, time.Hour, time.Minute).
+			Return(entity.IdempotencyRecord{}, true, nil)
+		mockUserService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+		mockRepo.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "ip:192.0.2.1", "k2").Return(nil)
+
+		w := send("k2", body)
+		r.Equal(http.StatusInternalServerError, w.Code)
+	})
+}
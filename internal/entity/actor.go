@@ -0,0 +1,51 @@
+package entity
+
+import "context"
+
+type ActorKind string
+
+const (
+	ActorAnonymous ActorKind = "anonymous"
+	ActorSystem    ActorKind = "system"
+	// ActorUser is a caller authenticated by a bearer token; its ID is the
+	// token subject.
+	ActorUser ActorKind = "user"
+	// ActorAPIKey is a machine client authenticated by an API key; its ID is
+	// the key ID.
+	ActorAPIKey ActorKind = "api_key"
+)
+
+// Actor is whoever performs an operation, as far as the transport could tell.
+type Actor struct {
+	Kind ActorKind `json:"kind"`
+	ID   string    `json:"id"`
+}
+
+var SystemActor = Actor{Kind: ActorSystem, ID: "users-app"}
+
+type actorKey struct{}
+
+type requestIDKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx. Operations started
+// outside of a request are attributed to the system.
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+
+	return SystemActor
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type AuditAction string
+
+const (
+	AuditCreated        AuditAction = "created"
+	AuditUpdated        AuditAction = "updated"
+	AuditDeleted        AuditAction = "deleted"
+	AuditRestored       AuditAction = "restored"
+	AuditBalanceChanged AuditAction = "balance_changed"
+	AuditLimitsChanged  AuditAction = "limits_changed"
+)
+
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditRecord is an append-only record of one change to a user.
+type AuditRecord struct {
+	ID        int64                  `json:"id"`
+	UserID    uuid.UUID              `json:"user_id"`
+	Action    AuditAction            `json:"action"`
+	Actor     Actor                  `json:"actor"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type AuditPage struct {
+	Items      []AuditRecord `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
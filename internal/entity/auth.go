@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Claims are the verified claims of the bearer token a request came with.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	Roles     []string
+	Scopes    []string
+}
+
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+type claimsKey struct{}
+
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller, if any.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+// Permission is what a role grants. A ":self" permission only covers the
+// caller's own user record, the one whose ID is the token subject.
+type Permission string
+
+const (
+	PermUsersRead         Permission = "users:read"
+	PermUsersReadSelf     Permission = "users:read:self"
+	PermUsersReadDeleted  Permission = "users:read:deleted"
+	PermUsersCreate       Permission = "users:create"
+	PermUsersUpdate       Permission = "users:update"
+	PermUsersUpdateSelf   Permission = "users:update:self"
+	PermUsersDelete       Permission = "users:delete"
+	PermUsersBalanceWrite Permission = "users.balance:write"
+	PermTransfersCreate   Permission = "transfers:create"
+	PermDepositsCreate    Permission = "deposits:create"
+	PermWithdrawalsCreate Permission = "withdrawals:create"
+	PermHoldsManage       Permission = "holds:manage"
+	PermLimitsManage      Permission = "limits:manage"
+	PermReviewsManage     Permission = "reviews:manage"
+	PermWebhooksManage    Permission = "webhooks:manage"
+	PermAPIKeysManage     Permission = "apikeys:manage"
+)
+
+// Permissions lists every permission a policy may grant.
+var Permissions = []Permission{
+	PermUsersRead,
+	PermUsersReadSelf,
+	PermUsersReadDeleted,
+	PermUsersCreate,
+	PermUsersUpdate,
+	PermUsersUpdateSelf,
+	PermUsersDelete,
+	PermUsersBalanceWrite,
+	PermTransfersCreate,
+	PermDepositsCreate,
+	PermWithdrawalsCreate,
+	PermHoldsManage,
+	PermLimitsManage,
+	PermReviewsManage,
+	PermWebhooksManage,
+	PermAPIKeysManage,
+}
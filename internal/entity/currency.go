@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// minorUnits is the number of decimal places of the ISO 4217 currencies the
+// service accepts.
+var minorUnits = map[string]int32{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3,
+	"KZT": 2, "MXN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PLN": 2, "RUB": 2,
+	"SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "UAH": 2, "USD": 2,
+	"VND": 0, "ZAR": 2,
+}
+
+// Currency is an ISO 4217 currency and the number of decimal places of its
+// minor unit.
+type Currency struct {
+	Code       string
+	MinorUnits int32
+}
+
+// LookupCurrency returns the currency with the given upper-case code.
+func LookupCurrency(code string) (Currency, bool) {
+	units, ok := minorUnits[code]
+	return Currency{Code: code, MinorUnits: units}, ok
+}
+
+// Round rounds an amount to the minor unit, half to even.
+func (c Currency) Round(amount decimal.Decimal) decimal.Decimal {
+	return amount.RoundBank(c.MinorUnits)
+}
+
+// Exact reports whether amount has no more decimal places than the minor
+// unit allows.
+func (c Currency) Exact(amount decimal.Decimal) bool {
+	return amount.Equal(amount.Truncate(c.MinorUnits))
+}
+
+// Wallet is the money a user holds in one currency. The wallet in the base
+// currency is the balance of the user itself, and the only one holds are
+// placed on.
+type Wallet struct {
+	Currency    string          `json:"currency"`
+	Balance     decimal.Decimal `json:"balance"`
+	HeldBalance decimal.Decimal `json:"held_balance"`
+}
+
+// Money is an amount in a currency.
+type Money struct {
+	Currency string          `json:"currency"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+// Balances lists the wallets of a user, the base currency first. Total, when
+// asked for, is the available balance of all of them in one currency.
+type Balances struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Wallets []Wallet  `json:"wallets"`
+	Total   *Money    `json:"total,omitempty"`
+}
+
+// ExchangeRate is the price of one unit of Base in Quote from EffectiveAt
+// until the next rate of the pair takes effect.
+type ExchangeRate struct {
+	Base        string          `json:"base"`
+	Quote       string          `json:"quote"`
+	Rate        decimal.Decimal `json:"rate"`
+	EffectiveAt time.Time       `json:"effective_at"`
+}
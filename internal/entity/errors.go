@@ -1,8 +1,50 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrNotFound      = errors.New("not found")
-	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound          = errors.New("not found")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrConflict          = errors.New("version conflict")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidPatch      = errors.New("invalid patch")
+	ErrInvalidTransfer   = errors.New("invalid transfer")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrHoldNotActive     = errors.New("hold not active")
+	ErrNoExchangeRate    = errors.New("no exchange rate")
+	ErrLimitExceeded     = errors.New("limit exceeded")
+	ErrOperationDenied   = errors.New("operation denied")
+	ErrPendingReview     = errors.New("pending review")
+	ErrReviewNotPending  = errors.New("review not pending")
+	ErrValidation        = errors.New("validation failed")
+	ErrUnauthenticated   = errors.New("unauthenticated")
+	ErrForbidden         = errors.New("forbidden")
 )
+
+// DetailError tells why Err happened with a catalog message, so that the
+// client gets the reason in its own language. Key names the message and Args
+// fill its placeholders.
+type DetailError struct {
+	Err  error
+	Key  string
+	Args map[string]any
+}
+
+func NewDetailError(err error, key string, args map[string]any) error {
+	return &DetailError{Err: err, Key: key, Args: args}
+}
+
+func (e *DetailError) Error() string {
+	if len(e.Args) == 0 {
+		return fmt.Sprintf("%s: %s", e.Err, e.Key)
+	}
+
+	return fmt.Sprintf("%s: %s %v", e.Err, e.Key, e.Args)
+}
+
+func (e *DetailError) Unwrap() error {
+	return e.Err
+}
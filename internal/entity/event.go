@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type EventType string
+
+const (
+	EventUserCreated        EventType = "user.created"
+	EventUserUpdated        EventType = "user.updated"
+	EventUserDeleted        EventType = "user.deleted"
+	EventUserRestored       EventType = "user.restored"
+	EventUserBalanceChanged EventType = "user.balance_changed"
+)
+
+func (t EventType) Valid() bool {
+	switch t {
+	case EventUserCreated, EventUserUpdated, EventUserDeleted, EventUserRestored, EventUserBalanceChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event is a domain event published through the transactional outbox. Its ID
+// does not change between redeliveries, so consumers can use it to drop
+// duplicates.
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       EventType       `json:"type"`
+	UserID     uuid.UUID       `json:"user_id"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxPublished OutboxStatus = "published"
+	// OutboxDead is an event that ran out of attempts. It is never published.
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxEntry is an event waiting in the outbox together with the outcome of
+// the last attempt to publish it.
+type OutboxEntry struct {
+	Event         Event
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+// UserEventPayload is the payload of user lifecycle events: the user as of
+// the event and the fields that changed.
+type UserEventPayload struct {
+	User    User                   `json:"user"`
+	Changes map[string]FieldChange `json:"changes"`
+}
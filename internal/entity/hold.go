@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldReleased HoldStatus = "released"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// Hold reserves part of a user's balance for a payment to be settled later.
+// Placing it moves Amount from the user's balance to the held balance;
+// capturing it takes CapturedAmount out of the system and gives the rest
+// back, releasing or expiring it gives everything back.
+type Hold struct {
+	ID             uuid.UUID       `json:"id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	CapturedAmount decimal.Decimal `json:"captured_amount"`
+	Status         HoldStatus      `json:"status"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	SettledAt      *time.Time      `json:"settled_at,omitempty"`
+}
+
+// Movement is money entering the system through a deposit to a user or
+// leaving it through a withdrawal. Balance is the user's balance after it.
+type Movement struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Kind      OperationKind   `json:"kind"`
+	Amount    decimal.Decimal `json:"amount"`
+	Balance   decimal.Decimal `json:"balance"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// IdempotencyRecord is the outcome of the first request a client sent with an
+// Idempotency-Key. Fingerprint identifies that request, so that the key
+// cannot be reused for another one. Status is zero while the request is in
+// progress.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	Fingerprint []byte
+	Status      int
+	Header      map[string][]string
+	Body        []byte
+	LockedAt    time.Time
+	CompletedAt *time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the request has been stored.
+func (r IdempotencyRecord) Completed() bool {
+	return r.CompletedAt != nil
+}
@@ -0,0 +1,142 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultLimitTier is the tier of users whose limits were never set.
+const DefaultLimitTier = "standard"
+
+// LimitKind names one of the spending limits. The periods are calendar
+// periods in UTC; weeks start on Monday.
+type LimitKind string
+
+const (
+	LimitPerTransaction LimitKind = "per_transaction"
+	LimitDaily          LimitKind = "daily"
+	LimitWeekly         LimitKind = "weekly"
+	LimitMonthly        LimitKind = "monthly"
+)
+
+// SpendingLimits cap the money a user may withdraw, capture from holds or
+// transfer to other users, in the base currency. A nil limit does not apply.
+type SpendingLimits struct {
+	PerTransaction *decimal.Decimal `json:"per_transaction"`
+	Daily          *decimal.Decimal `json:"daily"`
+	Weekly         *decimal.Decimal `json:"weekly"`
+	Monthly        *decimal.Decimal `json:"monthly"`
+}
+
+// Spending is the money a user withdrew, captured from holds or transferred to
+// other users in the current day, week and month.
+type Spending struct {
+	Daily   decimal.Decimal `json:"daily"`
+	Weekly  decimal.Decimal `json:"weekly"`
+	Monthly decimal.Decimal `json:"monthly"`
+}
+
+// Spend is one debit counted against the limits of a user. Amount is in the
+// base currency.
+type Spend struct {
+	OperationID uuid.UUID
+	UserID      uuid.UUID
+	Kind        OperationKind
+	Amount      decimal.Decimal
+	CreatedAt   time.Time
+}
+
+// UserLimits are the limits of a user: those of its tier, except where
+// Overrides sets one. Remaining is what is left of each limit after Spent;
+// its PerTransaction is the most a single debit may be right now.
+type UserLimits struct {
+	UserID    uuid.UUID      `json:"user_id"`
+	Tier      string         `json:"tier"`
+	Overrides SpendingLimits `json:"overrides"`
+	Limits    SpendingLimits `json:"limits"`
+	Spent     Spending       `json:"spent"`
+	Remaining SpendingLimits `json:"remaining"`
+	Currency  string         `json:"currency"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+}
+
+// Remaining returns what is left of the limits after spent, never below zero.
+func (l SpendingLimits) Remaining(spent Spending) SpendingLimits {
+	left := func(limit *decimal.Decimal, spent decimal.Decimal) *decimal.Decimal {
+		if limit == nil {
+			return nil
+		}
+
+		rest := decimal.Max(limit.Sub(spent), decimal.Zero)
+
+		return &rest
+	}
+
+	remaining := SpendingLimits{
+		PerTransaction: l.PerTransaction,
+		Daily:          left(l.Daily, spent.Daily),
+		Weekly:         left(l.Weekly, spent.Weekly),
+		Monthly:        left(l.Monthly, spent.Monthly),
+	}
+
+	for _, rest := range []*decimal.Decimal{remaining.Daily, remaining.Weekly, remaining.Monthly} {
+		if rest != nil && (remaining.PerTransaction == nil || rest.LessThan(*remaining.PerTransaction)) {
+			remaining.PerTransaction = rest
+		}
+	}
+
+	return remaining
+}
+
+// Check returns a *LimitError for the tightest limit a debit of amount would
+// exceed after spent, and nil if it fits them all.
+func (l SpendingLimits) Check(amount decimal.Decimal, spent Spending, currency string) error {
+	remaining := l.Remaining(spent)
+
+	limits := []struct {
+		kind LimitKind
+		left *decimal.Decimal
+	}{
+		{LimitPerTransaction, l.PerTransaction},
+		{LimitDaily, remaining.Daily},
+		{LimitWeekly, remaining.Weekly},
+		{LimitMonthly, remaining.Monthly},
+	}
+
+	var exceeded *LimitError
+
+	for _, limit := range limits {
+		if limit.left == nil || !amount.GreaterThan(*limit.left) {
+			continue
+		}
+
+		if exceeded == nil || limit.left.LessThan(exceeded.Remaining) {
+			exceeded = &LimitError{Limit: limit.kind, Remaining: *limit.left, Currency: currency}
+		}
+	}
+
+	if exceeded == nil {
+		return nil
+	}
+
+	return exceeded
+}
+
+// LimitError reports the limit a debit would exceed and what is left of it.
+// It matches ErrLimitExceeded with errors.Is.
+type LimitError struct {
+	Limit     LimitKind
+	Remaining decimal.Decimal
+	Currency  string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s limit leaves %s %s", ErrLimitExceeded, e.Limit, e.Remaining, e.Currency)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
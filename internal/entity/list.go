@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+type SortField string
+
+const (
+	SortByName    SortField = "name"
+	SortByEmail   SortField = "email"
+	SortByAge     SortField = "age"
+	SortByBalance SortField = "balance"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByName, SortByEmail, SortByAge, SortByBalance:
+		return true
+	default:
+		return false
+	}
+}
+
+type UserSort struct {
+	Field SortField `json:"f"`
+	Desc  bool      `json:"d,omitempty"`
+}
+
+type UserFilter struct {
+	NamePrefix  string
+	EmailDomain string
+	MinAge      *int
+	MaxAge      *int
+	MinBalance  *decimal.Decimal
+	MaxBalance  *decimal.Decimal
+
+	IncludeDeleted bool
+}
+
+// UserCursor points at the last row of a page: the value of the sort column
+// and the id used as a tie-breaker.
+type UserCursor struct {
+	Sort UserSort  `json:"s"`
+	Key  string    `json:"k"`
+	ID   uuid.UUID `json:"id"`
+}
+
+type UserQuery struct {
+	Filter UserFilter
+	Sort   UserSort
+	After  *UserCursor
+	Limit  int
+}
+
+type ListUsersParams struct {
+	Filter UserFilter
+	Sort   UserSort
+	Cursor string
+	Limit  int
+}
+
+type UserPage struct {
+	Items      []User `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
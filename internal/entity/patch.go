@@ -0,0 +1,18 @@
+package entity
+
+type PatchFormat string
+
+const (
+	// MergePatch is an RFC 7396 JSON Merge Patch document.
+	MergePatch PatchFormat = "application/merge-patch+json"
+	// JSONPatch is an RFC 6902 JSON Patch operation list.
+	JSONPatch PatchFormat = "application/json-patch+json"
+)
+
+type UserPatch struct {
+	Format   PatchFormat
+	Document []byte
+
+	// Version, when non-zero, must match the current version of the user.
+	Version int64
+}
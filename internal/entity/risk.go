@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// RiskVerdict is what the risk engine makes of a balance operation, from the
+// mildest to the strictest.
+type RiskVerdict string
+
+const (
+	VerdictAllow  RiskVerdict = "allow"
+	VerdictReview RiskVerdict = "review"
+	VerdictDeny   RiskVerdict = "deny"
+)
+
+// Stricter reports whether v is stricter than other.
+func (v RiskVerdict) Stricter(other RiskVerdict) bool {
+	severity := map[RiskVerdict]int{VerdictAllow: 0, VerdictReview: 1, VerdictDeny: 2}
+
+	return severity[v] > severity[other]
+}
+
+// RiskOperation is a deposit, a withdrawal, a transfer or a hold up for
+// screening. UserID is the user whose balance it debits, or credits for a
+// deposit; CounterpartyID is the recipient of a transfer. Amount is in
+// Currency, and Value is what it is worth in the base currency, the one rules
+// look at. ExpiresAt is the expiry of a hold.
+type RiskOperation struct {
+	Kind           OperationKind   `json:"kind"`
+	UserID         uuid.UUID       `json:"user_id"`
+	CounterpartyID *uuid.UUID      `json:"counterparty_id,omitempty"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	ToCurrency     string          `json:"to_currency,omitempty"`
+	Value          decimal.Decimal `json:"value"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"`
+}
+
+// RiskDecision records the verdict on one operation and the rule that reached
+// it. Rule and Reason are empty when no rule fired. ReviewID is set when the
+// operation was sent to review.
+type RiskDecision struct {
+	ID        int64
+	Operation RiskOperation
+	Verdict   RiskVerdict
+	Rule      string
+	Reason    string
+	ReviewID  *int64
+	CreatedAt time.Time
+}
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// RiskReview is an operation held until an admin approves or rejects it.
+// Approving it applies the operation; OperationID is then the id of the
+// resulting movement, transfer or hold.
+type RiskReview struct {
+	ID int64 `json:"id"`
+	RiskOperation
+	Rule        string       `json:"rule"`
+	Reason      string       `json:"reason"`
+	Status      ReviewStatus `json:"status"`
+	OperationID *uuid.UUID   `json:"operation_id,omitempty"`
+	DecidedBy   *Actor       `json:"decided_by,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	DecidedAt   *time.Time   `json:"decided_at,omitempty"`
+}
+
+type RiskReviewPage struct {
+	Items      []RiskReview `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// ReviewError reports an operation held for review instead of applied. It
+// matches ErrPendingReview with errors.Is.
+type ReviewError struct {
+	Review RiskReview
+}
+
+func (e *ReviewError) Error() string {
+	return fmt.Sprintf("%s: %s held as review %d", ErrPendingReview, e.Review.Kind, e.Review.ID)
+}
+
+func (e *ReviewError) Unwrap() error {
+	return ErrPendingReview
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Transfer moves Amount in Currency out of one wallet and ToAmount in
+// ToCurrency into another. Between currencies, ToAmount is Amount converted at
+// Rate; otherwise they are the same and Rate is nil.
+type Transfer struct {
+	ID         uuid.UUID        `json:"id"`
+	FromUserID uuid.UUID        `json:"from_user_id"`
+	ToUserID   uuid.UUID        `json:"to_user_id"`
+	Amount     decimal.Decimal  `json:"amount"`
+	Currency   string           `json:"currency"`
+	ToAmount   decimal.Decimal  `json:"to_amount"`
+	ToCurrency string           `json:"to_currency"`
+	Rate       *decimal.Decimal `json:"rate,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type EntryDirection string
+
+const (
+	Debit  EntryDirection = "debit"
+	Credit EntryDirection = "credit"
+)
+
+type OperationKind string
+
+const (
+	OperationOpening    OperationKind = "opening"
+	OperationAdjustment OperationKind = "adjustment"
+	OperationTransfer   OperationKind = "transfer"
+	OperationDeposit    OperationKind = "deposit"
+	OperationWithdrawal OperationKind = "withdrawal"
+	// A hold moves money from the user to an account of its own, named by
+	// the hold ID, until it is captured to the external account or released
+	// back to the user.
+	OperationHold    OperationKind = "hold"
+	OperationCapture OperationKind = "capture"
+	OperationRelease OperationKind = "release"
+)
+
+// ExternalAccountID is the ledger account on the other side of money entering
+// or leaving the system (opening balances, manual adjustments, deposits,
+// withdrawals and captured holds). A transfer between currencies goes through
+// it too: the source currency leaves and the target currency enters, so that
+// every currency balances on its own.
+var ExternalAccountID = uuid.Nil
+
+// LedgerEntry is one side of a double-entry record. Entries sharing an
+// OperationID and a Currency always balance: the sum of debits equals the sum
+// of credits. Entries in the base currency leave Currency empty, and entries
+// of an exchange record its Rate.
+type LedgerEntry struct {
+	ID          int64            `json:"id"`
+	OperationID uuid.UUID        `json:"operation_id"`
+	Kind        OperationKind    `json:"kind"`
+	AccountID   uuid.UUID        `json:"account_id"`
+	Direction   EntryDirection   `json:"direction"`
+	Amount      decimal.Decimal  `json:"amount"`
+	Currency    string           `json:"currency,omitempty"`
+	Rate        *decimal.Decimal `json:"rate,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// Entries returns the balanced pair of ledger entries describing t, in the
+// base currency.
+func (t Transfer) Entries(kind OperationKind) []LedgerEntry {
+	return []LedgerEntry{
+		{OperationID: t.ID, Kind: kind, AccountID: t.FromUserID, Direction: Debit, Amount: t.Amount},
+		{OperationID: t.ID, Kind: kind, AccountID: t.ToUserID, Direction: Credit, Amount: t.Amount},
+	}
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid/v5"
 	"github.com/shopspring/decimal"
 )
@@ -11,4 +13,12 @@ type User struct {
 	Email   string          `json:"email"`
 	Age     int             `json:"age"`
 	Balance decimal.Decimal `json:"balance"`
+	// HeldBalance is reserved by active holds and cannot be spent; Balance
+	// is what is available.
+	HeldBalance decimal.Decimal `json:"held_balance"`
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Version is bumped on every write and exposed to clients as an ETag.
+	Version int64 `json:"-"`
 }
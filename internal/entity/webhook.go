@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// WebhookSubscription registers a URL for the events listed in Events, or
+// for every event when Events is empty. Secret signs the payloads and is only
+// returned when the subscription is created.
+type WebhookSubscription struct {
+	ID        uuid.UUID   `json:"id"`
+	URL       string      `json:"url"`
+	Events    []EventType `json:"events"`
+	Active    bool        `json:"active"`
+	Secret    string      `json:"secret,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Matches reports whether the subscription wants events of type t.
+func (s WebhookSubscription) Matches(t EventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+
+	for _, e := range s.Events {
+		if e == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead is a delivery that ran out of attempts. It is only sent
+	// again when redelivered by hand.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery is one event to be sent to one subscription together with
+// the outcome of the last attempt.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      EventType       `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveryPage struct {
+	Items      []WebhookDelivery `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// WebhookJob is a due delivery together with where to send it and the secret
+// to sign it with.
+type WebhookJob struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// RetryPolicy decides when failed deliveries are attempted again.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns the delay after the given number of failed attempts: the
+// base delay doubled for every failure, capped at the maximum delay.
+func (p RetryPolicy) Backoff(failures int) time.Duration {
+	delay := p.BaseDelay
+
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
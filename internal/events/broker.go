@@ -0,0 +1,177 @@
+package events
+
+import (
+	"sync"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Message is an event numbered by the broker. IDs increase monotonically and
+// start from the time the broker was created, so that IDs handed out before a
+// restart stay lower than the new ones.
+type Message struct {
+	ID    uint64
+	Event entity.Event
+}
+
+// Filter selects events by user and type. Empty sets match everything.
+type Filter struct {
+	UserIDs map[uuid.UUID]struct{}
+	Types   map[entity.EventType]struct{}
+}
+
+func (f Filter) Matches(event entity.Event) bool {
+	if len(f.UserIDs) > 0 {
+		if _, ok := f.UserIDs[event.UserID]; !ok {
+			return false
+		}
+	}
+
+	if len(f.Types) > 0 {
+		if _, ok := f.Types[event.Type]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Subscription receives live messages until it is closed by Unsubscribe,
+// by Close or because it fell behind.
+type Subscription struct {
+	ch     chan Message
+	filter Filter
+}
+
+// Messages is closed when the subscription ends.
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+// Broker fans events out to stream subscribers and keeps the latest of them
+// so that reconnecting clients can resume where they left off.
+type Broker struct {
+	mu           sync.Mutex
+	nextID       uint64
+	replay       []Message
+	replaySize   int
+	clientBuffer int
+	heartbeat    time.Duration
+	subs         map[*Subscription]struct{}
+	closed       bool
+}
+
+func NewBroker(replaySize, clientBuffer int, heartbeat time.Duration) *Broker {
+	return &Broker{
+		nextID:       uint64(time.Now().UnixNano()),
+		replay:       make([]Message, 0, replaySize),
+		replaySize:   replaySize,
+		clientBuffer: clientBuffer,
+		heartbeat:    heartbeat,
+		subs:         make(map[*Subscription]struct{}),
+	}
+}
+
+// Heartbeat is how often idle streams should send a keep-alive.
+func (b *Broker) Heartbeat() time.Duration {
+	return b.heartbeat
+}
+
+// Publish numbers event and delivers it to every matching subscriber. A
+// subscriber whose buffer is full is dropped rather than blocking the others;
+// it is expected to reconnect and resume from the replay buffer.
+func (b *Broker) Publish(event entity.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	msg := Message{ID: b.nextID, Event: event}
+	b.nextID++
+
+	if b.replaySize > 0 {
+		if len(b.replay) == b.replaySize {
+			copy(b.replay, b.replay[1:])
+			b.replay = b.replay[:len(b.replay)-1]
+		}
+
+		b.replay = append(b.replay, msg)
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.ch <- msg:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered messages after
+// lastID that match filter. A zero lastID replays nothing. When lastID is
+// older than the buffer, the whole buffer is replayed.
+func (b *Broker) Subscribe(lastID uint64, filter Filter) (*Subscription, []Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		ch:     make(chan Message, b.clientBuffer),
+		filter: filter,
+	}
+
+	if b.closed {
+		close(sub.ch)
+		return sub, nil
+	}
+
+	var missed []Message
+
+	if lastID != 0 {
+		for _, msg := range b.replay {
+			if msg.ID > lastID && filter.Matches(msg.Event) {
+				missed = append(missed, msg)
+			}
+		}
+	}
+
+	b.subs[sub] = struct{}{}
+
+	return sub, missed
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+// Close ends every subscription and refuses new ones, so that open streams
+// finish and the server can shut down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for sub := range b.subs {
+		b.remove(sub)
+	}
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+
+	delete(b.subs, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,105 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/events"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_Replay(t *testing.T) {
+	r := require.New(t)
+
+	broker := events.NewBroker(3, 8, time.Second)
+
+	userID := uuid.Must(uuid.NewV4())
+
+	first, missed := broker.Subscribe(0, events.Filter{})
+	r.Empty(missed)
+
+	for _, t := range []entity.EventType{
+		entity.EventUserCreated, entity.EventUserUpdated, entity.EventUserBalanceChanged, entity.EventUserDeleted,
+	} {
+		broker.Publish(entity.Event{Type: t, UserID: userID})
+	}
+
+	var ids []uint64
+
+	for range 4 {
+		msg := <-first.Messages()
+		ids = append(ids, msg.ID)
+	}
+
+	r.IsIncreasing(ids)
+
+	// The oldest event fell out of the buffer of three.
+	_, missed = broker.Subscribe(ids[0], events.Filter{})
+	r.Len(missed, 3)
+	r.Equal(ids[1], missed[0].ID)
+
+	_, missed = broker.Subscribe(ids[2], events.Filter{})
+	r.Len(missed, 1)
+	r.Equal(entity.EventUserDeleted, missed[0].Event.Type)
+
+	_, missed = broker.Subscribe(ids[0], events.Filter{
+		Types: map[entity.EventType]struct{}{entity.EventUserBalanceChanged: {}},
+	})
+	r.Len(missed, 1)
+	r.Equal(ids[2], missed[0].ID)
+}
+
+func TestBroker_Filter(t *testing.T) {
+	r := require.New(t)
+
+	broker := events.NewBroker(10, 8, time.Second)
+
+	watched := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+
+	sub, _ := broker.Subscribe(0, events.Filter{UserIDs: map[uuid.UUID]struct{}{watched: {}}})
+
+	broker.Publish(entity.Event{Type: entity.EventUserUpdated, UserID: other})
+	broker.Publish(entity.Event{Type: entity.EventUserUpdated, UserID: watched})
+
+	msg := <-sub.Messages()
+	r.Equal(watched, msg.Event.UserID)
+	r.Empty(sub.Messages())
+}
+
+func TestBroker_SlowSubscriberDropped(t *testing.T) {
+	r := require.New(t)
+
+	broker := events.NewBroker(10, 1, time.Second)
+
+	slow, _ := broker.Subscribe(0, events.Filter{})
+
+	broker.Publish(entity.Event{Type: entity.EventUserCreated})
+	broker.Publish(entity.Event{Type: entity.EventUserUpdated})
+
+	_, ok := <-slow.Messages()
+	r.True(ok)
+
+	_, ok = <-slow.Messages()
+	r.False(ok)
+}
+
+func TestBroker_Close(t *testing.T) {
+	r := require.New(t)
+
+	broker := events.NewBroker(10, 8, time.Second)
+
+	sub, _ := broker.Subscribe(0, events.Filter{})
+	broker.Close()
+
+	_, ok := <-sub.Messages()
+	r.False(ok)
+
+	late, _ := broker.Subscribe(0, events.Filter{})
+	_, ok = <-late.Messages()
+	r.False(ok)
+
+	broker.Unsubscribe(sub)
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"users-app/internal/entity"
+)
+
+// ChannelSink delivers events to in-process subscribers reading Events.
+type ChannelSink struct {
+	ch chan entity.Event
+}
+
+func NewChannelSink(buffer int) *ChannelSink {
+	return &ChannelSink{
+		ch: make(chan entity.Event, buffer),
+	}
+}
+
+func (s *ChannelSink) Events() <-chan entity.Event {
+	return s.ch
+}
+
+// Deliver blocks until the event is buffered or ctx is cancelled.
+func (s *ChannelSink) Deliver(ctx context.Context, event entity.Event) error {
+	select {
+	case s.ch <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NDJSONSink writes events as newline-delimited JSON, e.g. to stdout or a file.
+type NDJSONSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (s *NDJSONSink) Deliver(_ context.Context, event entity.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(event); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookSink POSTs every event as JSON to a fixed URL. The event ID is sent
+// in the X-Event-ID header for receivers to deduplicate redeliveries.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: client,
+	}
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, event entity.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID.String())
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package events_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"users-app/internal/entity"
+	"users-app/internal/events"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNDJSONSink(t *testing.T) {
+	r := require.New(t)
+
+	var buf bytes.Buffer
+
+	sink := events.NewNDJSONSink(&buf)
+	ctx := context.Background()
+
+	first := entity.Event{ID: uuid.Must(uuid.NewV4()), Type: entity.EventUserCreated, Payload: json.RawMessage(`{}`)}
+	second := entity.Event{ID: uuid.Must(uuid.NewV4()), Type: entity.EventUserDeleted, Payload: json.RawMessage(`{}`)}
+
+	r.NoError(sink.Deliver(ctx, first))
+	r.NoError(sink.Deliver(ctx, second))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	r.Len(lines, 2)
+
+	var got entity.Event
+	r.NoError(json.Unmarshal(lines[1], &got))
+	r.Equal(second.ID, got.ID)
+	r.Equal(second.Type, got.Type)
+}
+
+func TestWebhookSink(t *testing.T) {
+	r := require.New(t)
+
+	event := entity.Event{ID: uuid.Must(uuid.NewV4()), Type: entity.EventUserUpdated, Payload: json.RawMessage(`{}`)}
+
+	tests := []struct {
+		name        string
+		status      int
+		expectedErr bool
+	}{
+		{
+			name:   "delivered",
+			status: http.StatusNoContent,
+		},
+		{
+			name:        "rejected",
+			status:      http.StatusInternalServerError,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				r.Equal(http.MethodPost, req.Method)
+				r.Equal(event.ID.String(), req.Header.Get("X-Event-ID"))
+				r.Equal(string(event.Type), req.Header.Get("X-Event-Type"))
+
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := events.NewWebhookSink(srv.URL, srv.Client()).Deliver(context.Background(), event)
+			if tt.expectedErr {
+				r.Error(err)
+			} else {
+				r.NoError(err)
+			}
+		})
+	}
+}
+
+func TestChannelSink(t *testing.T) {
+	r := require.New(t)
+
+	sink := events.NewChannelSink(1)
+	event := entity.Event{ID: uuid.Must(uuid.NewV4())}
+
+	r.NoError(sink.Deliver(context.Background(), event))
+	r.Equal(event, <-sink.Events())
+
+	r.NoError(sink.Deliver(context.Background(), event))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r.ErrorIs(sink.Deliver(ctx, event), context.Canceled)
+}
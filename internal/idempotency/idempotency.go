@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"users-app/internal/entity"
+)
+
+// MaxKeyLength bounds the Idempotency-Key header.
+const MaxKeyLength = 255
+
+var (
+	ErrInvalidKey = errors.New("invalid idempotency key")
+	// ErrInUse is returned for a duplicate of a request still in progress.
+	ErrInUse = errors.New("idempotency key in use")
+	// ErrReused is returned when a key comes back with another request.
+	ErrReused = errors.New("idempotency key reused")
+)
+
+//go:generate go run go.uber.org/mock/mockgen@latest -source=idempotency.go -destination=../mocks/idempotency.go -package=mocks -typed -mock_names=Repository=MockIdempotencyRepository
+
+// Repository keeps the keys in the database. repository.Repository
+// implements it.
+type Repository interface {
+	ClaimIdempotencyKey(ctx context.Context, scope, key string, fingerprint []byte,
+		ttl, lockTimeout time.Duration) (entity.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(ctx context.Context, scope, key string, status int,
+		header map[string][]string, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, scope, key string) error
+	PurgeIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// Options configure a Keeper. Responses are kept for TTL. A duplicate of a
+// request in progress waits up to Wait for its response before failing with
+// ErrInUse, and a request in progress for longer than LockTimeout is taken to
+// be abandoned.
+type Options struct {
+	TTL         time.Duration
+	Wait        time.Duration
+	LockTimeout time.Duration
+}
+
+// Response is a stored response, replayed to the retries of its request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Keeper remembers the response to every request sent with an idempotency key.
+// Keys are scoped, to the client that sent them, so that clients cannot read
+// each other's responses.
+type Keeper struct {
+	repo Repository
+	opts Options
+	poll time.Duration
+}
+
+func New(repo Repository, opts Options) *Keeper {
+	return &Keeper{
+		repo: repo,
+		opts: opts,
+		poll: 100 * time.Millisecond,
+	}
+}
+
+// Fingerprint identifies a request by its method, URI and body.
+func Fingerprint(method, uri string, body []byte) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", method, uri)
+	h.Write(body)
+
+	return h.Sum(nil)
+}
+
+// ValidateKey checks an Idempotency-Key header value.
+func ValidateKey(key string) error {
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidKey, MaxKeyLength)
+	}
+
+	return nil
+}
+
+// Begin claims key for the request with fingerprint. It returns the response
+// of the first request sent with the key, or nil when the caller claimed the
+// key and has to Complete or Release it.
+func (k *Keeper) Begin(ctx context.Context, scope, key string, fingerprint []byte) (*Response, error) {
+	deadline := time.Now().Add(k.opts.Wait)
+
+	for {
+		rec, claimed, err := k.repo.ClaimIdempotencyKey(ctx, scope, key, fingerprint, k.opts.TTL, k.opts.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		if claimed {
+			return nil, nil
+		}
+
+		if !bytes.Equal(rec.Fingerprint, fingerprint) {
+			return nil, fmt.Errorf("%w: key %s was sent with another request", ErrReused, key)
+		}
+
+		if rec.Completed() {
+			return &Response{Status: rec.Status, Header: rec.Header, Body: rec.Body}, nil
+		}
+
+		if !time.Now().Add(k.poll).Before(deadline) {
+			return nil, fmt.Errorf("%w: the request with key %s is in progress", ErrInUse, key)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(k.poll):
+		}
+	}
+}
+
+// LockTimeout is how long a request may hold its key before a retry takes
+// it over.
+func (k *Keeper) LockTimeout() time.Duration {
+	return k.opts.LockTimeout
+}
+
+// Complete stores the response to the request holding key.
+func (k *Keeper) Complete(ctx context.Context, scope, key string, resp Response) error {
+	return k.repo.CompleteIdempotencyKey(ctx, scope, key, resp.Status, resp.Header, resp.Body)
+}
+
+// Release drops key without a response, so that the request may be retried.
+func (k *Keeper) Release(ctx context.Context, scope, key string) error {
+	return k.repo.ReleaseIdempotencyKey(ctx, scope, key)
+}
+
+// PurgeExpired removes the keys older than their TTL.
+func (k *Keeper) PurgeExpired(ctx context.Context) (int64, error) {
+	return k.repo.PurgeIdempotencyKeys(ctx)
+}
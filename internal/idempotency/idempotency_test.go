@@ -0,0 +1,124 @@
+package idempotency_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/idempotency"
+	"users-app/internal/mocks"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestKeeper_Begin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockIdempotencyRepository(ctrl)
+	keeper := idempotency.New(repo, idempotency.Options{TTL: time.Hour, LockTimeout: time.Minute})
+
+	ctx := context.Background()
+	fingerprint := idempotency.Fingerprint("POST", "/api/v1/users", []byte(`{"name":"test"}`))
+	other := idempotency.Fingerprint("POST", "/api/v1/users", []byte(`{"name":"other"}`))
+	now := time.Now()
+
+	completed := entity.IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Status:      201,
+		Header:      map[string][]string{"Location": {"/api/v1/users/1"}},
+		Body:        []byte(`{"id":"1"}`),
+		CompletedAt: &now,
+	}
+
+	tests := []struct {
+		name        string
+		record      entity.IdempotencyRecord
+		claimed     bool
+		want        *idempotency.Response
+		expectedErr error
+	}{
+		{
+			name:    "first request",
+			claimed: true,
+		},
+		{
+			name:   "retry",
+			record: completed,
+			want:   &idempotency.Response{Status: 201, Header: completed.Header, Body: completed.Body},
+		},
+		{
+			name:        "another request",
+			record:      entity.IdempotencyRecord{Fingerprint: other, Status: 201, CompletedAt: &now},
+			expectedErr: idempotency.ErrReused,
+		},
+		{
+			name:        "in progress",
+			record:      entity.IdempotencyRecord{Fingerprint: fingerprint},
+			expectedErr: idempotency.ErrInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			repo.EXPECT().ClaimIdempotencyKey(ctx, "user:1", "key", fingerprint, time.Hour, time.Minute).
+				Return(tt.record, tt.claimed, nil)
+
+			got, err := keeper.Begin(ctx, "user:1", "key", fingerprint)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+// TestKeeper_BeginWaits checks that a duplicate waits for the response of the
+// request in progress.
+func TestKeeper_BeginWaits(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockIdempotencyRepository(ctrl)
+	keeper := idempotency.New(repo, idempotency.Options{TTL: time.Hour, Wait: time.Second, LockTimeout: time.Minute})
+
+	ctx := context.Background()
+	fingerprint := idempotency.Fingerprint("DELETE", "/api/v1/users/1", nil)
+	now := time.Now()
+
+	gomock.InOrder(
+		repo.EXPECT().ClaimIdempotencyKey(ctx, "user:1", "key", fingerprint, time.Hour, time.Minute).
+			Return(entity.IdempotencyRecord{Fingerprint: fingerprint}, false, nil),
+		repo.EXPECT().ClaimIdempotencyKey(ctx, "user:1", "key", fingerprint, time.Hour, time.Minute).
+			Return(entity.IdempotencyRecord{Fingerprint: fingerprint, Status: 200, CompletedAt: &now}, false, nil),
+	)
+
+	got, err := keeper.Begin(ctx, "user:1", "key", fingerprint)
+	r.NoError(err)
+	r.Equal(200, got.Status)
+}
+
+func TestFingerprint(t *testing.T) {
+	r := require.New(t)
+
+	base := idempotency.Fingerprint("POST", "/api/v1/transfers", []byte(`{"amount":"1"}`))
+
+	r.Equal(base, idempotency.Fingerprint("POST", "/api/v1/transfers", []byte(`{"amount":"1"}`)))
+	r.NotEqual(base, idempotency.Fingerprint("POST", "/api/v1/transfers", []byte(`{"amount":"2"}`)))
+	r.NotEqual(base, idempotency.Fingerprint("PUT", "/api/v1/transfers", []byte(`{"amount":"1"}`)))
+	r.NotEqual(base, idempotency.Fingerprint("POST", "/api/v1/users", []byte(`{"amount":"1"}`)))
+}
+
+func TestValidateKey(t *testing.T) {
+	require.NoError(t, idempotency.ValidateKey("8e03978e-40d5-43e8-bc93-6894a57f9324"))
+	require.ErrorIs(t, idempotency.ValidateKey(strings.Repeat("k", 256)), idempotency.ErrInvalidKey)
+}
@@ -0,0 +1,392 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: server.go
+//
+// Generated by this command:
+//
+//	mockgen -source=server.go -destination=../../mocks/grpc.go -package=mocks -typed -mock_names=UserService=MockGRPCUserService
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "users-app/internal/entity"
+
+	uuid "github.com/gofrs/uuid/v5"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGRPCUserService is a mock of UserService interface.
+type MockGRPCUserService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGRPCUserServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockGRPCUserServiceMockRecorder is the mock recorder for MockGRPCUserService.
+type MockGRPCUserServiceMockRecorder struct {
+	mock *MockGRPCUserService
+}
+
+// NewMockGRPCUserService creates a new mock instance.
+func NewMockGRPCUserService(ctrl *gomock.Controller) *MockGRPCUserService {
+	mock := &MockGRPCUserService{ctrl: ctrl}
+	mock.recorder = &MockGRPCUserServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGRPCUserService) EXPECT() *MockGRPCUserServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockGRPCUserService) AuthenticateAPIKey(ctx context.Context, key string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockGRPCUserServiceMockRecorder) AuthenticateAPIKey(ctx, key any) *MockGRPCUserServiceAuthenticateAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockGRPCUserService)(nil).AuthenticateAPIKey), ctx, key)
+	return &MockGRPCUserServiceAuthenticateAPIKeyCall{Call: call}
+}
+
+// MockGRPCUserServiceAuthenticateAPIKeyCall wrap *gomock.Call
+type MockGRPCUserServiceAuthenticateAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceAuthenticateAPIKeyCall) Return(arg0 entity.APIKey, arg1 error) *MockGRPCUserServiceAuthenticateAPIKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceAuthenticateAPIKeyCall) Do(f func(context.Context, string) (entity.APIKey, error)) *MockGRPCUserServiceAuthenticateAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceAuthenticateAPIKeyCall) DoAndReturn(f func(context.Context, string) (entity.APIKey, error)) *MockGRPCUserServiceAuthenticateAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateUser mocks base method.
+func (m *MockGRPCUserService) CreateUser(ctx context.Context, user entity.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockGRPCUserServiceMockRecorder) CreateUser(ctx, user any) *MockGRPCUserServiceCreateUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockGRPCUserService)(nil).CreateUser), ctx, user)
+	return &MockGRPCUserServiceCreateUserCall{Call: call}
+}
+
+// MockGRPCUserServiceCreateUserCall wrap *gomock.Call
+type MockGRPCUserServiceCreateUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceCreateUserCall) Return(arg0 error) *MockGRPCUserServiceCreateUserCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceCreateUserCall) Do(f func(context.Context, entity.User) error) *MockGRPCUserServiceCreateUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceCreateUserCall) DoAndReturn(f func(context.Context, entity.User) error) *MockGRPCUserServiceCreateUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteUser mocks base method.
+func (m *MockGRPCUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockGRPCUserServiceMockRecorder) DeleteUser(ctx, id any) *MockGRPCUserServiceDeleteUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockGRPCUserService)(nil).DeleteUser), ctx, id)
+	return &MockGRPCUserServiceDeleteUserCall{Call: call}
+}
+
+// MockGRPCUserServiceDeleteUserCall wrap *gomock.Call
+type MockGRPCUserServiceDeleteUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceDeleteUserCall) Return(arg0 error) *MockGRPCUserServiceDeleteUserCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceDeleteUserCall) Do(f func(context.Context, uuid.UUID) error) *MockGRPCUserServiceDeleteUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceDeleteUserCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockGRPCUserServiceDeleteUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByID mocks base method.
+func (m *MockGRPCUserService) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockGRPCUserServiceMockRecorder) GetUserByID(ctx, id any) *MockGRPCUserServiceGetUserByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockGRPCUserService)(nil).GetUserByID), ctx, id)
+	return &MockGRPCUserServiceGetUserByIDCall{Call: call}
+}
+
+// MockGRPCUserServiceGetUserByIDCall wrap *gomock.Call
+type MockGRPCUserServiceGetUserByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceGetUserByIDCall) Return(arg0 entity.User, arg1 error) *MockGRPCUserServiceGetUserByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceGetUserByIDCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockGRPCUserServiceGetUserByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceGetUserByIDCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockGRPCUserServiceGetUserByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByIDWithDeleted mocks base method.
+func (m *MockGRPCUserService) GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByIDWithDeleted", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByIDWithDeleted indicates an expected call of GetUserByIDWithDeleted.
+func (mr *MockGRPCUserServiceMockRecorder) GetUserByIDWithDeleted(ctx, id any) *MockGRPCUserServiceGetUserByIDWithDeletedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIDWithDeleted", reflect.TypeOf((*MockGRPCUserService)(nil).GetUserByIDWithDeleted), ctx, id)
+	return &MockGRPCUserServiceGetUserByIDWithDeletedCall{Call: call}
+}
+
+// MockGRPCUserServiceGetUserByIDWithDeletedCall wrap *gomock.Call
+type MockGRPCUserServiceGetUserByIDWithDeletedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceGetUserByIDWithDeletedCall) Return(arg0 entity.User, arg1 error) *MockGRPCUserServiceGetUserByIDWithDeletedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceGetUserByIDWithDeletedCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockGRPCUserServiceGetUserByIDWithDeletedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceGetUserByIDWithDeletedCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockGRPCUserServiceGetUserByIDWithDeletedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListUsers mocks base method.
+func (m *MockGRPCUserService) ListUsers(ctx context.Context, params entity.ListUsersParams) (entity.UserPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, params)
+	ret0, _ := ret[0].(entity.UserPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockGRPCUserServiceMockRecorder) ListUsers(ctx, params any) *MockGRPCUserServiceListUsersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockGRPCUserService)(nil).ListUsers), ctx, params)
+	return &MockGRPCUserServiceListUsersCall{Call: call}
+}
+
+// MockGRPCUserServiceListUsersCall wrap *gomock.Call
+type MockGRPCUserServiceListUsersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceListUsersCall) Return(arg0 entity.UserPage, arg1 error) *MockGRPCUserServiceListUsersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceListUsersCall) Do(f func(context.Context, entity.ListUsersParams) (entity.UserPage, error)) *MockGRPCUserServiceListUsersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceListUsersCall) DoAndReturn(f func(context.Context, entity.ListUsersParams) (entity.UserPage, error)) *MockGRPCUserServiceListUsersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PatchUser mocks base method.
+func (m *MockGRPCUserService) PatchUser(ctx context.Context, id uuid.UUID, patch entity.UserPatch) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUser", ctx, id, patch)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchUser indicates an expected call of PatchUser.
+func (mr *MockGRPCUserServiceMockRecorder) PatchUser(ctx, id, patch any) *MockGRPCUserServicePatchUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUser", reflect.TypeOf((*MockGRPCUserService)(nil).PatchUser), ctx, id, patch)
+	return &MockGRPCUserServicePatchUserCall{Call: call}
+}
+
+// MockGRPCUserServicePatchUserCall wrap *gomock.Call
+type MockGRPCUserServicePatchUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServicePatchUserCall) Return(arg0 entity.User, arg1 error) *MockGRPCUserServicePatchUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServicePatchUserCall) Do(f func(context.Context, uuid.UUID, entity.UserPatch) (entity.User, error)) *MockGRPCUserServicePatchUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServicePatchUserCall) DoAndReturn(f func(context.Context, uuid.UUID, entity.UserPatch) (entity.User, error)) *MockGRPCUserServicePatchUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Transfer mocks base method.
+func (m *MockGRPCUserService) Transfer(ctx context.Context, t entity.Transfer) (entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, t)
+	ret0, _ := ret[0].(entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockGRPCUserServiceMockRecorder) Transfer(ctx, t any) *MockGRPCUserServiceTransferCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockGRPCUserService)(nil).Transfer), ctx, t)
+	return &MockGRPCUserServiceTransferCall{Call: call}
+}
+
+// MockGRPCUserServiceTransferCall wrap *gomock.Call
+type MockGRPCUserServiceTransferCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceTransferCall) Return(arg0 entity.Transfer, arg1 error) *MockGRPCUserServiceTransferCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceTransferCall) Do(f func(context.Context, entity.Transfer) (entity.Transfer, error)) *MockGRPCUserServiceTransferCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceTransferCall) DoAndReturn(f func(context.Context, entity.Transfer) (entity.Transfer, error)) *MockGRPCUserServiceTransferCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateUser mocks base method.
+func (m *MockGRPCUserService) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, user)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockGRPCUserServiceMockRecorder) UpdateUser(ctx, user any) *MockGRPCUserServiceUpdateUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockGRPCUserService)(nil).UpdateUser), ctx, user)
+	return &MockGRPCUserServiceUpdateUserCall{Call: call}
+}
+
+// MockGRPCUserServiceUpdateUserCall wrap *gomock.Call
+type MockGRPCUserServiceUpdateUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockGRPCUserServiceUpdateUserCall) Return(arg0 entity.User, arg1 error) *MockGRPCUserServiceUpdateUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockGRPCUserServiceUpdateUserCall) Do(f func(context.Context, entity.User) (entity.User, error)) *MockGRPCUserServiceUpdateUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockGRPCUserServiceUpdateUserCall) DoAndReturn(f func(context.Context, entity.User) (entity.User, error)) *MockGRPCUserServiceUpdateUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
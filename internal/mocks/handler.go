@@ -15,6 +15,7 @@ import (
 	entity "users-app/internal/entity"
 
 	uuid "github.com/gofrs/uuid/v5"
+	decimal "github.com/shopspring/decimal"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,6 +43,239 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ApproveReview mocks base method.
+func (m *MockUserService) ApproveReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveReview", ctx, id)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveReview indicates an expected call of ApproveReview.
+func (mr *MockUserServiceMockRecorder) ApproveReview(ctx, id any) *MockUserServiceApproveReviewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveReview", reflect.TypeOf((*MockUserService)(nil).ApproveReview), ctx, id)
+	return &MockUserServiceApproveReviewCall{Call: call}
+}
+
+// MockUserServiceApproveReviewCall wrap *gomock.Call
+type MockUserServiceApproveReviewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceApproveReviewCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserServiceApproveReviewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceApproveReviewCall) Do(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserServiceApproveReviewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceApproveReviewCall) DoAndReturn(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserServiceApproveReviewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockUserService) AuthenticateAPIKey(ctx context.Context, key string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockUserServiceMockRecorder) AuthenticateAPIKey(ctx, key any) *MockUserServiceAuthenticateAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockUserService)(nil).AuthenticateAPIKey), ctx, key)
+	return &MockUserServiceAuthenticateAPIKeyCall{Call: call}
+}
+
+// MockUserServiceAuthenticateAPIKeyCall wrap *gomock.Call
+type MockUserServiceAuthenticateAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceAuthenticateAPIKeyCall) Return(arg0 entity.APIKey, arg1 error) *MockUserServiceAuthenticateAPIKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceAuthenticateAPIKeyCall) Do(f func(context.Context, string) (entity.APIKey, error)) *MockUserServiceAuthenticateAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceAuthenticateAPIKeyCall) DoAndReturn(f func(context.Context, string) (entity.APIKey, error)) *MockUserServiceAuthenticateAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// AuthorizeUserEvents mocks base method.
+func (m *MockUserService) AuthorizeUserEvents(ctx context.Context, ids []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeUserEvents", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeUserEvents indicates an expected call of AuthorizeUserEvents.
+func (mr *MockUserServiceMockRecorder) AuthorizeUserEvents(ctx, ids any) *MockUserServiceAuthorizeUserEventsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeUserEvents", reflect.TypeOf((*MockUserService)(nil).AuthorizeUserEvents), ctx, ids)
+	return &MockUserServiceAuthorizeUserEventsCall{Call: call}
+}
+
+// MockUserServiceAuthorizeUserEventsCall wrap *gomock.Call
+type MockUserServiceAuthorizeUserEventsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceAuthorizeUserEventsCall) Return(arg0 error) *MockUserServiceAuthorizeUserEventsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceAuthorizeUserEventsCall) Do(f func(context.Context, []uuid.UUID) error) *MockUserServiceAuthorizeUserEventsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceAuthorizeUserEventsCall) DoAndReturn(f func(context.Context, []uuid.UUID) error) *MockUserServiceAuthorizeUserEventsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Balances mocks base method.
+func (m *MockUserService) Balances(ctx context.Context, userID uuid.UUID, currency string) (entity.Balances, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Balances", ctx, userID, currency)
+	ret0, _ := ret[0].(entity.Balances)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Balances indicates an expected call of Balances.
+func (mr *MockUserServiceMockRecorder) Balances(ctx, userID, currency any) *MockUserServiceBalancesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Balances", reflect.TypeOf((*MockUserService)(nil).Balances), ctx, userID, currency)
+	return &MockUserServiceBalancesCall{Call: call}
+}
+
+// MockUserServiceBalancesCall wrap *gomock.Call
+type MockUserServiceBalancesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceBalancesCall) Return(arg0 entity.Balances, arg1 error) *MockUserServiceBalancesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceBalancesCall) Do(f func(context.Context, uuid.UUID, string) (entity.Balances, error)) *MockUserServiceBalancesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceBalancesCall) DoAndReturn(f func(context.Context, uuid.UUID, string) (entity.Balances, error)) *MockUserServiceBalancesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CaptureHold mocks base method.
+func (m *MockUserService) CaptureHold(ctx context.Context, userID, holdID uuid.UUID, amount decimal.Decimal) (entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, userID, holdID, amount)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockUserServiceMockRecorder) CaptureHold(ctx, userID, holdID, amount any) *MockUserServiceCaptureHoldCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockUserService)(nil).CaptureHold), ctx, userID, holdID, amount)
+	return &MockUserServiceCaptureHoldCall{Call: call}
+}
+
+// MockUserServiceCaptureHoldCall wrap *gomock.Call
+type MockUserServiceCaptureHoldCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceCaptureHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserServiceCaptureHoldCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceCaptureHoldCall) Do(f func(context.Context, uuid.UUID, uuid.UUID, decimal.Decimal) (entity.Hold, error)) *MockUserServiceCaptureHoldCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceCaptureHoldCall) DoAndReturn(f func(context.Context, uuid.UUID, uuid.UUID, decimal.Decimal) (entity.Hold, error)) *MockUserServiceCaptureHoldCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockUserService) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockUserServiceMockRecorder) CreateAPIKey(ctx, key any) *MockUserServiceCreateAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUserService)(nil).CreateAPIKey), ctx, key)
+	return &MockUserServiceCreateAPIKeyCall{Call: call}
+}
+
+// MockUserServiceCreateAPIKeyCall wrap *gomock.Call
+type MockUserServiceCreateAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceCreateAPIKeyCall) Return(arg0 entity.APIKey, arg1 error) *MockUserServiceCreateAPIKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceCreateAPIKeyCall) Do(f func(context.Context, entity.APIKey) (entity.APIKey, error)) *MockUserServiceCreateAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceCreateAPIKeyCall) DoAndReturn(f func(context.Context, entity.APIKey) (entity.APIKey, error)) *MockUserServiceCreateAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CreateUser mocks base method.
 func (m *MockUserService) CreateUser(ctx context.Context, user entity.User) error {
 	m.ctrl.T.Helper()
@@ -80,6 +314,45 @@ func (c *MockUserServiceCreateUserCall) DoAndReturn(f func(context.Context, enti
 	return c
 }
 
+// CreateWebhook mocks base method.
+func (m *MockUserService) CreateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, sub)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockUserServiceMockRecorder) CreateWebhook(ctx, sub any) *MockUserServiceCreateWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockUserService)(nil).CreateWebhook), ctx, sub)
+	return &MockUserServiceCreateWebhookCall{Call: call}
+}
+
+// MockUserServiceCreateWebhookCall wrap *gomock.Call
+type MockUserServiceCreateWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceCreateWebhookCall) Return(arg0 entity.WebhookSubscription, arg1 error) *MockUserServiceCreateWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceCreateWebhookCall) Do(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserServiceCreateWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceCreateWebhookCall) DoAndReturn(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserServiceCreateWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DeleteUser mocks base method.
 func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -118,79 +391,1093 @@ func (c *MockUserServiceDeleteUserCall) DoAndReturn(f func(context.Context, uuid
 	return c
 }
 
-// GetUserByID mocks base method.
-func (m *MockUserService) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+// DeleteWebhook mocks base method.
+func (m *MockUserService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
-	ret0, _ := ret[0].(entity.User)
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockUserServiceMockRecorder) DeleteWebhook(ctx, id any) *MockUserServiceDeleteWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockUserService)(nil).DeleteWebhook), ctx, id)
+	return &MockUserServiceDeleteWebhookCall{Call: call}
+}
+
+// MockUserServiceDeleteWebhookCall wrap *gomock.Call
+type MockUserServiceDeleteWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceDeleteWebhookCall) Return(arg0 error) *MockUserServiceDeleteWebhookCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceDeleteWebhookCall) Do(f func(context.Context, uuid.UUID) error) *MockUserServiceDeleteWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceDeleteWebhookCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockUserServiceDeleteWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Deposit mocks base method.
+func (m *MockUserService) Deposit(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", ctx, userID, amount)
+	ret0, _ := ret[0].(entity.Movement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockUserServiceMockRecorder) GetUserByID(ctx, id any) *MockUserServiceGetUserByIDCall {
+// Deposit indicates an expected call of Deposit.
+func (mr *MockUserServiceMockRecorder) Deposit(ctx, userID, amount any) *MockUserServiceDepositCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserService)(nil).GetUserByID), ctx, id)
-	return &MockUserServiceGetUserByIDCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockUserService)(nil).Deposit), ctx, userID, amount)
+	return &MockUserServiceDepositCall{Call: call}
 }
 
-// MockUserServiceGetUserByIDCall wrap *gomock.Call
-type MockUserServiceGetUserByIDCall struct {
+// MockUserServiceDepositCall wrap *gomock.Call
+type MockUserServiceDepositCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockUserServiceGetUserByIDCall) Return(arg0 entity.User, arg1 error) *MockUserServiceGetUserByIDCall {
+func (c *MockUserServiceDepositCall) Return(arg0 entity.Movement, arg1 error) *MockUserServiceDepositCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockUserServiceGetUserByIDCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceGetUserByIDCall {
+func (c *MockUserServiceDepositCall) Do(f func(context.Context, uuid.UUID, decimal.Decimal) (entity.Movement, error)) *MockUserServiceDepositCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockUserServiceGetUserByIDCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceGetUserByIDCall {
+func (c *MockUserServiceDepositCall) DoAndReturn(f func(context.Context, uuid.UUID, decimal.Decimal) (entity.Movement, error)) *MockUserServiceDepositCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// UpdateUser mocks base method.
-func (m *MockUserService) UpdateUser(ctx context.Context, user entity.User) error {
+// GetHold mocks base method.
+func (m *MockUserService) GetHold(ctx context.Context, userID, holdID uuid.UUID) (entity.Hold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", ctx, user)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockUserServiceMockRecorder) UpdateUser(ctx, user any) *MockUserServiceUpdateUserCall {
+// GetHold indicates an expected call of GetHold.
+func (mr *MockUserServiceMockRecorder) GetHold(ctx, userID, holdID any) *MockUserServiceGetHoldCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserService)(nil).UpdateUser), ctx, user)
-	return &MockUserServiceUpdateUserCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockUserService)(nil).GetHold), ctx, userID, holdID)
+	return &MockUserServiceGetHoldCall{Call: call}
 }
 
-// MockUserServiceUpdateUserCall wrap *gomock.Call
-type MockUserServiceUpdateUserCall struct {
+// MockUserServiceGetHoldCall wrap *gomock.Call
+type MockUserServiceGetHoldCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockUserServiceUpdateUserCall) Return(arg0 error) *MockUserServiceUpdateUserCall {
-	c.Call = c.Call.Return(arg0)
+func (c *MockUserServiceGetHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserServiceGetHoldCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetHoldCall) Do(f func(context.Context, uuid.UUID, uuid.UUID) (entity.Hold, error)) *MockUserServiceGetHoldCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetHoldCall) DoAndReturn(f func(context.Context, uuid.UUID, uuid.UUID) (entity.Hold, error)) *MockUserServiceGetHoldCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetLimits mocks base method.
+func (m *MockUserService) GetLimits(ctx context.Context, userID uuid.UUID) (entity.UserLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimits", ctx, userID)
+	ret0, _ := ret[0].(entity.UserLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimits indicates an expected call of GetLimits.
+func (mr *MockUserServiceMockRecorder) GetLimits(ctx, userID any) *MockUserServiceGetLimitsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimits", reflect.TypeOf((*MockUserService)(nil).GetLimits), ctx, userID)
+	return &MockUserServiceGetLimitsCall{Call: call}
+}
+
+// MockUserServiceGetLimitsCall wrap *gomock.Call
+type MockUserServiceGetLimitsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetLimitsCall) Return(arg0 entity.UserLimits, arg1 error) *MockUserServiceGetLimitsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetLimitsCall) Do(f func(context.Context, uuid.UUID) (entity.UserLimits, error)) *MockUserServiceGetLimitsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetLimitsCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.UserLimits, error)) *MockUserServiceGetLimitsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetReview mocks base method.
+func (m *MockUserService) GetReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReview", ctx, id)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReview indicates an expected call of GetReview.
+func (mr *MockUserServiceMockRecorder) GetReview(ctx, id any) *MockUserServiceGetReviewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReview", reflect.TypeOf((*MockUserService)(nil).GetReview), ctx, id)
+	return &MockUserServiceGetReviewCall{Call: call}
+}
+
+// MockUserServiceGetReviewCall wrap *gomock.Call
+type MockUserServiceGetReviewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetReviewCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserServiceGetReviewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetReviewCall) Do(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserServiceGetReviewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetReviewCall) DoAndReturn(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserServiceGetReviewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockUserService) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockUserServiceMockRecorder) GetUserByEmail(ctx, email any) *MockUserServiceGetUserByEmailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserService)(nil).GetUserByEmail), ctx, email)
+	return &MockUserServiceGetUserByEmailCall{Call: call}
+}
+
+// MockUserServiceGetUserByEmailCall wrap *gomock.Call
+type MockUserServiceGetUserByEmailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetUserByEmailCall) Return(arg0 entity.User, arg1 error) *MockUserServiceGetUserByEmailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetUserByEmailCall) Do(f func(context.Context, string) (entity.User, error)) *MockUserServiceGetUserByEmailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetUserByEmailCall) DoAndReturn(f func(context.Context, string) (entity.User, error)) *MockUserServiceGetUserByEmailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserService) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserServiceMockRecorder) GetUserByID(ctx, id any) *MockUserServiceGetUserByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserService)(nil).GetUserByID), ctx, id)
+	return &MockUserServiceGetUserByIDCall{Call: call}
+}
+
+// MockUserServiceGetUserByIDCall wrap *gomock.Call
+type MockUserServiceGetUserByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetUserByIDCall) Return(arg0 entity.User, arg1 error) *MockUserServiceGetUserByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetUserByIDCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceGetUserByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetUserByIDCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceGetUserByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByIDWithDeleted mocks base method.
+func (m *MockUserService) GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByIDWithDeleted", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByIDWithDeleted indicates an expected call of GetUserByIDWithDeleted.
+func (mr *MockUserServiceMockRecorder) GetUserByIDWithDeleted(ctx, id any) *MockUserServiceGetUserByIDWithDeletedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIDWithDeleted", reflect.TypeOf((*MockUserService)(nil).GetUserByIDWithDeleted), ctx, id)
+	return &MockUserServiceGetUserByIDWithDeletedCall{Call: call}
+}
+
+// MockUserServiceGetUserByIDWithDeletedCall wrap *gomock.Call
+type MockUserServiceGetUserByIDWithDeletedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetUserByIDWithDeletedCall) Return(arg0 entity.User, arg1 error) *MockUserServiceGetUserByIDWithDeletedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetUserByIDWithDeletedCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceGetUserByIDWithDeletedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetUserByIDWithDeletedCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceGetUserByIDWithDeletedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserHistory mocks base method.
+func (m *MockUserService) GetUserHistory(ctx context.Context, id uuid.UUID, cursor string, limit int) (entity.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserHistory", ctx, id, cursor, limit)
+	ret0, _ := ret[0].(entity.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserHistory indicates an expected call of GetUserHistory.
+func (mr *MockUserServiceMockRecorder) GetUserHistory(ctx, id, cursor, limit any) *MockUserServiceGetUserHistoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserHistory", reflect.TypeOf((*MockUserService)(nil).GetUserHistory), ctx, id, cursor, limit)
+	return &MockUserServiceGetUserHistoryCall{Call: call}
+}
+
+// MockUserServiceGetUserHistoryCall wrap *gomock.Call
+type MockUserServiceGetUserHistoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetUserHistoryCall) Return(arg0 entity.AuditPage, arg1 error) *MockUserServiceGetUserHistoryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetUserHistoryCall) Do(f func(context.Context, uuid.UUID, string, int) (entity.AuditPage, error)) *MockUserServiceGetUserHistoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetUserHistoryCall) DoAndReturn(f func(context.Context, uuid.UUID, string, int) (entity.AuditPage, error)) *MockUserServiceGetUserHistoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUsersByIDs mocks base method.
+func (m *MockUserService) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockUserServiceMockRecorder) GetUsersByIDs(ctx, ids any) *MockUserServiceGetUsersByIDsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockUserService)(nil).GetUsersByIDs), ctx, ids)
+	return &MockUserServiceGetUsersByIDsCall{Call: call}
+}
+
+// MockUserServiceGetUsersByIDsCall wrap *gomock.Call
+type MockUserServiceGetUsersByIDsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetUsersByIDsCall) Return(arg0 []entity.User, arg1 error) *MockUserServiceGetUsersByIDsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetUsersByIDsCall) Do(f func(context.Context, []uuid.UUID) ([]entity.User, error)) *MockUserServiceGetUsersByIDsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetUsersByIDsCall) DoAndReturn(f func(context.Context, []uuid.UUID) ([]entity.User, error)) *MockUserServiceGetUsersByIDsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWebhook mocks base method.
+func (m *MockUserService) GetWebhook(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockUserServiceMockRecorder) GetWebhook(ctx, id any) *MockUserServiceGetWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockUserService)(nil).GetWebhook), ctx, id)
+	return &MockUserServiceGetWebhookCall{Call: call}
+}
+
+// MockUserServiceGetWebhookCall wrap *gomock.Call
+type MockUserServiceGetWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceGetWebhookCall) Return(arg0 entity.WebhookSubscription, arg1 error) *MockUserServiceGetWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceGetWebhookCall) Do(f func(context.Context, uuid.UUID) (entity.WebhookSubscription, error)) *MockUserServiceGetWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceGetWebhookCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.WebhookSubscription, error)) *MockUserServiceGetWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockUserService) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockUserServiceMockRecorder) ListAPIKeys(ctx any) *MockUserServiceListAPIKeysCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockUserService)(nil).ListAPIKeys), ctx)
+	return &MockUserServiceListAPIKeysCall{Call: call}
+}
+
+// MockUserServiceListAPIKeysCall wrap *gomock.Call
+type MockUserServiceListAPIKeysCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceListAPIKeysCall) Return(arg0 []entity.APIKey, arg1 error) *MockUserServiceListAPIKeysCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceListAPIKeysCall) Do(f func(context.Context) ([]entity.APIKey, error)) *MockUserServiceListAPIKeysCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceListAPIKeysCall) DoAndReturn(f func(context.Context) ([]entity.APIKey, error)) *MockUserServiceListAPIKeysCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListReviews mocks base method.
+func (m *MockUserService) ListReviews(ctx context.Context, status entity.ReviewStatus, cursor string, limit int) (entity.RiskReviewPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReviews", ctx, status, cursor, limit)
+	ret0, _ := ret[0].(entity.RiskReviewPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReviews indicates an expected call of ListReviews.
+func (mr *MockUserServiceMockRecorder) ListReviews(ctx, status, cursor, limit any) *MockUserServiceListReviewsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReviews", reflect.TypeOf((*MockUserService)(nil).ListReviews), ctx, status, cursor, limit)
+	return &MockUserServiceListReviewsCall{Call: call}
+}
+
+// MockUserServiceListReviewsCall wrap *gomock.Call
+type MockUserServiceListReviewsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceListReviewsCall) Return(arg0 entity.RiskReviewPage, arg1 error) *MockUserServiceListReviewsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceListReviewsCall) Do(f func(context.Context, entity.ReviewStatus, string, int) (entity.RiskReviewPage, error)) *MockUserServiceListReviewsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceListReviewsCall) DoAndReturn(f func(context.Context, entity.ReviewStatus, string, int) (entity.RiskReviewPage, error)) *MockUserServiceListReviewsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListUsers mocks base method.
+func (m *MockUserService) ListUsers(ctx context.Context, params entity.ListUsersParams) (entity.UserPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, params)
+	ret0, _ := ret[0].(entity.UserPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserServiceMockRecorder) ListUsers(ctx, params any) *MockUserServiceListUsersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserService)(nil).ListUsers), ctx, params)
+	return &MockUserServiceListUsersCall{Call: call}
+}
+
+// MockUserServiceListUsersCall wrap *gomock.Call
+type MockUserServiceListUsersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceListUsersCall) Return(arg0 entity.UserPage, arg1 error) *MockUserServiceListUsersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceListUsersCall) Do(f func(context.Context, entity.ListUsersParams) (entity.UserPage, error)) *MockUserServiceListUsersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceListUsersCall) DoAndReturn(f func(context.Context, entity.ListUsersParams) (entity.UserPage, error)) *MockUserServiceListUsersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockUserService) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, cursor string, limit int) (entity.WebhookDeliveryPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, subscriptionID, cursor, limit)
+	ret0, _ := ret[0].(entity.WebhookDeliveryPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockUserServiceMockRecorder) ListWebhookDeliveries(ctx, subscriptionID, cursor, limit any) *MockUserServiceListWebhookDeliveriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockUserService)(nil).ListWebhookDeliveries), ctx, subscriptionID, cursor, limit)
+	return &MockUserServiceListWebhookDeliveriesCall{Call: call}
+}
+
+// MockUserServiceListWebhookDeliveriesCall wrap *gomock.Call
+type MockUserServiceListWebhookDeliveriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceListWebhookDeliveriesCall) Return(arg0 entity.WebhookDeliveryPage, arg1 error) *MockUserServiceListWebhookDeliveriesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceListWebhookDeliveriesCall) Do(f func(context.Context, uuid.UUID, string, int) (entity.WebhookDeliveryPage, error)) *MockUserServiceListWebhookDeliveriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceListWebhookDeliveriesCall) DoAndReturn(f func(context.Context, uuid.UUID, string, int) (entity.WebhookDeliveryPage, error)) *MockUserServiceListWebhookDeliveriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListWebhooks mocks base method.
+func (m *MockUserService) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx)
+	ret0, _ := ret[0].([]entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockUserServiceMockRecorder) ListWebhooks(ctx any) *MockUserServiceListWebhooksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockUserService)(nil).ListWebhooks), ctx)
+	return &MockUserServiceListWebhooksCall{Call: call}
+}
+
+// MockUserServiceListWebhooksCall wrap *gomock.Call
+type MockUserServiceListWebhooksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceListWebhooksCall) Return(arg0 []entity.WebhookSubscription, arg1 error) *MockUserServiceListWebhooksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceListWebhooksCall) Do(f func(context.Context) ([]entity.WebhookSubscription, error)) *MockUserServiceListWebhooksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceListWebhooksCall) DoAndReturn(f func(context.Context) ([]entity.WebhookSubscription, error)) *MockUserServiceListWebhooksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PatchUser mocks base method.
+func (m *MockUserService) PatchUser(ctx context.Context, id uuid.UUID, patch entity.UserPatch) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUser", ctx, id, patch)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchUser indicates an expected call of PatchUser.
+func (mr *MockUserServiceMockRecorder) PatchUser(ctx, id, patch any) *MockUserServicePatchUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUser", reflect.TypeOf((*MockUserService)(nil).PatchUser), ctx, id, patch)
+	return &MockUserServicePatchUserCall{Call: call}
+}
+
+// MockUserServicePatchUserCall wrap *gomock.Call
+type MockUserServicePatchUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServicePatchUserCall) Return(arg0 entity.User, arg1 error) *MockUserServicePatchUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServicePatchUserCall) Do(f func(context.Context, uuid.UUID, entity.UserPatch) (entity.User, error)) *MockUserServicePatchUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServicePatchUserCall) DoAndReturn(f func(context.Context, uuid.UUID, entity.UserPatch) (entity.User, error)) *MockUserServicePatchUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PlaceHold mocks base method.
+func (m *MockUserService) PlaceHold(ctx context.Context, hold entity.Hold) (entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceHold", ctx, hold)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceHold indicates an expected call of PlaceHold.
+func (mr *MockUserServiceMockRecorder) PlaceHold(ctx, hold any) *MockUserServicePlaceHoldCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceHold", reflect.TypeOf((*MockUserService)(nil).PlaceHold), ctx, hold)
+	return &MockUserServicePlaceHoldCall{Call: call}
+}
+
+// MockUserServicePlaceHoldCall wrap *gomock.Call
+type MockUserServicePlaceHoldCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServicePlaceHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserServicePlaceHoldCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServicePlaceHoldCall) Do(f func(context.Context, entity.Hold) (entity.Hold, error)) *MockUserServicePlaceHoldCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServicePlaceHoldCall) DoAndReturn(f func(context.Context, entity.Hold) (entity.Hold, error)) *MockUserServicePlaceHoldCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RedeliverWebhook mocks base method.
+func (m *MockUserService) RedeliverWebhook(ctx context.Context, subscriptionID uuid.UUID, deliveryID int64) (entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhook", ctx, subscriptionID, deliveryID)
+	ret0, _ := ret[0].(entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverWebhook indicates an expected call of RedeliverWebhook.
+func (mr *MockUserServiceMockRecorder) RedeliverWebhook(ctx, subscriptionID, deliveryID any) *MockUserServiceRedeliverWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhook", reflect.TypeOf((*MockUserService)(nil).RedeliverWebhook), ctx, subscriptionID, deliveryID)
+	return &MockUserServiceRedeliverWebhookCall{Call: call}
+}
+
+// MockUserServiceRedeliverWebhookCall wrap *gomock.Call
+type MockUserServiceRedeliverWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceRedeliverWebhookCall) Return(arg0 entity.WebhookDelivery, arg1 error) *MockUserServiceRedeliverWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceRedeliverWebhookCall) Do(f func(context.Context, uuid.UUID, int64) (entity.WebhookDelivery, error)) *MockUserServiceRedeliverWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceRedeliverWebhookCall) DoAndReturn(f func(context.Context, uuid.UUID, int64) (entity.WebhookDelivery, error)) *MockUserServiceRedeliverWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RejectReview mocks base method.
+func (m *MockUserService) RejectReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectReview", ctx, id)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectReview indicates an expected call of RejectReview.
+func (mr *MockUserServiceMockRecorder) RejectReview(ctx, id any) *MockUserServiceRejectReviewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectReview", reflect.TypeOf((*MockUserService)(nil).RejectReview), ctx, id)
+	return &MockUserServiceRejectReviewCall{Call: call}
+}
+
+// MockUserServiceRejectReviewCall wrap *gomock.Call
+type MockUserServiceRejectReviewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceRejectReviewCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserServiceRejectReviewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceRejectReviewCall) Do(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserServiceRejectReviewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceRejectReviewCall) DoAndReturn(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserServiceRejectReviewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReleaseHold mocks base method.
+func (m *MockUserService) ReleaseHold(ctx context.Context, userID, holdID uuid.UUID) (entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockUserServiceMockRecorder) ReleaseHold(ctx, userID, holdID any) *MockUserServiceReleaseHoldCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockUserService)(nil).ReleaseHold), ctx, userID, holdID)
+	return &MockUserServiceReleaseHoldCall{Call: call}
+}
+
+// MockUserServiceReleaseHoldCall wrap *gomock.Call
+type MockUserServiceReleaseHoldCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceReleaseHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserServiceReleaseHoldCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceReleaseHoldCall) Do(f func(context.Context, uuid.UUID, uuid.UUID) (entity.Hold, error)) *MockUserServiceReleaseHoldCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceReleaseHoldCall) DoAndReturn(f func(context.Context, uuid.UUID, uuid.UUID) (entity.Hold, error)) *MockUserServiceReleaseHoldCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RestoreUser mocks base method.
+func (m *MockUserService) RestoreUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockUserServiceMockRecorder) RestoreUser(ctx, id any) *MockUserServiceRestoreUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockUserService)(nil).RestoreUser), ctx, id)
+	return &MockUserServiceRestoreUserCall{Call: call}
+}
+
+// MockUserServiceRestoreUserCall wrap *gomock.Call
+type MockUserServiceRestoreUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceRestoreUserCall) Return(arg0 entity.User, arg1 error) *MockUserServiceRestoreUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceRestoreUserCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceRestoreUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceRestoreUserCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserServiceRestoreUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockUserService) RevokeAPIKey(ctx context.Context, id uuid.UUID) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockUserServiceMockRecorder) RevokeAPIKey(ctx, id any) *MockUserServiceRevokeAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockUserService)(nil).RevokeAPIKey), ctx, id)
+	return &MockUserServiceRevokeAPIKeyCall{Call: call}
+}
+
+// MockUserServiceRevokeAPIKeyCall wrap *gomock.Call
+type MockUserServiceRevokeAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceRevokeAPIKeyCall) Return(arg0 entity.APIKey, arg1 error) *MockUserServiceRevokeAPIKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceRevokeAPIKeyCall) Do(f func(context.Context, uuid.UUID) (entity.APIKey, error)) *MockUserServiceRevokeAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceRevokeAPIKeyCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.APIKey, error)) *MockUserServiceRevokeAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetLimits mocks base method.
+func (m *MockUserService) SetLimits(ctx context.Context, userID uuid.UUID, tier string, overrides entity.SpendingLimits) (entity.UserLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimits", ctx, userID, tier, overrides)
+	ret0, _ := ret[0].(entity.UserLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLimits indicates an expected call of SetLimits.
+func (mr *MockUserServiceMockRecorder) SetLimits(ctx, userID, tier, overrides any) *MockUserServiceSetLimitsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimits", reflect.TypeOf((*MockUserService)(nil).SetLimits), ctx, userID, tier, overrides)
+	return &MockUserServiceSetLimitsCall{Call: call}
+}
+
+// MockUserServiceSetLimitsCall wrap *gomock.Call
+type MockUserServiceSetLimitsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceSetLimitsCall) Return(arg0 entity.UserLimits, arg1 error) *MockUserServiceSetLimitsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceSetLimitsCall) Do(f func(context.Context, uuid.UUID, string, entity.SpendingLimits) (entity.UserLimits, error)) *MockUserServiceSetLimitsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceSetLimitsCall) DoAndReturn(f func(context.Context, uuid.UUID, string, entity.SpendingLimits) (entity.UserLimits, error)) *MockUserServiceSetLimitsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Transfer mocks base method.
+func (m *MockUserService) Transfer(ctx context.Context, t entity.Transfer) (entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, t)
+	ret0, _ := ret[0].(entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockUserServiceMockRecorder) Transfer(ctx, t any) *MockUserServiceTransferCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockUserService)(nil).Transfer), ctx, t)
+	return &MockUserServiceTransferCall{Call: call}
+}
+
+// MockUserServiceTransferCall wrap *gomock.Call
+type MockUserServiceTransferCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceTransferCall) Return(arg0 entity.Transfer, arg1 error) *MockUserServiceTransferCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceTransferCall) Do(f func(context.Context, entity.Transfer) (entity.Transfer, error)) *MockUserServiceTransferCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceTransferCall) DoAndReturn(f func(context.Context, entity.Transfer) (entity.Transfer, error)) *MockUserServiceTransferCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateUser mocks base method.
+func (m *MockUserService) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, user)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserServiceMockRecorder) UpdateUser(ctx, user any) *MockUserServiceUpdateUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserService)(nil).UpdateUser), ctx, user)
+	return &MockUserServiceUpdateUserCall{Call: call}
+}
+
+// MockUserServiceUpdateUserCall wrap *gomock.Call
+type MockUserServiceUpdateUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceUpdateUserCall) Return(arg0 entity.User, arg1 error) *MockUserServiceUpdateUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceUpdateUserCall) Do(f func(context.Context, entity.User) (entity.User, error)) *MockUserServiceUpdateUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceUpdateUserCall) DoAndReturn(f func(context.Context, entity.User) (entity.User, error)) *MockUserServiceUpdateUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockUserService) UpdateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, sub)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockUserServiceMockRecorder) UpdateWebhook(ctx, sub any) *MockUserServiceUpdateWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockUserService)(nil).UpdateWebhook), ctx, sub)
+	return &MockUserServiceUpdateWebhookCall{Call: call}
+}
+
+// MockUserServiceUpdateWebhookCall wrap *gomock.Call
+type MockUserServiceUpdateWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceUpdateWebhookCall) Return(arg0 entity.WebhookSubscription, arg1 error) *MockUserServiceUpdateWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserServiceUpdateWebhookCall) Do(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserServiceUpdateWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserServiceUpdateWebhookCall) DoAndReturn(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserServiceUpdateWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Withdraw mocks base method.
+func (m *MockUserService) Withdraw(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Withdraw", ctx, userID, amount)
+	ret0, _ := ret[0].(entity.Movement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockUserServiceMockRecorder) Withdraw(ctx, userID, amount any) *MockUserServiceWithdrawCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockUserService)(nil).Withdraw), ctx, userID, amount)
+	return &MockUserServiceWithdrawCall{Call: call}
+}
+
+// MockUserServiceWithdrawCall wrap *gomock.Call
+type MockUserServiceWithdrawCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserServiceWithdrawCall) Return(arg0 entity.Movement, arg1 error) *MockUserServiceWithdrawCall {
+	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockUserServiceUpdateUserCall) Do(f func(context.Context, entity.User) error) *MockUserServiceUpdateUserCall {
+func (c *MockUserServiceWithdrawCall) Do(f func(context.Context, uuid.UUID, decimal.Decimal) (entity.Movement, error)) *MockUserServiceWithdrawCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockUserServiceUpdateUserCall) DoAndReturn(f func(context.Context, entity.User) error) *MockUserServiceUpdateUserCall {
+func (c *MockUserServiceWithdrawCall) DoAndReturn(f func(context.Context, uuid.UUID, decimal.Decimal) (entity.Movement, error)) *MockUserServiceWithdrawCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
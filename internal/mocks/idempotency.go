@@ -0,0 +1,198 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: idempotency.go
+//
+// Generated by this command:
+//
+//	mockgen -source=idempotency.go -destination=../mocks/idempotency.go -package=mocks -typed -mock_names=Repository=MockIdempotencyRepository
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	entity "users-app/internal/entity"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIdempotencyRepository is a mock of Repository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimIdempotencyKey mocks base method.
+func (m *MockIdempotencyRepository) ClaimIdempotencyKey(ctx context.Context, scope, key string, fingerprint []byte, ttl, lockTimeout time.Duration) (entity.IdempotencyRecord, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", ctx, scope, key, fingerprint, ttl, lockTimeout)
+	ret0, _ := ret[0].(entity.IdempotencyRecord)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockIdempotencyRepositoryMockRecorder) ClaimIdempotencyKey(ctx, scope, key, fingerprint, ttl, lockTimeout any) *MockIdempotencyRepositoryClaimIdempotencyKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockIdempotencyRepository)(nil).ClaimIdempotencyKey), ctx, scope, key, fingerprint, ttl, lockTimeout)
+	return &MockIdempotencyRepositoryClaimIdempotencyKeyCall{Call: call}
+}
+
+// MockIdempotencyRepositoryClaimIdempotencyKeyCall wrap *gomock.Call
+type MockIdempotencyRepositoryClaimIdempotencyKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIdempotencyRepositoryClaimIdempotencyKeyCall) Return(arg0 entity.IdempotencyRecord, arg1 bool, arg2 error) *MockIdempotencyRepositoryClaimIdempotencyKeyCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIdempotencyRepositoryClaimIdempotencyKeyCall) Do(f func(context.Context, string, string, []byte, time.Duration, time.Duration) (entity.IdempotencyRecord, bool, error)) *MockIdempotencyRepositoryClaimIdempotencyKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIdempotencyRepositoryClaimIdempotencyKeyCall) DoAndReturn(f func(context.Context, string, string, []byte, time.Duration, time.Duration) (entity.IdempotencyRecord, bool, error)) *MockIdempotencyRepositoryClaimIdempotencyKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockIdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, scope, key string, status int, header map[string][]string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, scope, key, status, header, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockIdempotencyRepositoryMockRecorder) CompleteIdempotencyKey(ctx, scope, key, status, header, body any) *MockIdempotencyRepositoryCompleteIdempotencyKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockIdempotencyRepository)(nil).CompleteIdempotencyKey), ctx, scope, key, status, header, body)
+	return &MockIdempotencyRepositoryCompleteIdempotencyKeyCall{Call: call}
+}
+
+// MockIdempotencyRepositoryCompleteIdempotencyKeyCall wrap *gomock.Call
+type MockIdempotencyRepositoryCompleteIdempotencyKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIdempotencyRepositoryCompleteIdempotencyKeyCall) Return(arg0 error) *MockIdempotencyRepositoryCompleteIdempotencyKeyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIdempotencyRepositoryCompleteIdempotencyKeyCall) Do(f func(context.Context, string, string, int, map[string][]string, []byte) error) *MockIdempotencyRepositoryCompleteIdempotencyKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIdempotencyRepositoryCompleteIdempotencyKeyCall) DoAndReturn(f func(context.Context, string, string, int, map[string][]string, []byte) error) *MockIdempotencyRepositoryCompleteIdempotencyKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgeIdempotencyKeys mocks base method.
+func (m *MockIdempotencyRepository) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeIdempotencyKeys", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeIdempotencyKeys indicates an expected call of PurgeIdempotencyKeys.
+func (mr *MockIdempotencyRepositoryMockRecorder) PurgeIdempotencyKeys(ctx any) *MockIdempotencyRepositoryPurgeIdempotencyKeysCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeIdempotencyKeys", reflect.TypeOf((*MockIdempotencyRepository)(nil).PurgeIdempotencyKeys), ctx)
+	return &MockIdempotencyRepositoryPurgeIdempotencyKeysCall{Call: call}
+}
+
+// MockIdempotencyRepositoryPurgeIdempotencyKeysCall wrap *gomock.Call
+type MockIdempotencyRepositoryPurgeIdempotencyKeysCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIdempotencyRepositoryPurgeIdempotencyKeysCall) Return(arg0 int64, arg1 error) *MockIdempotencyRepositoryPurgeIdempotencyKeysCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIdempotencyRepositoryPurgeIdempotencyKeysCall) Do(f func(context.Context) (int64, error)) *MockIdempotencyRepositoryPurgeIdempotencyKeysCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIdempotencyRepositoryPurgeIdempotencyKeysCall) DoAndReturn(f func(context.Context) (int64, error)) *MockIdempotencyRepositoryPurgeIdempotencyKeysCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockIdempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockIdempotencyRepositoryMockRecorder) ReleaseIdempotencyKey(ctx, scope, key any) *MockIdempotencyRepositoryReleaseIdempotencyKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockIdempotencyRepository)(nil).ReleaseIdempotencyKey), ctx, scope, key)
+	return &MockIdempotencyRepositoryReleaseIdempotencyKeyCall{Call: call}
+}
+
+// MockIdempotencyRepositoryReleaseIdempotencyKeyCall wrap *gomock.Call
+type MockIdempotencyRepositoryReleaseIdempotencyKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockIdempotencyRepositoryReleaseIdempotencyKeyCall) Return(arg0 error) *MockIdempotencyRepositoryReleaseIdempotencyKeyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockIdempotencyRepositoryReleaseIdempotencyKeyCall) Do(f func(context.Context, string, string) error) *MockIdempotencyRepositoryReleaseIdempotencyKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockIdempotencyRepositoryReleaseIdempotencyKeyCall) DoAndReturn(f func(context.Context, string, string) error) *MockIdempotencyRepositoryReleaseIdempotencyKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
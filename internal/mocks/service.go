@@ -12,9 +12,11 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	entity "users-app/internal/entity"
 
 	uuid "github.com/gofrs/uuid/v5"
+	decimal "github.com/shopspring/decimal"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,129 +44,2221 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimOutboxEntries mocks base method.
+func (m *MockUserRepository) ClaimOutboxEntries(ctx context.Context, limit int, until time.Time) ([]entity.OutboxEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEntries", ctx, limit, until)
+	ret0, _ := ret[0].([]entity.OutboxEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEntries indicates an expected call of ClaimOutboxEntries.
+func (mr *MockUserRepositoryMockRecorder) ClaimOutboxEntries(ctx, limit, until any) *MockUserRepositoryClaimOutboxEntriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEntries", reflect.TypeOf((*MockUserRepository)(nil).ClaimOutboxEntries), ctx, limit, until)
+	return &MockUserRepositoryClaimOutboxEntriesCall{Call: call}
+}
+
+// MockUserRepositoryClaimOutboxEntriesCall wrap *gomock.Call
+type MockUserRepositoryClaimOutboxEntriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryClaimOutboxEntriesCall) Return(arg0 []entity.OutboxEntry, arg1 error) *MockUserRepositoryClaimOutboxEntriesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryClaimOutboxEntriesCall) Do(f func(context.Context, int, time.Time) ([]entity.OutboxEntry, error)) *MockUserRepositoryClaimOutboxEntriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryClaimOutboxEntriesCall) DoAndReturn(f func(context.Context, int, time.Time) ([]entity.OutboxEntry, error)) *MockUserRepositoryClaimOutboxEntriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ClaimWebhookJobs mocks base method.
+func (m *MockUserRepository) ClaimWebhookJobs(ctx context.Context, limit int, until time.Time) ([]entity.WebhookJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookJobs", ctx, limit, until)
+	ret0, _ := ret[0].([]entity.WebhookJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookJobs indicates an expected call of ClaimWebhookJobs.
+func (mr *MockUserRepositoryMockRecorder) ClaimWebhookJobs(ctx, limit, until any) *MockUserRepositoryClaimWebhookJobsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookJobs", reflect.TypeOf((*MockUserRepository)(nil).ClaimWebhookJobs), ctx, limit, until)
+	return &MockUserRepositoryClaimWebhookJobsCall{Call: call}
+}
+
+// MockUserRepositoryClaimWebhookJobsCall wrap *gomock.Call
+type MockUserRepositoryClaimWebhookJobsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryClaimWebhookJobsCall) Return(arg0 []entity.WebhookJob, arg1 error) *MockUserRepositoryClaimWebhookJobsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryClaimWebhookJobsCall) Do(f func(context.Context, int, time.Time) ([]entity.WebhookJob, error)) *MockUserRepositoryClaimWebhookJobsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryClaimWebhookJobsCall) DoAndReturn(f func(context.Context, int, time.Time) ([]entity.WebhookJob, error)) *MockUserRepositoryClaimWebhookJobsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CountRiskOperations mocks base method.
+func (m *MockUserRepository) CountRiskOperations(ctx context.Context, userID uuid.UUID, kind entity.OperationKind, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRiskOperations", ctx, userID, kind, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRiskOperations indicates an expected call of CountRiskOperations.
+func (mr *MockUserRepositoryMockRecorder) CountRiskOperations(ctx, userID, kind, since any) *MockUserRepositoryCountRiskOperationsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRiskOperations", reflect.TypeOf((*MockUserRepository)(nil).CountRiskOperations), ctx, userID, kind, since)
+	return &MockUserRepositoryCountRiskOperationsCall{Call: call}
+}
+
+// MockUserRepositoryCountRiskOperationsCall wrap *gomock.Call
+type MockUserRepositoryCountRiskOperationsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCountRiskOperationsCall) Return(arg0 int, arg1 error) *MockUserRepositoryCountRiskOperationsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCountRiskOperationsCall) Do(f func(context.Context, uuid.UUID, entity.OperationKind, time.Time) (int, error)) *MockUserRepositoryCountRiskOperationsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCountRiskOperationsCall) DoAndReturn(f func(context.Context, uuid.UUID, entity.OperationKind, time.Time) (int, error)) *MockUserRepositoryCountRiskOperationsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockUserRepository) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockUserRepositoryMockRecorder) CreateAPIKey(ctx, key any) *MockUserRepositoryCreateAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUserRepository)(nil).CreateAPIKey), ctx, key)
+	return &MockUserRepositoryCreateAPIKeyCall{Call: call}
+}
+
+// MockUserRepositoryCreateAPIKeyCall wrap *gomock.Call
+type MockUserRepositoryCreateAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateAPIKeyCall) Return(arg0 entity.APIKey, arg1 error) *MockUserRepositoryCreateAPIKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateAPIKeyCall) Do(f func(context.Context, entity.APIKey) (entity.APIKey, error)) *MockUserRepositoryCreateAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateAPIKeyCall) DoAndReturn(f func(context.Context, entity.APIKey) (entity.APIKey, error)) *MockUserRepositoryCreateAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateAuditRecord mocks base method.
+func (m *MockUserRepository) CreateAuditRecord(ctx context.Context, record entity.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditRecord", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditRecord indicates an expected call of CreateAuditRecord.
+func (mr *MockUserRepositoryMockRecorder) CreateAuditRecord(ctx, record any) *MockUserRepositoryCreateAuditRecordCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditRecord", reflect.TypeOf((*MockUserRepository)(nil).CreateAuditRecord), ctx, record)
+	return &MockUserRepositoryCreateAuditRecordCall{Call: call}
+}
+
+// MockUserRepositoryCreateAuditRecordCall wrap *gomock.Call
+type MockUserRepositoryCreateAuditRecordCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateAuditRecordCall) Return(arg0 error) *MockUserRepositoryCreateAuditRecordCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateAuditRecordCall) Do(f func(context.Context, entity.AuditRecord) error) *MockUserRepositoryCreateAuditRecordCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateAuditRecordCall) DoAndReturn(f func(context.Context, entity.AuditRecord) error) *MockUserRepositoryCreateAuditRecordCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateHold mocks base method.
+func (m *MockUserRepository) CreateHold(ctx context.Context, hold entity.Hold) (entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, hold)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockUserRepositoryMockRecorder) CreateHold(ctx, hold any) *MockUserRepositoryCreateHoldCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockUserRepository)(nil).CreateHold), ctx, hold)
+	return &MockUserRepositoryCreateHoldCall{Call: call}
+}
+
+// MockUserRepositoryCreateHoldCall wrap *gomock.Call
+type MockUserRepositoryCreateHoldCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserRepositoryCreateHoldCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateHoldCall) Do(f func(context.Context, entity.Hold) (entity.Hold, error)) *MockUserRepositoryCreateHoldCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateHoldCall) DoAndReturn(f func(context.Context, entity.Hold) (entity.Hold, error)) *MockUserRepositoryCreateHoldCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateLedgerEntries mocks base method.
+func (m *MockUserRepository) CreateLedgerEntries(ctx context.Context, entries []entity.LedgerEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLedgerEntries", ctx, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLedgerEntries indicates an expected call of CreateLedgerEntries.
+func (mr *MockUserRepositoryMockRecorder) CreateLedgerEntries(ctx, entries any) *MockUserRepositoryCreateLedgerEntriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLedgerEntries", reflect.TypeOf((*MockUserRepository)(nil).CreateLedgerEntries), ctx, entries)
+	return &MockUserRepositoryCreateLedgerEntriesCall{Call: call}
+}
+
+// MockUserRepositoryCreateLedgerEntriesCall wrap *gomock.Call
+type MockUserRepositoryCreateLedgerEntriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateLedgerEntriesCall) Return(arg0 error) *MockUserRepositoryCreateLedgerEntriesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateLedgerEntriesCall) Do(f func(context.Context, []entity.LedgerEntry) error) *MockUserRepositoryCreateLedgerEntriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateLedgerEntriesCall) DoAndReturn(f func(context.Context, []entity.LedgerEntry) error) *MockUserRepositoryCreateLedgerEntriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockUserRepository) CreateOutboxEvent(ctx context.Context, event entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockUserRepositoryMockRecorder) CreateOutboxEvent(ctx, event any) *MockUserRepositoryCreateOutboxEventCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockUserRepository)(nil).CreateOutboxEvent), ctx, event)
+	return &MockUserRepositoryCreateOutboxEventCall{Call: call}
+}
+
+// MockUserRepositoryCreateOutboxEventCall wrap *gomock.Call
+type MockUserRepositoryCreateOutboxEventCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateOutboxEventCall) Return(arg0 error) *MockUserRepositoryCreateOutboxEventCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateOutboxEventCall) Do(f func(context.Context, entity.Event) error) *MockUserRepositoryCreateOutboxEventCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateOutboxEventCall) DoAndReturn(f func(context.Context, entity.Event) error) *MockUserRepositoryCreateOutboxEventCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateRiskDecision mocks base method.
+func (m *MockUserRepository) CreateRiskDecision(ctx context.Context, d entity.RiskDecision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRiskDecision", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRiskDecision indicates an expected call of CreateRiskDecision.
+func (mr *MockUserRepositoryMockRecorder) CreateRiskDecision(ctx, d any) *MockUserRepositoryCreateRiskDecisionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRiskDecision", reflect.TypeOf((*MockUserRepository)(nil).CreateRiskDecision), ctx, d)
+	return &MockUserRepositoryCreateRiskDecisionCall{Call: call}
+}
+
+// MockUserRepositoryCreateRiskDecisionCall wrap *gomock.Call
+type MockUserRepositoryCreateRiskDecisionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateRiskDecisionCall) Return(arg0 error) *MockUserRepositoryCreateRiskDecisionCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateRiskDecisionCall) Do(f func(context.Context, entity.RiskDecision) error) *MockUserRepositoryCreateRiskDecisionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateRiskDecisionCall) DoAndReturn(f func(context.Context, entity.RiskDecision) error) *MockUserRepositoryCreateRiskDecisionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateRiskReview mocks base method.
+func (m *MockUserRepository) CreateRiskReview(ctx context.Context, review entity.RiskReview) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRiskReview", ctx, review)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRiskReview indicates an expected call of CreateRiskReview.
+func (mr *MockUserRepositoryMockRecorder) CreateRiskReview(ctx, review any) *MockUserRepositoryCreateRiskReviewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRiskReview", reflect.TypeOf((*MockUserRepository)(nil).CreateRiskReview), ctx, review)
+	return &MockUserRepositoryCreateRiskReviewCall{Call: call}
+}
+
+// MockUserRepositoryCreateRiskReviewCall wrap *gomock.Call
+type MockUserRepositoryCreateRiskReviewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateRiskReviewCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserRepositoryCreateRiskReviewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateRiskReviewCall) Do(f func(context.Context, entity.RiskReview) (entity.RiskReview, error)) *MockUserRepositoryCreateRiskReviewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateRiskReviewCall) DoAndReturn(f func(context.Context, entity.RiskReview) (entity.RiskReview, error)) *MockUserRepositoryCreateRiskReviewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateSpend mocks base method.
+func (m *MockUserRepository) CreateSpend(ctx context.Context, spend entity.Spend) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSpend", ctx, spend)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSpend indicates an expected call of CreateSpend.
+func (mr *MockUserRepositoryMockRecorder) CreateSpend(ctx, spend any) *MockUserRepositoryCreateSpendCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSpend", reflect.TypeOf((*MockUserRepository)(nil).CreateSpend), ctx, spend)
+	return &MockUserRepositoryCreateSpendCall{Call: call}
+}
+
+// MockUserRepositoryCreateSpendCall wrap *gomock.Call
+type MockUserRepositoryCreateSpendCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateSpendCall) Return(arg0 error) *MockUserRepositoryCreateSpendCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateSpendCall) Do(f func(context.Context, entity.Spend) error) *MockUserRepositoryCreateSpendCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateSpendCall) DoAndReturn(f func(context.Context, entity.Spend) error) *MockUserRepositoryCreateSpendCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepository) CreateUser(ctx context.Context, user entity.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, user any) *MockUserRepositoryCreateUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, user)
+	return &MockUserRepositoryCreateUserCall{Call: call}
+}
+
+// MockUserRepositoryCreateUserCall wrap *gomock.Call
+type MockUserRepositoryCreateUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateUserCall) Return(arg0 error) *MockUserRepositoryCreateUserCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateUserCall) Do(f func(context.Context, entity.User) error) *MockUserRepositoryCreateUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateUserCall) DoAndReturn(f func(context.Context, entity.User) error) *MockUserRepositoryCreateUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateWebhook mocks base method.
+func (m *MockUserRepository) CreateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, sub)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockUserRepositoryMockRecorder) CreateWebhook(ctx, sub any) *MockUserRepositoryCreateWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockUserRepository)(nil).CreateWebhook), ctx, sub)
+	return &MockUserRepositoryCreateWebhookCall{Call: call}
+}
+
+// MockUserRepositoryCreateWebhookCall wrap *gomock.Call
+type MockUserRepositoryCreateWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateWebhookCall) Return(arg0 entity.WebhookSubscription, arg1 error) *MockUserRepositoryCreateWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateWebhookCall) Do(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserRepositoryCreateWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateWebhookCall) DoAndReturn(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserRepositoryCreateWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// CreateWebhookDeliveries mocks base method.
+func (m *MockUserRepository) CreateWebhookDeliveries(ctx context.Context, event entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockUserRepositoryMockRecorder) CreateWebhookDeliveries(ctx, event any) *MockUserRepositoryCreateWebhookDeliveriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockUserRepository)(nil).CreateWebhookDeliveries), ctx, event)
+	return &MockUserRepositoryCreateWebhookDeliveriesCall{Call: call}
+}
+
+// MockUserRepositoryCreateWebhookDeliveriesCall wrap *gomock.Call
+type MockUserRepositoryCreateWebhookDeliveriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryCreateWebhookDeliveriesCall) Return(arg0 error) *MockUserRepositoryCreateWebhookDeliveriesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryCreateWebhookDeliveriesCall) Do(f func(context.Context, entity.Event) error) *MockUserRepositoryCreateWebhookDeliveriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryCreateWebhookDeliveriesCall) DoAndReturn(f func(context.Context, entity.Event) error) *MockUserRepositoryCreateWebhookDeliveriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DecideRiskReview mocks base method.
+func (m *MockUserRepository) DecideRiskReview(ctx context.Context, review entity.RiskReview) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideRiskReview", ctx, review)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideRiskReview indicates an expected call of DecideRiskReview.
+func (mr *MockUserRepositoryMockRecorder) DecideRiskReview(ctx, review any) *MockUserRepositoryDecideRiskReviewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideRiskReview", reflect.TypeOf((*MockUserRepository)(nil).DecideRiskReview), ctx, review)
+	return &MockUserRepositoryDecideRiskReviewCall{Call: call}
+}
+
+// MockUserRepositoryDecideRiskReviewCall wrap *gomock.Call
+type MockUserRepositoryDecideRiskReviewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryDecideRiskReviewCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserRepositoryDecideRiskReviewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryDecideRiskReviewCall) Do(f func(context.Context, entity.RiskReview) (entity.RiskReview, error)) *MockUserRepositoryDecideRiskReviewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryDecideRiskReviewCall) DoAndReturn(f func(context.Context, entity.RiskReview) (entity.RiskReview, error)) *MockUserRepositoryDecideRiskReviewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepositoryMockRecorder) DeleteUser(ctx, id any) *MockUserRepositoryDeleteUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), ctx, id)
+	return &MockUserRepositoryDeleteUserCall{Call: call}
+}
+
+// MockUserRepositoryDeleteUserCall wrap *gomock.Call
+type MockUserRepositoryDeleteUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryDeleteUserCall) Return(arg0 error) *MockUserRepositoryDeleteUserCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryDeleteUserCall) Do(f func(context.Context, uuid.UUID) error) *MockUserRepositoryDeleteUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryDeleteUserCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockUserRepositoryDeleteUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockUserRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockUserRepositoryMockRecorder) DeleteWebhook(ctx, id any) *MockUserRepositoryDeleteWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockUserRepository)(nil).DeleteWebhook), ctx, id)
+	return &MockUserRepositoryDeleteWebhookCall{Call: call}
+}
+
+// MockUserRepositoryDeleteWebhookCall wrap *gomock.Call
+type MockUserRepositoryDeleteWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryDeleteWebhookCall) Return(arg0 error) *MockUserRepositoryDeleteWebhookCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryDeleteWebhookCall) Do(f func(context.Context, uuid.UUID) error) *MockUserRepositoryDeleteWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryDeleteWebhookCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockUserRepositoryDeleteWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockUserRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockUserRepositoryMockRecorder) GetAPIKeyByPrefix(ctx, prefix any) *MockUserRepositoryGetAPIKeyByPrefixCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockUserRepository)(nil).GetAPIKeyByPrefix), ctx, prefix)
+	return &MockUserRepositoryGetAPIKeyByPrefixCall{Call: call}
+}
+
+// MockUserRepositoryGetAPIKeyByPrefixCall wrap *gomock.Call
+type MockUserRepositoryGetAPIKeyByPrefixCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetAPIKeyByPrefixCall) Return(arg0 entity.APIKey, arg1 error) *MockUserRepositoryGetAPIKeyByPrefixCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetAPIKeyByPrefixCall) Do(f func(context.Context, string) (entity.APIKey, error)) *MockUserRepositoryGetAPIKeyByPrefixCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetAPIKeyByPrefixCall) DoAndReturn(f func(context.Context, string) (entity.APIKey, error)) *MockUserRepositoryGetAPIKeyByPrefixCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetExchangeRate mocks base method.
+func (m *MockUserRepository) GetExchangeRate(ctx context.Context, base, quote string, at time.Time) (entity.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRate", ctx, base, quote, at)
+	ret0, _ := ret[0].(entity.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRate indicates an expected call of GetExchangeRate.
+func (mr *MockUserRepositoryMockRecorder) GetExchangeRate(ctx, base, quote, at any) *MockUserRepositoryGetExchangeRateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRate", reflect.TypeOf((*MockUserRepository)(nil).GetExchangeRate), ctx, base, quote, at)
+	return &MockUserRepositoryGetExchangeRateCall{Call: call}
+}
+
+// MockUserRepositoryGetExchangeRateCall wrap *gomock.Call
+type MockUserRepositoryGetExchangeRateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetExchangeRateCall) Return(arg0 entity.ExchangeRate, arg1 error) *MockUserRepositoryGetExchangeRateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetExchangeRateCall) Do(f func(context.Context, string, string, time.Time) (entity.ExchangeRate, error)) *MockUserRepositoryGetExchangeRateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetExchangeRateCall) DoAndReturn(f func(context.Context, string, string, time.Time) (entity.ExchangeRate, error)) *MockUserRepositoryGetExchangeRateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetHold mocks base method.
+func (m *MockUserRepository) GetHold(ctx context.Context, id uuid.UUID) (entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, id)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockUserRepositoryMockRecorder) GetHold(ctx, id any) *MockUserRepositoryGetHoldCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockUserRepository)(nil).GetHold), ctx, id)
+	return &MockUserRepositoryGetHoldCall{Call: call}
+}
+
+// MockUserRepositoryGetHoldCall wrap *gomock.Call
+type MockUserRepositoryGetHoldCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserRepositoryGetHoldCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetHoldCall) Do(f func(context.Context, uuid.UUID) (entity.Hold, error)) *MockUserRepositoryGetHoldCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetHoldCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.Hold, error)) *MockUserRepositoryGetHoldCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetHoldForUpdate mocks base method.
+func (m *MockUserRepository) GetHoldForUpdate(ctx context.Context, id uuid.UUID) (entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldForUpdate", ctx, id)
+	ret0, _ := ret[0].(entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldForUpdate indicates an expected call of GetHoldForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetHoldForUpdate(ctx, id any) *MockUserRepositoryGetHoldForUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetHoldForUpdate), ctx, id)
+	return &MockUserRepositoryGetHoldForUpdateCall{Call: call}
+}
+
+// MockUserRepositoryGetHoldForUpdateCall wrap *gomock.Call
+type MockUserRepositoryGetHoldForUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetHoldForUpdateCall) Return(arg0 entity.Hold, arg1 error) *MockUserRepositoryGetHoldForUpdateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetHoldForUpdateCall) Do(f func(context.Context, uuid.UUID) (entity.Hold, error)) *MockUserRepositoryGetHoldForUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetHoldForUpdateCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.Hold, error)) *MockUserRepositoryGetHoldForUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetLimitTier mocks base method.
+func (m *MockUserRepository) GetLimitTier(ctx context.Context, name string) (entity.SpendingLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimitTier", ctx, name)
+	ret0, _ := ret[0].(entity.SpendingLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimitTier indicates an expected call of GetLimitTier.
+func (mr *MockUserRepositoryMockRecorder) GetLimitTier(ctx, name any) *MockUserRepositoryGetLimitTierCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimitTier", reflect.TypeOf((*MockUserRepository)(nil).GetLimitTier), ctx, name)
+	return &MockUserRepositoryGetLimitTierCall{Call: call}
+}
+
+// MockUserRepositoryGetLimitTierCall wrap *gomock.Call
+type MockUserRepositoryGetLimitTierCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetLimitTierCall) Return(arg0 entity.SpendingLimits, arg1 error) *MockUserRepositoryGetLimitTierCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetLimitTierCall) Do(f func(context.Context, string) (entity.SpendingLimits, error)) *MockUserRepositoryGetLimitTierCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetLimitTierCall) DoAndReturn(f func(context.Context, string) (entity.SpendingLimits, error)) *MockUserRepositoryGetLimitTierCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetRiskReview mocks base method.
+func (m *MockUserRepository) GetRiskReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskReview", ctx, id)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskReview indicates an expected call of GetRiskReview.
+func (mr *MockUserRepositoryMockRecorder) GetRiskReview(ctx, id any) *MockUserRepositoryGetRiskReviewCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskReview", reflect.TypeOf((*MockUserRepository)(nil).GetRiskReview), ctx, id)
+	return &MockUserRepositoryGetRiskReviewCall{Call: call}
+}
+
+// MockUserRepositoryGetRiskReviewCall wrap *gomock.Call
+type MockUserRepositoryGetRiskReviewCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetRiskReviewCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserRepositoryGetRiskReviewCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetRiskReviewCall) Do(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserRepositoryGetRiskReviewCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetRiskReviewCall) DoAndReturn(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserRepositoryGetRiskReviewCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetRiskReviewForUpdate mocks base method.
+func (m *MockUserRepository) GetRiskReviewForUpdate(ctx context.Context, id int64) (entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskReviewForUpdate", ctx, id)
+	ret0, _ := ret[0].(entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskReviewForUpdate indicates an expected call of GetRiskReviewForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetRiskReviewForUpdate(ctx, id any) *MockUserRepositoryGetRiskReviewForUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskReviewForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetRiskReviewForUpdate), ctx, id)
+	return &MockUserRepositoryGetRiskReviewForUpdateCall{Call: call}
+}
+
+// MockUserRepositoryGetRiskReviewForUpdateCall wrap *gomock.Call
+type MockUserRepositoryGetRiskReviewForUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetRiskReviewForUpdateCall) Return(arg0 entity.RiskReview, arg1 error) *MockUserRepositoryGetRiskReviewForUpdateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetRiskReviewForUpdateCall) Do(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserRepositoryGetRiskReviewForUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetRiskReviewForUpdateCall) DoAndReturn(f func(context.Context, int64) (entity.RiskReview, error)) *MockUserRepositoryGetRiskReviewForUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetSpending mocks base method.
+func (m *MockUserRepository) GetSpending(ctx context.Context, userID uuid.UUID, day, week, month time.Time) (entity.Spending, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSpending", ctx, userID, day, week, month)
+	ret0, _ := ret[0].(entity.Spending)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSpending indicates an expected call of GetSpending.
+func (mr *MockUserRepositoryMockRecorder) GetSpending(ctx, userID, day, week, month any) *MockUserRepositoryGetSpendingCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSpending", reflect.TypeOf((*MockUserRepository)(nil).GetSpending), ctx, userID, day, week, month)
+	return &MockUserRepositoryGetSpendingCall{Call: call}
+}
+
+// MockUserRepositoryGetSpendingCall wrap *gomock.Call
+type MockUserRepositoryGetSpendingCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetSpendingCall) Return(arg0 entity.Spending, arg1 error) *MockUserRepositoryGetSpendingCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetSpendingCall) Do(f func(context.Context, uuid.UUID, time.Time, time.Time, time.Time) (entity.Spending, error)) *MockUserRepositoryGetSpendingCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetSpendingCall) DoAndReturn(f func(context.Context, uuid.UUID, time.Time, time.Time, time.Time) (entity.Spending, error)) *MockUserRepositoryGetSpendingCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockUserRepositoryMockRecorder) GetUserByEmail(ctx, email any) *MockUserRepositoryGetUserByEmailCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserRepository)(nil).GetUserByEmail), ctx, email)
+	return &MockUserRepositoryGetUserByEmailCall{Call: call}
+}
+
+// MockUserRepositoryGetUserByEmailCall wrap *gomock.Call
+type MockUserRepositoryGetUserByEmailCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUserByEmailCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryGetUserByEmailCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUserByEmailCall) Do(f func(context.Context, string) (entity.User, error)) *MockUserRepositoryGetUserByEmailCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUserByEmailCall) DoAndReturn(f func(context.Context, string) (entity.User, error)) *MockUserRepositoryGetUserByEmailCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, id any) *MockUserRepositoryGetUserByIDCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, id)
+	return &MockUserRepositoryGetUserByIDCall{Call: call}
+}
+
+// MockUserRepositoryGetUserByIDCall wrap *gomock.Call
+type MockUserRepositoryGetUserByIDCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUserByIDCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryGetUserByIDCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUserByIDCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserByIDCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUserByIDCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserByIDCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserByIDWithDeleted mocks base method.
+func (m *MockUserRepository) GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByIDWithDeleted", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByIDWithDeleted indicates an expected call of GetUserByIDWithDeleted.
+func (mr *MockUserRepositoryMockRecorder) GetUserByIDWithDeleted(ctx, id any) *MockUserRepositoryGetUserByIDWithDeletedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIDWithDeleted", reflect.TypeOf((*MockUserRepository)(nil).GetUserByIDWithDeleted), ctx, id)
+	return &MockUserRepositoryGetUserByIDWithDeletedCall{Call: call}
+}
+
+// MockUserRepositoryGetUserByIDWithDeletedCall wrap *gomock.Call
+type MockUserRepositoryGetUserByIDWithDeletedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUserByIDWithDeletedCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryGetUserByIDWithDeletedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUserByIDWithDeletedCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserByIDWithDeletedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUserByIDWithDeletedCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserByIDWithDeletedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserCreatedAt mocks base method.
+func (m *MockUserRepository) GetUserCreatedAt(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserCreatedAt", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserCreatedAt indicates an expected call of GetUserCreatedAt.
+func (mr *MockUserRepositoryMockRecorder) GetUserCreatedAt(ctx, userID any) *MockUserRepositoryGetUserCreatedAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCreatedAt", reflect.TypeOf((*MockUserRepository)(nil).GetUserCreatedAt), ctx, userID)
+	return &MockUserRepositoryGetUserCreatedAtCall{Call: call}
+}
+
+// MockUserRepositoryGetUserCreatedAtCall wrap *gomock.Call
+type MockUserRepositoryGetUserCreatedAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUserCreatedAtCall) Return(arg0 time.Time, arg1 error) *MockUserRepositoryGetUserCreatedAtCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUserCreatedAtCall) Do(f func(context.Context, uuid.UUID) (time.Time, error)) *MockUserRepositoryGetUserCreatedAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUserCreatedAtCall) DoAndReturn(f func(context.Context, uuid.UUID) (time.Time, error)) *MockUserRepositoryGetUserCreatedAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserForUpdate mocks base method.
+func (m *MockUserRepository) GetUserForUpdate(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserForUpdate", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserForUpdate indicates an expected call of GetUserForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetUserForUpdate(ctx, id any) *MockUserRepositoryGetUserForUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetUserForUpdate), ctx, id)
+	return &MockUserRepositoryGetUserForUpdateCall{Call: call}
+}
+
+// MockUserRepositoryGetUserForUpdateCall wrap *gomock.Call
+type MockUserRepositoryGetUserForUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUserForUpdateCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryGetUserForUpdateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUserForUpdateCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserForUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUserForUpdateCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserForUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUserLimits mocks base method.
+func (m *MockUserRepository) GetUserLimits(ctx context.Context, userID uuid.UUID) (entity.UserLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLimits", ctx, userID)
+	ret0, _ := ret[0].(entity.UserLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLimits indicates an expected call of GetUserLimits.
+func (mr *MockUserRepositoryMockRecorder) GetUserLimits(ctx, userID any) *MockUserRepositoryGetUserLimitsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLimits", reflect.TypeOf((*MockUserRepository)(nil).GetUserLimits), ctx, userID)
+	return &MockUserRepositoryGetUserLimitsCall{Call: call}
+}
+
+// MockUserRepositoryGetUserLimitsCall wrap *gomock.Call
+type MockUserRepositoryGetUserLimitsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUserLimitsCall) Return(arg0 entity.UserLimits, arg1 error) *MockUserRepositoryGetUserLimitsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUserLimitsCall) Do(f func(context.Context, uuid.UUID) (entity.UserLimits, error)) *MockUserRepositoryGetUserLimitsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUserLimitsCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.UserLimits, error)) *MockUserRepositoryGetUserLimitsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetUsersByIDs mocks base method.
+func (m *MockUserRepository) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockUserRepositoryMockRecorder) GetUsersByIDs(ctx, ids any) *MockUserRepositoryGetUsersByIDsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockUserRepository)(nil).GetUsersByIDs), ctx, ids)
+	return &MockUserRepositoryGetUsersByIDsCall{Call: call}
+}
+
+// MockUserRepositoryGetUsersByIDsCall wrap *gomock.Call
+type MockUserRepositoryGetUsersByIDsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetUsersByIDsCall) Return(arg0 []entity.User, arg1 error) *MockUserRepositoryGetUsersByIDsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetUsersByIDsCall) Do(f func(context.Context, []uuid.UUID) ([]entity.User, error)) *MockUserRepositoryGetUsersByIDsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetUsersByIDsCall) DoAndReturn(f func(context.Context, []uuid.UUID) ([]entity.User, error)) *MockUserRepositoryGetUsersByIDsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWalletForUpdate mocks base method.
+func (m *MockUserRepository) GetWalletForUpdate(ctx context.Context, userID uuid.UUID, currency string) (entity.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletForUpdate", ctx, userID, currency)
+	ret0, _ := ret[0].(entity.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletForUpdate indicates an expected call of GetWalletForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetWalletForUpdate(ctx, userID, currency any) *MockUserRepositoryGetWalletForUpdateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetWalletForUpdate), ctx, userID, currency)
+	return &MockUserRepositoryGetWalletForUpdateCall{Call: call}
+}
+
+// MockUserRepositoryGetWalletForUpdateCall wrap *gomock.Call
+type MockUserRepositoryGetWalletForUpdateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetWalletForUpdateCall) Return(arg0 entity.Wallet, arg1 error) *MockUserRepositoryGetWalletForUpdateCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetWalletForUpdateCall) Do(f func(context.Context, uuid.UUID, string) (entity.Wallet, error)) *MockUserRepositoryGetWalletForUpdateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetWalletForUpdateCall) DoAndReturn(f func(context.Context, uuid.UUID, string) (entity.Wallet, error)) *MockUserRepositoryGetWalletForUpdateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWebhook mocks base method.
+func (m *MockUserRepository) GetWebhook(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockUserRepositoryMockRecorder) GetWebhook(ctx, id any) *MockUserRepositoryGetWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockUserRepository)(nil).GetWebhook), ctx, id)
+	return &MockUserRepositoryGetWebhookCall{Call: call}
+}
+
+// MockUserRepositoryGetWebhookCall wrap *gomock.Call
+type MockUserRepositoryGetWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetWebhookCall) Return(arg0 entity.WebhookSubscription, arg1 error) *MockUserRepositoryGetWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetWebhookCall) Do(f func(context.Context, uuid.UUID) (entity.WebhookSubscription, error)) *MockUserRepositoryGetWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetWebhookCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.WebhookSubscription, error)) *MockUserRepositoryGetWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetWebhookDelivery mocks base method.
+func (m *MockUserRepository) GetWebhookDelivery(ctx context.Context, subscriptionID uuid.UUID, id int64) (entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", ctx, subscriptionID, id)
+	ret0, _ := ret[0].(entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockUserRepositoryMockRecorder) GetWebhookDelivery(ctx, subscriptionID, id any) *MockUserRepositoryGetWebhookDeliveryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockUserRepository)(nil).GetWebhookDelivery), ctx, subscriptionID, id)
+	return &MockUserRepositoryGetWebhookDeliveryCall{Call: call}
+}
+
+// MockUserRepositoryGetWebhookDeliveryCall wrap *gomock.Call
+type MockUserRepositoryGetWebhookDeliveryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryGetWebhookDeliveryCall) Return(arg0 entity.WebhookDelivery, arg1 error) *MockUserRepositoryGetWebhookDeliveryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryGetWebhookDeliveryCall) Do(f func(context.Context, uuid.UUID, int64) (entity.WebhookDelivery, error)) *MockUserRepositoryGetWebhookDeliveryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryGetWebhookDeliveryCall) DoAndReturn(f func(context.Context, uuid.UUID, int64) (entity.WebhookDelivery, error)) *MockUserRepositoryGetWebhookDeliveryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockUserRepository) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockUserRepositoryMockRecorder) ListAPIKeys(ctx any) *MockUserRepositoryListAPIKeysCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockUserRepository)(nil).ListAPIKeys), ctx)
+	return &MockUserRepositoryListAPIKeysCall{Call: call}
+}
+
+// MockUserRepositoryListAPIKeysCall wrap *gomock.Call
+type MockUserRepositoryListAPIKeysCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListAPIKeysCall) Return(arg0 []entity.APIKey, arg1 error) *MockUserRepositoryListAPIKeysCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListAPIKeysCall) Do(f func(context.Context) ([]entity.APIKey, error)) *MockUserRepositoryListAPIKeysCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListAPIKeysCall) DoAndReturn(f func(context.Context) ([]entity.APIKey, error)) *MockUserRepositoryListAPIKeysCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListActiveHolds mocks base method.
+func (m *MockUserRepository) ListActiveHolds(ctx context.Context, userID uuid.UUID) ([]entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveHolds", ctx, userID)
+	ret0, _ := ret[0].([]entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveHolds indicates an expected call of ListActiveHolds.
+func (mr *MockUserRepositoryMockRecorder) ListActiveHolds(ctx, userID any) *MockUserRepositoryListActiveHoldsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveHolds", reflect.TypeOf((*MockUserRepository)(nil).ListActiveHolds), ctx, userID)
+	return &MockUserRepositoryListActiveHoldsCall{Call: call}
+}
+
+// MockUserRepositoryListActiveHoldsCall wrap *gomock.Call
+type MockUserRepositoryListActiveHoldsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListActiveHoldsCall) Return(arg0 []entity.Hold, arg1 error) *MockUserRepositoryListActiveHoldsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListActiveHoldsCall) Do(f func(context.Context, uuid.UUID) ([]entity.Hold, error)) *MockUserRepositoryListActiveHoldsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListActiveHoldsCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]entity.Hold, error)) *MockUserRepositoryListActiveHoldsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListAuditRecords mocks base method.
+func (m *MockUserRepository) ListAuditRecords(ctx context.Context, userID uuid.UUID, beforeID int64, limit int) ([]entity.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditRecords", ctx, userID, beforeID, limit)
+	ret0, _ := ret[0].([]entity.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditRecords indicates an expected call of ListAuditRecords.
+func (mr *MockUserRepositoryMockRecorder) ListAuditRecords(ctx, userID, beforeID, limit any) *MockUserRepositoryListAuditRecordsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditRecords", reflect.TypeOf((*MockUserRepository)(nil).ListAuditRecords), ctx, userID, beforeID, limit)
+	return &MockUserRepositoryListAuditRecordsCall{Call: call}
+}
+
+// MockUserRepositoryListAuditRecordsCall wrap *gomock.Call
+type MockUserRepositoryListAuditRecordsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListAuditRecordsCall) Return(arg0 []entity.AuditRecord, arg1 error) *MockUserRepositoryListAuditRecordsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListAuditRecordsCall) Do(f func(context.Context, uuid.UUID, int64, int) ([]entity.AuditRecord, error)) *MockUserRepositoryListAuditRecordsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListAuditRecordsCall) DoAndReturn(f func(context.Context, uuid.UUID, int64, int) ([]entity.AuditRecord, error)) *MockUserRepositoryListAuditRecordsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListExpiredHolds mocks base method.
+func (m *MockUserRepository) ListExpiredHolds(ctx context.Context, limit int) ([]entity.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredHolds", ctx, limit)
+	ret0, _ := ret[0].([]entity.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredHolds indicates an expected call of ListExpiredHolds.
+func (mr *MockUserRepositoryMockRecorder) ListExpiredHolds(ctx, limit any) *MockUserRepositoryListExpiredHoldsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredHolds", reflect.TypeOf((*MockUserRepository)(nil).ListExpiredHolds), ctx, limit)
+	return &MockUserRepositoryListExpiredHoldsCall{Call: call}
+}
+
+// MockUserRepositoryListExpiredHoldsCall wrap *gomock.Call
+type MockUserRepositoryListExpiredHoldsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListExpiredHoldsCall) Return(arg0 []entity.Hold, arg1 error) *MockUserRepositoryListExpiredHoldsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListExpiredHoldsCall) Do(f func(context.Context, int) ([]entity.Hold, error)) *MockUserRepositoryListExpiredHoldsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListExpiredHoldsCall) DoAndReturn(f func(context.Context, int) ([]entity.Hold, error)) *MockUserRepositoryListExpiredHoldsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListRiskCounterparties mocks base method.
+func (m *MockUserRepository) ListRiskCounterparties(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskCounterparties", ctx, userID, since)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskCounterparties indicates an expected call of ListRiskCounterparties.
+func (mr *MockUserRepositoryMockRecorder) ListRiskCounterparties(ctx, userID, since any) *MockUserRepositoryListRiskCounterpartiesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskCounterparties", reflect.TypeOf((*MockUserRepository)(nil).ListRiskCounterparties), ctx, userID, since)
+	return &MockUserRepositoryListRiskCounterpartiesCall{Call: call}
+}
+
+// MockUserRepositoryListRiskCounterpartiesCall wrap *gomock.Call
+type MockUserRepositoryListRiskCounterpartiesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListRiskCounterpartiesCall) Return(arg0 []uuid.UUID, arg1 error) *MockUserRepositoryListRiskCounterpartiesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListRiskCounterpartiesCall) Do(f func(context.Context, uuid.UUID, time.Time) ([]uuid.UUID, error)) *MockUserRepositoryListRiskCounterpartiesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListRiskCounterpartiesCall) DoAndReturn(f func(context.Context, uuid.UUID, time.Time) ([]uuid.UUID, error)) *MockUserRepositoryListRiskCounterpartiesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListRiskReviews mocks base method.
+func (m *MockUserRepository) ListRiskReviews(ctx context.Context, status entity.ReviewStatus, beforeID int64, limit int) ([]entity.RiskReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskReviews", ctx, status, beforeID, limit)
+	ret0, _ := ret[0].([]entity.RiskReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskReviews indicates an expected call of ListRiskReviews.
+func (mr *MockUserRepositoryMockRecorder) ListRiskReviews(ctx, status, beforeID, limit any) *MockUserRepositoryListRiskReviewsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskReviews", reflect.TypeOf((*MockUserRepository)(nil).ListRiskReviews), ctx, status, beforeID, limit)
+	return &MockUserRepositoryListRiskReviewsCall{Call: call}
+}
+
+// MockUserRepositoryListRiskReviewsCall wrap *gomock.Call
+type MockUserRepositoryListRiskReviewsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListRiskReviewsCall) Return(arg0 []entity.RiskReview, arg1 error) *MockUserRepositoryListRiskReviewsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListRiskReviewsCall) Do(f func(context.Context, entity.ReviewStatus, int64, int) ([]entity.RiskReview, error)) *MockUserRepositoryListRiskReviewsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListRiskReviewsCall) DoAndReturn(f func(context.Context, entity.ReviewStatus, int64, int) ([]entity.RiskReview, error)) *MockUserRepositoryListRiskReviewsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListUsers mocks base method.
+func (m *MockUserRepository) ListUsers(ctx context.Context, q entity.UserQuery) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, q)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserRepositoryMockRecorder) ListUsers(ctx, q any) *MockUserRepositoryListUsersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRepository)(nil).ListUsers), ctx, q)
+	return &MockUserRepositoryListUsersCall{Call: call}
+}
+
+// MockUserRepositoryListUsersCall wrap *gomock.Call
+type MockUserRepositoryListUsersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListUsersCall) Return(arg0 []entity.User, arg1 error) *MockUserRepositoryListUsersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListUsersCall) Do(f func(context.Context, entity.UserQuery) ([]entity.User, error)) *MockUserRepositoryListUsersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListUsersCall) DoAndReturn(f func(context.Context, entity.UserQuery) ([]entity.User, error)) *MockUserRepositoryListUsersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListWallets mocks base method.
+func (m *MockUserRepository) ListWallets(ctx context.Context, userID uuid.UUID) ([]entity.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", ctx, userID)
+	ret0, _ := ret[0].([]entity.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockUserRepositoryMockRecorder) ListWallets(ctx, userID any) *MockUserRepositoryListWalletsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockUserRepository)(nil).ListWallets), ctx, userID)
+	return &MockUserRepositoryListWalletsCall{Call: call}
+}
+
+// MockUserRepositoryListWalletsCall wrap *gomock.Call
+type MockUserRepositoryListWalletsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListWalletsCall) Return(arg0 []entity.Wallet, arg1 error) *MockUserRepositoryListWalletsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListWalletsCall) Do(f func(context.Context, uuid.UUID) ([]entity.Wallet, error)) *MockUserRepositoryListWalletsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListWalletsCall) DoAndReturn(f func(context.Context, uuid.UUID) ([]entity.Wallet, error)) *MockUserRepositoryListWalletsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockUserRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, beforeID int64, limit int) ([]entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, subscriptionID, beforeID, limit)
+	ret0, _ := ret[0].([]entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockUserRepositoryMockRecorder) ListWebhookDeliveries(ctx, subscriptionID, beforeID, limit any) *MockUserRepositoryListWebhookDeliveriesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockUserRepository)(nil).ListWebhookDeliveries), ctx, subscriptionID, beforeID, limit)
+	return &MockUserRepositoryListWebhookDeliveriesCall{Call: call}
+}
+
+// MockUserRepositoryListWebhookDeliveriesCall wrap *gomock.Call
+type MockUserRepositoryListWebhookDeliveriesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListWebhookDeliveriesCall) Return(arg0 []entity.WebhookDelivery, arg1 error) *MockUserRepositoryListWebhookDeliveriesCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListWebhookDeliveriesCall) Do(f func(context.Context, uuid.UUID, int64, int) ([]entity.WebhookDelivery, error)) *MockUserRepositoryListWebhookDeliveriesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListWebhookDeliveriesCall) DoAndReturn(f func(context.Context, uuid.UUID, int64, int) ([]entity.WebhookDelivery, error)) *MockUserRepositoryListWebhookDeliveriesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListWebhooks mocks base method.
+func (m *MockUserRepository) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx)
+	ret0, _ := ret[0].([]entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockUserRepositoryMockRecorder) ListWebhooks(ctx any) *MockUserRepositoryListWebhooksCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockUserRepository)(nil).ListWebhooks), ctx)
+	return &MockUserRepositoryListWebhooksCall{Call: call}
+}
+
+// MockUserRepositoryListWebhooksCall wrap *gomock.Call
+type MockUserRepositoryListWebhooksCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryListWebhooksCall) Return(arg0 []entity.WebhookSubscription, arg1 error) *MockUserRepositoryListWebhooksCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryListWebhooksCall) Do(f func(context.Context) ([]entity.WebhookSubscription, error)) *MockUserRepositoryListWebhooksCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryListWebhooksCall) DoAndReturn(f func(context.Context) ([]entity.WebhookSubscription, error)) *MockUserRepositoryListWebhooksCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MarkOutboxEventsPublished mocks base method.
+func (m *MockUserRepository) MarkOutboxEventsPublished(ctx context.Context, ids []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventsPublished", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventsPublished indicates an expected call of MarkOutboxEventsPublished.
+func (mr *MockUserRepositoryMockRecorder) MarkOutboxEventsPublished(ctx, ids any) *MockUserRepositoryMarkOutboxEventsPublishedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventsPublished", reflect.TypeOf((*MockUserRepository)(nil).MarkOutboxEventsPublished), ctx, ids)
+	return &MockUserRepositoryMarkOutboxEventsPublishedCall{Call: call}
+}
+
+// MockUserRepositoryMarkOutboxEventsPublishedCall wrap *gomock.Call
+type MockUserRepositoryMarkOutboxEventsPublishedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryMarkOutboxEventsPublishedCall) Return(arg0 error) *MockUserRepositoryMarkOutboxEventsPublishedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryMarkOutboxEventsPublishedCall) Do(f func(context.Context, []uuid.UUID) error) *MockUserRepositoryMarkOutboxEventsPublishedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryMarkOutboxEventsPublishedCall) DoAndReturn(f func(context.Context, []uuid.UUID) error) *MockUserRepositoryMarkOutboxEventsPublishedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockUserRepository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockUserRepositoryMockRecorder) PurgeDeletedUsers(ctx, deletedBefore any) *MockUserRepositoryPurgeDeletedUsersCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockUserRepository)(nil).PurgeDeletedUsers), ctx, deletedBefore)
+	return &MockUserRepositoryPurgeDeletedUsersCall{Call: call}
+}
+
+// MockUserRepositoryPurgeDeletedUsersCall wrap *gomock.Call
+type MockUserRepositoryPurgeDeletedUsersCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryPurgeDeletedUsersCall) Return(arg0 int64, arg1 error) *MockUserRepositoryPurgeDeletedUsersCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryPurgeDeletedUsersCall) Do(f func(context.Context, time.Time) (int64, error)) *MockUserRepositoryPurgeDeletedUsersCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryPurgeDeletedUsersCall) DoAndReturn(f func(context.Context, time.Time) (int64, error)) *MockUserRepositoryPurgeDeletedUsersCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RecordOutboxFailure mocks base method.
+func (m *MockUserRepository) RecordOutboxFailure(ctx context.Context, entry entity.OutboxEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOutboxFailure", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordOutboxFailure indicates an expected call of RecordOutboxFailure.
+func (mr *MockUserRepositoryMockRecorder) RecordOutboxFailure(ctx, entry any) *MockUserRepositoryRecordOutboxFailureCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOutboxFailure", reflect.TypeOf((*MockUserRepository)(nil).RecordOutboxFailure), ctx, entry)
+	return &MockUserRepositoryRecordOutboxFailureCall{Call: call}
+}
+
+// MockUserRepositoryRecordOutboxFailureCall wrap *gomock.Call
+type MockUserRepositoryRecordOutboxFailureCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryRecordOutboxFailureCall) Return(arg0 error) *MockUserRepositoryRecordOutboxFailureCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryRecordOutboxFailureCall) Do(f func(context.Context, entity.OutboxEntry) error) *MockUserRepositoryRecordOutboxFailureCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryRecordOutboxFailureCall) DoAndReturn(f func(context.Context, entity.OutboxEntry) error) *MockUserRepositoryRecordOutboxFailureCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReleaseOutboxEvents mocks base method.
+func (m *MockUserRepository) ReleaseOutboxEvents(ctx context.Context, ids []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseOutboxEvents", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseOutboxEvents indicates an expected call of ReleaseOutboxEvents.
+func (mr *MockUserRepositoryMockRecorder) ReleaseOutboxEvents(ctx, ids any) *MockUserRepositoryReleaseOutboxEventsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseOutboxEvents", reflect.TypeOf((*MockUserRepository)(nil).ReleaseOutboxEvents), ctx, ids)
+	return &MockUserRepositoryReleaseOutboxEventsCall{Call: call}
+}
+
+// MockUserRepositoryReleaseOutboxEventsCall wrap *gomock.Call
+type MockUserRepositoryReleaseOutboxEventsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryReleaseOutboxEventsCall) Return(arg0 error) *MockUserRepositoryReleaseOutboxEventsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryReleaseOutboxEventsCall) Do(f func(context.Context, []uuid.UUID) error) *MockUserRepositoryReleaseOutboxEventsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryReleaseOutboxEventsCall) DoAndReturn(f func(context.Context, []uuid.UUID) error) *MockUserRepositoryReleaseOutboxEventsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RestoreUser mocks base method.
+func (m *MockUserRepository) RestoreUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, id)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockUserRepositoryMockRecorder) RestoreUser(ctx, id any) *MockUserRepositoryRestoreUserCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockUserRepository)(nil).RestoreUser), ctx, id)
+	return &MockUserRepositoryRestoreUserCall{Call: call}
+}
+
+// MockUserRepositoryRestoreUserCall wrap *gomock.Call
+type MockUserRepositoryRestoreUserCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryRestoreUserCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryRestoreUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryRestoreUserCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryRestoreUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryRestoreUserCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryRestoreUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockUserRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockUserRepositoryMockRecorder) RevokeAPIKey(ctx, id any) *MockUserRepositoryRevokeAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockUserRepository)(nil).RevokeAPIKey), ctx, id)
+	return &MockUserRepositoryRevokeAPIKeyCall{Call: call}
+}
+
+// MockUserRepositoryRevokeAPIKeyCall wrap *gomock.Call
+type MockUserRepositoryRevokeAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryRevokeAPIKeyCall) Return(arg0 entity.APIKey, arg1 error) *MockUserRepositoryRevokeAPIKeyCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryRevokeAPIKeyCall) Do(f func(context.Context, uuid.UUID) (entity.APIKey, error)) *MockUserRepositoryRevokeAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryRevokeAPIKeyCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.APIKey, error)) *MockUserRepositoryRevokeAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SaveExchangeRates mocks base method.
+func (m *MockUserRepository) SaveExchangeRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveExchangeRates", ctx, rates)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockUserRepositoryMockRecorder) CreateUser(ctx, user any) *MockUserRepositoryCreateUserCall {
+// SaveExchangeRates indicates an expected call of SaveExchangeRates.
+func (mr *MockUserRepositoryMockRecorder) SaveExchangeRates(ctx, rates any) *MockUserRepositorySaveExchangeRatesCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), ctx, user)
-	return &MockUserRepositoryCreateUserCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveExchangeRates", reflect.TypeOf((*MockUserRepository)(nil).SaveExchangeRates), ctx, rates)
+	return &MockUserRepositorySaveExchangeRatesCall{Call: call}
 }
 
-// MockUserRepositoryCreateUserCall wrap *gomock.Call
-type MockUserRepositoryCreateUserCall struct {
+// MockUserRepositorySaveExchangeRatesCall wrap *gomock.Call
+type MockUserRepositorySaveExchangeRatesCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockUserRepositoryCreateUserCall) Return(arg0 error) *MockUserRepositoryCreateUserCall {
+func (c *MockUserRepositorySaveExchangeRatesCall) Return(arg0 error) *MockUserRepositorySaveExchangeRatesCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockUserRepositoryCreateUserCall) Do(f func(context.Context, entity.User) error) *MockUserRepositoryCreateUserCall {
+func (c *MockUserRepositorySaveExchangeRatesCall) Do(f func(context.Context, []entity.ExchangeRate) error) *MockUserRepositorySaveExchangeRatesCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockUserRepositoryCreateUserCall) DoAndReturn(f func(context.Context, entity.User) error) *MockUserRepositoryCreateUserCall {
+func (c *MockUserRepositorySaveExchangeRatesCall) DoAndReturn(f func(context.Context, []entity.ExchangeRate) error) *MockUserRepositorySaveExchangeRatesCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// DeleteUser mocks base method.
-func (m *MockUserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+// SaveUserLimits mocks base method.
+func (m *MockUserRepository) SaveUserLimits(ctx context.Context, limits entity.UserLimits) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret := m.ctrl.Call(m, "SaveUserLimits", ctx, limits)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockUserRepositoryMockRecorder) DeleteUser(ctx, id any) *MockUserRepositoryDeleteUserCall {
+// SaveUserLimits indicates an expected call of SaveUserLimits.
+func (mr *MockUserRepositoryMockRecorder) SaveUserLimits(ctx, limits any) *MockUserRepositorySaveUserLimitsCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), ctx, id)
-	return &MockUserRepositoryDeleteUserCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUserLimits", reflect.TypeOf((*MockUserRepository)(nil).SaveUserLimits), ctx, limits)
+	return &MockUserRepositorySaveUserLimitsCall{Call: call}
 }
 
-// MockUserRepositoryDeleteUserCall wrap *gomock.Call
-type MockUserRepositoryDeleteUserCall struct {
+// MockUserRepositorySaveUserLimitsCall wrap *gomock.Call
+type MockUserRepositorySaveUserLimitsCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockUserRepositoryDeleteUserCall) Return(arg0 error) *MockUserRepositoryDeleteUserCall {
+func (c *MockUserRepositorySaveUserLimitsCall) Return(arg0 error) *MockUserRepositorySaveUserLimitsCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockUserRepositoryDeleteUserCall) Do(f func(context.Context, uuid.UUID) error) *MockUserRepositoryDeleteUserCall {
+func (c *MockUserRepositorySaveUserLimitsCall) Do(f func(context.Context, entity.UserLimits) error) *MockUserRepositorySaveUserLimitsCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockUserRepositoryDeleteUserCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockUserRepositoryDeleteUserCall {
+func (c *MockUserRepositorySaveUserLimitsCall) DoAndReturn(f func(context.Context, entity.UserLimits) error) *MockUserRepositorySaveUserLimitsCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// GetUserByID mocks base method.
-func (m *MockUserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+// SettleHold mocks base method.
+func (m *MockUserRepository) SettleHold(ctx context.Context, hold entity.Hold) (entity.Hold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
-	ret0, _ := ret[0].(entity.User)
+	ret := m.ctrl.Call(m, "SettleHold", ctx, hold)
+	ret0, _ := ret[0].(entity.Hold)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, id any) *MockUserRepositoryGetUserByIDCall {
+// SettleHold indicates an expected call of SettleHold.
+func (mr *MockUserRepositoryMockRecorder) SettleHold(ctx, hold any) *MockUserRepositorySettleHoldCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, id)
-	return &MockUserRepositoryGetUserByIDCall{Call: call}
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleHold", reflect.TypeOf((*MockUserRepository)(nil).SettleHold), ctx, hold)
+	return &MockUserRepositorySettleHoldCall{Call: call}
 }
 
-// MockUserRepositoryGetUserByIDCall wrap *gomock.Call
-type MockUserRepositoryGetUserByIDCall struct {
+// MockUserRepositorySettleHoldCall wrap *gomock.Call
+type MockUserRepositorySettleHoldCall struct {
 	*gomock.Call
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockUserRepositoryGetUserByIDCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryGetUserByIDCall {
+func (c *MockUserRepositorySettleHoldCall) Return(arg0 entity.Hold, arg1 error) *MockUserRepositorySettleHoldCall {
 	c.Call = c.Call.Return(arg0, arg1)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockUserRepositoryGetUserByIDCall) Do(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserByIDCall {
+func (c *MockUserRepositorySettleHoldCall) Do(f func(context.Context, entity.Hold) (entity.Hold, error)) *MockUserRepositorySettleHoldCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockUserRepositoryGetUserByIDCall) DoAndReturn(f func(context.Context, uuid.UUID) (entity.User, error)) *MockUserRepositoryGetUserByIDCall {
+func (c *MockUserRepositorySettleHoldCall) DoAndReturn(f func(context.Context, entity.Hold) (entity.Hold, error)) *MockUserRepositorySettleHoldCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
-// UpdateUser mocks base method.
-func (m *MockUserRepository) UpdateUser(ctx context.Context, user entity.User) error {
+// TouchAPIKey mocks base method.
+func (m *MockUserRepository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", ctx, user)
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockUserRepositoryMockRecorder) TouchAPIKey(ctx, id any) *MockUserRepositoryTouchAPIKeyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockUserRepository)(nil).TouchAPIKey), ctx, id)
+	return &MockUserRepositoryTouchAPIKeyCall{Call: call}
+}
+
+// MockUserRepositoryTouchAPIKeyCall wrap *gomock.Call
+type MockUserRepositoryTouchAPIKeyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryTouchAPIKeyCall) Return(arg0 error) *MockUserRepositoryTouchAPIKeyCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryTouchAPIKeyCall) Do(f func(context.Context, uuid.UUID) error) *MockUserRepositoryTouchAPIKeyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryTouchAPIKeyCall) DoAndReturn(f func(context.Context, uuid.UUID) error) *MockUserRepositoryTouchAPIKeyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateBalance mocks base method.
+func (m *MockUserRepository) UpdateBalance(ctx context.Context, id uuid.UUID, balance decimal.Decimal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalance", ctx, id, balance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBalance indicates an expected call of UpdateBalance.
+func (mr *MockUserRepositoryMockRecorder) UpdateBalance(ctx, id, balance any) *MockUserRepositoryUpdateBalanceCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockUserRepository)(nil).UpdateBalance), ctx, id, balance)
+	return &MockUserRepositoryUpdateBalanceCall{Call: call}
+}
+
+// MockUserRepositoryUpdateBalanceCall wrap *gomock.Call
+type MockUserRepositoryUpdateBalanceCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryUpdateBalanceCall) Return(arg0 error) *MockUserRepositoryUpdateBalanceCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryUpdateBalanceCall) Do(f func(context.Context, uuid.UUID, decimal.Decimal) error) *MockUserRepositoryUpdateBalanceCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryUpdateBalanceCall) DoAndReturn(f func(context.Context, uuid.UUID, decimal.Decimal) error) *MockUserRepositoryUpdateBalanceCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateBalances mocks base method.
+func (m *MockUserRepository) UpdateBalances(ctx context.Context, id uuid.UUID, balance, held decimal.Decimal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalances", ctx, id, balance, held)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// UpdateBalances indicates an expected call of UpdateBalances.
+func (mr *MockUserRepositoryMockRecorder) UpdateBalances(ctx, id, balance, held any) *MockUserRepositoryUpdateBalancesCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalances", reflect.TypeOf((*MockUserRepository)(nil).UpdateBalances), ctx, id, balance, held)
+	return &MockUserRepositoryUpdateBalancesCall{Call: call}
+}
+
+// MockUserRepositoryUpdateBalancesCall wrap *gomock.Call
+type MockUserRepositoryUpdateBalancesCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryUpdateBalancesCall) Return(arg0 error) *MockUserRepositoryUpdateBalancesCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryUpdateBalancesCall) Do(f func(context.Context, uuid.UUID, decimal.Decimal, decimal.Decimal) error) *MockUserRepositoryUpdateBalancesCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryUpdateBalancesCall) DoAndReturn(f func(context.Context, uuid.UUID, decimal.Decimal, decimal.Decimal) error) *MockUserRepositoryUpdateBalancesCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateUser mocks base method.
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, user)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateUser indicates an expected call of UpdateUser.
 func (mr *MockUserRepositoryMockRecorder) UpdateUser(ctx, user any) *MockUserRepositoryUpdateUserCall {
 	mr.mock.ctrl.T.Helper()
@@ -178,19 +2272,173 @@ type MockUserRepositoryUpdateUserCall struct {
 }
 
 // Return rewrite *gomock.Call.Return
-func (c *MockUserRepositoryUpdateUserCall) Return(arg0 error) *MockUserRepositoryUpdateUserCall {
+func (c *MockUserRepositoryUpdateUserCall) Return(arg0 entity.User, arg1 error) *MockUserRepositoryUpdateUserCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryUpdateUserCall) Do(f func(context.Context, entity.User) (entity.User, error)) *MockUserRepositoryUpdateUserCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryUpdateUserCall) DoAndReturn(f func(context.Context, entity.User) (entity.User, error)) *MockUserRepositoryUpdateUserCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateWalletBalance mocks base method.
+func (m *MockUserRepository) UpdateWalletBalance(ctx context.Context, userID uuid.UUID, currency string, balance decimal.Decimal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletBalance", ctx, userID, currency, balance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletBalance indicates an expected call of UpdateWalletBalance.
+func (mr *MockUserRepositoryMockRecorder) UpdateWalletBalance(ctx, userID, currency, balance any) *MockUserRepositoryUpdateWalletBalanceCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletBalance", reflect.TypeOf((*MockUserRepository)(nil).UpdateWalletBalance), ctx, userID, currency, balance)
+	return &MockUserRepositoryUpdateWalletBalanceCall{Call: call}
+}
+
+// MockUserRepositoryUpdateWalletBalanceCall wrap *gomock.Call
+type MockUserRepositoryUpdateWalletBalanceCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryUpdateWalletBalanceCall) Return(arg0 error) *MockUserRepositoryUpdateWalletBalanceCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryUpdateWalletBalanceCall) Do(f func(context.Context, uuid.UUID, string, decimal.Decimal) error) *MockUserRepositoryUpdateWalletBalanceCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryUpdateWalletBalanceCall) DoAndReturn(f func(context.Context, uuid.UUID, string, decimal.Decimal) error) *MockUserRepositoryUpdateWalletBalanceCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockUserRepository) UpdateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, sub)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockUserRepositoryMockRecorder) UpdateWebhook(ctx, sub any) *MockUserRepositoryUpdateWebhookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockUserRepository)(nil).UpdateWebhook), ctx, sub)
+	return &MockUserRepositoryUpdateWebhookCall{Call: call}
+}
+
+// MockUserRepositoryUpdateWebhookCall wrap *gomock.Call
+type MockUserRepositoryUpdateWebhookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryUpdateWebhookCall) Return(arg0 entity.WebhookSubscription, arg1 error) *MockUserRepositoryUpdateWebhookCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryUpdateWebhookCall) Do(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserRepositoryUpdateWebhookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryUpdateWebhookCall) DoAndReturn(f func(context.Context, entity.WebhookSubscription) (entity.WebhookSubscription, error)) *MockUserRepositoryUpdateWebhookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockUserRepository) UpdateWebhookDelivery(ctx context.Context, d entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, d)
+	ret0, _ := ret[0].(entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockUserRepositoryMockRecorder) UpdateWebhookDelivery(ctx, d any) *MockUserRepositoryUpdateWebhookDeliveryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockUserRepository)(nil).UpdateWebhookDelivery), ctx, d)
+	return &MockUserRepositoryUpdateWebhookDeliveryCall{Call: call}
+}
+
+// MockUserRepositoryUpdateWebhookDeliveryCall wrap *gomock.Call
+type MockUserRepositoryUpdateWebhookDeliveryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryUpdateWebhookDeliveryCall) Return(arg0 entity.WebhookDelivery, arg1 error) *MockUserRepositoryUpdateWebhookDeliveryCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockUserRepositoryUpdateWebhookDeliveryCall) Do(f func(context.Context, entity.WebhookDelivery) (entity.WebhookDelivery, error)) *MockUserRepositoryUpdateWebhookDeliveryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockUserRepositoryUpdateWebhookDeliveryCall) DoAndReturn(f func(context.Context, entity.WebhookDelivery) (entity.WebhookDelivery, error)) *MockUserRepositoryUpdateWebhookDeliveryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// WithinTx mocks base method.
+func (m *MockUserRepository) WithinTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockUserRepositoryMockRecorder) WithinTx(ctx, fn any) *MockUserRepositoryWithinTxCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockUserRepository)(nil).WithinTx), ctx, fn)
+	return &MockUserRepositoryWithinTxCall{Call: call}
+}
+
+// MockUserRepositoryWithinTxCall wrap *gomock.Call
+type MockUserRepositoryWithinTxCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockUserRepositoryWithinTxCall) Return(arg0 error) *MockUserRepositoryWithinTxCall {
 	c.Call = c.Call.Return(arg0)
 	return c
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockUserRepositoryUpdateUserCall) Do(f func(context.Context, entity.User) error) *MockUserRepositoryUpdateUserCall {
+func (c *MockUserRepositoryWithinTxCall) Do(f func(context.Context, func(context.Context) error) error) *MockUserRepositoryWithinTxCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockUserRepositoryUpdateUserCall) DoAndReturn(f func(context.Context, entity.User) error) *MockUserRepositoryUpdateUserCall {
+func (c *MockUserRepositoryWithinTxCall) DoAndReturn(f func(context.Context, func(context.Context) error) error) *MockUserRepositoryWithinTxCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
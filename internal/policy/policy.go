@@ -0,0 +1,168 @@
+// Package policy maps the roles in a caller's token to the permissions the
+// service layer checks.
+//
+// A policy document is JSON of the form
+//
+//	{
+//	  "roles": {"support": ["users:read", "users:update"]},
+//	  "fields": {"balance": "users.balance:write"}
+//	}
+//
+// roles grants permissions to roles; "*" grants every permission. fields names
+// user fields that may only be written with an extra permission, on top of
+// the one the operation needs.
+package policy
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"users-app/internal/entity"
+)
+
+//go:embed default.json
+var defaultPolicy []byte
+
+const wildcard = "*"
+
+type Policy struct {
+	roles  map[string]map[entity.Permission]struct{}
+	fields map[string]entity.Permission
+}
+
+type document struct {
+	Roles  map[string][]string `json:"roles"`
+	Fields map[string]string   `json:"fields"`
+}
+
+// Default is the built-in policy: admins may do anything, support staff may
+// read and edit profiles but not balances, and users may read and edit their
+// own profile.
+func Default() *Policy {
+	p, err := Parse(defaultPolicy)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default policy: %s", err))
+	}
+
+	return p
+}
+
+// Load reads the policy at path, or returns Default when path is empty.
+func Load(path string) (*Policy, error) {
+	if path == "" {
+		return Default(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse reads a policy document. Unknown permissions and fields are errors,
+// so that a typo cannot silently take a permission away or grant one.
+func Parse(data []byte) (*Policy, error) {
+	var doc document
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+
+	p := &Policy{
+		roles:  make(map[string]map[entity.Permission]struct{}, len(doc.Roles)),
+		fields: make(map[string]entity.Permission, len(doc.Fields)),
+	}
+
+	for role, names := range doc.Roles {
+		perms := make(map[entity.Permission]struct{}, len(names))
+
+		for _, name := range names {
+			if name == wildcard {
+				for _, perm := range entity.Permissions {
+					perms[perm] = struct{}{}
+				}
+
+				continue
+			}
+
+			perm, err := parsePermission(name)
+			if err != nil {
+				return nil, fmt.Errorf("role %q: %w", role, err)
+			}
+
+			perms[perm] = struct{}{}
+		}
+
+		p.roles[role] = perms
+	}
+
+	known := userFields()
+
+	for field, name := range doc.Fields {
+		if !slices.Contains(known, field) {
+			return nil, fmt.Errorf("unknown user field %q", field)
+		}
+
+		perm, err := parsePermission(name)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", field, err)
+		}
+
+		p.fields[field] = perm
+	}
+
+	return p, nil
+}
+
+// Allows reports whether any of roles grants perm. Roles missing from the
+// policy grant nothing.
+func (p *Policy) Allows(roles []string, perm entity.Permission) bool {
+	for _, role := range roles {
+		if _, ok := p.roles[role][perm]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FieldPermission returns the permission needed to write the user field with
+// the given JSON name, if it is restricted.
+func (p *Policy) FieldPermission(field string) (entity.Permission, bool) {
+	perm, ok := p.fields[field]
+	return perm, ok
+}
+
+func parsePermission(name string) (entity.Permission, error) {
+	perm := entity.Permission(name)
+	if !slices.Contains(entity.Permissions, perm) {
+		return "", fmt.Errorf("unknown permission %q", name)
+	}
+
+	return perm, nil
+}
+
+// userFields returns the JSON names of the fields of entity.User.
+func userFields() []string {
+	t := reflect.TypeOf(entity.User{})
+	fields := make([]string, 0, t.NumField())
+
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
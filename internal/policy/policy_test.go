@@ -0,0 +1,112 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"users-app/internal/entity"
+	"users-app/internal/policy"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	p := policy.Default()
+
+	tests := []struct {
+		roles   []string
+		perm    entity.Permission
+		allowed bool
+	}{
+		{[]string{"admin"}, entity.PermUsersBalanceWrite, true},
+		{[]string{"admin"}, entity.PermWebhooksManage, true},
+		{[]string{"support"}, entity.PermUsersRead, true},
+		{[]string{"support"}, entity.PermUsersUpdate, true},
+		{[]string{"support"}, entity.PermUsersBalanceWrite, false},
+		{[]string{"support"}, entity.PermTransfersCreate, false},
+		{[]string{"user"}, entity.PermUsersReadSelf, true},
+		{[]string{"user"}, entity.PermUsersUpdateSelf, true},
+		{[]string{"user"}, entity.PermUsersRead, false},
+		{[]string{"user", "support"}, entity.PermUsersRead, true},
+		{[]string{"unknown"}, entity.PermUsersReadSelf, false},
+		{nil, entity.PermUsersReadSelf, false},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.allowed, p.Allows(tt.roles, tt.perm), "%v %s", tt.roles, tt.perm)
+	}
+
+	perm, ok := p.FieldPermission("balance")
+	require.True(t, ok)
+	require.Equal(t, entity.PermUsersBalanceWrite, perm)
+
+	_, ok = p.FieldPermission("name")
+	require.False(t, ok)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			data: `{"roles":{"auditor":["users:read"]},"fields":{"email":"users:update"}}`,
+		},
+		{
+			name:    "unknown permission",
+			data:    `{"roles":{"auditor":["users:reed"]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			data:    `{"fields":{"balanse":"users.balance:write"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "field hidden from json",
+			data:    `{"fields":{"Version":"users:update"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			data:    `{"role":{"auditor":["users:read"]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := policy.Parse([]byte(tt.data))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	r.NoError(os.WriteFile(path, []byte(`{"roles":{"auditor":["users:read"]}}`), 0o600))
+
+	p, err := policy.Load(path)
+	r.NoError(err)
+	r.True(p.Allows([]string{"auditor"}, entity.PermUsersRead))
+	r.False(p.Allows([]string{"admin"}, entity.PermUsersRead))
+
+	_, restricted := p.FieldPermission("balance")
+	r.False(restricted)
+
+	p, err = policy.Load("")
+	r.NoError(err)
+	r.True(p.Allows([]string{"admin"}, entity.PermUsersRead))
+
+	_, err = policy.Load(filepath.Join(t.TempDir(), "missing.json"))
+	r.Error(err)
+}
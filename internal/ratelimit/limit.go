@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket holding Requests tokens, refilled at Requests per
+// Period. A client may burst up to Requests at once and then keeps Requests
+// per Period on average.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit reads "<requests>/<period>", the period being s, m, h or a
+// duration such as 10s or 24h: "60/m" allows 60 requests a minute.
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q is not <requests>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: requests must be a positive integer", s)
+	}
+
+	var d time.Duration
+
+	switch period {
+	case "s":
+		d = time.Second
+	case "m":
+		d = time.Minute
+	case "h":
+		d = time.Hour
+	default:
+		d, err = time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return Limit{}, fmt.Errorf("limit %q: invalid period %q", s, period)
+		}
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// rate is the number of tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the state of a bucket after a request took, or failed to take, a
+// token from it.
+type Result struct {
+	Allowed   bool
+	Limit     Limit
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, when none was left.
+	RetryAfter time.Duration
+}
+
+// newResult describes a bucket left with tokens.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.rate()
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Requests) - tokens) / rate),
+	}
+
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(s, 0) * float64(time.Second))
+}
+
+// take refills a bucket holding tokens for the time elapsed since it was last
+// updated and takes a token from it if one is left.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, bool) {
+	elapsed = max(elapsed, 0)
+	tokens = math.Min(float64(limit.Requests), tokens+elapsed.Seconds()*limit.rate())
+
+	if tokens < 1 {
+		return tokens, false
+	}
+
+	return tokens - 1, true
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"users-app/pkg/logger"
+)
+
+// ErrLimited is returned to a client that used up its limit.
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Limiter gives every client a bucket per route with its own limit and one
+// shared by all the other routes. Every address also has a bucket of its own
+// across all routes, taken from before the caller is known.
+type Limiter struct {
+	log          logger.Logger
+	store        Store
+	defaultLimit Limit
+	addressLimit Limit
+	routes       map[string]Limit
+}
+
+// NewLimiter parses the limits, see ParseLimit. routes maps
+// "<METHOD> <route pattern>", e.g. "POST /api/v1/users", to the limit of
+// that route.
+func NewLimiter(log logger.Logger, store Store, defaultLimit, addressLimit string,
+	routes map[string]string) (*Limiter, error) {
+	def, err := ParseLimit(defaultLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default limit: %w", err)
+	}
+
+	addr, err := ParseLimit(addressLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address limit: %w", err)
+	}
+
+	limits := make(map[string]Limit, len(routes))
+
+	for route, s := range routes {
+		method, pattern, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("route %q is not <METHOD> <route pattern>", route)
+		}
+
+		limit, err := ParseLimit(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit of route %q: %w", route, err)
+		}
+
+		limits[strings.ToUpper(method)+" "+pattern] = limit
+	}
+
+	return &Limiter{
+		log:          log,
+		store:        store,
+		defaultLimit: def,
+		addressLimit: addr,
+		routes:       limits,
+	}, nil
+}
+
+// Take takes a token from the bucket of client for the route with the given
+// method and pattern.
+func (l *Limiter) Take(ctx context.Context, client, method, pattern string) (Result, error) {
+	route := method + " " + pattern
+
+	limit, ok := l.routes[route]
+	if !ok {
+		limit, route = l.defaultLimit, "*"
+	}
+
+	return l.store.Take(ctx, client+" "+route, limit)
+}
+
+// TakeAddress takes a token from the bucket of the client address, the one
+// checked before authentication so that failed attempts are limited as well.
+func (l *Limiter) TakeAddress(ctx context.Context, addr string) (Result, error) {
+	return l.store.Take(ctx, "ip:"+addr+" address", l.addressLimit)
+}
+
+// Routes lists the routes with a limit of their own.
+func (l *Limiter) Routes() []string {
+	routes := make([]string, 0, len(l.routes))
+	for route := range l.routes {
+		routes = append(routes, route)
+	}
+
+	slices.Sort(routes)
+
+	return routes
+}
+
+// Run drops idle buckets on every tick until ctx is cancelled.
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	idle := max(l.defaultLimit.Period, l.addressLimit.Period)
+	for _, limit := range l.routes {
+		idle = max(idle, limit.Period)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := l.store.Sweep(ctx, idle)
+			if err != nil {
+				l.log.ErrorF("failed to sweep rate limit buckets: %s", err.Error())
+			} else if n > 0 {
+				l.log.InfoF("swept %d idle rate limit buckets", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"users-app/internal/ratelimit"
+	"users-app/pkg/logger"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ratelimit.Limit
+		wantErr bool
+	}{
+		{in: "60/m", want: ratelimit.Limit{Requests: 60, Period: time.Minute}},
+		{in: "10/s", want: ratelimit.Limit{Requests: 10, Period: time.Second}},
+		{in: "1000/h", want: ratelimit.Limit{Requests: 1000, Period: time.Hour}},
+		{in: " 5/30s ", want: ratelimit.Limit{Requests: 5, Period: 30 * time.Second}},
+		{in: "10000/24h", want: ratelimit.Limit{Requests: 10000, Period: 24 * time.Hour}},
+		{in: "60", wantErr: true},
+		{in: "0/m", wantErr: true},
+		{in: "-1/m", wantErr: true},
+		{in: "x/m", wantErr: true},
+		{in: "60/d", wantErr: true},
+		{in: "60/-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ratelimit.ParseLimit(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	r := require.New(t)
+
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 2, Period: 100 * time.Millisecond}
+
+	res, err := store.Take(ctx, "a", limit)
+	r.NoError(err)
+	r.True(res.Allowed)
+	r.Equal(1, res.Remaining)
+
+	res, err = store.Take(ctx, "a", limit)
+	r.NoError(err)
+	r.True(res.Allowed)
+	r.Equal(0, res.Remaining)
+
+	res, err = store.Take(ctx, "a", limit)
+	r.NoError(err)
+	r.False(res.Allowed)
+	r.Positive(res.RetryAfter)
+	r.LessOrEqual(res.RetryAfter, 50*time.Millisecond)
+	r.LessOrEqual(res.Reset, 100*time.Millisecond)
+
+	// Another key has a bucket of its own.
+	res, err = store.Take(ctx, "b", limit)
+	r.NoError(err)
+	r.True(res.Allowed)
+
+	time.Sleep(60 * time.Millisecond)
+
+	res, err = store.Take(ctx, "a", limit)
+	r.NoError(err)
+	r.True(res.Allowed)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	r := require.New(t)
+
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Hour}
+
+	_, err := store.Take(ctx, "a", limit)
+	r.NoError(err)
+
+	n, err := store.Sweep(ctx, time.Hour)
+	r.NoError(err)
+	r.Zero(n)
+
+	time.Sleep(time.Millisecond)
+
+	n, err = store.Sweep(ctx, 0)
+	r.NoError(err)
+	r.EqualValues(1, n)
+
+	// The bucket starts full again.
+	res, err := store.Take(ctx, "a", limit)
+	r.NoError(err)
+	r.True(res.Allowed)
+}
+
+func TestLimiter_Take(t *testing.T) {
+	r := require.New(t)
+
+	log, err := logger.New("mock")
+	r.NoError(err)
+
+	limiter, err := ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "3/h", "5/h", map[string]string{
+		"post /api/v1/users": "1/h",
+	})
+	r.NoError(err)
+	r.Equal([]string{"POST /api/v1/users"}, limiter.Routes())
+
+	ctx := context.Background()
+
+	res, err := limiter.Take(ctx, "user:1", "POST", "/api/v1/users")
+	r.NoError(err)
+	r.True(res.Allowed)
+	r.Equal(1, res.Limit.Requests)
+
+	res, err = limiter.Take(ctx, "user:1", "POST", "/api/v1/users")
+	r.NoError(err)
+	r.False(res.Allowed)
+
+	// The other routes share the default bucket.
+	for _, pattern := range []string{"/api/v1/users", "/api/v1/users/{id}", "/api/v1/webhooks"} {
+		res, err = limiter.Take(ctx, "user:1", "GET", pattern)
+		r.NoError(err)
+		r.True(res.Allowed)
+		r.Equal(3, res.Limit.Requests)
+	}
+
+	res, err = limiter.Take(ctx, "user:1", "GET", "/api/v1/users")
+	r.NoError(err)
+	r.False(res.Allowed)
+
+	// Other clients are not affected.
+	res, err = limiter.Take(ctx, "user:2", "POST", "/api/v1/users")
+	r.NoError(err)
+	r.True(res.Allowed)
+
+	// Nor is the bucket of the address.
+	res, err = limiter.TakeAddress(ctx, "203.0.113.1")
+	r.NoError(err)
+	r.True(res.Allowed)
+	r.Equal(5, res.Limit.Requests)
+	r.Equal(4, res.Remaining)
+}
+
+func TestNewLimiter_InvalidConfig(t *testing.T) {
+	log, err := logger.New("mock")
+	require.NoError(t, err)
+
+	_, err = ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "often", "60/m", nil)
+	require.Error(t, err)
+
+	_, err = ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "60/m", "60/m", map[string]string{"/api/v1/users": "1/m"})
+	require.Error(t, err)
+
+	_, err = ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "60/m", "60/m", map[string]string{"POST /api/v1/users": "1"})
+	require.Error(t, err)
+
+	_, err = ratelimit.NewLimiter(log, ratelimit.NewMemoryStore(), "60/m", "often", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take takes a token from the bucket under key, creating it full.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Sweep drops the buckets untouched for idle. A bucket idle for its
+	// limit's period is full, so dropping it changes nothing.
+	Sweep(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryStore keeps the buckets in the process: each replica limits the
+// requests it serves on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]memoryBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = memoryBucket{tokens: float64(limit.Requests), updatedAt: now}
+	}
+
+	tokens, allowed := take(b.tokens, now.Sub(b.updatedAt), limit)
+	s.buckets[key] = memoryBucket{tokens: tokens, updatedAt: now}
+
+	return newResult(limit, tokens, allowed), nil
+}
+
+func (s *MemoryStore) Sweep(_ context.Context, idle time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-idle)
+
+	var n int64
+
+	for key, b := range s.buckets {
+		if b.updatedAt.Before(cutoff) {
+			delete(s.buckets, key)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// BucketRepository keeps the buckets in the database. repository.Repository
+// implements it.
+type BucketRepository interface {
+	// TakeRateLimitToken refills the bucket under key at perSecond tokens,
+	// up to capacity, and takes a token from it. It returns the tokens left
+	// and whether one was taken.
+	TakeRateLimitToken(ctx context.Context, key string, capacity, perSecond float64) (float64, bool, error)
+	PurgeRateLimitBuckets(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+// PostgresStore keeps the buckets in Postgres, so that every replica draws
+// from the same ones.
+type PostgresStore struct {
+	repo BucketRepository
+}
+
+func NewPostgresStore(repo BucketRepository) *PostgresStore {
+	return &PostgresStore{repo: repo}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	tokens, allowed, err := s.repo.TakeRateLimitToken(ctx, key, float64(limit.Requests), limit.rate())
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	return newResult(limit, tokens, allowed), nil
+}
+
+func (s *PostgresStore) Sweep(ctx context.Context, idle time.Duration) (int64, error) {
+	return s.repo.PurgeRateLimitBuckets(ctx, idle)
+}
@@ -0,0 +1,114 @@
+// Package rates reads exchange rates from CSV files.
+//
+// A file has a header row naming the columns base, quote, rate and
+// effective_at, in any order, followed by one rate per row:
+//
+//	base,quote,rate,effective_at
+//	EUR,USD,1.0842,2026-10-01T00:00:00Z
+//	USD,JPY,149.35,2026-10-01
+//
+// effective_at is an RFC 3339 time or a date, taken as midnight UTC.
+package rates
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/shopspring/decimal"
+)
+
+var columns = []string{"base", "quote", "rate", "effective_at"}
+
+// Load reads the rates in the file at path.
+func Load(path string) ([]entity.ExchangeRate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exchange rates: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads rates in CSV. Currency codes are upper-cased; whether they are
+// known is left to the caller.
+func Parse(r io.Reader) ([]entity.ExchangeRate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = len(columns)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("exchange rates have no %s column", name)
+		}
+	}
+
+	var rates []entity.ExchangeRate
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		rate, err := parseRate(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rates line %d: %w", line, err)
+		}
+
+		rates = append(rates, rate)
+	}
+}
+
+func parseRate(record []string, index map[string]int) (entity.ExchangeRate, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[index[name]])
+	}
+
+	rate := entity.ExchangeRate{
+		Base:  strings.ToUpper(field("base")),
+		Quote: strings.ToUpper(field("quote")),
+	}
+
+	var err error
+
+	if rate.Rate, err = decimal.NewFromString(field("rate")); err != nil {
+		return rate, fmt.Errorf("invalid rate %q", field("rate"))
+	}
+
+	if rate.EffectiveAt, err = parseTime(field("effective_at")); err != nil {
+		return rate, fmt.Errorf("invalid effective_at %q", field("effective_at"))
+	}
+
+	return rate, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+
+	return time.Parse(time.DateOnly, s)
+}
@@ -0,0 +1,47 @@
+package rates_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/rates"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	r := require.New(t)
+
+	got, err := rates.Parse(strings.NewReader(
+		"effective_at,base,quote,rate\n" +
+			"2026-10-01T03:00:00+03:00,eur,USD,1.0842\n" +
+			"2026-10-02, USD, JPY, 149.35\n",
+	))
+	r.NoError(err)
+	r.Equal([]entity.ExchangeRate{
+		{Base: "EUR", Quote: "USD", Rate: decimal.RequireFromString("1.0842"), EffectiveAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{Base: "USD", Quote: "JPY", Rate: decimal.RequireFromString("149.35"), EffectiveAt: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)},
+	}, got)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "missing column", data: "base,quote,rate,when\nEUR,USD,1,2026-10-01\n"},
+		{name: "missing field", data: "base,quote,rate,effective_at\nEUR,USD,1\n"},
+		{name: "invalid rate", data: "base,quote,rate,effective_at\nEUR,USD,one,2026-10-01\n"},
+		{name: "invalid time", data: "base,quote,rate,effective_at\nEUR,USD,1,yesterday\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rates.Parse(strings.NewReader(tt.data))
+			require.Error(t, err)
+		})
+	}
+}
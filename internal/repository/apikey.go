@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const apiKeyColumns = `id, name, prefix, hash, scopes, created_by_kind, created_by_id,
+	created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIKey(row pgx.Row) (entity.APIKey, error) {
+	var k entity.APIKey
+
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, &k.Scopes, &k.CreatedBy.Kind, &k.CreatedBy.ID,
+		&k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt)
+
+	return k, err
+}
+
+func (r *Repository) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	sqlQuery := `
+	insert into api_keys
+	(id, name, prefix, hash, scopes, created_by_kind, created_by_id, expires_at)
+	values ($1, $2, $3, $4, $5, $6, $7, $8)
+	returning ` + apiKeyColumns
+
+	created, err := scanAPIKey(r.conn(ctx).QueryRow(ctx, sqlQuery, key.ID, key.Name, key.Prefix, key.Hash,
+		key.Scopes, key.CreatedBy.Kind, key.CreatedBy.ID, key.ExpiresAt))
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return entity.APIKey{}, fmt.Errorf("api key with prefix %s %w", key.Prefix, entity.ErrAlreadyExists)
+		}
+
+		return entity.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	sqlQuery := `select ` + apiKeyColumns + ` from api_keys where prefix = $1`
+
+	key, err := scanAPIKey(r.conn(ctx).QueryRow(ctx, sqlQuery, prefix))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.APIKey{}, fmt.Errorf("api key with prefix %s %w", prefix, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.APIKey{}, fmt.Errorf("failed to get api key with prefix %s: %w", prefix, err)
+	}
+
+	return key, nil
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	sqlQuery := `select ` + apiKeyColumns + ` from api_keys order by created_at, id`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]entity.APIKey, 0)
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks the key revoked. Revoking a revoked key keeps the
+// original revocation time.
+func (r *Repository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (entity.APIKey, error) {
+	sqlQuery := `
+	update api_keys
+	set revoked_at = coalesce(revoked_at, now())
+	where id = $1
+	returning ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.conn(ctx).QueryRow(ctx, sqlQuery, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.APIKey{}, fmt.Errorf("api key with id %s %w", id, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.APIKey{}, fmt.Errorf("failed to revoke api key with id %s: %w", id, err)
+	}
+
+	return key, nil
+}
+
+// TouchAPIKey records that the key was used. The row is only written when
+// the recorded time is older than a minute, so a busy client does not turn
+// every request into a write.
+func (r *Repository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	sqlQuery := `
+	update api_keys
+	set last_used_at = now()
+	where id = $1 and (last_used_at is null or last_used_at < now() - interval '1 minute')`
+
+	if _, err := r.conn(ctx).Exec(ctx, sqlQuery, id); err != nil {
+		return fmt.Errorf("failed to record use of api key with id %s: %w", id, err)
+	}
+
+	return nil
+}
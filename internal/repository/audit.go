@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func (r *Repository) CreateAuditRecord(ctx context.Context, record entity.AuditRecord) error {
+	sqlQuery := `
+	insert into audit_log
+	(user_id, action, actor_kind, actor_id, request_id, changes)
+	values ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.conn(ctx).Exec(ctx, sqlQuery, record.UserID, record.Action,
+		record.Actor.Kind, record.Actor.ID, record.RequestID, record.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to create audit record for user with id %s: %w", record.UserID, err)
+	}
+
+	return nil
+}
+
+// ListAuditRecords returns the newest records of a user older than beforeID;
+// a zero beforeID starts from the latest record.
+func (r *Repository) ListAuditRecords(ctx context.Context, userID uuid.UUID, beforeID int64, limit int) ([]entity.AuditRecord, error) {
+	sqlQuery := `
+	select id, user_id, action, actor_kind, actor_id, request_id, changes, created_at
+	from audit_log
+	where user_id = $1 and ($2::bigint = 0 or id < $2)
+	order by id desc
+	limit $3`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, userID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit records of user with id %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	records := make([]entity.AuditRecord, 0, limit)
+
+	for rows.Next() {
+		var rec entity.AuditRecord
+
+		if err := rows.Scan(&rec.ID, &rec.UserID, &rec.Action, &rec.Actor.Kind, &rec.Actor.ID,
+			&rec.RequestID, &rec.Changes, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+
+		records = append(records, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit records of user with id %s: %w", userID, err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// ListWallets returns the wallets of the user in currencies other than the
+// base one, ordered by currency.
+func (r *Repository) ListWallets(ctx context.Context, userID uuid.UUID) ([]entity.Wallet, error) {
+	sqlQuery := `
+	select currency, balance
+	from wallets
+	where user_id = $1
+	order by currency`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets of user with id %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var wallets []entity.Wallet
+
+	for rows.Next() {
+		var w entity.Wallet
+
+		if err := rows.Scan(&w.Currency, &w.Balance); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet: %w", err)
+		}
+
+		wallets = append(wallets, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list wallets of user with id %s: %w", userID, err)
+	}
+
+	return wallets, nil
+}
+
+// GetWalletForUpdate locks the wallet of the user in currency until the
+// transaction ends. A wallet that was never credited is empty. Callers lock
+// the user first.
+func (r *Repository) GetWalletForUpdate(ctx context.Context, userID uuid.UUID, currency string) (entity.Wallet, error) {
+	sqlQuery := `
+	select currency, balance
+	from wallets
+	where user_id = $1 and currency = $2
+	for update`
+
+	w := entity.Wallet{Currency: currency}
+
+	err := r.conn(ctx).QueryRow(ctx, sqlQuery, userID, currency).Scan(&w.Currency, &w.Balance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return w, nil
+	}
+
+	if err != nil {
+		return entity.Wallet{}, fmt.Errorf("failed to get %s wallet of user with id %s: %w", currency, userID, err)
+	}
+
+	return w, nil
+}
+
+// UpdateWalletBalance sets the balance of the wallet of the user in currency,
+// creating the wallet on its first credit.
+func (r *Repository) UpdateWalletBalance(ctx context.Context, userID uuid.UUID, currency string, balance decimal.Decimal) error {
+	sqlQuery := `
+	insert into wallets (user_id, currency, balance)
+	values ($1, $2, $3)
+	on conflict (user_id, currency)
+	do update set balance = excluded.balance, updated_at = now()`
+
+	if _, err := r.conn(ctx).Exec(ctx, sqlQuery, userID, currency, balance); err != nil {
+		return balanceErr(userID, err)
+	}
+
+	return nil
+}
+
+// SaveExchangeRates stores rates, replacing those of the same pair and
+// effective time.
+func (r *Repository) SaveExchangeRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	sqlQuery := `
+	insert into exchange_rates (base, quote, rate, effective_at)
+	values ($1, $2, $3, $4)
+	on conflict (base, quote, effective_at)
+	do update set rate = excluded.rate`
+
+	batch := &pgx.Batch{}
+
+	for _, rate := range rates {
+		batch.Queue(sqlQuery, rate.Base, rate.Quote, rate.Rate, rate.EffectiveAt)
+	}
+
+	br := r.conn(ctx).SendBatch(ctx, batch)
+	defer br.Close()
+
+	for range rates {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("failed to save exchange rate: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetExchangeRate returns the rate of base in quote in effect at the given
+// time.
+func (r *Repository) GetExchangeRate(ctx context.Context, base, quote string, at time.Time) (entity.ExchangeRate, error) {
+	sqlQuery := `
+	select base, quote, rate, effective_at
+	from exchange_rates
+	where base = $1 and quote = $2 and effective_at <= $3
+	order by effective_at desc
+	limit 1`
+
+	var rate entity.ExchangeRate
+
+	err := r.conn(ctx).QueryRow(ctx, sqlQuery, base, quote, at).
+		Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.EffectiveAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.ExchangeRate{}, fmt.Errorf("rate of %s in %s %w", base, quote, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.ExchangeRate{}, fmt.Errorf("failed to get rate of %s in %s: %w", base, quote, err)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+const holdColumns = `id, user_id, amount, captured_amount, status, expires_at, created_at, settled_at`
+
+func scanHold(row pgx.Row) (entity.Hold, error) {
+	var h entity.Hold
+
+	err := row.Scan(&h.ID, &h.UserID, &h.Amount, &h.CapturedAmount, &h.Status, &h.ExpiresAt, &h.CreatedAt, &h.SettledAt)
+
+	return h, err
+}
+
+func (r *Repository) CreateHold(ctx context.Context, hold entity.Hold) (entity.Hold, error) {
+	sqlQuery := `
+	insert into holds
+	(id, user_id, amount, status, expires_at)
+	values ($1, $2, $3, $4, $5)
+	returning ` + holdColumns
+
+	created, err := scanHold(r.conn(ctx).QueryRow(ctx, sqlQuery, hold.ID, hold.UserID, hold.Amount, hold.Status, hold.ExpiresAt))
+	if err != nil {
+		return entity.Hold{}, fmt.Errorf("failed to create hold: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *Repository) GetHold(ctx context.Context, id uuid.UUID) (entity.Hold, error) {
+	return r.getHold(ctx, id, "")
+}
+
+// GetHoldForUpdate locks the hold until the transaction ends. Callers lock
+// the user of the hold first, as every balance operation does.
+func (r *Repository) GetHoldForUpdate(ctx context.Context, id uuid.UUID) (entity.Hold, error) {
+	return r.getHold(ctx, id, "for update")
+}
+
+func (r *Repository) getHold(ctx context.Context, id uuid.UUID, lock string) (entity.Hold, error) {
+	sqlQuery := `select ` + holdColumns + ` from holds where id = $1 ` + lock
+
+	hold, err := scanHold(r.conn(ctx).QueryRow(ctx, sqlQuery, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Hold{}, fmt.Errorf("hold with id %s %w", id, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.Hold{}, fmt.Errorf("failed to get hold with id %s: %w", id, err)
+	}
+
+	return hold, nil
+}
+
+// SettleHold records how the hold ended.
+func (r *Repository) SettleHold(ctx context.Context, hold entity.Hold) (entity.Hold, error) {
+	sqlQuery := `
+	update holds
+	set status = $2, captured_amount = $3, settled_at = now()
+	where id = $1
+	returning ` + holdColumns
+
+	settled, err := scanHold(r.conn(ctx).QueryRow(ctx, sqlQuery, hold.ID, hold.Status, hold.CapturedAmount))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Hold{}, fmt.Errorf("hold with id %s %w", hold.ID, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.Hold{}, fmt.Errorf("failed to settle hold with id %s: %w", hold.ID, err)
+	}
+
+	return settled, nil
+}
+
+// ListExpiredHolds returns up to limit active holds past their expiry, the
+// longest expired first. Holds of deleted users are left out: they cannot be
+// settled, and would otherwise come first in every batch.
+func (r *Repository) ListExpiredHolds(ctx context.Context, limit int) ([]entity.Hold, error) {
+	sqlQuery := `
+	select ` + holdColumns + `
+	from holds
+	where status = 'active' and expires_at <= now()
+	and exists (select 1 from users where users.id = holds.user_id and users.deleted_at is null)
+	order by expires_at
+	limit $1`
+
+	holds, err := r.listHolds(ctx, sqlQuery, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// ListActiveHolds returns the active holds of the user, the oldest first.
+func (r *Repository) ListActiveHolds(ctx context.Context, userID uuid.UUID) ([]entity.Hold, error) {
+	sqlQuery := `
+	select ` + holdColumns + `
+	from holds
+	where user_id = $1 and status = 'active'
+	order by created_at`
+
+	holds, err := r.listHolds(ctx, sqlQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active holds of user with id %s: %w", userID, err)
+	}
+
+	return holds, nil
+}
+
+func (r *Repository) listHolds(ctx context.Context, sqlQuery string, args ...any) ([]entity.Hold, error) {
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []entity.Hold
+
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hold: %w", err)
+		}
+
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
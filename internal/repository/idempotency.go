@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const idempotencyColumns = `scope, key, fingerprint, status, header, body, locked_at, completed_at, expires_at`
+
+func scanIdempotencyRecord(row pgx.Row) (entity.IdempotencyRecord, error) {
+	var (
+		rec    entity.IdempotencyRecord
+		status *int
+	)
+
+	err := row.Scan(&rec.Scope, &rec.Key, &rec.Fingerprint, &status, &rec.Header, &rec.Body,
+		&rec.LockedAt, &rec.CompletedAt, &rec.ExpiresAt)
+	if status != nil {
+		rec.Status = *status
+	}
+
+	return rec, err
+}
+
+// ClaimIdempotencyKey claims key for a request with fingerprint, unless
+// another request holds it. An expired key is claimed anew, and so is one
+// locked for longer than lockTimeout by an identical request, whose server
+// is taken to have died. It returns the claimed record, or the one holding
+// the key and false.
+func (r *Repository) ClaimIdempotencyKey(ctx context.Context, scope, key string, fingerprint []byte,
+	ttl, lockTimeout time.Duration) (entity.IdempotencyRecord, bool, error) {
+	claimQuery := `
+	insert into idempotency_keys (scope, key, fingerprint, locked_at, expires_at)
+	values ($1, $2, $3, now(), now() + make_interval(secs => $4))
+	on conflict (scope, key) do update
+	set fingerprint = excluded.fingerprint, status = null, header = null, body = null,
+		locked_at = excluded.locked_at, completed_at = null, expires_at = excluded.expires_at
+	where idempotency_keys.expires_at < now()
+		or (idempotency_keys.completed_at is null
+			and idempotency_keys.fingerprint = excluded.fingerprint
+			and idempotency_keys.locked_at < now() - make_interval(secs => $5))
+	returning ` + idempotencyColumns
+
+	getQuery := `select ` + idempotencyColumns + ` from idempotency_keys where scope = $1 and key = $2`
+
+	// The holder may release the key between the two statements, in which
+	// case it is claimed again.
+	for range 3 {
+		rec, err := scanIdempotencyRecord(r.conn(ctx).QueryRow(ctx, claimQuery, scope, key, fingerprint,
+			ttl.Seconds(), lockTimeout.Seconds()))
+		if err == nil {
+			return rec, true, nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return entity.IdempotencyRecord{}, false, fmt.Errorf("failed to claim idempotency key %s: %w", key, err)
+		}
+
+		rec, err = scanIdempotencyRecord(r.conn(ctx).QueryRow(ctx, getQuery, scope, key))
+		if err == nil {
+			return rec, false, nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return entity.IdempotencyRecord{}, false, fmt.Errorf("failed to get idempotency key %s: %w", key, err)
+		}
+	}
+
+	return entity.IdempotencyRecord{}, false, fmt.Errorf("failed to claim idempotency key %s: too much contention", key)
+}
+
+// CompleteIdempotencyKey stores the response of the request holding key.
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, scope, key string, status int,
+	header map[string][]string, body []byte) error {
+	sqlQuery := `
+	update idempotency_keys
+	set status = $3, header = $4, body = $5, completed_at = now()
+	where scope = $1 and key = $2 and completed_at is null`
+
+	result, err := r.conn(ctx).Exec(ctx, sqlQuery, scope, key, status, header, body)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key %s: %w", key, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("idempotency key %s in progress %w", key, entity.ErrNotFound)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey drops key while its request is in progress, so that
+// the request may be retried with it.
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	sqlQuery := `
+	delete from idempotency_keys
+	where scope = $1 and key = $2 and completed_at is null`
+
+	if _, err := r.conn(ctx).Exec(ctx, sqlQuery, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys removes the expired keys.
+func (r *Repository) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	sqlQuery := `
+	delete from idempotency_keys
+	where expires_at < now()`
+
+	result, err := r.conn(ctx).Exec(ctx, sqlQuery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,228 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+	"users-app/internal/repository"
+	"users-app/pkg/postgres"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepository connects to the database under TEST_PG_DSN and migrates it.
+// Every test works on keys of its own, so the database may be shared.
+func newRepository(t *testing.T) *repository.Repository {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_PG_DSN")
+	if dsn == "" {
+		t.Skip("TEST_PG_DSN is not set")
+	}
+
+	pool, err := postgres.Connect(context.Background(), dsn, 32)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	require.NoError(t, postgres.UpMigrations(pool))
+
+	return repository.New(pool)
+}
+
+func newKey() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// concurrently runs fn n times at once and counts the calls that returned
+// true. It returns the first error of any call.
+func concurrently(n int, fn func() (bool, error)) (int, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		count    int
+		firstErr error
+	)
+
+	for range n {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ok, err := fn()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+
+			if ok {
+				count++
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return count, firstErr
+}
+
+func TestRepository_ClaimIdempotencyKey(t *testing.T) {
+	repo := newRepository(t)
+	ctx := context.Background()
+
+	const scope = "test"
+
+	t.Run("Concurrent claims", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		claimed, err := concurrently(20, func() (bool, error) {
+			_, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, time.Hour)
+			return ok, err
+		})
+		r.NoError(err)
+		r.Equal(1, claimed)
+	})
+
+	t.Run("Fingerprint mismatch", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		_, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, time.Hour)
+		r.NoError(err)
+		r.True(ok)
+
+		time.Sleep(10 * time.Millisecond)
+
+		// another request may not take over the key, however stale its lock
+		rec, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("other"), time.Hour, 0)
+		r.NoError(err)
+		r.False(ok)
+		r.Equal([]byte("fp"), rec.Fingerprint)
+	})
+
+	t.Run("Stale lock takeover", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		_, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, time.Hour)
+		r.NoError(err)
+		r.True(ok)
+
+		_, ok, err = repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, time.Hour)
+		r.NoError(err)
+		r.False(ok)
+
+		time.Sleep(10 * time.Millisecond)
+
+		rec, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, 0)
+		r.NoError(err)
+		r.True(ok)
+		r.Nil(rec.CompletedAt)
+	})
+
+	t.Run("Completed key is not taken over", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		_, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, time.Hour)
+		r.NoError(err)
+		r.True(ok)
+
+		r.NoError(repo.CompleteIdempotencyKey(ctx, scope, key, 201, map[string][]string{}, []byte(`{}`)))
+
+		time.Sleep(10 * time.Millisecond)
+
+		rec, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), time.Hour, 0)
+		r.NoError(err)
+		r.False(ok)
+		r.Equal(201, rec.Status)
+		r.NotNil(rec.CompletedAt)
+	})
+
+	t.Run("Expired key is claimed anew", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		_, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("fp"), 0, time.Hour)
+		r.NoError(err)
+		r.True(ok)
+
+		time.Sleep(10 * time.Millisecond)
+
+		rec, ok, err := repo.ClaimIdempotencyKey(ctx, scope, key, []byte("other"), time.Hour, time.Hour)
+		r.NoError(err)
+		r.True(ok)
+		r.Equal([]byte("other"), rec.Fingerprint)
+	})
+}
+
+func TestRepository_TakeRateLimitToken(t *testing.T) {
+	repo := newRepository(t)
+	ctx := context.Background()
+
+	// take takes a token from the bucket under key, which barely refills.
+	take := func(key string, capacity float64) func() (bool, error) {
+		return func() (bool, error) {
+			_, ok, err := repo.TakeRateLimitToken(ctx, key, capacity, 1e-9)
+			return ok, err
+		}
+	}
+
+	t.Run("Concurrent takes from a new bucket", func(t *testing.T) {
+		r := require.New(t)
+
+		allowed, err := concurrently(20, take(newKey(), 5))
+		r.NoError(err)
+		r.Equal(5, allowed)
+	})
+
+	t.Run("Concurrent takes from an existing bucket", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		tokens, ok, err := repo.TakeRateLimitToken(ctx, key, 10, 1e-9)
+		r.NoError(err)
+		r.True(ok)
+		r.InDelta(9, tokens, 0.001)
+
+		allowed, err := concurrently(20, take(key, 10))
+		r.NoError(err)
+		r.Equal(9, allowed)
+
+		tokens, ok, err = repo.TakeRateLimitToken(ctx, key, 10, 1e-9)
+		r.NoError(err)
+		r.False(ok)
+		r.Less(tokens, 1.0)
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		r := require.New(t)
+
+		key := newKey()
+
+		_, ok, err := repo.TakeRateLimitToken(ctx, key, 1, 100)
+		r.NoError(err)
+		r.True(ok)
+
+		time.Sleep(50 * time.Millisecond)
+
+		_, ok, err = repo.TakeRateLimitToken(ctx, key, 1, 100)
+		r.NoError(err)
+		r.True(ok)
+	})
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetLimitTier returns the limits of the tier with the given name.
+func (r *Repository) GetLimitTier(ctx context.Context, name string) (entity.SpendingLimits, error) {
+	sqlQuery := `
+	select per_transaction, daily, weekly, monthly
+	from limit_tiers
+	where name = $1`
+
+	var l entity.SpendingLimits
+
+	err := r.conn(ctx).QueryRow(ctx, sqlQuery, name).Scan(&l.PerTransaction, &l.Daily, &l.Weekly, &l.Monthly)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.SpendingLimits{}, fmt.Errorf("limit tier %q %w", name, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.SpendingLimits{}, fmt.Errorf("failed to get limit tier %q: %w", name, err)
+	}
+
+	return l, nil
+}
+
+// GetUserLimits returns the tier, the overrides and the resulting limits of
+// the user. A user whose limits were never set is in the default tier.
+func (r *Repository) GetUserLimits(ctx context.Context, userID uuid.UUID) (entity.UserLimits, error) {
+	sqlQuery := `
+	select t.name,
+		l.per_transaction, l.daily, l.weekly, l.monthly,
+		coalesce(l.per_transaction, t.per_transaction), coalesce(l.daily, t.daily),
+		coalesce(l.weekly, t.weekly), coalesce(l.monthly, t.monthly),
+		l.updated_at
+	from (select $1::uuid as user_id) u
+	left join spending_limits l on l.user_id = u.user_id
+	join limit_tiers t on t.name = coalesce(l.tier, $2)`
+
+	limits := entity.UserLimits{UserID: userID}
+
+	err := r.conn(ctx).QueryRow(ctx, sqlQuery, userID, entity.DefaultLimitTier).Scan(
+		&limits.Tier,
+		&limits.Overrides.PerTransaction, &limits.Overrides.Daily, &limits.Overrides.Weekly, &limits.Overrides.Monthly,
+		&limits.Limits.PerTransaction, &limits.Limits.Daily, &limits.Limits.Weekly, &limits.Limits.Monthly,
+		&limits.UpdatedAt,
+	)
+	if err != nil {
+		return entity.UserLimits{}, fmt.Errorf("failed to get limits of user with id %s: %w", userID, err)
+	}
+
+	return limits, nil
+}
+
+// SaveUserLimits sets the tier and the overrides of the user.
+func (r *Repository) SaveUserLimits(ctx context.Context, limits entity.UserLimits) error {
+	sqlQuery := `
+	insert into spending_limits (user_id, tier, per_transaction, daily, weekly, monthly)
+	values ($1, $2, $3, $4, $5, $6)
+	on conflict (user_id)
+	do update set tier = excluded.tier,
+		per_transaction = excluded.per_transaction,
+		daily = excluded.daily,
+		weekly = excluded.weekly,
+		monthly = excluded.monthly,
+		updated_at = now()`
+
+	o := limits.Overrides
+
+	_, err := r.conn(ctx).Exec(ctx, sqlQuery, limits.UserID, limits.Tier, o.PerTransaction, o.Daily, o.Weekly, o.Monthly)
+	if err != nil {
+		return fmt.Errorf("failed to save limits of user with id %s: %w", limits.UserID, err)
+	}
+
+	return nil
+}
+
+// GetSpending sums what the user spent since the start of the current day,
+// week and month.
+func (r *Repository) GetSpending(ctx context.Context, userID uuid.UUID, day, week, month time.Time) (entity.Spending, error) {
+	sqlQuery := `
+	select
+		coalesce(sum(amount) filter (where created_at >= $2), 0),
+		coalesce(sum(amount) filter (where created_at >= $3), 0),
+		coalesce(sum(amount) filter (where created_at >= $4), 0)
+	from spendings
+	where user_id = $1 and created_at >= least($2::timestamptz, $3::timestamptz, $4::timestamptz)`
+
+	var s entity.Spending
+
+	err := r.conn(ctx).QueryRow(ctx, sqlQuery, userID, day, week, month).Scan(&s.Daily, &s.Weekly, &s.Monthly)
+	if err != nil {
+		return entity.Spending{}, fmt.Errorf("failed to get spending of user with id %s: %w", userID, err)
+	}
+
+	return s, nil
+}
+
+func (r *Repository) CreateSpend(ctx context.Context, spend entity.Spend) error {
+	sqlQuery := `
+	insert into spendings (operation_id, user_id, kind, amount, created_at)
+	values ($1, $2, $3, $4, $5)`
+
+	_, err := r.conn(ctx).Exec(ctx, sqlQuery, spend.OperationID, spend.UserID, spend.Kind, spend.Amount, spend.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record spend of operation %s: %w", spend.OperationID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func (r *Repository) CreateOutboxEvent(ctx context.Context, event entity.Event) error {
+	sqlQuery := `
+	insert into outbox
+	(id, type, user_id, request_id, payload, occurred_at)
+	values ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.conn(ctx).Exec(ctx, sqlQuery, event.ID, event.Type, event.UserID,
+		event.RequestID, event.Payload, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// ClaimOutboxEntries claims the oldest pending events by moving their next
+// attempt to until, so that no other relay picks them up meanwhile. Only the
+// events before the first one that is not due are claimed, which keeps them
+// in order behind an event waiting to be retried. An event whose outcome is
+// not recorded by until is due again.
+func (r *Repository) ClaimOutboxEntries(ctx context.Context, limit int, until time.Time) ([]entity.OutboxEntry, error) {
+	sqlQuery := `
+	with due as (
+		select seq
+		from outbox
+		where status = 'pending' and seq < coalesce((
+			select min(seq) from outbox where status = 'pending' and next_attempt_at > now()
+		), 9223372036854775807)
+		order by seq
+		limit $1
+		for update skip locked
+	), claimed as (
+		update outbox o
+		set next_attempt_at = $2
+		from due
+		where o.seq = due.seq
+		returning o.seq, o.id, o.type, o.user_id, o.request_id, o.payload, o.occurred_at,
+			o.status, o.attempts, o.next_attempt_at, o.last_error
+	)
+	select id, type, user_id, request_id, payload, occurred_at, status, attempts, next_attempt_at, last_error
+	from claimed
+	order by seq`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, limit, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.OutboxEntry, 0, limit)
+
+	for rows.Next() {
+		var (
+			entry entity.OutboxEntry
+			e     = &entry.Event
+		)
+
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &e.RequestID, &e.Payload, &e.OccurredAt,
+			&entry.Status, &entry.Attempts, &entry.NextAttemptAt, &entry.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *Repository) MarkOutboxEventsPublished(ctx context.Context, ids []uuid.UUID) error {
+	sqlQuery := `update outbox set status = 'published', published_at = now() where id = any($1)`
+
+	if _, err := r.conn(ctx).Exec(ctx, sqlQuery, ids); err != nil {
+		return fmt.Errorf("failed to mark outbox events as published: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseOutboxEvents makes claimed events that were not attempted due again.
+func (r *Repository) ReleaseOutboxEvents(ctx context.Context, ids []uuid.UUID) error {
+	sqlQuery := `update outbox set next_attempt_at = now() where id = any($1) and status = 'pending'`
+
+	if _, err := r.conn(ctx).Exec(ctx, sqlQuery, ids); err != nil {
+		return fmt.Errorf("failed to release outbox events: %w", err)
+	}
+
+	return nil
+}
+
+// RecordOutboxFailure stores the state of an event after a failed attempt.
+func (r *Repository) RecordOutboxFailure(ctx context.Context, entry entity.OutboxEntry) error {
+	sqlQuery := `update outbox set status = $2, attempts = $3, next_attempt_at = $4, last_error = $5 where id = $1`
+
+	_, err := r.conn(ctx).Exec(ctx, sqlQuery, entry.Event.ID, entry.Status, entry.Attempts,
+		entry.NextAttemptAt, entry.LastError)
+	if err != nil {
+		return fmt.Errorf("failed to record failure of outbox event %s: %w", entry.Event.ID, err)
+	}
+
+	return nil
+}
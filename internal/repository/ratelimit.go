@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TakeRateLimitToken refills the bucket under key for the time since it was
+// last used and takes a token from it. A missing bucket is created full; an
+// existing one is locked, so that concurrent requests of every replica wait
+// for each other, and the database clock keeps the refill independent of
+// theirs.
+func (r *Repository) TakeRateLimitToken(ctx context.Context, key string, capacity, perSecond float64) (float64, bool, error) {
+	createQuery := `
+	insert into rate_limit_buckets (key, tokens, updated_at)
+	values ($1, greatest($2::float8 - 1, 0), now())
+	on conflict (key) do nothing
+	returning tokens, $2::float8 >= 1`
+
+	takeQuery := `
+	with refill as (
+		select key, least($2::float8, tokens + extract(epoch from greatest(now() - updated_at, interval '0'))::float8 * $3::float8) as tokens
+		from rate_limit_buckets where key = $1 for update
+	)
+	update rate_limit_buckets b
+	set tokens = case when refill.tokens >= 1 then refill.tokens - 1 else refill.tokens end, updated_at = now()
+	from refill
+	where b.key = refill.key
+	returning b.tokens, refill.tokens >= 1`
+
+	var (
+		tokens  float64
+		allowed bool
+	)
+
+	// The bucket may be purged between the two statements, in which case it
+	// is created again.
+	for range 3 {
+		err := r.conn(ctx).QueryRow(ctx, createQuery, key, capacity).Scan(&tokens, &allowed)
+		if err == nil {
+			return tokens, allowed, nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, fmt.Errorf("failed to create rate limit bucket %s: %w", key, err)
+		}
+
+		err = r.conn(ctx).QueryRow(ctx, takeQuery, key, capacity, perSecond).Scan(&tokens, &allowed)
+		if err == nil {
+			return tokens, allowed, nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, fmt.Errorf("failed to take token from rate limit bucket %s: %w", key, err)
+		}
+	}
+
+	return 0, false, fmt.Errorf("failed to take token from rate limit bucket %s: too much contention", key)
+}
+
+// PurgeRateLimitBuckets removes the buckets unused for idle.
+func (r *Repository) PurgeRateLimitBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	sqlQuery := `
+	delete from rate_limit_buckets
+	where updated_at < now() - make_interval(secs => $1)`
+
+	result, err := r.conn(ctx).Exec(ctx, sqlQuery, idle.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge rate limit buckets: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -4,15 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 	"users-app/internal/entity"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	uniqueViolationCode = "23505"
+	checkViolationCode  = "23514"
+)
+
 type Repository struct {
 	pool *pgxpool.Pool
 }
@@ -23,75 +31,425 @@ func New(pool *pgxpool.Pool) *Repository {
 	}
 }
 
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+type txKey struct{}
+
+// WithinTx runs fn in a database transaction. Repository calls made with the
+// context passed to fn join that transaction; nested calls reuse the outer one.
+func (r *Repository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return r.pool
+}
+
+// userColumns is the column list scanned by scanUser.
+const userColumns = "id, name, email, age, balance, held_balance, version, deleted_at"
+
+func scanUser(row pgx.Row) (entity.User, error) {
+	var user entity.User
+
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Age, &user.Balance, &user.HeldBalance,
+		&user.Version, &user.DeletedAt)
+
+	return user, err
+}
+
 func (r *Repository) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	return r.getUser(ctx, id, false)
+}
+
+// GetUserByIDWithDeleted also returns soft-deleted users.
+func (r *Repository) GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	return r.getUser(ctx, id, true)
+}
+
+func (r *Repository) getUser(ctx context.Context, id uuid.UUID, withDeleted bool) (entity.User, error) {
 	sqlQuery := `
-	select id, name, email, age, balance
+	select ` + userColumns + `
 	from users
-	where id = $1`
+	where id = $1 and ($2 or deleted_at is null)`
 
-	var user entity.User
+	user, err := scanUser(r.conn(ctx).QueryRow(ctx, sqlQuery, id, withDeleted))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, fmt.Errorf("user with id %s %w", id, entity.ErrNotFound)
+		}
+
+		return entity.User{}, fmt.Errorf("failed to get user with id %s: %w", id, err)
+	}
+
+	return user, nil
+}
+
+// GetUsersByIDs returns the users with the given ids in a single query, in no
+// particular order. Ids without a user are skipped.
+func (r *Repository) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.User, error) {
+	sqlQuery := `
+	select ` + userColumns + `
+	from users
+	where id = any($1) and deleted_at is null`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0, len(ids))
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		users = append(users, user)
+	}
 
-	if err := r.pool.QueryRow(ctx, sqlQuery, id).
-		Scan(&user.ID, &user.Name, &user.Email, &user.Age, &user.Balance); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
 
+	return users, nil
+}
+
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	sqlQuery := `
+	select ` + userColumns + `
+	from users
+	where email = $1 and deleted_at is null`
+
+	user, err := scanUser(r.conn(ctx).QueryRow(ctx, sqlQuery, email))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return entity.User{}, fmt.Errorf("user with id %s not found", id)
+			return entity.User{}, fmt.Errorf("user with email %s %w", email, entity.ErrNotFound)
 		}
 
-		return entity.User{}, fmt.Errorf("failed to get user with id %s: %w", id, err)
+		return entity.User{}, fmt.Errorf("failed to get user with email %s: %w", email, err)
 	}
 
 	return user, nil
 }
 
 func (r *Repository) CreateUser(ctx context.Context, user entity.User) error {
-	constraintCode := "23505"
-
 	sqlQuery := `
 	insert into users
 	(id, name, email, age, balance)
 	values ($1, $2, $3, $4, $5)`
 
-	_, err := r.pool.Exec(ctx, sqlQuery, user.ID, user.Name, user.Email, user.Age, user.Balance)
+	return r.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := r.conn(ctx).Exec(ctx, sqlQuery, user.ID, user.Name, user.Email, user.Age, user.Balance)
+		if err != nil {
+			var pgErr *pgconn.PgError
+
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+				return fmt.Errorf("user with email %s %w", user.Email, entity.ErrAlreadyExists)
+			}
+
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		return r.recordExternal(ctx, entity.OperationOpening, user.ID, user.Balance)
+	})
+}
+
+// UpdateUser overwrites the user row if its version still equals user.Version
+// and returns the row as stored. A zero user.Version skips the check.
+func (r *Repository) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	sqlQuery := `
+	update users
+	set name = $2, email = $3, age = $4, balance = $5, version = version + 1
+	where id = $1
+	returning version`
+
+	err := r.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := r.GetUserForUpdate(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if user.Version != 0 && user.Version != current.Version {
+			return entity.NewDetailError(fmt.Errorf("user with id %s is at version %d, not %d: %w",
+				user.ID, current.Version, user.Version, entity.ErrConflict),
+				"detail.version_mismatch", map[string]any{"version": current.Version})
+		}
+
+		if err := r.conn(ctx).QueryRow(ctx, sqlQuery, user.ID, user.Name, user.Email, user.Age, user.Balance).
+			Scan(&user.Version); err != nil {
+			var pgErr *pgconn.PgError
+
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+				return fmt.Errorf("user with email %s %w", user.Email, entity.ErrAlreadyExists)
+			}
+
+			return fmt.Errorf("failed to update user with id %s: %w", user.ID, err)
+		}
+
+		return r.recordExternal(ctx, entity.OperationAdjustment, user.ID, user.Balance.Sub(current.Balance))
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// DeleteUser soft-deletes the user. The row is kept, with its ledger history,
+// until PurgeDeletedUsers removes it.
+func (r *Repository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	sqlQuery := `
+	update users
+	set deleted_at = now(), version = version + 1
+	where id = $1 and deleted_at is null`
+
+	result, err := r.conn(ctx).Exec(ctx, sqlQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user with id %s: %w", id, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with id %s %w", id, entity.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (r *Repository) RestoreUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	sqlQuery := `
+	update users
+	set deleted_at = null, version = version + 1
+	where id = $1 and deleted_at is not null
+	returning ` + userColumns
+
+	user, err := scanUser(r.conn(ctx).QueryRow(ctx, sqlQuery, id))
 	if err != nil {
 		var pgErr *pgconn.PgError
 
-		if errors.As(err, &pgErr) && pgErr.Code == constraintCode {
-			return fmt.Errorf("user with email %s %w", user.Email, entity.ErrAlreadyExists)
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return entity.User{}, fmt.Errorf("email of user with id %s is taken: %w", id, entity.ErrAlreadyExists)
 		}
 
-		return fmt.Errorf("failed to create user: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, fmt.Errorf("deleted user with id %s %w", id, entity.ErrNotFound)
+		}
+
+		return entity.User{}, fmt.Errorf("failed to restore user with id %s: %w", id, err)
 	}
 
-	return nil
+	return user, nil
+}
+
+// PurgeDeletedUsers permanently removes users soft-deleted before the cutoff.
+// Holds they still have are marked released, since nothing could settle them
+// once their user is gone.
+func (r *Repository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	sqlQuery := `
+	with purged as (
+		delete from users
+		where deleted_at < $1
+		returning id
+	), released as (
+		update holds
+		set status = 'released', settled_at = now()
+		where status = 'active' and user_id in (select id from purged)
+	)
+	select count(*) from purged`
+
+	var purged int64
+
+	if err := r.conn(ctx).QueryRow(ctx, sqlQuery, deletedBefore).Scan(&purged); err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+
+	return purged, nil
+}
+
+func (r *Repository) ListUsers(ctx context.Context, q entity.UserQuery) ([]entity.User, error) {
+	sortColumns := map[entity.SortField]string{
+		entity.SortByName:    "name",
+		entity.SortByEmail:   "email",
+		entity.SortByAge:     "age",
+		entity.SortByBalance: "balance",
+	}
+
+	sortTypes := map[entity.SortField]string{
+		entity.SortByName:    "varchar",
+		entity.SortByEmail:   "varchar",
+		entity.SortByAge:     "int",
+		entity.SortByBalance: "decimal",
+	}
+
+	column, ok := sortColumns[q.Sort.Field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", q.Sort.Field)
+	}
+
+	var (
+		conds []string
+		args  []any
+	)
+
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !q.Filter.IncludeDeleted {
+		conds = append(conds, "deleted_at is null")
+	}
+
+	if q.Filter.NamePrefix != "" {
+		conds = append(conds, "name like "+arg(escapeLike(q.Filter.NamePrefix))+" || '%'")
+	}
+
+	if q.Filter.EmailDomain != "" {
+		conds = append(conds, "lower(split_part(email, '@', 2)) = lower("+arg(q.Filter.EmailDomain)+")")
+	}
+
+	if q.Filter.MinAge != nil {
+		conds = append(conds, "age >= "+arg(*q.Filter.MinAge))
+	}
+
+	if q.Filter.MaxAge != nil {
+		conds = append(conds, "age <= "+arg(*q.Filter.MaxAge))
+	}
+
+	if q.Filter.MinBalance != nil {
+		conds = append(conds, "balance >= "+arg(*q.Filter.MinBalance))
+	}
+
+	if q.Filter.MaxBalance != nil {
+		conds = append(conds, "balance <= "+arg(*q.Filter.MaxBalance))
+	}
+
+	order, cmp := "asc", ">"
+	if q.Sort.Desc {
+		order, cmp = "desc", "<"
+	}
+
+	if q.After != nil {
+		conds = append(conds, fmt.Sprintf("(%s, id) %s (%s::%s, %s)",
+			column, cmp, arg(q.After.Key), sortTypes[q.Sort.Field], arg(q.After.ID)))
+	}
+
+	sqlQuery := `
+	select ` + userColumns + `
+	from users`
+
+	if len(conds) > 0 {
+		sqlQuery += "\n\twhere " + strings.Join(conds, " and ")
+	}
+
+	sqlQuery += fmt.Sprintf("\n\torder by %s %s, id %s\n\tlimit %s", column, order, order, arg(q.Limit))
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0, q.Limit)
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *Repository) GetUserForUpdate(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	sqlQuery := `
+	select ` + userColumns + `
+	from users
+	where id = $1 and deleted_at is null
+	for update`
+
+	user, err := scanUser(r.conn(ctx).QueryRow(ctx, sqlQuery, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, fmt.Errorf("user with id %s %w", id, entity.ErrNotFound)
+		}
+
+		return entity.User{}, fmt.Errorf("failed to lock user with id %s: %w", id, err)
+	}
+
+	return user, nil
 }
 
-func (r *Repository) UpdateUser(ctx context.Context, user entity.User) error {
+func (r *Repository) UpdateBalance(ctx context.Context, id uuid.UUID, balance decimal.Decimal) error {
 	sqlQuery := `
 	update users
-	set name = $2, email = $3, age = $4, balance = $5
+	set balance = $2, version = version + 1
 	where id = $1`
 
-	result, err := r.pool.Exec(ctx, sqlQuery, user.ID, user.Name, user.Email, user.Age, user.Balance)
+	result, err := r.conn(ctx).Exec(ctx, sqlQuery, id, balance)
 	if err != nil {
-		return fmt.Errorf("failed to update user with id %s: %w", user.ID, err)
+		return balanceErr(id, err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("user with id %s %w", user.ID, entity.ErrNotFound)
+		return fmt.Errorf("user with id %s %w", id, entity.ErrNotFound)
 	}
 
 	return nil
 }
 
-func (r *Repository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+// UpdateBalances sets both the available and the held balance of the user.
+func (r *Repository) UpdateBalances(ctx context.Context, id uuid.UUID, balance, held decimal.Decimal) error {
 	sqlQuery := `
-	delete from users
+	update users
+	set balance = $2, held_balance = $3, version = version + 1
 	where id = $1`
 
-	result, err := r.pool.Exec(ctx, sqlQuery, id)
+	result, err := r.conn(ctx).Exec(ctx, sqlQuery, id, balance, held)
 	if err != nil {
-		return fmt.Errorf("failed to delete user with id %s: %w", id, err)
+		return balanceErr(id, err)
 	}
 
 	if result.RowsAffected() == 0 {
@@ -100,3 +458,64 @@ func (r *Repository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// balanceErr reports a balance driven below zero, which the service should
+// have refused, as insufficient funds.
+func balanceErr(id uuid.UUID, err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == checkViolationCode {
+		return fmt.Errorf("balance of user with id %s would go negative: %w", id, entity.ErrInsufficientFunds)
+	}
+
+	return fmt.Errorf("failed to update balance of user with id %s: %w", id, err)
+}
+
+func (r *Repository) CreateLedgerEntries(ctx context.Context, entries []entity.LedgerEntry) error {
+	sqlQuery := `
+	insert into ledger_entries
+	(operation_id, kind, account_id, direction, amount, currency, rate)
+	values ($1, $2, $3, $4, $5, nullif($6, ''), $7)`
+
+	batch := &pgx.Batch{}
+
+	for _, e := range entries {
+		batch.Queue(sqlQuery, e.OperationID, e.Kind, e.AccountID, e.Direction, e.Amount, e.Currency, e.Rate)
+	}
+
+	br := r.conn(ctx).SendBatch(ctx, batch)
+	defer br.Close()
+
+	for range entries {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("failed to create ledger entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recordExternal books a balance change of a user against the external
+// account, so that balances set directly stay derivable from the ledger.
+func (r *Repository) recordExternal(ctx context.Context, kind entity.OperationKind, userID uuid.UUID, delta decimal.Decimal) error {
+	if delta.IsZero() {
+		return nil
+	}
+
+	operationID, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("failed to generate operation id: %w", err)
+	}
+
+	from, to := entity.ExternalAccountID, userID
+	if delta.IsNegative() {
+		from, to = to, from
+	}
+
+	return r.CreateLedgerEntries(ctx, entity.Transfer{
+		ID:         operationID,
+		FromUserID: from,
+		ToUserID:   to,
+		Amount:     delta.Abs(),
+	}.Entries(kind))
+}
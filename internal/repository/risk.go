@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+const reviewColumns = `id, kind, user_id, counterparty_id, amount, currency, to_currency, value, expires_at, rule,
+	reason, status, operation_id, decided_by_kind, decided_by_id, created_at, decided_at`
+
+func scanReview(row pgx.Row) (entity.RiskReview, error) {
+	var (
+		r                  entity.RiskReview
+		actorKind, actorID *string
+	)
+
+	err := row.Scan(&r.ID, &r.Kind, &r.UserID, &r.CounterpartyID, &r.Amount, &r.Currency, &r.ToCurrency, &r.Value,
+		&r.ExpiresAt, &r.Rule, &r.Reason, &r.Status, &r.OperationID, &actorKind, &actorID, &r.CreatedAt, &r.DecidedAt)
+	if err != nil {
+		return entity.RiskReview{}, err
+	}
+
+	if actorKind != nil && actorID != nil {
+		r.DecidedBy = &entity.Actor{Kind: entity.ActorKind(*actorKind), ID: *actorID}
+	}
+
+	return r, nil
+}
+
+// CreateRiskDecision logs the verdict of the risk engine on an operation.
+func (r *Repository) CreateRiskDecision(ctx context.Context, d entity.RiskDecision) error {
+	sqlQuery := `
+	insert into risk_decisions
+	(kind, user_id, counterparty_id, value, verdict, rule, reason, review_id)
+	values ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	op := d.Operation
+
+	_, err := r.conn(ctx).Exec(ctx, sqlQuery, op.Kind, op.UserID, op.CounterpartyID, op.Value,
+		d.Verdict, d.Rule, d.Reason, d.ReviewID)
+	if err != nil {
+		return fmt.Errorf("failed to log risk decision for user with id %s: %w", op.UserID, err)
+	}
+
+	return nil
+}
+
+// CountRiskOperations counts the operations of kind the user attempted since
+// the given time, whatever their verdict.
+func (r *Repository) CountRiskOperations(ctx context.Context, userID uuid.UUID, kind entity.OperationKind,
+	since time.Time) (int, error) {
+	sqlQuery := `
+	select count(*)
+	from risk_decisions
+	where user_id = $1 and kind = $2 and created_at >= $3`
+
+	var count int
+
+	if err := r.conn(ctx).QueryRow(ctx, sqlQuery, userID, kind, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s operations of user with id %s: %w", kind, userID, err)
+	}
+
+	return count, nil
+}
+
+// ListRiskCounterparties returns the users the user attempted to transfer
+// money to since the given time.
+func (r *Repository) ListRiskCounterparties(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	sqlQuery := `
+	select distinct counterparty_id
+	from risk_decisions
+	where user_id = $1 and counterparty_id is not null and created_at >= $2`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list counterparties of user with id %s: %w", userID, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list counterparties of user with id %s: %w", userID, err)
+	}
+
+	return ids, nil
+}
+
+// GetUserCreatedAt returns when the user was created, as recorded by the
+// audit log. Users created before the audit log existed are not found.
+func (r *Repository) GetUserCreatedAt(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	sqlQuery := `
+	select min(created_at)
+	from audit_log
+	where user_id = $1 and action = $2`
+
+	var createdAt *time.Time
+
+	if err := r.conn(ctx).QueryRow(ctx, sqlQuery, userID, entity.AuditCreated).Scan(&createdAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get creation time of user with id %s: %w", userID, err)
+	}
+
+	if createdAt == nil {
+		return time.Time{}, fmt.Errorf("creation time of user with id %s %w", userID, entity.ErrNotFound)
+	}
+
+	return *createdAt, nil
+}
+
+func (r *Repository) CreateRiskReview(ctx context.Context, review entity.RiskReview) (entity.RiskReview, error) {
+	sqlQuery := `
+	insert into risk_reviews
+	(kind, user_id, counterparty_id, amount, currency, to_currency, value, expires_at, rule, reason, status)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	returning ` + reviewColumns
+
+	created, err := scanReview(r.conn(ctx).QueryRow(ctx, sqlQuery, review.Kind, review.UserID, review.CounterpartyID,
+		review.Amount, review.Currency, review.ToCurrency, review.Value, review.ExpiresAt, review.Rule, review.Reason,
+		review.Status))
+	if err != nil {
+		return entity.RiskReview{}, fmt.Errorf("failed to create risk review: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *Repository) GetRiskReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	return r.getRiskReview(ctx, id, "")
+}
+
+// GetRiskReviewForUpdate locks the review until the transaction ends, so that
+// it is decided once.
+func (r *Repository) GetRiskReviewForUpdate(ctx context.Context, id int64) (entity.RiskReview, error) {
+	return r.getRiskReview(ctx, id, "for update")
+}
+
+func (r *Repository) getRiskReview(ctx context.Context, id int64, lock string) (entity.RiskReview, error) {
+	sqlQuery := `select ` + reviewColumns + ` from risk_reviews where id = $1 ` + lock
+
+	review, err := scanReview(r.conn(ctx).QueryRow(ctx, sqlQuery, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.RiskReview{}, fmt.Errorf("risk review with id %d %w", id, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.RiskReview{}, fmt.Errorf("failed to get risk review with id %d: %w", id, err)
+	}
+
+	return review, nil
+}
+
+// ListRiskReviews returns the newest reviews older than beforeID, only those
+// in status unless it is empty; a zero beforeID starts from the latest review.
+func (r *Repository) ListRiskReviews(ctx context.Context, status entity.ReviewStatus, beforeID int64,
+	limit int) ([]entity.RiskReview, error) {
+	sqlQuery := `
+	select ` + reviewColumns + `
+	from risk_reviews
+	where ($1::text = '' or status = $1) and ($2::bigint = 0 or id < $2)
+	order by id desc
+	limit $3`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, status, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list risk reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]entity.RiskReview, 0, limit)
+
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan risk review: %w", err)
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list risk reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// DecideRiskReview records the status the review was decided with, who
+// decided it and the operation approving it applied.
+func (r *Repository) DecideRiskReview(ctx context.Context, review entity.RiskReview) (entity.RiskReview, error) {
+	sqlQuery := `
+	update risk_reviews
+	set status = $2, operation_id = $3, decided_by_kind = $4, decided_by_id = $5, decided_at = now()
+	where id = $1
+	returning ` + reviewColumns
+
+	var actorKind, actorID *string
+
+	if review.DecidedBy != nil {
+		kind := string(review.DecidedBy.Kind)
+		actorKind, actorID = &kind, &review.DecidedBy.ID
+	}
+
+	decided, err := scanReview(r.conn(ctx).QueryRow(ctx, sqlQuery, review.ID, review.Status, review.OperationID,
+		actorKind, actorID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.RiskReview{}, fmt.Errorf("risk review with id %d %w", review.ID, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.RiskReview{}, fmt.Errorf("failed to decide risk review with id %d: %w", review.ID, err)
+	}
+
+	return decided, nil
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+const webhookColumns = `id, url, events, active, created_at, updated_at`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+func scanWebhook(row pgx.Row) (entity.WebhookSubscription, error) {
+	var sub entity.WebhookSubscription
+
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Events, &sub.Active, &sub.CreatedAt, &sub.UpdatedAt)
+
+	return sub, err
+}
+
+func scanDelivery(row pgx.Row) (entity.WebhookDelivery, error) {
+	var d entity.WebhookDelivery
+
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+
+	return d, err
+}
+
+func (r *Repository) CreateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	sqlQuery := `
+	insert into webhook_subscriptions
+	(id, url, events, active, secret)
+	values ($1, $2, $3, $4, $5)
+	returning ` + webhookColumns
+
+	created, err := scanWebhook(r.conn(ctx).QueryRow(ctx, sqlQuery, sub.ID, sub.URL, sub.Events, sub.Active, sub.Secret))
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *Repository) GetWebhook(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error) {
+	sqlQuery := `select ` + webhookColumns + ` from webhook_subscriptions where id = $1`
+
+	sub, err := scanWebhook(r.conn(ctx).QueryRow(ctx, sqlQuery, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.WebhookSubscription{}, fmt.Errorf("webhook with id %s %w", id, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to get webhook with id %s: %w", id, err)
+	}
+
+	return sub, nil
+}
+
+func (r *Repository) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	sqlQuery := `select ` + webhookColumns + ` from webhook_subscriptions order by created_at, id`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]entity.WebhookSubscription, 0)
+
+	for rows.Next() {
+		sub, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (r *Repository) UpdateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	sqlQuery := `
+	update webhook_subscriptions
+	set url = $2, events = $3, active = $4, updated_at = now()
+	where id = $1
+	returning ` + webhookColumns
+
+	updated, err := scanWebhook(r.conn(ctx).QueryRow(ctx, sqlQuery, sub.ID, sub.URL, sub.Events, sub.Active))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.WebhookSubscription{}, fmt.Errorf("webhook with id %s %w", sub.ID, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to update webhook with id %s: %w", sub.ID, err)
+	}
+
+	return updated, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	sqlQuery := `delete from webhook_subscriptions where id = $1`
+
+	tag, err := r.conn(ctx).Exec(ctx, sqlQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook with id %s: %w", id, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("webhook with id %s %w", id, entity.ErrNotFound)
+	}
+
+	return nil
+}
+
+// CreateWebhookDeliveries queues event for every active subscription that
+// wants it.
+func (r *Repository) CreateWebhookDeliveries(ctx context.Context, event entity.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %s: %w", event.ID, err)
+	}
+
+	sqlQuery := `
+	insert into webhook_deliveries
+	(subscription_id, event_id, event_type, payload)
+	select id, $1, $2::text, $3
+	from webhook_subscriptions
+	where active and (cardinality(events) = 0 or $2::text = any(events))`
+
+	if _, err := r.conn(ctx).Exec(ctx, sqlQuery, event.ID, string(event.Type), payload); err != nil {
+		return fmt.Errorf("failed to create webhook deliveries for event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// ClaimWebhookJobs claims pending deliveries whose next attempt is due by
+// moving their next attempt to until, so that no other dispatcher picks them
+// up meanwhile. A delivery whose outcome is not recorded by then is due again.
+func (r *Repository) ClaimWebhookJobs(ctx context.Context, limit int, until time.Time) ([]entity.WebhookJob, error) {
+	sqlQuery := `
+	with due as (
+		select id
+		from webhook_deliveries
+		where status = 'pending' and next_attempt_at <= now()
+		order by next_attempt_at, id
+		limit $1
+		for update skip locked
+	)
+	update webhook_deliveries d
+	set next_attempt_at = $2
+	from due, webhook_subscriptions s
+	where d.id = due.id and s.id = d.subscription_id
+	returning d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at,
+		s.url, s.secret`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, limit, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.WebhookJob, 0, limit)
+
+	for rows.Next() {
+		var (
+			job entity.WebhookJob
+			d   = &job.Delivery
+		)
+
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+			&job.URL, &job.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *Repository) GetWebhookDelivery(ctx context.Context, subscriptionID uuid.UUID, id int64) (entity.WebhookDelivery, error) {
+	sqlQuery := `select ` + deliveryColumns + ` from webhook_deliveries where subscription_id = $1 and id = $2`
+
+	d, err := scanDelivery(r.conn(ctx).QueryRow(ctx, sqlQuery, subscriptionID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.WebhookDelivery{}, fmt.Errorf("webhook delivery with id %d %w", id, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.WebhookDelivery{}, fmt.Errorf("failed to get webhook delivery with id %d: %w", id, err)
+	}
+
+	return d, nil
+}
+
+// ListWebhookDeliveries returns the newest deliveries of a subscription older
+// than beforeID; a zero beforeID starts from the latest delivery.
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, beforeID int64, limit int) ([]entity.WebhookDelivery, error) {
+	sqlQuery := `
+	select ` + deliveryColumns + `
+	from webhook_deliveries
+	where subscription_id = $1 and ($2::bigint = 0 or id < $2)
+	order by id desc
+	limit $3`
+
+	rows, err := r.conn(ctx).Query(ctx, sqlQuery, subscriptionID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries of webhook with id %s: %w", subscriptionID, err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.WebhookDelivery, 0, limit)
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list deliveries of webhook with id %s: %w", subscriptionID, err)
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery stores the state of a delivery after an attempt or a
+// manual redelivery.
+func (r *Repository) UpdateWebhookDelivery(ctx context.Context, d entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	sqlQuery := `
+	update webhook_deliveries
+	set status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+	where id = $1
+	returning ` + deliveryColumns
+
+	updated, err := scanDelivery(r.conn(ctx).QueryRow(ctx, sqlQuery, d.ID, d.Status, d.Attempts,
+		d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.WebhookDelivery{}, fmt.Errorf("webhook delivery with id %d %w", d.ID, entity.ErrNotFound)
+	}
+
+	if err != nil {
+		return entity.WebhookDelivery{}, fmt.Errorf("failed to update webhook delivery with id %d: %w", d.ID, err)
+	}
+
+	return updated, nil
+}
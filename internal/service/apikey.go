@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// An API key reads ua_<prefix>_<secret>. The prefix is stored in clear to
+// find the key; the whole key is only stored as a SHA-256 hash, which is
+// enough for a random secret of 256 bits. Keys issued with the former 4-byte
+// prefix still authenticate.
+const (
+	apiKeyScheme            = "ua_"
+	apiKeyPrefixBytes       = 8
+	legacyAPIKeyPrefixBytes = 4
+	apiKeySecretBytes       = 32
+
+	// apiKeyTouchInterval is how often the use of a key is recorded at most,
+	// so that a busy client does not turn every request into a write.
+	apiKeyTouchInterval = time.Minute
+)
+
+// CreateAPIKey issues a key with the name, scopes and expiry of key. The
+// caller must hold every scope it grants. The returned key is the only copy
+// of the full key.
+func (s *Service) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	if err := s.authorize(ctx, entity.PermAPIKeysManage); err != nil {
+		return entity.APIKey{}, err
+	}
+
+	if err := validateAPIKey(key, time.Now()); err != nil {
+		return entity.APIKey{}, err
+	}
+
+	for _, scope := range key.Scopes {
+		if err := s.authorize(ctx, scope); err != nil {
+			return entity.APIKey{}, fmt.Errorf("cannot grant %s: %w", scope, err)
+		}
+	}
+
+	key.CreatedBy = entity.ActorFromContext(ctx)
+
+	// The prefix of another key may come up, however unlikely; the key is
+	// then generated anew.
+	for range 3 {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return entity.APIKey{}, fmt.Errorf("failed to generate api key id: %w", err)
+		}
+
+		prefix, plain, err := newAPIKey()
+		if err != nil {
+			return entity.APIKey{}, err
+		}
+
+		hash := sha256.Sum256([]byte(plain))
+
+		key.ID = id
+		key.Prefix = prefix
+		key.Hash = hash[:]
+
+		created, err := s.userRepo.CreateAPIKey(ctx, key)
+		if errors.Is(err, entity.ErrAlreadyExists) {
+			continue
+		}
+
+		if err != nil {
+			return entity.APIKey{}, err
+		}
+
+		created.Key = plain
+
+		return created, nil
+	}
+
+	return entity.APIKey{}, errors.New("failed to generate api key: prefixes keep colliding")
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	if err := s.authorize(ctx, entity.PermAPIKeysManage); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey stops the key from authenticating. The key is kept so that
+// the requests made with it stay attributable.
+func (s *Service) RevokeAPIKey(ctx context.Context, id uuid.UUID) (entity.APIKey, error) {
+	if err := s.authorize(ctx, entity.PermAPIKeysManage); err != nil {
+		return entity.APIKey{}, err
+	}
+
+	return s.userRepo.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey returns the key plain stands for and records its use.
+// Every failure matches entity.ErrUnauthenticated, whatever the reason.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, plain string) (entity.APIKey, error) {
+	prefix, ok := apiKeyPrefix(plain)
+	if !ok {
+		return entity.APIKey{}, fmt.Errorf("malformed api key: %w", entity.ErrUnauthenticated)
+	}
+
+	key, err := s.userRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, entity.ErrNotFound) {
+		return entity.APIKey{}, fmt.Errorf("unknown api key %s: %w", prefix, entity.ErrUnauthenticated)
+	}
+
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+
+	hash := sha256.Sum256([]byte(plain))
+	if subtle.ConstantTimeCompare(hash[:], key.Hash) != 1 {
+		return entity.APIKey{}, fmt.Errorf("wrong secret for api key %s: %w", prefix, entity.ErrUnauthenticated)
+	}
+
+	now := time.Now()
+
+	if !key.Usable(now) {
+		return entity.APIKey{}, fmt.Errorf("api key %s is revoked or expired: %w", prefix, entity.ErrUnauthenticated)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.userRepo.TouchAPIKey(ctx, key.ID); err != nil {
+			return entity.APIKey{}, err
+		}
+	}
+
+	return key, nil
+}
+
+func newAPIKey() (prefix, plain string, err error) {
+	b := make([]byte, apiKeyPrefixBytes+apiKeySecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	prefix = apiKeyScheme + hex.EncodeToString(b[:apiKeyPrefixBytes])
+
+	return prefix, prefix + "_" + hex.EncodeToString(b[apiKeyPrefixBytes:]), nil
+}
+
+func apiKeyPrefix(plain string) (string, bool) {
+	if !strings.HasPrefix(plain, apiKeyScheme) {
+		return "", false
+	}
+
+	for _, n := range []int{apiKeyPrefixBytes, legacyAPIKeyPrefixBytes} {
+		prefixLen := len(apiKeyScheme) + 2*n
+		if len(plain) == prefixLen+1+2*apiKeySecretBytes && plain[prefixLen] == '_' {
+			return plain[:prefixLen], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,317 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/policy"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	admin := callerContext("admin-1", "admin")
+	support := callerContext("support-1", "support")
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		key         entity.APIKey
+		expectedErr error
+	}{
+		{
+			name: "admin issues a key",
+			ctx:  admin,
+			key:  entity.APIKey{Name: "export", Scopes: []entity.Permission{entity.PermUsersRead}},
+		},
+		{
+			name:        "support may not manage keys",
+			ctx:         support,
+			key:         entity.APIKey{Name: "export", Scopes: []entity.Permission{entity.PermUsersRead}},
+			expectedErr: entity.ErrForbidden,
+		},
+		{
+			name:        "no scopes",
+			ctx:         admin,
+			key:         entity.APIKey{Name: "export"},
+			expectedErr: entity.ErrValidation,
+		},
+		{
+			name:        "self scope",
+			ctx:         admin,
+			key:         entity.APIKey{Name: "export", Scopes: []entity.Permission{entity.PermUsersReadSelf}},
+			expectedErr: entity.ErrValidation,
+		},
+		{
+			name:        "unknown scope",
+			ctx:         admin,
+			key:         entity.APIKey{Name: "export", Scopes: []entity.Permission{"users:everything"}},
+			expectedErr: entity.ErrValidation,
+		},
+		{
+			name:        "expired",
+			ctx:         admin,
+			key:         entity.APIKey{Name: "export", Scopes: []entity.Permission{entity.PermUsersRead}, ExpiresAt: &past},
+			expectedErr: entity.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().CreateAPIKey(tt.ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, key entity.APIKey) (entity.APIKey, error) {
+						r.Empty(key.Key)
+						r.Equal(entity.Actor{Kind: entity.ActorUser, ID: "admin-1"}, key.CreatedBy)
+
+						return key, nil
+					},
+				)
+			}
+
+			created, err := svc.CreateAPIKey(tt.ctx, tt.key)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+			r.True(strings.HasPrefix(created.Key, created.Prefix+"_"))
+
+			hash := sha256.Sum256([]byte(created.Key))
+			r.Equal(hash[:], created.Hash)
+		})
+	}
+}
+
+// TestService_CreateAPIKey_PrefixCollision checks that a key whose prefix is
+// taken is issued again rather than failing.
+func TestService_CreateAPIKey_PrefixCollision(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	ctx := callerContext("admin-1", "admin")
+
+	var taken string
+
+	gomock.InOrder(
+		mockRepo.EXPECT().CreateAPIKey(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, key entity.APIKey) (entity.APIKey, error) {
+				taken = key.Prefix
+				return entity.APIKey{}, entity.ErrAlreadyExists
+			}),
+		mockRepo.EXPECT().CreateAPIKey(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, key entity.APIKey) (entity.APIKey, error) { return key, nil }),
+	)
+
+	created, err := svc.CreateAPIKey(ctx, entity.APIKey{Name: "export", Scopes: []entity.Permission{entity.PermUsersRead}})
+	r.NoError(err)
+	r.NotEqual(taken, created.Prefix)
+	r.Len(created.Prefix, len("ua_")+16)
+}
+
+// TestService_CreateAPIKey_Escalation checks that a key cannot grant more
+// than its creator holds.
+func TestService_CreateAPIKey_Escalation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	parent := entity.APIKey{ID: uuid.Must(uuid.NewV4()),
+		Scopes: []entity.Permission{entity.PermAPIKeysManage, entity.PermUsersRead}}
+	ctx := entity.WithClaims(entity.WithActor(context.Background(), parent.Actor()), parent.Claims())
+
+	_, err := svc.CreateAPIKey(ctx, entity.APIKey{Name: "child", Scopes: []entity.Permission{entity.PermUsersDelete}})
+	require.ErrorIs(t, err, entity.ErrForbidden)
+
+	mockRepo.EXPECT().CreateAPIKey(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, key entity.APIKey) (entity.APIKey, error) { return key, nil })
+
+	_, err = svc.CreateAPIKey(ctx, entity.APIKey{Name: "child", Scopes: []entity.Permission{entity.PermUsersRead}})
+	require.NoError(t, err)
+}
+
+func TestService_AuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	ctx := context.Background()
+
+	mockRepo.EXPECT().CreateAPIKey(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, key entity.APIKey) (entity.APIKey, error) { return key, nil })
+
+	issued, err := svc.CreateAPIKey(ctx, entity.APIKey{Name: "export", Scopes: []entity.Permission{entity.PermUsersRead}})
+	require.NoError(t, err)
+
+	plain := issued.Key
+	stored := issued
+	stored.Key = ""
+
+	past := time.Now().Add(-time.Minute)
+
+	revoked := stored
+	revoked.RevokedAt = &past
+
+	expired := stored
+	expired.ExpiresAt = &past
+
+	justNow := time.Now().Add(-time.Second)
+
+	recentlyUsed := stored
+	recentlyUsed.LastUsedAt = &justNow
+
+	longAgo := time.Now().Add(-time.Hour)
+
+	usedLongAgo := stored
+	usedLongAgo.LastUsedAt = &longAgo
+
+	// a key issued with the former 4-byte prefix
+	legacyPlain := "ua_0a1b2c3d_" + strings.Repeat("ab", 32)
+	legacyHash := sha256.Sum256([]byte(legacyPlain))
+
+	legacy := stored
+	legacy.Prefix = "ua_0a1b2c3d"
+	legacy.Hash = legacyHash[:]
+
+	tests := []struct {
+		name         string
+		key          string
+		mockBehavior func()
+		wantErr      bool
+	}{
+		{
+			name: "valid",
+			key:  plain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+				mockRepo.EXPECT().TouchAPIKey(ctx, stored.ID).Return(nil)
+			},
+		},
+		{
+			name: "recently used",
+			key:  plain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(recentlyUsed, nil)
+			},
+		},
+		{
+			name: "used long ago",
+			key:  plain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(usedLongAgo, nil)
+				mockRepo.EXPECT().TouchAPIKey(ctx, stored.ID).Return(nil)
+			},
+		},
+		{
+			name: "legacy prefix",
+			key:  legacyPlain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, legacy.Prefix).Return(legacy, nil)
+				mockRepo.EXPECT().TouchAPIKey(ctx, stored.ID).Return(nil)
+			},
+		},
+		{
+			name: "wrong secret",
+			key:  stored.Prefix + "_" + strings.Repeat("0", 64),
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "revoked",
+			key:  plain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(revoked, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			key:  plain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(expired, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown",
+			key:  plain,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(entity.APIKey{}, entity.ErrNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name:         "malformed",
+			key:          "not-a-key",
+			mockBehavior: func() {},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			key, err := svc.AuthenticateAPIKey(ctx, tt.key)
+			if tt.wantErr {
+				r.ErrorIs(err, entity.ErrUnauthenticated)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(stored.ID, key.ID)
+		})
+	}
+}
+
+func TestService_AuthorizeAPIKeyScopes(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	key := entity.APIKey{ID: uuid.Must(uuid.NewV4()), Scopes: []entity.Permission{entity.PermUsersRead}}
+	ctx := entity.WithClaims(entity.WithActor(context.Background(), key.Actor()), key.Claims())
+
+	userID := uuid.Must(uuid.NewV4())
+
+	mockRepo.EXPECT().GetUserByID(ctx, userID).Return(entity.User{ID: userID}, nil)
+	_, err := svc.GetUserByID(ctx, userID)
+	r.NoError(err)
+
+	err = svc.DeleteUser(ctx, userID)
+	r.ErrorIs(err, entity.ErrForbidden)
+}
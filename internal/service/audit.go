@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// audit records a change of a user in the transaction carried by ctx.
+func (s *Service) audit(ctx context.Context, action entity.AuditAction, userID uuid.UUID, changes map[string]entity.FieldChange) error {
+	return s.userRepo.CreateAuditRecord(ctx, entity.AuditRecord{
+		UserID:    userID,
+		Action:    action,
+		Actor:     entity.ActorFromContext(ctx),
+		RequestID: entity.RequestIDFromContext(ctx),
+		Changes:   changes,
+	})
+}
+
+// diffUsers compares the JSON form of two users. A nil before or after stands
+// for a user that did not or no longer exists.
+func diffUsers(before, after *entity.User) (map[string]entity.FieldChange, error) {
+	toMap := func(user *entity.User) (map[string]any, error) {
+		if user == nil {
+			return map[string]any{}, nil
+		}
+
+		data, err := json.Marshal(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal user: %w", err)
+		}
+
+		var m map[string]any
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+		}
+
+		return m, nil
+	}
+
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]entity.FieldChange)
+
+	for k, v := range a {
+		if !reflect.DeepEqual(b[k], v) {
+			changes[k] = entity.FieldChange{Before: b[k], After: v}
+		}
+	}
+
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			changes[k] = entity.FieldChange{Before: v}
+		}
+	}
+
+	return changes, nil
+}
+
+// GetUserHistory pages through the audit trail of a user, newest first.
+func (s *Service) GetUserHistory(ctx context.Context, id uuid.UUID, cursor string, limit int) (entity.AuditPage, error) {
+	if err := s.authorizeUser(ctx, entity.PermUsersRead, entity.PermUsersReadSelf, id); err != nil {
+		return entity.AuditPage{}, err
+	}
+
+	beforeID, err := decodeIDCursor(cursor)
+	if err != nil {
+		return entity.AuditPage{}, err
+	}
+
+	records, err := s.userRepo.ListAuditRecords(ctx, id, beforeID, limit+1)
+	if err != nil {
+		return entity.AuditPage{}, err
+	}
+
+	page := entity.AuditPage{Items: records}
+
+	if len(records) > limit {
+		page.Items = records[:limit]
+		page.NextCursor = encodeIDCursor(page.Items[len(page.Items)-1].ID)
+	}
+
+	return page, nil
+}
+
+// encodeIDCursor and decodeIDCursor handle cursors of lists ordered by a
+// serial id, newest first. An empty cursor starts from the newest row.
+func encodeIDCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeIDCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode cursor: %w", entity.ErrInvalidCursor)
+	}
+
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("failed to parse cursor: %w", entity.ErrInvalidCursor)
+	}
+
+	return id, nil
+}
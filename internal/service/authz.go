@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Authorizer decides what the roles of a caller grant. policy.Policy
+// implements it.
+type Authorizer interface {
+	Allows(roles []string, perm entity.Permission) bool
+	// FieldPermission returns the permission needed to write a user field,
+	// given by its JSON name, if it is restricted.
+	FieldPermission(field string) (entity.Permission, bool)
+}
+
+// authorize checks that the caller may perform an operation needing perm.
+// Work the service starts itself, such as the workers', runs as the system
+// actor and is not checked.
+func (s *Service) authorize(ctx context.Context, perm entity.Permission) error {
+	return s.authorizeUser(ctx, perm, "", uuid.Nil)
+}
+
+// authorizeUser checks that the caller may perform an operation needing perm
+// on the user with the given id. selfPerm, when set, grants the same on the
+// caller's own record.
+func (s *Service) authorizeUser(ctx context.Context, perm, selfPerm entity.Permission, id uuid.UUID) error {
+	claims, checked, err := s.caller(ctx)
+	if err != nil || !checked {
+		return err
+	}
+
+	if s.granted(ctx, claims, perm) {
+		return nil
+	}
+
+	if selfPerm != "" && isSelf(claims, id) && s.granted(ctx, claims, selfPerm) {
+		return nil
+	}
+
+	return fmt.Errorf("%s lacks %s: %w", claims.Subject, perm, entity.ErrForbidden)
+}
+
+// authorizeFields checks that the caller may write every restricted field
+// that differs between before and after.
+func (s *Service) authorizeFields(ctx context.Context, before, after *entity.User) error {
+	claims, checked, err := s.caller(ctx)
+	if err != nil || !checked {
+		return err
+	}
+
+	changes, err := diffUsers(before, after)
+	if err != nil {
+		return err
+	}
+
+	for field := range changes {
+		perm, restricted := s.authz.FieldPermission(field)
+		if restricted && !s.granted(ctx, claims, perm) {
+			return fmt.Errorf("%s lacks %s to write %q: %w", claims.Subject, perm, field, entity.ErrForbidden)
+		}
+	}
+
+	return nil
+}
+
+// caller returns the claims of the caller and whether its permissions have to
+// be checked at all.
+func (s *Service) caller(ctx context.Context) (entity.Claims, bool, error) {
+	if s.authz == nil || entity.ActorFromContext(ctx).Kind == entity.ActorSystem {
+		return entity.Claims{}, false, nil
+	}
+
+	claims, ok := entity.ClaimsFromContext(ctx)
+	if !ok {
+		return entity.Claims{}, false, fmt.Errorf("no caller credentials: %w", entity.ErrUnauthenticated)
+	}
+
+	return claims, true, nil
+}
+
+// granted reports whether the caller holds perm. Users get permissions
+// through the roles in their token, API keys through their scopes.
+func (s *Service) granted(ctx context.Context, claims entity.Claims, perm entity.Permission) bool {
+	if entity.ActorFromContext(ctx).Kind == entity.ActorAPIKey {
+		return slices.Contains(claims.Scopes, string(perm))
+	}
+
+	return s.authz.Allows(claims.Roles, perm)
+}
+
+func isSelf(claims entity.Claims, id uuid.UUID) bool {
+	return !id.IsNil() && claims.Subject == id.String()
+}
+
+// AuthorizeUserEvents checks that the caller may watch the changes of the
+// given users, or of every user when ids is empty.
+func (s *Service) AuthorizeUserEvents(ctx context.Context, ids []uuid.UUID) error {
+	claims, checked, err := s.caller(ctx)
+	if err != nil || !checked {
+		return err
+	}
+
+	if s.granted(ctx, claims, entity.PermUsersRead) {
+		return nil
+	}
+
+	if len(ids) > 0 && s.granted(ctx, claims, entity.PermUsersReadSelf) &&
+		!slices.ContainsFunc(ids, func(id uuid.UUID) bool { return !isSelf(claims, id) }) {
+		return nil
+	}
+
+	return fmt.Errorf("%s lacks %s: %w", claims.Subject, entity.PermUsersRead, entity.ErrForbidden)
+}
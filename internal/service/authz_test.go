@@ -0,0 +1,244 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/policy"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// callerContext returns the context of a request authenticated as subject
+// with the given roles.
+func callerContext(subject string, roles ...string) context.Context {
+	ctx := entity.WithActor(context.Background(), entity.Actor{Kind: entity.ActorUser, ID: subject})
+	return entity.WithClaims(ctx, entity.Claims{Subject: subject, Roles: roles})
+}
+
+func TestService_AuthorizeRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	self := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+
+	anonymous := entity.WithActor(context.Background(), entity.Actor{Kind: entity.ActorAnonymous, ID: "127.0.0.1"})
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		id          uuid.UUID
+		expectedErr error
+	}{
+		{"admin reads anyone", callerContext(self.String(), "admin"), other, nil},
+		{"support reads anyone", callerContext(self.String(), "support"), other, nil},
+		{"user reads own record", callerContext(self.String(), "user"), self, nil},
+		{"user reads another user", callerContext(self.String(), "user"), other, entity.ErrForbidden},
+		{"unknown role", callerContext(self.String(), "guest"), self, entity.ErrForbidden},
+		{"no credentials", anonymous, self, entity.ErrUnauthenticated},
+		{"system", context.Background(), other, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().GetUserByID(tt.ctx, tt.id).Return(entity.User{ID: tt.id}, nil)
+			}
+
+			_, err := svc.GetUserByID(tt.ctx, tt.id)
+			r.ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
+func TestService_AuthorizeListAndEmail(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	self := uuid.Must(uuid.NewV4())
+	ctx := callerContext(self.String(), "user")
+
+	_, err := svc.ListUsers(ctx, entity.ListUsersParams{Limit: 10})
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.GetUsersByIDs(ctx, []uuid.UUID{self, uuid.Must(uuid.NewV4())})
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	mockRepo.EXPECT().GetUserByEmail(ctx, "self@example.com").Return(entity.User{ID: self}, nil)
+	user, err := svc.GetUserByEmail(ctx, "self@example.com")
+	r.NoError(err)
+	r.Equal(self, user.ID)
+
+	mockRepo.EXPECT().GetUserByEmail(ctx, "other@example.com").Return(entity.User{ID: uuid.Must(uuid.NewV4())}, nil)
+	_, err = svc.GetUserByEmail(ctx, "other@example.com")
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	// A missing email must not be told apart from someone else's.
+	mockRepo.EXPECT().GetUserByEmail(ctx, "missing@example.com").Return(entity.User{}, entity.ErrNotFound)
+	_, err = svc.GetUserByEmail(ctx, "missing@example.com")
+	r.ErrorIs(err, entity.ErrForbidden)
+}
+
+func TestService_AuthorizeReadDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	self := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		id          uuid.UUID
+		expectedErr error
+	}{
+		{"admin reads deleted users", callerContext(self.String(), "admin"), other, nil},
+		{"support reads deleted users", callerContext(self.String(), "support"), other, entity.ErrForbidden},
+		{"user reads own deleted record", callerContext(self.String(), "user"), self, entity.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().GetUserByIDWithDeleted(tt.ctx, tt.id).Return(entity.User{ID: tt.id}, nil)
+				mockRepo.EXPECT().ListUsers(tt.ctx, gomock.Any()).Return(nil, nil)
+			}
+
+			_, err := svc.GetUserByIDWithDeleted(tt.ctx, tt.id)
+			r.ErrorIs(err, tt.expectedErr)
+
+			_, err = svc.ListUsers(tt.ctx, entity.ListUsersParams{Filter: entity.UserFilter{IncludeDeleted: true}, Limit: 10})
+			r.ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
+func TestService_AuthorizeWrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	self := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+
+	current := entity.User{ID: self, Name: "old", Email: "test@example.com", Balance: decimal.NewFromInt(10)}
+
+	renamed := current
+	renamed.Name = "new"
+
+	rich := current
+	rich.Balance = decimal.NewFromInt(1000)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		user        entity.User
+		locked      bool
+		expectedErr error
+	}{
+		{"user renames self", callerContext(self.String(), "user"), renamed, true, nil},
+		{"user renames another user", callerContext(other.String(), "user"), renamed, false, entity.ErrForbidden},
+		{"user changes own balance", callerContext(self.String(), "user"), rich, true, entity.ErrForbidden},
+		{"support renames anyone", callerContext(other.String(), "support"), renamed, true, nil},
+		{"support changes a balance", callerContext(other.String(), "support"), rich, true, entity.ErrForbidden},
+		{"admin changes a balance", callerContext(other.String(), "admin"), rich, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			if tt.locked {
+				mockRepo.EXPECT().WithinTx(tt.ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, fn func(context.Context) error) error {
+						return fn(ctx)
+					},
+				)
+				mockRepo.EXPECT().GetUserForUpdate(tt.ctx, self).Return(current, nil)
+			}
+
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().UpdateUser(tt.ctx, tt.user).Return(tt.user, nil)
+				mockRepo.EXPECT().CreateAuditRecord(tt.ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateOutboxEvent(tt.ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateWebhookDeliveries(tt.ctx, gomock.Any()).Return(nil)
+			}
+
+			_, err := svc.UpdateUser(tt.ctx, tt.user)
+			r.ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
+func TestService_AuthorizeOperations(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, policy.Default(), nil, "USD")
+
+	self := uuid.Must(uuid.NewV4())
+	support := callerContext(self.String(), "support")
+
+	err := svc.CreateUser(support, entity.User{ID: self, Name: "test", Email: "test@example.com"})
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	err = svc.DeleteUser(support, self)
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.Transfer(support, entity.Transfer{FromUserID: self, ToUserID: uuid.Must(uuid.NewV4()), Amount: decimal.NewFromInt(1)})
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.ListWebhooks(support)
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.Deposit(support, self, decimal.NewFromInt(1))
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.PlaceHold(support, entity.Hold{UserID: self, Amount: decimal.NewFromInt(1)})
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.GetLimits(support, self)
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.SetLimits(support, self, "unlimited", entity.SpendingLimits{})
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.ListReviews(support, entity.ReviewPending, "", 10)
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	_, err = svc.ApproveReview(support, 1)
+	r.ErrorIs(err, entity.ErrForbidden)
+
+	user := callerContext(self.String(), "user")
+
+	r.NoError(svc.AuthorizeUserEvents(user, []uuid.UUID{self}))
+	r.ErrorIs(svc.AuthorizeUserEvents(user, []uuid.UUID{self, uuid.Must(uuid.NewV4())}), entity.ErrForbidden)
+	r.ErrorIs(svc.AuthorizeUserEvents(user, nil), entity.ErrForbidden)
+	r.NoError(svc.AuthorizeUserEvents(support, nil))
+}
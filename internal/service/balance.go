@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// defaultHoldTTL is how long a hold placed without an expiry lasts.
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// Deposit credits amount to the user from outside the system, once screened
+// for risk.
+func (s *Service) Deposit(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error) {
+	if err := s.authorize(ctx, entity.PermDepositsCreate); err != nil {
+		return entity.Movement{}, err
+	}
+
+	return s.move(ctx, entity.OperationDeposit, userID, amount, true)
+}
+
+// Withdraw debits amount from the available balance of the user within its
+// spending limits, once screened for risk. Held money cannot be withdrawn.
+func (s *Service) Withdraw(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error) {
+	if err := s.authorize(ctx, entity.PermWithdrawalsCreate); err != nil {
+		return entity.Movement{}, err
+	}
+
+	return s.move(ctx, entity.OperationWithdrawal, userID, amount, true)
+}
+
+// move moves money between the user and the external account under a lock on
+// the user, screening it for risk first when screened is set.
+func (s *Service) move(ctx context.Context, kind entity.OperationKind, userID uuid.UUID,
+	amount decimal.Decimal, screened bool) (entity.Movement, error) {
+	if err := validateAmount("amount", amount, s.base); err != nil {
+		return entity.Movement{}, err
+	}
+
+	if screened {
+		err := s.screen(ctx, entity.RiskOperation{
+			Kind:     kind,
+			UserID:   userID,
+			Amount:   amount,
+			Currency: s.base.Code,
+			Value:    amount,
+		})
+		if err != nil {
+			return entity.Movement{}, err
+		}
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return entity.Movement{}, fmt.Errorf("failed to generate operation id: %w", err)
+	}
+
+	movement := entity.Movement{
+		ID:        id,
+		UserID:    userID,
+		Kind:      kind,
+		Amount:    amount,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	op := entity.Transfer{ID: id, FromUserID: entity.ExternalAccountID, ToUserID: userID, Amount: amount}
+	if kind == entity.OperationWithdrawal {
+		op.FromUserID, op.ToUserID = userID, entity.ExternalAccountID
+	}
+
+	err = s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		after := user
+		if kind == entity.OperationWithdrawal {
+			if user.Balance.LessThan(amount) {
+				return fmt.Errorf("user with id %s has %s, needs %s: %w",
+					userID, user.Balance, amount, entity.ErrInsufficientFunds)
+			}
+
+			err := s.spend(ctx, entity.Spend{
+				OperationID: id,
+				UserID:      userID,
+				Kind:        kind,
+				Amount:      amount,
+				CreatedAt:   movement.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			after.Balance = user.Balance.Sub(amount)
+		} else {
+			after.Balance = user.Balance.Add(amount)
+		}
+
+		if err := s.changeBalances(ctx, &user, &after); err != nil {
+			return err
+		}
+
+		movement.Balance = after.Balance
+
+		return s.userRepo.CreateLedgerEntries(ctx, op.Entries(kind))
+	})
+	if err != nil {
+		return entity.Movement{}, err
+	}
+
+	return movement, nil
+}
+
+// PlaceHold reserves hold.Amount of the user's available balance until the
+// hold is captured, released or expires. Holds are screened like
+// withdrawals, since a capture takes the money out of the system; captures
+// are not screened again.
+func (s *Service) PlaceHold(ctx context.Context, hold entity.Hold) (entity.Hold, error) {
+	if err := s.authorize(ctx, entity.PermHoldsManage); err != nil {
+		return entity.Hold{}, err
+	}
+
+	return s.placeHold(ctx, hold, true)
+}
+
+func (s *Service) placeHold(ctx context.Context, hold entity.Hold, screened bool) (entity.Hold, error) {
+	now := time.Now().UTC()
+
+	if hold.ExpiresAt.IsZero() {
+		hold.ExpiresAt = now.Add(defaultHoldTTL)
+	}
+
+	if err := validateHold(hold, now, s.base); err != nil {
+		return entity.Hold{}, err
+	}
+
+	if screened {
+		err := s.screen(ctx, entity.RiskOperation{
+			Kind:      entity.OperationHold,
+			UserID:    hold.UserID,
+			Amount:    hold.Amount,
+			Currency:  s.base.Code,
+			Value:     hold.Amount,
+			ExpiresAt: &hold.ExpiresAt,
+		})
+		if err != nil {
+			return entity.Hold{}, err
+		}
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return entity.Hold{}, fmt.Errorf("failed to generate hold id: %w", err)
+	}
+
+	hold.ID = id
+	hold.Status = entity.HoldActive
+
+	var placed entity.Hold
+
+	err = s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserForUpdate(ctx, hold.UserID)
+		if err != nil {
+			return err
+		}
+
+		if user.Balance.LessThan(hold.Amount) {
+			return fmt.Errorf("user with id %s has %s, needs %s: %w",
+				user.ID, user.Balance, hold.Amount, entity.ErrInsufficientFunds)
+		}
+
+		after := user
+		after.Balance = user.Balance.Sub(hold.Amount)
+		after.HeldBalance = user.HeldBalance.Add(hold.Amount)
+
+		if err := s.changeBalances(ctx, &user, &after); err != nil {
+			return err
+		}
+
+		if placed, err = s.userRepo.CreateHold(ctx, hold); err != nil {
+			return err
+		}
+
+		op := entity.Transfer{ID: id, FromUserID: user.ID, ToUserID: hold.ID, Amount: hold.Amount}
+
+		return s.userRepo.CreateLedgerEntries(ctx, op.Entries(entity.OperationHold))
+	})
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	return placed, nil
+}
+
+// GetHold returns a hold of the user. The hold of another user is not found.
+func (s *Service) GetHold(ctx context.Context, userID, holdID uuid.UUID) (entity.Hold, error) {
+	if err := s.authorize(ctx, entity.PermHoldsManage); err != nil {
+		return entity.Hold{}, err
+	}
+
+	hold, err := s.userRepo.GetHold(ctx, holdID)
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	if hold.UserID != userID {
+		return entity.Hold{}, fmt.Errorf("hold with id %s %w", holdID, entity.ErrNotFound)
+	}
+
+	return hold, nil
+}
+
+// CaptureHold takes amount of the hold out of the system and gives the rest
+// back to the user. A zero amount captures the whole hold, and the captured
+// amount counts against the spending limits of the user. An overdue hold
+// cannot be captured even before it is expired.
+func (s *Service) CaptureHold(ctx context.Context, userID, holdID uuid.UUID, amount decimal.Decimal) (entity.Hold, error) {
+	if err := s.authorize(ctx, entity.PermHoldsManage); err != nil {
+		return entity.Hold{}, err
+	}
+
+	if !amount.IsZero() {
+		if err := validateAmount("amount", amount, s.base); err != nil {
+			return entity.Hold{}, err
+		}
+	}
+
+	return s.settleHold(ctx, userID, holdID, entity.HoldCaptured, amount)
+}
+
+// ReleaseHold gives the whole hold back to the user.
+func (s *Service) ReleaseHold(ctx context.Context, userID, holdID uuid.UUID) (entity.Hold, error) {
+	if err := s.authorize(ctx, entity.PermHoldsManage); err != nil {
+		return entity.Hold{}, err
+	}
+
+	return s.settleHold(ctx, userID, holdID, entity.HoldReleased, decimal.Zero)
+}
+
+// ExpireHolds releases up to limit active holds past their expiry and returns
+// how many it expired. Holds settled meanwhile, or whose user was deleted
+// meanwhile, are skipped.
+func (s *Service) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	holds, err := s.userRepo.ListExpiredHolds(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+
+	for _, hold := range holds {
+		_, err := s.settleHold(ctx, hold.UserID, hold.ID, entity.HoldExpired, decimal.Zero)
+		if errors.Is(err, entity.ErrHoldNotActive) || errors.Is(err, entity.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return expired, err
+		}
+
+		expired++
+	}
+
+	return expired, nil
+}
+
+// settleHold ends an active hold with status, capturing amount of it when
+// status is captured. The user is locked before the hold, like everywhere
+// else a hold is touched.
+func (s *Service) settleHold(ctx context.Context, userID, holdID uuid.UUID, status entity.HoldStatus,
+	amount decimal.Decimal) (entity.Hold, error) {
+	var settled entity.Hold
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		settled, err = s.settleLockedHold(ctx, &user, holdID, status, amount)
+
+		return err
+	})
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	return settled, nil
+}
+
+// settleLockedHold is settleHold for a user the caller has locked. It updates
+// user to the balances it leaves behind.
+func (s *Service) settleLockedHold(ctx context.Context, user *entity.User, holdID uuid.UUID,
+	status entity.HoldStatus, amount decimal.Decimal) (entity.Hold, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return entity.Hold{}, fmt.Errorf("failed to generate operation id: %w", err)
+	}
+
+	hold, err := s.userRepo.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	if hold.UserID != user.ID {
+		return entity.Hold{}, fmt.Errorf("hold with id %s %w", holdID, entity.ErrNotFound)
+	}
+
+	if hold.Status != entity.HoldActive {
+		return entity.Hold{}, fmt.Errorf("hold with id %s is %s: %w", holdID, hold.Status, entity.ErrHoldNotActive)
+	}
+
+	overdue := !time.Now().Before(hold.ExpiresAt)
+
+	switch {
+	case status == entity.HoldCaptured && overdue:
+		return entity.Hold{}, fmt.Errorf("hold with id %s expired at %s: %w", holdID, hold.ExpiresAt, entity.ErrHoldNotActive)
+	case status == entity.HoldExpired && !overdue:
+		return entity.Hold{}, fmt.Errorf("hold with id %s expires at %s: %w", holdID, hold.ExpiresAt, entity.ErrHoldNotActive)
+	}
+
+	if status == entity.HoldCaptured && amount.IsZero() {
+		amount = hold.Amount
+	}
+
+	if amount.GreaterThan(hold.Amount) {
+		var v validator
+		v.add("amount", CodeOutOfRange, fmt.Sprintf("amount must be between 0 and %s", hold.Amount),
+			map[string]any{"min": 0, "max": hold.Amount.String()})
+
+		return entity.Hold{}, v.err()
+	}
+
+	// what a capture takes out of the system counts against the limits
+	if status == entity.HoldCaptured {
+		err := s.spend(ctx, entity.Spend{
+			OperationID: id,
+			UserID:      user.ID,
+			Kind:        entity.OperationCapture,
+			Amount:      amount,
+			CreatedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return entity.Hold{}, err
+		}
+	}
+
+	rest := hold.Amount.Sub(amount)
+
+	after := *user
+	after.Balance = user.Balance.Add(rest)
+	after.HeldBalance = user.HeldBalance.Sub(hold.Amount)
+
+	if err := s.changeBalances(ctx, user, &after); err != nil {
+		return entity.Hold{}, err
+	}
+
+	*user = after
+
+	hold.Status = status
+	hold.CapturedAmount = amount
+
+	settled, err := s.userRepo.SettleHold(ctx, hold)
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	var entries []entity.LedgerEntry
+
+	if amount.IsPositive() {
+		op := entity.Transfer{ID: id, FromUserID: hold.ID, ToUserID: entity.ExternalAccountID, Amount: amount}
+		entries = append(entries, op.Entries(entity.OperationCapture)...)
+	}
+
+	if rest.IsPositive() {
+		op := entity.Transfer{ID: id, FromUserID: hold.ID, ToUserID: user.ID, Amount: rest}
+		entries = append(entries, op.Entries(entity.OperationRelease)...)
+	}
+
+	if err := s.userRepo.CreateLedgerEntries(ctx, entries); err != nil {
+		return entity.Hold{}, err
+	}
+
+	return settled, nil
+}
+
+// changeBalances writes the balances of after, locked by the caller, and
+// records the change from before.
+func (s *Service) changeBalances(ctx context.Context, before, after *entity.User) error {
+	if err := s.userRepo.UpdateBalances(ctx, after.ID, after.Balance, after.HeldBalance); err != nil {
+		return err
+	}
+
+	return s.recordChange(ctx, entity.AuditBalanceChanged, after.ID, before, after)
+}
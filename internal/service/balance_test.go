@@ -0,0 +1,401 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// amountEq matches a decimal by value: zeros computed differently are not
+// deeply equal.
+type amountEq decimal.Decimal
+
+func (m amountEq) Matches(x any) bool {
+	d, ok := x.(decimal.Decimal)
+	return ok && d.Equal(decimal.Decimal(m))
+}
+
+func (m amountEq) String() string {
+	return "equals " + decimal.Decimal(m).String()
+}
+
+func withinTx(ctx context.Context, mockRepo *mocks.MockUserRepository) {
+	mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		},
+	)
+}
+
+// expectBalanceChange expects the audit record, event and webhook deliveries
+// of one balance change.
+func expectBalanceChange(ctx context.Context, mockRepo *mocks.MockUserRepository) {
+	mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil)
+}
+
+func TestService_DepositAndWithdraw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(100), HeldBalance: decimal.NewFromInt(30)}
+
+	tests := []struct {
+		name            string
+		move            func(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (entity.Movement, error)
+		amount          decimal.Decimal
+		expectedBalance decimal.Decimal
+		expectedErr     error
+		mockBehavior    func()
+	}{
+		{
+			name:            "Deposit",
+			move:            svc.Deposit,
+			amount:          decimal.NewFromInt(20),
+			expectedBalance: decimal.NewFromInt(120),
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.NewFromInt(120)), amountEq(user.HeldBalance)).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, entries []entity.LedgerEntry) error {
+						require.Len(t, entries, 2)
+						require.Equal(t, entity.ExternalAccountID, entries[0].AccountID)
+						require.Equal(t, user.ID, entries[1].AccountID)
+						require.Equal(t, entity.OperationDeposit, entries[0].Kind)
+
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name:            "Withdraw",
+			move:            svc.Withdraw,
+			amount:          decimal.NewFromInt(100),
+			expectedBalance: decimal.Zero,
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				expectSpend(ctx, mockRepo, user.ID, decimal.NewFromInt(100))
+				mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.Zero), amountEq(user.HeldBalance)).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, entries []entity.LedgerEntry) error {
+						require.Equal(t, user.ID, entries[0].AccountID)
+						require.Equal(t, entity.ExternalAccountID, entries[1].AccountID)
+
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name:        "Withdraw held money",
+			move:        svc.Withdraw,
+			amount:      decimal.NewFromInt(101),
+			expectedErr: entity.ErrInsufficientFunds,
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+			},
+		},
+		{
+			name:         "Non-positive amount",
+			move:         svc.Deposit,
+			amount:       decimal.Zero,
+			expectedErr:  entity.ErrValidation,
+			mockBehavior: func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			movement, err := tt.move(ctx, user.ID, tt.amount)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+			r.True(tt.expectedBalance.Equal(movement.Balance))
+			r.True(tt.amount.Equal(movement.Amount))
+		})
+	}
+}
+
+func TestService_PlaceHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(100)}
+
+	tests := []struct {
+		name         string
+		hold         entity.Hold
+		expectedErr  error
+		mockBehavior func()
+	}{
+		{
+			name: "Place hold",
+			hold: entity.Hold{UserID: user.ID, Amount: decimal.NewFromInt(40)},
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.NewFromInt(60)), amountEq(decimal.NewFromInt(40))).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().CreateHold(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, hold entity.Hold) (entity.Hold, error) {
+						require.Equal(t, entity.HoldActive, hold.Status)
+						require.WithinDuration(t, time.Now().Add(7*24*time.Hour), hold.ExpiresAt, time.Minute)
+
+						return hold, nil
+					},
+				)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:        "Insufficient funds",
+			hold:        entity.Hold{UserID: user.ID, Amount: decimal.NewFromInt(101)},
+			expectedErr: entity.ErrInsufficientFunds,
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+			},
+		},
+		{
+			name:         "Expiry in the past",
+			hold:         entity.Hold{UserID: user.ID, Amount: decimal.NewFromInt(1), ExpiresAt: time.Now().Add(-time.Minute)},
+			expectedErr:  entity.ErrValidation,
+			mockBehavior: func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			hold, err := svc.PlaceHold(ctx, tt.hold)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+			r.False(hold.ID.IsNil())
+		})
+	}
+}
+
+func TestService_SettleHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(60), HeldBalance: decimal.NewFromInt(40)}
+	active := entity.Hold{ID: uuid.Must(uuid.NewV4()), UserID: user.ID, Amount: decimal.NewFromInt(40),
+		Status: entity.HoldActive, ExpiresAt: time.Now().Add(time.Hour)}
+
+	overdue := active
+	overdue.ExpiresAt = time.Now().Add(-time.Minute)
+
+	released := active
+	released.Status = entity.HoldReleased
+
+	lock := func(hold entity.Hold) {
+		withinTx(ctx, mockRepo)
+		mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+		mockRepo.EXPECT().GetHoldForUpdate(ctx, hold.ID).Return(hold, nil)
+	}
+
+	settle := func(balance decimal.Decimal, status entity.HoldStatus, captured decimal.Decimal, entries int) {
+		mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(balance), amountEq(decimal.Zero)).Return(nil)
+		expectBalanceChange(ctx, mockRepo)
+		mockRepo.EXPECT().SettleHold(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, hold entity.Hold) (entity.Hold, error) {
+				require.Equal(t, status, hold.Status)
+				require.True(t, captured.Equal(hold.CapturedAmount))
+
+				return hold, nil
+			},
+		)
+		mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, e []entity.LedgerEntry) error {
+				require.Len(t, e, entries)
+				return nil
+			},
+		)
+	}
+
+	tests := []struct {
+		name         string
+		settle       func() (entity.Hold, error)
+		expectedErr  error
+		mockBehavior func()
+	}{
+		{
+			name: "Capture part",
+			settle: func() (entity.Hold, error) {
+				return svc.CaptureHold(ctx, user.ID, active.ID, decimal.NewFromInt(25))
+			},
+			mockBehavior: func() {
+				lock(active)
+				expectSpend(ctx, mockRepo, user.ID, decimal.NewFromInt(25))
+				settle(decimal.NewFromInt(75), entity.HoldCaptured, decimal.NewFromInt(25), 4)
+			},
+		},
+		{
+			name: "Capture all",
+			settle: func() (entity.Hold, error) {
+				return svc.CaptureHold(ctx, user.ID, active.ID, decimal.Zero)
+			},
+			mockBehavior: func() {
+				lock(active)
+				expectSpend(ctx, mockRepo, user.ID, decimal.NewFromInt(40))
+				settle(decimal.NewFromInt(60), entity.HoldCaptured, decimal.NewFromInt(40), 2)
+			},
+		},
+		{
+			name: "Capture more than held",
+			settle: func() (entity.Hold, error) {
+				return svc.CaptureHold(ctx, user.ID, active.ID, decimal.NewFromInt(41))
+			},
+			expectedErr:  entity.ErrValidation,
+			mockBehavior: func() { lock(active) },
+		},
+		{
+			name: "Capture overdue",
+			settle: func() (entity.Hold, error) {
+				return svc.CaptureHold(ctx, user.ID, overdue.ID, decimal.Zero)
+			},
+			expectedErr:  entity.ErrHoldNotActive,
+			mockBehavior: func() { lock(overdue) },
+		},
+		{
+			name: "Release",
+			settle: func() (entity.Hold, error) {
+				return svc.ReleaseHold(ctx, user.ID, active.ID)
+			},
+			mockBehavior: func() {
+				lock(active)
+				settle(decimal.NewFromInt(100), entity.HoldReleased, decimal.Zero, 2)
+			},
+		},
+		{
+			name: "Release twice",
+			settle: func() (entity.Hold, error) {
+				return svc.ReleaseHold(ctx, user.ID, released.ID)
+			},
+			expectedErr:  entity.ErrHoldNotActive,
+			mockBehavior: func() { lock(released) },
+		},
+		{
+			name: "Hold of another user",
+			settle: func() (entity.Hold, error) {
+				return svc.ReleaseHold(ctx, user.ID, active.ID)
+			},
+			expectedErr: entity.ErrNotFound,
+			mockBehavior: func() {
+				other := active
+				other.UserID = uuid.Must(uuid.NewV4())
+				lock(other)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			_, err := tt.settle()
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+		})
+	}
+}
+
+func TestService_ExpireHolds(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(60), HeldBalance: decimal.NewFromInt(40)}
+	overdue := entity.Hold{ID: uuid.Must(uuid.NewV4()), UserID: user.ID, Amount: decimal.NewFromInt(40),
+		Status: entity.HoldActive, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	// The last hold was captured after it was listed.
+	captured := overdue
+	captured.ID = uuid.Must(uuid.NewV4())
+	captured.Status = entity.HoldCaptured
+
+	// The first hold's user was deleted after it was listed.
+	orphaned := overdue
+	orphaned.ID = uuid.Must(uuid.NewV4())
+	orphaned.UserID = uuid.Must(uuid.NewV4())
+	orphaned.ExpiresAt = time.Now().Add(-time.Hour)
+
+	mockRepo.EXPECT().ListExpiredHolds(ctx, 10).Return([]entity.Hold{orphaned, overdue, captured}, nil)
+
+	withinTx(ctx, mockRepo)
+	mockRepo.EXPECT().GetUserForUpdate(ctx, orphaned.UserID).Return(entity.User{}, entity.ErrNotFound)
+
+	withinTx(ctx, mockRepo)
+	mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().GetHoldForUpdate(ctx, overdue.ID).Return(overdue, nil)
+	mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.NewFromInt(100)), amountEq(decimal.Zero)).Return(nil)
+	expectBalanceChange(ctx, mockRepo)
+	mockRepo.EXPECT().SettleHold(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, hold entity.Hold) (entity.Hold, error) {
+			r.Equal(entity.HoldExpired, hold.Status)
+			return hold, nil
+		},
+	)
+	mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+
+	withinTx(ctx, mockRepo)
+	mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().GetHoldForUpdate(ctx, captured.ID).Return(captured, nil)
+
+	n, err := svc.ExpireHolds(ctx, 10)
+	r.NoError(err)
+	r.Equal(1, n)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// Balances returns the wallets of the user. When currency is set, Total is
+// their available balance converted to it at the current rates.
+func (s *Service) Balances(ctx context.Context, userID uuid.UUID, currency string) (entity.Balances, error) {
+	if err := s.authorizeUser(ctx, entity.PermUsersRead, entity.PermUsersReadSelf, userID); err != nil {
+		return entity.Balances{}, err
+	}
+
+	var (
+		v      validator
+		target entity.Currency
+	)
+
+	if currency != "" {
+		target, _ = v.currency("currency", currency)
+	}
+
+	if err := v.err(); err != nil {
+		return entity.Balances{}, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return entity.Balances{}, err
+	}
+
+	wallets, err := s.userRepo.ListWallets(ctx, userID)
+	if err != nil {
+		return entity.Balances{}, err
+	}
+
+	balances := entity.Balances{
+		UserID: userID,
+		Wallets: append([]entity.Wallet{{
+			Currency:    s.base.Code,
+			Balance:     user.Balance,
+			HeldBalance: user.HeldBalance,
+		}}, wallets...),
+	}
+
+	if currency == "" {
+		return balances, nil
+	}
+
+	now := time.Now().UTC()
+	total := decimal.Zero
+
+	for _, w := range balances.Wallets {
+		rate, err := s.exchangeRate(ctx, w.Currency, currency, now)
+		if err != nil {
+			return entity.Balances{}, err
+		}
+
+		total = total.Add(w.Balance.Mul(rate))
+	}
+
+	balances.Total = &entity.Money{Currency: currency, Amount: target.Round(total)}
+
+	return balances, nil
+}
+
+// ImportExchangeRates stores rates, replacing those of the same pair and
+// effective time. Nothing is stored unless every rate is valid.
+func (s *Service) ImportExchangeRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	for i, rate := range rates {
+		if err := validateExchangeRate(rate); err != nil {
+			return fmt.Errorf("rate %d: %w", i+1, err)
+		}
+	}
+
+	return s.userRepo.SaveExchangeRates(ctx, rates)
+}
+
+// exchangeRate returns what one unit of base is worth in quote at the given
+// time, from the rate of the pair or else from the inverse of the opposite
+// one.
+func (s *Service) exchangeRate(ctx context.Context, base, quote string, at time.Time) (decimal.Decimal, error) {
+	if base == quote {
+		return decimal.NewFromInt(1), nil
+	}
+
+	rate, err := s.userRepo.GetExchangeRate(ctx, base, quote, at)
+	if err == nil {
+		return rate.Rate, nil
+	}
+
+	if !errors.Is(err, entity.ErrNotFound) {
+		return decimal.Zero, err
+	}
+
+	rate, err = s.userRepo.GetExchangeRate(ctx, quote, base, at)
+	if errors.Is(err, entity.ErrNotFound) {
+		return decimal.Zero, fmt.Errorf("%s in %s at %s: %w", base, quote, at.Format(time.RFC3339), entity.ErrNoExchangeRate)
+	}
+
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return decimal.NewFromInt(1).Div(rate.Rate), nil
+}
+
+// changeWallet adds delta to the wallet of the locked user in currency and
+// updates user when that is the base currency.
+func (s *Service) changeWallet(ctx context.Context, user *entity.User, currency string, delta decimal.Decimal) error {
+	if currency == s.base.Code {
+		if user.Balance.Add(delta).IsNegative() {
+			return fmt.Errorf("user with id %s has %s, needs %s: %w",
+				user.ID, user.Balance, delta.Neg(), entity.ErrInsufficientFunds)
+		}
+
+		after := *user
+		after.Balance = user.Balance.Add(delta)
+
+		if err := s.userRepo.UpdateBalance(ctx, user.ID, after.Balance); err != nil {
+			return err
+		}
+
+		if err := s.recordChange(ctx, entity.AuditBalanceChanged, user.ID, user, &after); err != nil {
+			return err
+		}
+
+		*user = after
+
+		return nil
+	}
+
+	wallet, err := s.userRepo.GetWalletForUpdate(ctx, user.ID, currency)
+	if err != nil {
+		return err
+	}
+
+	if wallet.Balance.Add(delta).IsNegative() {
+		return fmt.Errorf("user with id %s has %s %s, needs %s: %w",
+			user.ID, wallet.Balance, currency, delta.Neg(), entity.ErrInsufficientFunds)
+	}
+
+	return s.userRepo.UpdateWalletBalance(ctx, user.ID, currency, wallet.Balance.Add(delta))
+}
+
+// transferEntries books t. Between currencies, the source currency leaves for
+// the external account and the target currency comes from it, each pair
+// balancing in its own currency.
+func (s *Service) transferEntries(t entity.Transfer) []entity.LedgerEntry {
+	if t.Currency == t.ToCurrency {
+		entries := t.Entries(entity.OperationTransfer)
+		for i := range entries {
+			entries[i].Currency = s.ledgerCurrency(t.Currency)
+		}
+
+		return entries
+	}
+
+	out := entity.Transfer{ID: t.ID, FromUserID: t.FromUserID, ToUserID: entity.ExternalAccountID, Amount: t.Amount}
+	in := entity.Transfer{ID: t.ID, FromUserID: entity.ExternalAccountID, ToUserID: t.ToUserID, Amount: t.ToAmount}
+
+	entries := out.Entries(entity.OperationTransfer)
+	for i := range entries {
+		entries[i].Currency = s.ledgerCurrency(t.Currency)
+	}
+
+	for _, e := range in.Entries(entity.OperationTransfer) {
+		e.Currency = s.ledgerCurrency(t.ToCurrency)
+		entries = append(entries, e)
+	}
+
+	for i := range entries {
+		entries[i].Rate = t.Rate
+	}
+
+	return entries
+}
+
+// ledgerCurrency is the currency of a ledger entry, empty for the base one.
+func (s *Service) ledgerCurrency(code string) string {
+	if code == s.base.Code {
+		return ""
+	}
+
+	return code
+}
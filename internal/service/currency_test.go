@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_TransferBetweenCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(100)}
+	eurUSD := entity.ExchangeRate{Base: "EUR", Quote: "USD", Rate: decimal.RequireFromString("1.25")}
+
+	tests := []struct {
+		name             string
+		transfer         entity.Transfer
+		expectedToAmount decimal.Decimal
+		expectedErr      error
+		mockBehavior     func()
+	}{
+		{
+			name: "Base to EUR of the same user",
+			transfer: entity.Transfer{FromUserID: user.ID, ToUserID: user.ID, Amount: decimal.NewFromInt(10),
+				ToCurrency: "EUR"},
+			expectedToAmount: decimal.NewFromInt(8),
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetExchangeRate(ctx, "USD", "EUR", gomock.Any()).
+					Return(entity.ExchangeRate{}, entity.ErrNotFound)
+				mockRepo.EXPECT().GetExchangeRate(ctx, "EUR", "USD", gomock.Any()).Return(eurUSD, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().UpdateBalance(ctx, user.ID, amountEq(decimal.NewFromInt(90))).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().GetWalletForUpdate(ctx, user.ID, "EUR").
+					Return(entity.Wallet{Currency: "EUR", Balance: decimal.NewFromInt(1)}, nil)
+				mockRepo.EXPECT().UpdateWalletBalance(ctx, user.ID, "EUR", amountEq(decimal.NewFromInt(9))).Return(nil)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, entries []entity.LedgerEntry) error {
+						require.Len(t, entries, 4)
+						require.Empty(t, entries[0].Currency)
+						require.Equal(t, entity.ExternalAccountID, entries[1].AccountID)
+						require.Equal(t, "EUR", entries[3].Currency)
+						require.True(t, decimal.NewFromInt(8).Equal(entries[3].Amount))
+						require.True(t, decimal.RequireFromString("0.8").Equal(*entries[3].Rate))
+
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name: "EUR wallet too low",
+			transfer: entity.Transfer{FromUserID: user.ID, ToUserID: user.ID, Amount: decimal.NewFromInt(2),
+				Currency: "EUR", ToCurrency: "USD"},
+			expectedErr: entity.ErrInsufficientFunds,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetExchangeRate(ctx, "EUR", "USD", gomock.Any()).Return(eurUSD, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().GetWalletForUpdate(ctx, user.ID, "EUR").
+					Return(entity.Wallet{Currency: "EUR", Balance: decimal.NewFromInt(1)}, nil)
+			},
+		},
+		{
+			name: "No rate",
+			transfer: entity.Transfer{FromUserID: user.ID, ToUserID: user.ID, Amount: decimal.NewFromInt(1),
+				ToCurrency: "JPY"},
+			expectedErr: entity.ErrNoExchangeRate,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetExchangeRate(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(entity.ExchangeRate{}, entity.ErrNotFound).Times(2)
+			},
+		},
+		{
+			name:         "Finer than the minor unit",
+			transfer:     entity.Transfer{FromUserID: user.ID, ToUserID: uuid.Must(uuid.NewV4()), Amount: decimal.RequireFromString("0.001")},
+			expectedErr:  entity.ErrValidation,
+			mockBehavior: func() {},
+		},
+		{
+			name: "Unknown currency",
+			transfer: entity.Transfer{FromUserID: user.ID, ToUserID: user.ID, Amount: decimal.NewFromInt(1),
+				ToCurrency: "XXX"},
+			expectedErr:  entity.ErrValidation,
+			mockBehavior: func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			transfer, err := svc.Transfer(ctx, tt.transfer)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal("USD", transfer.Currency)
+			r.True(tt.expectedToAmount.Equal(transfer.ToAmount))
+			r.NotNil(transfer.Rate)
+		})
+	}
+}
+
+func TestService_Balances(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(10), HeldBalance: decimal.NewFromInt(5)}
+
+	mockRepo.EXPECT().GetUserByID(ctx, user.ID).Return(user, nil)
+	mockRepo.EXPECT().ListWallets(ctx, user.ID).Return([]entity.Wallet{
+		{Currency: "JPY", Balance: decimal.NewFromInt(1000)},
+	}, nil)
+	mockRepo.EXPECT().GetExchangeRate(ctx, "USD", "JPY", gomock.Any()).
+		Return(entity.ExchangeRate{Rate: decimal.RequireFromString("149.35")}, nil)
+
+	balances, err := svc.Balances(ctx, user.ID, "JPY")
+	r.NoError(err)
+	r.Len(balances.Wallets, 2)
+	r.Equal("USD", balances.Wallets[0].Currency)
+	r.True(user.HeldBalance.Equal(balances.Wallets[0].HeldBalance))
+	r.Equal("JPY", balances.Total.Currency)
+	r.Equal("2494", balances.Total.Amount.String())
+
+	_, err = svc.Balances(ctx, user.ID, "usd")
+	r.ErrorIs(err, entity.ErrValidation)
+}
+
+func TestService_ImportExchangeRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	now := time.Now()
+	valid := entity.ExchangeRate{Base: "EUR", Quote: "USD", Rate: decimal.RequireFromString("1.08"), EffectiveAt: now}
+
+	mockRepo.EXPECT().SaveExchangeRates(ctx, []entity.ExchangeRate{valid}).Return(nil)
+	require.NoError(t, svc.ImportExchangeRates(ctx, []entity.ExchangeRate{valid}))
+
+	for _, rate := range []entity.ExchangeRate{
+		{Base: "EUR", Quote: "EUR", Rate: decimal.NewFromInt(1), EffectiveAt: now},
+		{Base: "EUR", Quote: "XXX", Rate: decimal.NewFromInt(1), EffectiveAt: now},
+		{Base: "EUR", Quote: "USD", Rate: decimal.Zero, EffectiveAt: now},
+		{Base: "EUR", Quote: "USD", Rate: decimal.NewFromInt(1)},
+	} {
+		require.ErrorIs(t, svc.ImportExchangeRates(ctx, []entity.ExchangeRate{valid, rate}), entity.ErrValidation)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var eventTypes = map[entity.AuditAction]entity.EventType{
+	entity.AuditCreated:        entity.EventUserCreated,
+	entity.AuditUpdated:        entity.EventUserUpdated,
+	entity.AuditDeleted:        entity.EventUserDeleted,
+	entity.AuditRestored:       entity.EventUserRestored,
+	entity.AuditBalanceChanged: entity.EventUserBalanceChanged,
+}
+
+// recordChange writes the audit record, the outbox event and the webhook
+// deliveries of a change to user in the transaction carried by ctx, so none
+// of them outlives a rollback.
+func (s *Service) recordChange(ctx context.Context, action entity.AuditAction, userID uuid.UUID, before, after *entity.User) error {
+	changes, err := diffUsers(before, after)
+	if err != nil {
+		return err
+	}
+
+	if err := s.audit(ctx, action, userID, changes); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(entity.UserEventPayload{User: *after, Changes: changes})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("failed to generate event id: %w", err)
+	}
+
+	event := entity.Event{
+		ID:         id,
+		Type:       eventTypes[action],
+		UserID:     userID,
+		RequestID:  entity.RequestIDFromContext(ctx),
+		Payload:    payload,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	if err := s.userRepo.CreateOutboxEvent(ctx, event); err != nil {
+		return err
+	}
+
+	return s.userRepo.CreateWebhookDeliveries(ctx, event)
+}
+
+// PublishEvents hands up to limit pending outbox events to publish in the
+// order they were written and returns how many were published. An event is
+// marked published only after publish succeeds, so delivery is at least once.
+// Publishing stops at the first failure to keep the order of later events;
+// the failed event is retried after the backoff of policy, holding back the
+// later ones, until it runs out of attempts and becomes dead.
+//
+// The events are claimed for lease and published outside any transaction. An
+// event whose outcome is never recorded is due again once its lease runs
+// out; lease should outlast publishing a whole batch.
+func (s *Service) PublishEvents(ctx context.Context, limit int, lease time.Duration, policy entity.RetryPolicy,
+	publish func(ctx context.Context, event entity.Event) error) (int, error) {
+	entries, err := s.userRepo.ClaimOutboxEntries(ctx, limit, time.Now().UTC().Add(lease))
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		published  []uuid.UUID
+		publishErr error
+	)
+
+	for _, entry := range entries {
+		if publishErr = publish(ctx, entry.Event); publishErr != nil {
+			break
+		}
+
+		published = append(published, entry.Event.ID)
+	}
+
+	if len(published) > 0 {
+		if err := s.userRepo.MarkOutboxEventsPublished(ctx, published); err != nil {
+			return 0, err
+		}
+	}
+
+	if publishErr != nil {
+		failed := entries[len(published)]
+		publishErr = fmt.Errorf("failed to publish event %s: %w", failed.Event.ID, publishErr)
+
+		return len(published), s.failOutboxEntry(ctx, failed, entries[len(published)+1:], policy, publishErr)
+	}
+
+	return len(published), nil
+}
+
+// failOutboxEntry records the failure of entry, which is dead once it runs
+// out of attempts, and makes the claimed entries after it due again. It
+// returns publishErr unless recording fails.
+func (s *Service) failOutboxEntry(ctx context.Context, entry entity.OutboxEntry, rest []entity.OutboxEntry,
+	policy entity.RetryPolicy, publishErr error) error {
+	entry.Attempts++
+	entry.LastError = publishErr.Error()
+	entry.NextAttemptAt = time.Now().UTC().Add(policy.Backoff(entry.Attempts))
+
+	if entry.Attempts >= policy.MaxAttempts {
+		entry.Status = entity.OutboxDead
+	}
+
+	if err := s.userRepo.RecordOutboxFailure(ctx, entry); err != nil {
+		return err
+	}
+
+	if len(rest) > 0 {
+		ids := make([]uuid.UUID, 0, len(rest))
+		for _, e := range rest {
+			ids = append(ids, e.Event.ID)
+		}
+
+		if err := s.userRepo.ReleaseOutboxEvents(ctx, ids); err != nil {
+			return err
+		}
+	}
+
+	return publishErr
+}
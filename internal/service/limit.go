@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// GetLimits returns the limits of the user and what is left of them.
+func (s *Service) GetLimits(ctx context.Context, userID uuid.UUID) (entity.UserLimits, error) {
+	if err := s.authorize(ctx, entity.PermLimitsManage); err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	return s.userLimits(ctx, userID, time.Now().UTC())
+}
+
+// SetLimits puts the user in tier, the default one when empty, and replaces
+// the overrides of its limits. A nil override keeps the limit of the tier.
+func (s *Service) SetLimits(ctx context.Context, userID uuid.UUID, tier string,
+	overrides entity.SpendingLimits) (entity.UserLimits, error) {
+	if err := s.authorize(ctx, entity.PermLimitsManage); err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	if tier == "" {
+		tier = entity.DefaultLimitTier
+	}
+
+	var v validator
+
+	v.limits(overrides, s.base)
+
+	_, err := s.userRepo.GetLimitTier(ctx, tier)
+
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		v.add("tier", CodeUnsupported, fmt.Sprintf("tier contains unsupported value %s", tier),
+			map[string]any{"value": tier})
+	case err != nil:
+		return entity.UserLimits{}, err
+	}
+
+	if err := v.err(); err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	var limits entity.UserLimits
+
+	err = s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepo.GetUserForUpdate(ctx, userID); err != nil {
+			return err
+		}
+
+		before, err := s.userRepo.GetUserLimits(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		after := entity.UserLimits{UserID: userID, Tier: tier, Overrides: overrides}
+
+		if err := s.userRepo.SaveUserLimits(ctx, after); err != nil {
+			return err
+		}
+
+		if err := s.audit(ctx, entity.AuditLimitsChanged, userID, diffLimits(before, after)); err != nil {
+			return err
+		}
+
+		limits, err = s.userLimits(ctx, userID, time.Now().UTC())
+
+		return err
+	})
+	if err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	return limits, nil
+}
+
+func (s *Service) userLimits(ctx context.Context, userID uuid.UUID, now time.Time) (entity.UserLimits, error) {
+	limits, err := s.userRepo.GetUserLimits(ctx, userID)
+	if err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	day, week, month := spendingPeriods(now)
+
+	spent, err := s.userRepo.GetSpending(ctx, userID, day, week, month)
+	if err != nil {
+		return entity.UserLimits{}, err
+	}
+
+	limits.Spent = spent
+	limits.Remaining = limits.Limits.Remaining(spent)
+	limits.Currency = s.base.Code
+
+	return limits, nil
+}
+
+// spend counts a debit against the limits of its user and records it, or
+// refuses it with a *entity.LimitError. Callers lock the user first, so that
+// concurrent debits see each other.
+func (s *Service) spend(ctx context.Context, spend entity.Spend) error {
+	limits, err := s.userLimits(ctx, spend.UserID, spend.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := limits.Limits.Check(spend.Amount, limits.Spent, s.base.Code); err != nil {
+		return fmt.Errorf("user with id %s cannot spend %s: %w", spend.UserID, spend.Amount, err)
+	}
+
+	return s.userRepo.CreateSpend(ctx, spend)
+}
+
+// spendingPeriods returns the start of the day, the week and the month of
+// now in UTC. Weeks start on Monday.
+func spendingPeriods(now time.Time) (day, week, month time.Time) {
+	now = now.UTC()
+
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	week = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return day, week, month
+}
+
+// diffLimits lists the changes of the tier and of the overrides.
+func diffLimits(before, after entity.UserLimits) map[string]entity.FieldChange {
+	value := func(d *decimal.Decimal) any {
+		if d == nil {
+			return nil
+		}
+
+		return d.String()
+	}
+
+	fields := []struct {
+		name          string
+		before, after any
+	}{
+		{"tier", before.Tier, after.Tier},
+		{"per_transaction", value(before.Overrides.PerTransaction), value(after.Overrides.PerTransaction)},
+		{"daily", value(before.Overrides.Daily), value(after.Overrides.Daily)},
+		{"weekly", value(before.Overrides.Weekly), value(after.Overrides.Weekly)},
+		{"monthly", value(before.Overrides.Monthly), value(after.Overrides.Monthly)},
+	}
+
+	changes := make(map[string]entity.FieldChange)
+
+	for _, f := range fields {
+		if f.before != f.after {
+			changes[f.name] = entity.FieldChange{Before: f.before, After: f.after}
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,341 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// expectSpend expects a debit of amount by the user to be checked against no
+// limits and recorded.
+func expectSpend(ctx context.Context, mockRepo *mocks.MockUserRepository, userID uuid.UUID, amount decimal.Decimal) {
+	mockRepo.EXPECT().GetUserLimits(ctx, userID).Return(entity.UserLimits{UserID: userID, Tier: "unlimited"}, nil)
+	mockRepo.EXPECT().GetSpending(ctx, userID, gomock.Any(), gomock.Any(), gomock.Any()).Return(entity.Spending{}, nil)
+	mockRepo.EXPECT().CreateSpend(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, spend entity.Spend) error {
+			if spend.UserID != userID || !spend.Amount.Equal(amount) {
+				return errors.New("unexpected spend")
+			}
+
+			return nil
+		},
+	)
+}
+
+func limit(v int64) *decimal.Decimal {
+	d := decimal.NewFromInt(v)
+	return &d
+}
+
+func TestService_SpendingLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(1000)}
+	other := entity.User{ID: uuid.Must(uuid.NewV4())}
+	hold := entity.Hold{ID: uuid.Must(uuid.NewV4()), UserID: user.ID, Amount: decimal.NewFromInt(80),
+		Status: entity.HoldActive, ExpiresAt: time.Now().Add(time.Hour)}
+
+	limits := entity.UserLimits{
+		UserID: user.ID,
+		Tier:   entity.DefaultLimitTier,
+		Limits: entity.SpendingLimits{PerTransaction: limit(100), Daily: limit(300), Monthly: limit(1000)},
+	}
+
+	withdraw := func(amount int64) func() error {
+		return func() error {
+			_, err := svc.Withdraw(ctx, user.ID, decimal.NewFromInt(amount))
+			return err
+		}
+	}
+
+	tests := []struct {
+		name              string
+		spend             func() error
+		spent             entity.Spending
+		expectedLimit     entity.LimitKind
+		expectedRemaining decimal.Decimal
+		mockBehavior      func(spent entity.Spending)
+	}{
+		{
+			name:  "Within limits",
+			spend: withdraw(100),
+			spent: entity.Spending{Daily: decimal.NewFromInt(200), Monthly: decimal.NewFromInt(200)},
+			mockBehavior: func(spent entity.Spending) {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().GetUserLimits(ctx, user.ID).Return(limits, nil)
+				mockRepo.EXPECT().GetSpending(ctx, user.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(spent, nil)
+				mockRepo.EXPECT().CreateSpend(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, spend entity.Spend) error {
+						require.Equal(t, entity.OperationWithdrawal, spend.Kind)
+						require.True(t, spend.Amount.Equal(decimal.NewFromInt(100)))
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().UpdateBalances(ctx, user.ID, gomock.Any(), gomock.Any()).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:              "Per transaction",
+			spend:             withdraw(101),
+			expectedLimit:     entity.LimitPerTransaction,
+			expectedRemaining: decimal.NewFromInt(100),
+			mockBehavior: func(spent entity.Spending) {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().GetUserLimits(ctx, user.ID).Return(limits, nil)
+				mockRepo.EXPECT().GetSpending(ctx, user.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(spent, nil)
+			},
+		},
+		{
+			name:              "Tightest period",
+			spend:             withdraw(50),
+			spent:             entity.Spending{Daily: decimal.NewFromInt(260), Monthly: decimal.NewFromInt(980)},
+			expectedLimit:     entity.LimitMonthly,
+			expectedRemaining: decimal.NewFromInt(20),
+			mockBehavior: func(spent entity.Spending) {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().GetUserLimits(ctx, user.ID).Return(limits, nil)
+				mockRepo.EXPECT().GetSpending(ctx, user.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(spent, nil)
+			},
+		},
+		{
+			name: "Hold capture",
+			spend: func() error {
+				_, err := svc.CaptureHold(ctx, user.ID, hold.ID, decimal.Zero)
+				return err
+			},
+			spent:             entity.Spending{Daily: decimal.NewFromInt(250), Monthly: decimal.NewFromInt(250)},
+			expectedLimit:     entity.LimitDaily,
+			expectedRemaining: decimal.NewFromInt(50),
+			mockBehavior: func(spent entity.Spending) {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().GetHoldForUpdate(ctx, hold.ID).Return(hold, nil)
+				mockRepo.EXPECT().GetUserLimits(ctx, user.ID).Return(limits, nil)
+				mockRepo.EXPECT().GetSpending(ctx, user.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(spent, nil)
+			},
+		},
+		{
+			name: "Transfer in base currency value",
+			spend: func() error {
+				_, err := svc.Transfer(ctx, entity.Transfer{
+					FromUserID: user.ID, ToUserID: other.ID, Amount: decimal.NewFromInt(80), Currency: "EUR",
+				})
+				return err
+			},
+			expectedLimit:     entity.LimitPerTransaction,
+			expectedRemaining: decimal.NewFromInt(100),
+			mockBehavior: func(spent entity.Spending) {
+				mockRepo.EXPECT().GetExchangeRate(ctx, "EUR", "USD", gomock.Any()).
+					Return(entity.ExchangeRate{Base: "EUR", Quote: "USD", Rate: decimal.RequireFromString("1.5")}, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, other.ID).Return(other, nil)
+				mockRepo.EXPECT().GetWalletForUpdate(ctx, user.ID, "EUR").
+					Return(entity.Wallet{Currency: "EUR", Balance: decimal.NewFromInt(500)}, nil)
+				mockRepo.EXPECT().UpdateWalletBalance(ctx, user.ID, "EUR", amountEq(decimal.NewFromInt(420))).Return(nil)
+				mockRepo.EXPECT().GetUserLimits(ctx, user.ID).Return(limits, nil)
+				mockRepo.EXPECT().GetSpending(ctx, user.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(spent, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior(tt.spent)
+
+			err := tt.spend()
+			if tt.expectedLimit == "" {
+				r.NoError(err)
+				return
+			}
+
+			r.ErrorIs(err, entity.ErrLimitExceeded)
+
+			var limitErr *entity.LimitError
+			r.ErrorAs(err, &limitErr)
+			r.Equal(tt.expectedLimit, limitErr.Limit)
+			r.True(tt.expectedRemaining.Equal(limitErr.Remaining), limitErr.Remaining.String())
+			r.Equal("USD", limitErr.Currency)
+		})
+	}
+}
+
+func TestService_GetLimits(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+
+	mockRepo.EXPECT().GetUserByID(ctx, userID).Return(entity.User{ID: userID}, nil)
+	mockRepo.EXPECT().GetUserLimits(ctx, userID).Return(entity.UserLimits{
+		UserID: userID,
+		Tier:   entity.DefaultLimitTier,
+		Limits: entity.SpendingLimits{PerTransaction: limit(100), Daily: limit(300), Weekly: limit(400)},
+	}, nil)
+	mockRepo.EXPECT().GetSpending(ctx, userID, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, day, week, month time.Time) (entity.Spending, error) {
+			r.Equal(time.Monday, week.Weekday())
+			r.False(week.After(day))
+			r.Equal(1, month.Day())
+
+			return entity.Spending{Daily: decimal.NewFromInt(250), Weekly: decimal.NewFromInt(450)}, nil
+		},
+	)
+
+	limits, err := svc.GetLimits(ctx, userID)
+	r.NoError(err)
+	r.Equal("USD", limits.Currency)
+	r.True(limits.Remaining.Daily.Equal(decimal.NewFromInt(50)))
+	r.True(limits.Remaining.Weekly.IsZero())
+	r.Nil(limits.Remaining.Monthly)
+	r.True(limits.Remaining.PerTransaction.IsZero())
+}
+
+func TestService_SetLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	userID := uuid.Must(uuid.NewV4())
+
+	negative := decimal.NewFromInt(-1)
+	precise := decimal.RequireFromString("1.001")
+
+	tests := []struct {
+		name          string
+		tier          string
+		overrides     entity.SpendingLimits
+		expectedErr   error
+		expectedField string
+		mockBehavior  func()
+	}{
+		{
+			name:      "Override",
+			overrides: entity.SpendingLimits{Daily: limit(500)},
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetLimitTier(ctx, entity.DefaultLimitTier).Return(entity.SpendingLimits{}, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, userID).Return(entity.User{ID: userID}, nil)
+				mockRepo.EXPECT().GetUserLimits(ctx, userID).
+					Return(entity.UserLimits{UserID: userID, Tier: "verified"}, nil)
+				mockRepo.EXPECT().SaveUserLimits(ctx, entity.UserLimits{
+					UserID:    userID,
+					Tier:      entity.DefaultLimitTier,
+					Overrides: entity.SpendingLimits{Daily: limit(500)},
+				}).Return(nil)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, record entity.AuditRecord) error {
+						require.Equal(t, entity.AuditLimitsChanged, record.Action)
+						require.Equal(t, map[string]entity.FieldChange{
+							"tier":  {Before: "verified", After: entity.DefaultLimitTier},
+							"daily": {Before: nil, After: "500"},
+						}, record.Changes)
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().GetUserLimits(ctx, userID).Return(entity.UserLimits{
+					UserID:    userID,
+					Tier:      entity.DefaultLimitTier,
+					Overrides: entity.SpendingLimits{Daily: limit(500)},
+					Limits:    entity.SpendingLimits{Daily: limit(500)},
+				}, nil)
+				mockRepo.EXPECT().GetSpending(ctx, userID, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(entity.Spending{}, nil)
+			},
+		},
+		{
+			name:          "Unknown tier",
+			tier:          "gold",
+			expectedErr:   entity.ErrValidation,
+			expectedField: "tier",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetLimitTier(ctx, "gold").Return(entity.SpendingLimits{}, entity.ErrNotFound)
+			},
+		},
+		{
+			name:          "Negative limit",
+			overrides:     entity.SpendingLimits{Weekly: &negative},
+			expectedErr:   entity.ErrValidation,
+			expectedField: "weekly",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetLimitTier(ctx, entity.DefaultLimitTier).Return(entity.SpendingLimits{}, nil)
+			},
+		},
+		{
+			name:          "Too precise limit",
+			overrides:     entity.SpendingLimits{PerTransaction: &precise},
+			expectedErr:   entity.ErrValidation,
+			expectedField: "per_transaction",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetLimitTier(ctx, entity.DefaultLimitTier).Return(entity.SpendingLimits{}, nil)
+			},
+		},
+		{
+			name:        "User not found",
+			tier:        "unlimited",
+			expectedErr: entity.ErrNotFound,
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetLimitTier(ctx, "unlimited").Return(entity.SpendingLimits{}, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, userID).Return(entity.User{}, entity.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			limits, err := svc.SetLimits(ctx, userID, tt.tier, tt.overrides)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+
+				var validationErr *entity.ValidationError
+				if errors.As(err, &validationErr) {
+					r.Len(validationErr.Fields, 1)
+					r.Equal(tt.expectedField, validationErr.Fields[0].Field)
+				}
+
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(entity.DefaultLimitTier, limits.Tier)
+			r.True(limits.Remaining.Daily.Equal(decimal.NewFromInt(500)))
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"users-app/internal/entity"
+)
+
+// immutableUserFields cannot be changed by a patch. The balances only move
+// through ledger operations such as transfers and holds, deletion has its own
+// endpoints.
+var immutableUserFields = []string{"id", "balance", "held_balance", "deleted_at"}
+
+// applyUserPatch applies patch to user and returns the patched copy.
+func applyUserPatch(user entity.User, patch entity.UserPatch) (entity.User, error) {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("failed to marshal user: %w", err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return entity.User{}, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+
+	original, _ := doc.(map[string]any)
+	before := make(map[string]any, len(original))
+
+	for k, v := range original {
+		before[k] = v
+	}
+
+	switch patch.Format {
+	case entity.MergePatch:
+		var p any
+		if err := json.Unmarshal(patch.Document, &p); err != nil {
+			return entity.User{}, entity.NewDetailError(fmt.Errorf("malformed merge patch: %w", entity.ErrInvalidPatch),
+				"detail.patch_malformed", nil)
+		}
+
+		doc = mergePatch(doc, p)
+	case entity.JSONPatch:
+		var ops []patchOperation
+		if err := json.Unmarshal(patch.Document, &ops); err != nil {
+			return entity.User{}, entity.NewDetailError(fmt.Errorf("malformed json patch: %w", entity.ErrInvalidPatch),
+				"detail.patch_malformed", nil)
+		}
+
+		if doc, err = jsonPatch(doc, ops); err != nil {
+			return entity.User{}, err
+		}
+	default:
+		return entity.User{}, entity.NewDetailError(
+			fmt.Errorf("unsupported patch format %q: %w", patch.Format, entity.ErrInvalidPatch),
+			"detail.media_type_unsupported", map[string]any{"type": patch.Format})
+	}
+
+	after, ok := doc.(map[string]any)
+	if !ok {
+		return entity.User{}, entity.NewDetailError(fmt.Errorf("patched user is not an object: %w", entity.ErrInvalidPatch),
+			"detail.patch_not_object", nil)
+	}
+
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			return entity.User{}, entity.NewDetailError(fmt.Errorf("field %q cannot be removed: %w", field, entity.ErrInvalidPatch),
+				"detail.field_required", map[string]any{"field": field})
+		}
+	}
+
+	for _, field := range immutableUserFields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			return entity.User{}, entity.NewDetailError(fmt.Errorf("field %q is immutable: %w", field, entity.ErrInvalidPatch),
+				"detail.field_immutable", map[string]any{"field": field})
+		}
+	}
+
+	data, err = json.Marshal(after)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("failed to marshal patched user: %w", err)
+	}
+
+	var patched entity.User
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&patched); err != nil {
+		return entity.User{}, patchFieldError(err)
+	}
+
+	patched.Version = user.Version
+	patched.HeldBalance = user.HeldBalance
+
+	return patched, nil
+}
+
+// patchFieldError explains why the patched document is not a user.
+func patchFieldError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return entity.NewDetailError(
+			fmt.Errorf("field %q must be %s: %w", typeErr.Field, typeErr.Type, entity.ErrInvalidPatch),
+			"detail.field_type_mismatch", map[string]any{"field": typeErr.Field, "type": typeErr.Type.String()})
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field, _ = strconv.Unquote(field)
+
+		return entity.NewDetailError(fmt.Errorf("field %q is unknown: %w", field, entity.ErrInvalidPatch),
+			"detail.field_unknown", map[string]any{"field": field})
+	}
+
+	return entity.NewDetailError(fmt.Errorf("%s: %w", strings.TrimPrefix(err.Error(), "json: "), entity.ErrInvalidPatch),
+		"detail.patch_malformed", nil)
+}
+
+// mergePatch implements RFC 7396.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonPatch implements RFC 6902. Operations are applied in order and the
+// whole patch fails if any of them does.
+func jsonPatch(doc any, ops []patchOperation) (any, error) {
+	var err error
+
+	for i, op := range ops {
+		if doc, err = applyOperation(doc, op); err != nil {
+			return nil, entity.NewDetailError(
+				fmt.Errorf("operation %d (%s %s): %w: %w", i, op.Op, op.Path, err, entity.ErrInvalidPatch),
+				"detail.patch_operation_failed", map[string]any{"index": i, "op": op.Op, "path": op.Path})
+		}
+	}
+
+	return doc, nil
+}
+
+func applyOperation(doc any, op patchOperation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func() (any, error) {
+		if op.Value == nil {
+			return nil, fmt.Errorf("value is required")
+		}
+
+		var v any
+		if err := json.Unmarshal(op.Value, &v); err != nil {
+			return nil, fmt.Errorf("invalid value")
+		}
+
+		return v, nil
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, v)
+	case "remove":
+		doc, _, err := pointerRemove(doc, path)
+		return doc, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+
+		if doc, _, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, v)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "move" {
+			if doc, _, err = pointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			v = deepCopy(v)
+		}
+
+		return pointerAdd(doc, path, v)
+	case "test":
+		want, err := value()
+		if err != nil {
+			return nil, err
+		}
+
+		got, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			return nil, fmt.Errorf("test failed")
+		}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation")
+	}
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+func pointerGet(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+
+			doc = v
+		case []any:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	}
+
+	return doc, nil
+}
+
+func pointerAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return doc, nil
+	case []any:
+		i := len(node)
+		if last != "-" {
+			if i, err = arrayIndex(last, len(node)); err != nil {
+				return nil, err
+			}
+		}
+
+		node = append(node[:i:i], append([]any{value}, node[i:]...)...)
+
+		return pointerSet(doc, path[:len(path)-1], node)
+	default:
+		return nil, fmt.Errorf("path not found")
+	}
+}
+
+func pointerRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("path not found")
+		}
+
+		delete(node, last)
+
+		return doc, v, nil
+	case []any:
+		i, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+
+		doc, err = pointerSet(doc, path[:len(path)-1], node)
+
+		return doc, v, err
+	default:
+		return nil, nil, fmt.Errorf("path not found")
+	}
+}
+
+// pointerSet replaces the existing value at path.
+func pointerSet(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+	case []any:
+		i, err := arrayIndex(last, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		node[i] = value
+	default:
+		return nil, fmt.Errorf("path not found")
+	}
+
+	return doc, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	return i, nil
+}
+
+func deepCopy(v any) any {
+	data, _ := json.Marshal(v)
+
+	var c any
+	_ = json.Unmarshal(data, &c)
+
+	return c
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+// RiskHistory is what rules may look up about the past operations of a user.
+// Every screened operation counts, whatever its verdict.
+type RiskHistory interface {
+	CountRiskOperations(ctx context.Context, userID uuid.UUID, kind entity.OperationKind, since time.Time) (int, error)
+	ListRiskCounterparties(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error)
+	GetUserCreatedAt(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+// RiskRule judges balance operations. Evaluate returns VerdictAllow when the
+// rule has nothing against op, and a reason for any other verdict.
+type RiskRule interface {
+	Name() string
+	Evaluate(ctx context.Context, op entity.RiskOperation, history RiskHistory) (entity.RiskVerdict, string, error)
+}
+
+// RiskEngine screens balance operations against its rules. The strictest
+// verdict wins; among the rules reaching it, the first one is recorded.
+type RiskEngine struct {
+	rules []RiskRule
+}
+
+func NewRiskEngine(rules ...RiskRule) *RiskEngine {
+	return &RiskEngine{rules: rules}
+}
+
+// Evaluate runs every rule against op. An operation no rule objects to is
+// allowed.
+func (e *RiskEngine) Evaluate(ctx context.Context, op entity.RiskOperation, history RiskHistory) (entity.RiskDecision, error) {
+	decision := entity.RiskDecision{Operation: op, Verdict: entity.VerdictAllow}
+
+	for _, rule := range e.rules {
+		verdict, reason, err := rule.Evaluate(ctx, op, history)
+		if err != nil {
+			return entity.RiskDecision{}, fmt.Errorf("risk rule %s: %w", rule.Name(), err)
+		}
+
+		if verdict.Stricter(decision.Verdict) {
+			decision.Verdict, decision.Rule, decision.Reason = verdict, rule.Name(), reason
+		}
+	}
+
+	return decision, nil
+}
+
+// AmountRule sends operations worth at least Review to review and denies
+// those worth at least Deny, both in the base currency. A zero threshold does
+// not apply.
+type AmountRule struct {
+	Review decimal.Decimal
+	Deny   decimal.Decimal
+}
+
+func (AmountRule) Name() string { return "amount_threshold" }
+
+func (r AmountRule) Evaluate(_ context.Context, op entity.RiskOperation, _ RiskHistory) (entity.RiskVerdict, string, error) {
+	switch {
+	case r.Deny.IsPositive() && op.Value.GreaterThanOrEqual(r.Deny):
+		return entity.VerdictDeny, fmt.Sprintf("%s worth %s reaches %s", op.Kind, op.Value, r.Deny), nil
+	case r.Review.IsPositive() && op.Value.GreaterThanOrEqual(r.Review):
+		return entity.VerdictReview, fmt.Sprintf("%s worth %s reaches %s", op.Kind, op.Value, r.Review), nil
+	}
+
+	return entity.VerdictAllow, "", nil
+}
+
+// VelocityRule sends a transfer to review when its sender already attempted
+// Max transfers within the last Window. A zero Max does not apply.
+type VelocityRule struct {
+	Window time.Duration
+	Max    int
+}
+
+func (VelocityRule) Name() string { return "rapid_transfers" }
+
+func (r VelocityRule) Evaluate(ctx context.Context, op entity.RiskOperation,
+	history RiskHistory) (entity.RiskVerdict, string, error) {
+	if r.Max <= 0 || op.Kind != entity.OperationTransfer {
+		return entity.VerdictAllow, "", nil
+	}
+
+	count, err := history.CountRiskOperations(ctx, op.UserID, op.Kind, time.Now().Add(-r.Window))
+	if err != nil {
+		return "", "", err
+	}
+
+	if count < r.Max {
+		return entity.VerdictAllow, "", nil
+	}
+
+	return entity.VerdictReview, fmt.Sprintf("%d transfers within %s", count+1, r.Window), nil
+}
+
+// NewAccountRule sends withdrawals and holds worth at least Amount to review
+// when the account is younger than Age. Accounts of unknown age are taken to
+// be old. A zero Age does not apply.
+type NewAccountRule struct {
+	Age    time.Duration
+	Amount decimal.Decimal
+}
+
+func (NewAccountRule) Name() string { return "new_account_withdrawal" }
+
+func (r NewAccountRule) Evaluate(ctx context.Context, op entity.RiskOperation,
+	history RiskHistory) (entity.RiskVerdict, string, error) {
+	payout := op.Kind == entity.OperationWithdrawal || op.Kind == entity.OperationHold
+
+	if r.Age <= 0 || !payout || op.Value.LessThan(r.Amount) {
+		return entity.VerdictAllow, "", nil
+	}
+
+	createdAt, err := history.GetUserCreatedAt(ctx, op.UserID)
+	if errors.Is(err, entity.ErrNotFound) {
+		return entity.VerdictAllow, "", nil
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	age := time.Since(createdAt)
+	if age >= r.Age {
+		return entity.VerdictAllow, "", nil
+	}
+
+	return entity.VerdictReview, fmt.Sprintf("%s worth %s from an account %s old",
+		op.Kind, op.Value, age.Truncate(time.Minute)), nil
+}
+
+// FanOutRule sends a transfer to review when it makes its sender pay more
+// than Max distinct recipients within the last Window. A zero Max does not
+// apply.
+type FanOutRule struct {
+	Window time.Duration
+	Max    int
+}
+
+func (FanOutRule) Name() string { return "recipient_fan_out" }
+
+func (r FanOutRule) Evaluate(ctx context.Context, op entity.RiskOperation,
+	history RiskHistory) (entity.RiskVerdict, string, error) {
+	if r.Max <= 0 || op.Kind != entity.OperationTransfer || op.CounterpartyID == nil {
+		return entity.VerdictAllow, "", nil
+	}
+
+	recipients, err := history.ListRiskCounterparties(ctx, op.UserID, time.Now().Add(-r.Window))
+	if err != nil {
+		return "", "", err
+	}
+
+	if !slices.Contains(recipients, *op.CounterpartyID) {
+		recipients = append(recipients, *op.CounterpartyID)
+	}
+
+	if len(recipients) <= r.Max {
+		return entity.VerdictAllow, "", nil
+	}
+
+	return entity.VerdictReview, fmt.Sprintf("%d recipients within %s", len(recipients), r.Window), nil
+}
+
+// screen runs op through the risk engine and logs the decision. A denied
+// operation fails with entity.ErrOperationDenied, and one sent to review with
+// a *entity.ReviewError once it is queued. Every operation is allowed when
+// the service has no engine.
+//
+// The user is locked while its history is read and the decision logged, so
+// that concurrent operations of the user are judged one after another, each
+// seeing the ones before.
+func (s *Service) screen(ctx context.Context, op entity.RiskOperation) error {
+	if s.risk == nil {
+		return nil
+	}
+
+	// operations on missing users are refused before they are judged
+	if op.CounterpartyID != nil {
+		if _, err := s.userRepo.GetUserByID(ctx, *op.CounterpartyID); err != nil {
+			return err
+		}
+	}
+
+	var (
+		decision entity.RiskDecision
+		review   entity.RiskReview
+	)
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepo.GetUserForUpdate(ctx, op.UserID); err != nil {
+			return err
+		}
+
+		var err error
+
+		decision, err = s.risk.Evaluate(ctx, op, s.userRepo)
+		if err != nil {
+			return err
+		}
+
+		if decision.Verdict == entity.VerdictReview {
+			review, err = s.userRepo.CreateRiskReview(ctx, entity.RiskReview{
+				RiskOperation: op,
+				Rule:          decision.Rule,
+				Reason:        decision.Reason,
+				Status:        entity.ReviewPending,
+			})
+			if err != nil {
+				return err
+			}
+
+			decision.ReviewID = &review.ID
+		}
+
+		return s.userRepo.CreateRiskDecision(ctx, decision)
+	})
+	if err != nil {
+		return err
+	}
+
+	switch decision.Verdict {
+	case entity.VerdictDeny:
+		return fmt.Errorf("%s of user with id %s refused by rule %s: %w",
+			op.Kind, op.UserID, decision.Rule, entity.ErrOperationDenied)
+	case entity.VerdictReview:
+		return &entity.ReviewError{Review: review}
+	}
+
+	return nil
+}
+
+// ListReviews pages through the operations held for review, newest first,
+// only those in status unless it is empty.
+func (s *Service) ListReviews(ctx context.Context, status entity.ReviewStatus, cursor string,
+	limit int) (entity.RiskReviewPage, error) {
+	if err := s.authorize(ctx, entity.PermReviewsManage); err != nil {
+		return entity.RiskReviewPage{}, err
+	}
+
+	if status != "" && !slices.Contains([]entity.ReviewStatus{
+		entity.ReviewPending, entity.ReviewApproved, entity.ReviewRejected,
+	}, status) {
+		var v validator
+
+		v.add("status", CodeUnsupported, fmt.Sprintf("status contains unsupported value %s", status),
+			map[string]any{"value": status})
+
+		return entity.RiskReviewPage{}, v.err()
+	}
+
+	beforeID, err := decodeIDCursor(cursor)
+	if err != nil {
+		return entity.RiskReviewPage{}, err
+	}
+
+	reviews, err := s.userRepo.ListRiskReviews(ctx, status, beforeID, limit+1)
+	if err != nil {
+		return entity.RiskReviewPage{}, err
+	}
+
+	page := entity.RiskReviewPage{Items: reviews}
+
+	if len(reviews) > limit {
+		page.Items = reviews[:limit]
+		page.NextCursor = encodeIDCursor(page.Items[len(page.Items)-1].ID)
+	}
+
+	return page, nil
+}
+
+func (s *Service) GetReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	if err := s.authorize(ctx, entity.PermReviewsManage); err != nil {
+		return entity.RiskReview{}, err
+	}
+
+	return s.userRepo.GetRiskReview(ctx, id)
+}
+
+// ApproveReview applies the operation held by a pending review, without
+// screening it again. The review stays pending when the operation fails, for
+// instance on insufficient funds.
+func (s *Service) ApproveReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	return s.decideReview(ctx, id, entity.ReviewApproved)
+}
+
+// RejectReview drops the operation held by a pending review.
+func (s *Service) RejectReview(ctx context.Context, id int64) (entity.RiskReview, error) {
+	return s.decideReview(ctx, id, entity.ReviewRejected)
+}
+
+func (s *Service) decideReview(ctx context.Context, id int64, status entity.ReviewStatus) (entity.RiskReview, error) {
+	if err := s.authorize(ctx, entity.PermReviewsManage); err != nil {
+		return entity.RiskReview{}, err
+	}
+
+	var decided entity.RiskReview
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		review, err := s.userRepo.GetRiskReviewForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if review.Status != entity.ReviewPending {
+			return fmt.Errorf("review with id %d is %s: %w", id, review.Status, entity.ErrReviewNotPending)
+		}
+
+		if status == entity.ReviewApproved {
+			operationID, err := s.applyReview(ctx, review)
+			if err != nil {
+				return err
+			}
+
+			review.OperationID = &operationID
+		}
+
+		actor := entity.ActorFromContext(ctx)
+
+		review.Status = status
+		review.DecidedBy = &actor
+
+		decided, err = s.userRepo.DecideRiskReview(ctx, review)
+
+		return err
+	})
+	if err != nil {
+		return entity.RiskReview{}, err
+	}
+
+	return decided, nil
+}
+
+// applyReview makes the operation held by review and returns its id.
+func (s *Service) applyReview(ctx context.Context, review entity.RiskReview) (uuid.UUID, error) {
+	switch review.Kind {
+	case entity.OperationDeposit, entity.OperationWithdrawal:
+		movement, err := s.move(ctx, review.Kind, review.UserID, review.Amount, false)
+		return movement.ID, err
+	case entity.OperationHold:
+		hold := entity.Hold{UserID: review.UserID, Amount: review.Amount}
+		if review.ExpiresAt != nil {
+			hold.ExpiresAt = *review.ExpiresAt
+		}
+
+		placed, err := s.placeHold(ctx, hold, false)
+
+		return placed.ID, err
+	case entity.OperationTransfer:
+		if review.CounterpartyID == nil {
+			return uuid.Nil, entity.NewDetailError(
+				fmt.Errorf("review with id %d has no recipient: %w", review.ID, entity.ErrInvalidTransfer),
+				"detail.transfer_no_recipient", nil)
+		}
+
+		t, err := s.transfer(ctx, entity.Transfer{
+			FromUserID: review.UserID,
+			ToUserID:   *review.CounterpartyID,
+			Amount:     review.Amount,
+			Currency:   review.Currency,
+			ToCurrency: review.ToCurrency,
+		}, false)
+
+		return t.ID, err
+	}
+
+	return uuid.Nil, fmt.Errorf("review with id %d holds unknown operation %s", review.ID, review.Kind)
+}
@@ -0,0 +1,417 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRiskEngine_Evaluate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	history := mocks.NewMockUserRepository(ctrl)
+	ctx := context.Background()
+
+	engine := service.NewRiskEngine(
+		service.AmountRule{Review: decimal.NewFromInt(1000), Deny: decimal.NewFromInt(5000)},
+		service.VelocityRule{Window: 10 * time.Minute, Max: 3},
+		service.NewAccountRule{Age: 72 * time.Hour, Amount: decimal.NewFromInt(100)},
+		service.FanOutRule{Window: time.Hour, Max: 2},
+	)
+
+	userID := uuid.Must(uuid.NewV4())
+	known, other, newcomer := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	transfer := func(to uuid.UUID, value int64) entity.RiskOperation {
+		return entity.RiskOperation{Kind: entity.OperationTransfer, UserID: userID, CounterpartyID: &to,
+			Value: decimal.NewFromInt(value)}
+	}
+
+	withdrawal := func(value int64) entity.RiskOperation {
+		return entity.RiskOperation{Kind: entity.OperationWithdrawal, UserID: userID, Value: decimal.NewFromInt(value)}
+	}
+
+	// transferHistory expects the lookups of the transfer rules
+	transferHistory := func(count int, recipients ...uuid.UUID) {
+		history.EXPECT().CountRiskOperations(ctx, userID, entity.OperationTransfer, gomock.Any()).Return(count, nil)
+		history.EXPECT().ListRiskCounterparties(ctx, userID, gomock.Any()).Return(recipients, nil)
+	}
+
+	tests := []struct {
+		name            string
+		op              entity.RiskOperation
+		expectedVerdict entity.RiskVerdict
+		expectedRule    string
+		mockBehavior    func()
+	}{
+		{
+			name:            "Small deposit",
+			op:              entity.RiskOperation{Kind: entity.OperationDeposit, UserID: userID, Value: decimal.NewFromInt(999)},
+			expectedVerdict: entity.VerdictAllow,
+			mockBehavior:    func() {},
+		},
+		{
+			name:            "Large deposit",
+			op:              entity.RiskOperation{Kind: entity.OperationDeposit, UserID: userID, Value: decimal.NewFromInt(1000)},
+			expectedVerdict: entity.VerdictReview,
+			expectedRule:    "amount_threshold",
+			mockBehavior:    func() {},
+		},
+		{
+			name:            "Huge transfer",
+			op:              transfer(known, 5000),
+			expectedVerdict: entity.VerdictDeny,
+			expectedRule:    "amount_threshold",
+			mockBehavior: func() {
+				transferHistory(5, known, other)
+			},
+		},
+		{
+			name:            "Transfer to a known recipient",
+			op:              transfer(known, 10),
+			expectedVerdict: entity.VerdictAllow,
+			mockBehavior: func() {
+				transferHistory(2, known, other)
+			},
+		},
+		{
+			name:            "Rapid transfers",
+			op:              transfer(known, 10),
+			expectedVerdict: entity.VerdictReview,
+			expectedRule:    "rapid_transfers",
+			mockBehavior: func() {
+				transferHistory(3, known)
+			},
+		},
+		{
+			name:            "Transfer fanning out",
+			op:              transfer(newcomer, 10),
+			expectedVerdict: entity.VerdictReview,
+			expectedRule:    "recipient_fan_out",
+			mockBehavior: func() {
+				transferHistory(2, known, other)
+			},
+		},
+		{
+			name:            "Withdrawal from a new account",
+			op:              withdrawal(100),
+			expectedVerdict: entity.VerdictReview,
+			expectedRule:    "new_account_withdrawal",
+			mockBehavior: func() {
+				history.EXPECT().GetUserCreatedAt(ctx, userID).Return(time.Now().Add(-time.Hour), nil)
+			},
+		},
+		{
+			name:            "Withdrawal from an old account",
+			op:              withdrawal(100),
+			expectedVerdict: entity.VerdictAllow,
+			mockBehavior: func() {
+				history.EXPECT().GetUserCreatedAt(ctx, userID).Return(time.Now().Add(-100*time.Hour), nil)
+			},
+		},
+		{
+			name:            "Withdrawal from an account of unknown age",
+			op:              withdrawal(100),
+			expectedVerdict: entity.VerdictAllow,
+			mockBehavior: func() {
+				history.EXPECT().GetUserCreatedAt(ctx, userID).Return(time.Time{}, entity.ErrNotFound)
+			},
+		},
+		{
+			name:            "Small withdrawal from a new account",
+			op:              withdrawal(99),
+			expectedVerdict: entity.VerdictAllow,
+			mockBehavior:    func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			decision, err := engine.Evaluate(ctx, tt.op, history)
+			r.NoError(err)
+			r.Equal(tt.expectedVerdict, decision.Verdict)
+			r.Equal(tt.expectedRule, decision.Rule)
+			r.Equal(tt.op, decision.Operation)
+
+			if tt.expectedVerdict != entity.VerdictAllow {
+				r.NotEmpty(decision.Reason)
+			}
+		})
+	}
+}
+
+func TestService_Screen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	engine := service.NewRiskEngine(service.AmountRule{Review: decimal.NewFromInt(1000), Deny: decimal.NewFromInt(5000)})
+	svc := service.New(mockRepo, nil, engine, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(10000)}
+	other := entity.User{ID: uuid.Must(uuid.NewV4())}
+
+	// expectDecision expects the recipient to be looked up, the user to be
+	// locked and the decision to be logged with the verdict.
+	expectDecision := func(verdict entity.RiskVerdict, to *entity.User) {
+		if to != nil {
+			mockRepo.EXPECT().GetUserByID(ctx, to.ID).Return(*to, nil)
+		}
+
+		withinTx(ctx, mockRepo)
+		mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+		mockRepo.EXPECT().CreateRiskDecision(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, d entity.RiskDecision) error {
+				require.Equal(t, verdict, d.Verdict)
+				require.Equal(t, verdict == entity.VerdictReview, d.ReviewID != nil)
+
+				if verdict != entity.VerdictAllow {
+					require.Equal(t, "amount_threshold", d.Rule)
+				}
+
+				return nil
+			},
+		)
+	}
+
+	t.Run("Allowed deposit is made", func(t *testing.T) {
+		r := require.New(t)
+
+		expectDecision(entity.VerdictAllow, nil)
+		withinTx(ctx, mockRepo)
+		mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+		mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.NewFromInt(10500)), gomock.Any()).Return(nil)
+		expectBalanceChange(ctx, mockRepo)
+		mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+
+		_, err := svc.Deposit(ctx, user.ID, decimal.NewFromInt(500))
+		r.NoError(err)
+	})
+
+	t.Run("Withdrawal is held for review", func(t *testing.T) {
+		r := require.New(t)
+
+		mockRepo.EXPECT().CreateRiskReview(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, review entity.RiskReview) (entity.RiskReview, error) {
+				r.Equal(entity.ReviewPending, review.Status)
+				r.Equal(entity.OperationWithdrawal, review.Kind)
+				r.True(review.Value.Equal(decimal.NewFromInt(2000)))
+
+				review.ID = 3
+
+				return review, nil
+			},
+		)
+		expectDecision(entity.VerdictReview, nil)
+
+		_, err := svc.Withdraw(ctx, user.ID, decimal.NewFromInt(2000))
+		r.ErrorIs(err, entity.ErrPendingReview)
+
+		var reviewErr *entity.ReviewError
+		r.ErrorAs(err, &reviewErr)
+		r.Equal(int64(3), reviewErr.Review.ID)
+	})
+
+	t.Run("Transfer is denied", func(t *testing.T) {
+		r := require.New(t)
+
+		expectDecision(entity.VerdictDeny, &other)
+
+		_, err := svc.Transfer(ctx, entity.Transfer{FromUserID: user.ID, ToUserID: other.ID, Amount: decimal.NewFromInt(5000)})
+		r.ErrorIs(err, entity.ErrOperationDenied)
+	})
+
+	t.Run("Hold is denied", func(t *testing.T) {
+		r := require.New(t)
+
+		expectDecision(entity.VerdictDeny, nil)
+
+		_, err := svc.PlaceHold(ctx, entity.Hold{UserID: user.ID, Amount: decimal.NewFromInt(6000)})
+		r.ErrorIs(err, entity.ErrOperationDenied)
+	})
+
+	t.Run("Missing user is not screened", func(t *testing.T) {
+		r := require.New(t)
+
+		withinTx(ctx, mockRepo)
+		mockRepo.EXPECT().GetUserForUpdate(ctx, other.ID).Return(entity.User{}, entity.ErrNotFound)
+
+		_, err := svc.Deposit(ctx, other.ID, decimal.NewFromInt(2000))
+		r.ErrorIs(err, entity.ErrNotFound)
+	})
+}
+
+func TestService_DecideReview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	engine := service.NewRiskEngine(service.AmountRule{Review: decimal.NewFromInt(1000)})
+	svc := service.New(mockRepo, nil, engine, "USD")
+
+	ctx := context.Background()
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(100)}
+	review := entity.RiskReview{
+		ID: 5,
+		RiskOperation: entity.RiskOperation{Kind: entity.OperationDeposit, UserID: user.ID,
+			Amount: decimal.NewFromInt(2000), Currency: "USD", Value: decimal.NewFromInt(2000)},
+		Rule:   "amount_threshold",
+		Status: entity.ReviewPending,
+	}
+
+	decide := func(status entity.ReviewStatus, applied bool) {
+		mockRepo.EXPECT().DecideRiskReview(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, decided entity.RiskReview) (entity.RiskReview, error) {
+				require.Equal(t, status, decided.Status)
+				require.Equal(t, applied, decided.OperationID != nil)
+				require.Equal(t, entity.SystemActor, *decided.DecidedBy)
+
+				return decided, nil
+			},
+		)
+	}
+
+	tests := []struct {
+		name           string
+		decide         func(ctx context.Context, id int64) (entity.RiskReview, error)
+		expectedStatus entity.ReviewStatus
+		expectedErr    error
+		mockBehavior   func()
+	}{
+		{
+			name:           "Approve applies the operation unscreened",
+			decide:         svc.ApproveReview,
+			expectedStatus: entity.ReviewApproved,
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetRiskReviewForUpdate(ctx, review.ID).Return(review, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.NewFromInt(2100)), gomock.Any()).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+				decide(entity.ReviewApproved, true)
+			},
+		},
+		{
+			name:           "Approve places a held hold",
+			decide:         svc.ApproveReview,
+			expectedStatus: entity.ReviewApproved,
+			mockBehavior: func() {
+				expiresAt := time.Now().Add(time.Hour)
+
+				held := review
+				held.Kind = entity.OperationHold
+				held.Amount = decimal.NewFromInt(60)
+				held.ExpiresAt = &expiresAt
+
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetRiskReviewForUpdate(ctx, review.ID).Return(held, nil)
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(user, nil)
+				mockRepo.EXPECT().UpdateBalances(ctx, user.ID, amountEq(decimal.NewFromInt(40)), gomock.Any()).Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().CreateHold(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, hold entity.Hold) (entity.Hold, error) {
+						require.True(t, hold.ExpiresAt.Equal(expiresAt))
+						return hold, nil
+					},
+				)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+				decide(entity.ReviewApproved, true)
+			},
+		},
+		{
+			name:           "Reject drops the operation",
+			decide:         svc.RejectReview,
+			expectedStatus: entity.ReviewRejected,
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetRiskReviewForUpdate(ctx, review.ID).Return(review, nil)
+				decide(entity.ReviewRejected, false)
+			},
+		},
+		{
+			name:        "Decided review",
+			decide:      svc.ApproveReview,
+			expectedErr: entity.ErrReviewNotPending,
+			mockBehavior: func() {
+				rejected := review
+				rejected.Status = entity.ReviewRejected
+
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetRiskReviewForUpdate(ctx, review.ID).Return(rejected, nil)
+			},
+		},
+		{
+			name:        "Missing review",
+			decide:      svc.RejectReview,
+			expectedErr: entity.ErrNotFound,
+			mockBehavior: func() {
+				withinTx(ctx, mockRepo)
+				mockRepo.EXPECT().GetRiskReviewForUpdate(ctx, review.ID).Return(entity.RiskReview{}, entity.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			tt.mockBehavior()
+
+			decided, err := tt.decide(ctx, review.ID)
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.expectedStatus, decided.Status)
+		})
+	}
+}
+
+func TestService_ListReviews(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	r := require.New(t)
+
+	mockRepo.EXPECT().ListRiskReviews(ctx, entity.ReviewPending, int64(0), 3).
+		Return([]entity.RiskReview{{ID: 9}, {ID: 8}, {ID: 7}}, nil)
+
+	page, err := svc.ListReviews(ctx, entity.ReviewPending, "", 2)
+	r.NoError(err)
+	r.Len(page.Items, 2)
+	r.NotEmpty(page.NextCursor)
+
+	mockRepo.EXPECT().ListRiskReviews(ctx, entity.ReviewStatus(""), int64(8), 3).
+		Return([]entity.RiskReview{{ID: 7}}, nil)
+
+	page, err = svc.ListReviews(ctx, "", page.NextCursor, 2)
+	r.NoError(err)
+	r.Len(page.Items, 1)
+	r.Empty(page.NextCursor)
+
+	_, err = svc.ListReviews(ctx, "stuck", "", 2)
+	r.ErrorIs(err, entity.ErrValidation)
+}
@@ -1,43 +1,568 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
 	"users-app/internal/entity"
+	"users-app/internal/webhook"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
 )
 
 //go:generate go run go.uber.org/mock/mockgen@latest -source=service.go -destination=../mocks/service.go -package=mocks -typed
 
 type UserRepository interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error)
+	GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error)
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
 	CreateUser(ctx context.Context, user entity.User) error
-	UpdateUser(ctx context.Context, user entity.User) error
+	UpdateUser(ctx context.Context, user entity.User) (entity.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) (entity.User, error)
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+	ListUsers(ctx context.Context, q entity.UserQuery) ([]entity.User, error)
+	GetUserForUpdate(ctx context.Context, id uuid.UUID) (entity.User, error)
+	UpdateBalance(ctx context.Context, id uuid.UUID, balance decimal.Decimal) error
+	UpdateBalances(ctx context.Context, id uuid.UUID, balance, held decimal.Decimal) error
+	CreateHold(ctx context.Context, hold entity.Hold) (entity.Hold, error)
+	GetHold(ctx context.Context, id uuid.UUID) (entity.Hold, error)
+	GetHoldForUpdate(ctx context.Context, id uuid.UUID) (entity.Hold, error)
+	SettleHold(ctx context.Context, hold entity.Hold) (entity.Hold, error)
+	ListExpiredHolds(ctx context.Context, limit int) ([]entity.Hold, error)
+	ListActiveHolds(ctx context.Context, userID uuid.UUID) ([]entity.Hold, error)
+	CreateLedgerEntries(ctx context.Context, entries []entity.LedgerEntry) error
+	CreateAuditRecord(ctx context.Context, record entity.AuditRecord) error
+	ListAuditRecords(ctx context.Context, userID uuid.UUID, beforeID int64, limit int) ([]entity.AuditRecord, error)
+	CreateOutboxEvent(ctx context.Context, event entity.Event) error
+	ClaimOutboxEntries(ctx context.Context, limit int, until time.Time) ([]entity.OutboxEntry, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []uuid.UUID) error
+	ReleaseOutboxEvents(ctx context.Context, ids []uuid.UUID) error
+	RecordOutboxFailure(ctx context.Context, entry entity.OutboxEntry) error
+	CreateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	CreateWebhookDeliveries(ctx context.Context, event entity.Event) error
+	ClaimWebhookJobs(ctx context.Context, limit int, until time.Time) ([]entity.WebhookJob, error)
+	GetWebhookDelivery(ctx context.Context, subscriptionID uuid.UUID, id int64) (entity.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, beforeID int64, limit int) ([]entity.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, d entity.WebhookDelivery) (entity.WebhookDelivery, error)
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (entity.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+	ListWallets(ctx context.Context, userID uuid.UUID) ([]entity.Wallet, error)
+	GetWalletForUpdate(ctx context.Context, userID uuid.UUID, currency string) (entity.Wallet, error)
+	UpdateWalletBalance(ctx context.Context, userID uuid.UUID, currency string, balance decimal.Decimal) error
+	SaveExchangeRates(ctx context.Context, rates []entity.ExchangeRate) error
+	GetExchangeRate(ctx context.Context, base, quote string, at time.Time) (entity.ExchangeRate, error)
+	GetLimitTier(ctx context.Context, name string) (entity.SpendingLimits, error)
+	GetUserLimits(ctx context.Context, userID uuid.UUID) (entity.UserLimits, error)
+	SaveUserLimits(ctx context.Context, limits entity.UserLimits) error
+	GetSpending(ctx context.Context, userID uuid.UUID, day, week, month time.Time) (entity.Spending, error)
+	CreateSpend(ctx context.Context, spend entity.Spend) error
+	CreateRiskDecision(ctx context.Context, d entity.RiskDecision) error
+	CountRiskOperations(ctx context.Context, userID uuid.UUID, kind entity.OperationKind, since time.Time) (int, error)
+	ListRiskCounterparties(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error)
+	GetUserCreatedAt(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	CreateRiskReview(ctx context.Context, review entity.RiskReview) (entity.RiskReview, error)
+	GetRiskReview(ctx context.Context, id int64) (entity.RiskReview, error)
+	GetRiskReviewForUpdate(ctx context.Context, id int64) (entity.RiskReview, error)
+	ListRiskReviews(ctx context.Context, status entity.ReviewStatus, beforeID int64, limit int) ([]entity.RiskReview, error)
+	DecideRiskReview(ctx context.Context, review entity.RiskReview) (entity.RiskReview, error)
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Service struct {
 	userRepo UserRepository
+	authz    Authorizer
+	risk     *RiskEngine
+	base     entity.Currency
+	resolver webhook.Resolver
 }
 
-func New(userRepo UserRepository) *Service {
+// New returns the service. Callers are only checked against authz, and
+// balance operations screened by risk, when they are not nil. baseCurrency is
+// the currency of the balance of users, and must be known to
+// entity.LookupCurrency.
+func New(userRepo UserRepository, authz Authorizer, risk *RiskEngine, baseCurrency string) *Service {
+	base, _ := entity.LookupCurrency(baseCurrency)
+
 	return &Service{
 		userRepo: userRepo,
+		authz:    authz,
+		risk:     risk,
+		base:     base,
+		resolver: net.DefaultResolver,
 	}
 }
 
 func (s *Service) GetUserByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	if err := s.authorizeUser(ctx, entity.PermUsersRead, entity.PermUsersReadSelf, id); err != nil {
+		return entity.User{}, err
+	}
+
 	return s.userRepo.GetUserByID(ctx, id)
 }
 
+// GetUserByIDWithDeleted is GetUserByID that also finds soft-deleted users,
+// which takes the users:read:deleted permission as well.
+func (s *Service) GetUserByIDWithDeleted(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	if err := s.authorizeUser(ctx, entity.PermUsersRead, entity.PermUsersReadSelf, id); err != nil {
+		return entity.User{}, err
+	}
+
+	if err := s.authorize(ctx, entity.PermUsersReadDeleted); err != nil {
+		return entity.User{}, err
+	}
+
+	return s.userRepo.GetUserByIDWithDeleted(ctx, id)
+}
+
+func (s *Service) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.User, error) {
+	for _, id := range ids {
+		if err := s.authorizeUser(ctx, entity.PermUsersRead, entity.PermUsersReadSelf, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.userRepo.GetUsersByIDs(ctx, ids)
+}
+
+func (s *Service) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+
+	// Checked after the lookup, since only then is it known whose email it
+	// is. Callers limited to their own record get the same error whether the
+	// email exists or not.
+	if authErr := s.authorizeUser(ctx, entity.PermUsersRead, entity.PermUsersReadSelf, user.ID); authErr != nil {
+		return entity.User{}, authErr
+	}
+
+	return user, err
+}
+
 func (s *Service) CreateUser(ctx context.Context, user entity.User) error {
-	return s.userRepo.CreateUser(ctx, user)
+	if err := s.authorize(ctx, entity.PermUsersCreate); err != nil {
+		return err
+	}
+
+	// Money is only ever held by placing holds.
+	user.HeldBalance = decimal.Decimal{}
+
+	if err := s.authorizeFields(ctx, &entity.User{ID: user.ID}, &user); err != nil {
+		return err
+	}
+
+	if err := validateUser(user); err != nil {
+		return err
+	}
+
+	return s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return s.recordChange(ctx, entity.AuditCreated, user.ID, nil, &user)
+	})
 }
 
-func (s *Service) UpdateUser(ctx context.Context, user entity.User) error {
-	return s.userRepo.UpdateUser(ctx, user)
+func (s *Service) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	if err := s.authorizeUser(ctx, entity.PermUsersUpdate, entity.PermUsersUpdateSelf, user.ID); err != nil {
+		return entity.User{}, err
+	}
+
+	if err := validateUser(user); err != nil {
+		return entity.User{}, err
+	}
+
+	var updated entity.User
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.userRepo.GetUserForUpdate(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		user.HeldBalance = current.HeldBalance
+
+		if err := s.authorizeFields(ctx, &current, &user); err != nil {
+			return err
+		}
+
+		if updated, err = s.userRepo.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return s.recordChange(ctx, entity.AuditUpdated, user.ID, &current, &updated)
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return updated, nil
+}
+
+// PatchUser applies patch to the current state of the user under a row lock,
+// so concurrent patches touching different fields do not overwrite each other.
+func (s *Service) PatchUser(ctx context.Context, id uuid.UUID, patch entity.UserPatch) (entity.User, error) {
+	if err := s.authorizeUser(ctx, entity.PermUsersUpdate, entity.PermUsersUpdateSelf, id); err != nil {
+		return entity.User{}, err
+	}
+
+	var updated entity.User
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.userRepo.GetUserForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if patch.Version != 0 && patch.Version != current.Version {
+			return entity.NewDetailError(fmt.Errorf("user with id %s is at version %d, not %d: %w",
+				id, current.Version, patch.Version, entity.ErrConflict),
+				"detail.version_mismatch", map[string]any{"version": current.Version})
+		}
+
+		patched, err := applyUserPatch(current, patch)
+		if err != nil {
+			return err
+		}
+
+		if err := validateUser(patched); err != nil {
+			return err
+		}
+
+		if err := s.authorizeFields(ctx, &current, &patched); err != nil {
+			return err
+		}
+
+		if updated, err = s.userRepo.UpdateUser(ctx, patched); err != nil {
+			return err
+		}
+
+		return s.recordChange(ctx, entity.AuditUpdated, id, &current, &updated)
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return updated, nil
 }
 
 func (s *Service) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	return s.userRepo.DeleteUser(ctx, id)
+	if err := s.authorize(ctx, entity.PermUsersDelete); err != nil {
+		return err
+	}
+
+	return s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.userRepo.GetUserForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// nothing settles the holds of a deleted user, so they go back now
+		holds, err := s.userRepo.ListActiveHolds(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			if _, err := s.settleLockedHold(ctx, &current, hold.ID, entity.HoldReleased, decimal.Zero); err != nil {
+				return err
+			}
+		}
+
+		if err := s.userRepo.DeleteUser(ctx, id); err != nil {
+			return err
+		}
+
+		deleted, err := s.userRepo.GetUserByIDWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.recordChange(ctx, entity.AuditDeleted, id, &current, &deleted)
+	})
+}
+
+func (s *Service) RestoreUser(ctx context.Context, id uuid.UUID) (entity.User, error) {
+	if err := s.authorize(ctx, entity.PermUsersDelete); err != nil {
+		return entity.User{}, err
+	}
+
+	var restored entity.User
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := s.userRepo.GetUserByIDWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if restored, err = s.userRepo.RestoreUser(ctx, id); err != nil {
+			return err
+		}
+
+		return s.recordChange(ctx, entity.AuditRestored, id, &deleted, &restored)
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return restored, nil
+}
+
+// PurgeDeletedUsers permanently removes users that were soft-deleted more
+// than retention ago and returns how many were removed.
+func (s *Service) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.userRepo.PurgeDeletedUsers(ctx, time.Now().Add(-retention))
+}
+
+func (s *Service) ListUsers(ctx context.Context, params entity.ListUsersParams) (entity.UserPage, error) {
+	if err := s.authorize(ctx, entity.PermUsersRead); err != nil {
+		return entity.UserPage{}, err
+	}
+
+	if params.Filter.IncludeDeleted {
+		if err := s.authorize(ctx, entity.PermUsersReadDeleted); err != nil {
+			return entity.UserPage{}, err
+		}
+	}
+
+	q := entity.UserQuery{
+		Filter: params.Filter,
+		Sort:   params.Sort,
+		Limit:  params.Limit + 1,
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return entity.UserPage{}, err
+		}
+
+		q.After = &cursor
+	}
+
+	users, err := s.userRepo.ListUsers(ctx, q)
+	if err != nil {
+		return entity.UserPage{}, err
+	}
+
+	page := entity.UserPage{Items: users}
+
+	if len(users) > params.Limit {
+		page.Items = users[:params.Limit]
+		last := page.Items[len(page.Items)-1]
+
+		page.NextCursor = encodeCursor(entity.UserCursor{
+			Sort: params.Sort,
+			Key:  sortKey(last, params.Sort.Field),
+			ID:   last.ID,
+		})
+	}
+
+	return page, nil
+}
+
+// Transfer moves t.Amount from a wallet of one user to a wallet of another,
+// or to another wallet of the same user, converting it between currencies at
+// the current rate. The currencies default to the base one. Both users are
+// locked in id order, so concurrent transfers between the same pair of users
+// cannot deadlock. Money sent to another user counts against the spending
+// limits of the sender, and is screened for risk first.
+func (s *Service) Transfer(ctx context.Context, t entity.Transfer) (entity.Transfer, error) {
+	if err := s.authorize(ctx, entity.PermTransfersCreate); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	return s.transfer(ctx, t, true)
+}
+
+// transfer makes t, screening it for risk first when screened is set.
+func (s *Service) transfer(ctx context.Context, t entity.Transfer, screened bool) (entity.Transfer, error) {
+	if t.Currency == "" {
+		t.Currency = s.base.Code
+	}
+
+	if t.ToCurrency == "" {
+		t.ToCurrency = t.Currency
+	}
+
+	if !t.Amount.IsPositive() {
+		return entity.Transfer{}, entity.NewDetailError(fmt.Errorf("amount must be positive: %w", entity.ErrInvalidTransfer),
+			"detail.amount_not_positive", nil)
+	}
+
+	if t.FromUserID == t.ToUserID && t.Currency == t.ToCurrency {
+		return entity.Transfer{}, entity.NewDetailError(fmt.Errorf("cannot transfer to the same wallet: %w", entity.ErrInvalidTransfer),
+			"detail.transfer_same_wallet", nil)
+	}
+
+	if err := validateTransfer(t); err != nil {
+		return entity.Transfer{}, err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return entity.Transfer{}, fmt.Errorf("failed to generate transfer id: %w", err)
+	}
+
+	t.ID = id
+	t.CreatedAt = time.Now().UTC()
+	t.ToAmount = t.Amount
+	t.Rate = nil
+
+	if t.ToCurrency != t.Currency {
+		rate, err := s.exchangeRate(ctx, t.Currency, t.ToCurrency, t.CreatedAt)
+		if err != nil {
+			return entity.Transfer{}, err
+		}
+
+		target, _ := entity.LookupCurrency(t.ToCurrency)
+
+		t.Rate = &rate
+		t.ToAmount = target.Round(t.Amount.Mul(rate))
+
+		if !t.ToAmount.IsPositive() {
+			return entity.Transfer{}, entity.NewDetailError(fmt.Errorf("%s %s is worth nothing in %s: %w",
+				t.Amount, t.Currency, t.ToCurrency, entity.ErrInvalidTransfer),
+				"detail.transfer_worthless", map[string]any{
+					"amount": t.Amount.String(), "currency": t.Currency, "to_currency": t.ToCurrency,
+				})
+		}
+	}
+
+	// only what leaves the user counts against its limits, in the base currency
+	var spent decimal.Decimal
+
+	if t.FromUserID != t.ToUserID {
+		rate, err := s.exchangeRate(ctx, t.Currency, s.base.Code, t.CreatedAt)
+		if err != nil {
+			return entity.Transfer{}, err
+		}
+
+		spent = s.base.Round(t.Amount.Mul(rate))
+
+		if screened {
+			err := s.screen(ctx, entity.RiskOperation{
+				Kind:           entity.OperationTransfer,
+				UserID:         t.FromUserID,
+				CounterpartyID: &t.ToUserID,
+				Amount:         t.Amount,
+				Currency:       t.Currency,
+				ToCurrency:     t.ToCurrency,
+				Value:          spent,
+			})
+			if err != nil {
+				return entity.Transfer{}, err
+			}
+		}
+	}
+
+	err = s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		ids := []uuid.UUID{t.FromUserID, t.ToUserID}
+		if bytes.Compare(ids[0].Bytes(), ids[1].Bytes()) > 0 {
+			ids[0], ids[1] = ids[1], ids[0]
+		}
+
+		users := make(map[uuid.UUID]*entity.User, len(ids))
+
+		for _, id := range slices.Compact(ids) {
+			user, err := s.userRepo.GetUserForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			users[id] = &user
+		}
+
+		if err := s.changeWallet(ctx, users[t.FromUserID], t.Currency, t.Amount.Neg()); err != nil {
+			return err
+		}
+
+		if spent.IsPositive() {
+			err := s.spend(ctx, entity.Spend{
+				OperationID: t.ID,
+				UserID:      t.FromUserID,
+				Kind:        entity.OperationTransfer,
+				Amount:      spent,
+				CreatedAt:   t.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.changeWallet(ctx, users[t.ToUserID], t.ToCurrency, t.ToAmount); err != nil {
+			return err
+		}
+
+		return s.userRepo.CreateLedgerEntries(ctx, s.transferEntries(t))
+	})
+	if err != nil {
+		return entity.Transfer{}, err
+	}
+
+	return t, nil
+}
+
+func sortKey(user entity.User, field entity.SortField) string {
+	switch field {
+	case entity.SortByEmail:
+		return user.Email
+	case entity.SortByAge:
+		return strconv.Itoa(user.Age)
+	case entity.SortByBalance:
+		return user.Balance.String()
+	default:
+		return user.Name
+	}
+}
+
+func encodeCursor(c entity.UserCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, sort entity.UserSort) (entity.UserCursor, error) {
+	var c entity.UserCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("failed to decode cursor: %w", entity.ErrInvalidCursor)
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil || c.ID.IsNil() {
+		return c, fmt.Errorf("failed to parse cursor: %w", entity.ErrInvalidCursor)
+	}
+
+	// a cursor is only meaningful for the ordering it was issued for
+	if c.Sort != sort {
+		return c, fmt.Errorf("cursor was issued for another sort order: %w", entity.ErrInvalidCursor)
+	}
+
+	switch sort.Field {
+	case entity.SortByAge:
+		_, err = strconv.Atoi(c.Key)
+	case entity.SortByBalance:
+		_, err = decimal.NewFromString(c.Key)
+	}
+
+	if err != nil {
+		return c, fmt.Errorf("malformed cursor key: %w", entity.ErrInvalidCursor)
+	}
+
+	return c, nil
 }
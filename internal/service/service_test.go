@@ -2,13 +2,17 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 	"users-app/internal/entity"
 	"users-app/internal/mocks"
 	"users-app/internal/service"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -20,7 +24,7 @@ func TestService_GetUserByID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	svc := service.New(mockRepo)
+	svc := service.New(mockRepo, nil, nil, "USD")
 
 	ctx := context.Background()
 
@@ -90,13 +94,21 @@ func TestService_CreateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	svc := service.New(mockRepo)
+	svc := service.New(mockRepo, nil, nil, "USD")
 
 	ctx := context.Background()
 
-	user := entity.User{ID: uuid.Must(uuid.NewV4()), Name: "test"}
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Name: "test", Email: "test@example.com"}
 	repositoryErr := errors.New("repository error")
 
+	withinTx := func() {
+		mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			},
+		)
+	}
+
 	tests := []struct {
 		name         string
 		user         entity.User
@@ -108,14 +120,46 @@ func TestService_CreateUser(t *testing.T) {
 			user:        user,
 			expectedErr: nil,
 			mockBehavior: func() {
+				withinTx()
 				mockRepo.EXPECT().CreateUser(ctx, user).Return(nil)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, record entity.AuditRecord) error {
+						r.Equal(entity.AuditCreated, record.Action)
+						r.Equal(user.ID, record.UserID)
+						r.Equal(entity.SystemActor, record.Actor)
+						r.Equal(entity.FieldChange{Before: nil, After: "test"}, record.Changes["name"])
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, event entity.Event) error {
+						r.Equal(entity.EventUserCreated, event.Type)
+						r.Equal(user.ID, event.UserID)
+						r.False(event.ID.IsNil())
+
+						var payload entity.UserEventPayload
+						r.NoError(json.Unmarshal(event.Payload, &payload))
+						r.Equal(user.Name, payload.User.Name)
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil)
 			},
 		},
+		{
+			name:         "Invalid user",
+			user:         entity.User{ID: user.ID, Email: "not-an-email", Age: -1},
+			expectedErr:  entity.ErrValidation,
+			mockBehavior: func() {},
+		},
 		{
 			name:        "User with email already exists",
 			user:        user,
 			expectedErr: entity.ErrAlreadyExists,
 			mockBehavior: func() {
+				withinTx()
 				mockRepo.EXPECT().CreateUser(ctx, user).Return(entity.ErrAlreadyExists)
 			},
 		},
@@ -124,6 +168,7 @@ func TestService_CreateUser(t *testing.T) {
 			user:        user,
 			expectedErr: repositoryErr,
 			mockBehavior: func() {
+				withinTx()
 				mockRepo.EXPECT().CreateUser(ctx, user).Return(repositoryErr)
 			},
 		},
@@ -151,13 +196,22 @@ func TestService_UpdateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	svc := service.New(mockRepo)
+	svc := service.New(mockRepo, nil, nil, "USD")
 
 	ctx := context.Background()
 
-	user := entity.User{ID: uuid.Must(uuid.NewV4()), Name: "test"}
+	user := entity.User{ID: uuid.Must(uuid.NewV4()), Name: "test", Email: "test@example.com"}
+	current := entity.User{ID: user.ID, Name: "old", Email: user.Email}
 	repositoryErr := errors.New("repository error")
 
+	withinTx := func() {
+		mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			},
+		)
+	}
+
 	tests := []struct {
 		name         string
 		user         entity.User
@@ -169,7 +223,21 @@ func TestService_UpdateUser(t *testing.T) {
 			user:        user,
 			expectedErr: nil,
 			mockBehavior: func() {
-				mockRepo.EXPECT().UpdateUser(ctx, user).Return(nil)
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(current, nil)
+				mockRepo.EXPECT().UpdateUser(ctx, user).Return(user, nil)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, record entity.AuditRecord) error {
+						r.Equal(entity.AuditUpdated, record.Action)
+						r.Equal(map[string]entity.FieldChange{
+							"name": {Before: "old", After: "test"},
+						}, record.Changes)
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil)
 			},
 		},
 		{
@@ -177,7 +245,18 @@ func TestService_UpdateUser(t *testing.T) {
 			user:        user,
 			expectedErr: entity.ErrNotFound,
 			mockBehavior: func() {
-				mockRepo.EXPECT().UpdateUser(ctx, user).Return(entity.ErrNotFound)
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(entity.User{}, entity.ErrNotFound)
+			},
+		},
+		{
+			name:        "Version conflict",
+			user:        user,
+			expectedErr: entity.ErrConflict,
+			mockBehavior: func() {
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(current, nil)
+				mockRepo.EXPECT().UpdateUser(ctx, user).Return(entity.User{}, entity.ErrConflict)
 			},
 		},
 		{
@@ -185,7 +264,9 @@ func TestService_UpdateUser(t *testing.T) {
 			user:        user,
 			expectedErr: repositoryErr,
 			mockBehavior: func() {
-				mockRepo.EXPECT().UpdateUser(ctx, user).Return(repositoryErr)
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, user.ID).Return(current, nil)
+				mockRepo.EXPECT().UpdateUser(ctx, user).Return(entity.User{}, repositoryErr)
 			},
 		},
 	}
@@ -194,7 +275,7 @@ func TestService_UpdateUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBehavior()
 
-			err := svc.UpdateUser(ctx, tt.user)
+			_, err := svc.UpdateUser(ctx, tt.user)
 			if tt.expectedErr != nil {
 				r.Error(err)
 				r.ErrorIs(err, tt.expectedErr)
@@ -212,13 +293,23 @@ func TestService_DeleteUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockUserRepository(ctrl)
-	svc := service.New(mockRepo)
+	svc := service.New(mockRepo, nil, nil, "USD")
 
 	ctx := context.Background()
 
 	userID := uuid.Must(uuid.NewV4())
+	user := entity.User{ID: userID, Name: "test"}
+	deletedAt := time.Now()
 	repositoryErr := errors.New("repository error")
 
+	withinTx := func() {
+		mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			},
+		)
+	}
+
 	tests := []struct {
 		name         string
 		userID       uuid.UUID
@@ -230,7 +321,63 @@ func TestService_DeleteUser(t *testing.T) {
 			userID:      userID,
 			expectedErr: nil,
 			mockBehavior: func() {
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, userID).Return(user, nil)
+				mockRepo.EXPECT().ListActiveHolds(ctx, userID).Return(nil, nil)
 				mockRepo.EXPECT().DeleteUser(ctx, userID).Return(nil)
+				mockRepo.EXPECT().GetUserByIDWithDeleted(ctx, userID).Return(
+					entity.User{ID: userID, Name: "test", DeletedAt: &deletedAt}, nil,
+				)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, record entity.AuditRecord) error {
+						r.Equal(entity.AuditDeleted, record.Action)
+						r.Len(record.Changes, 1)
+						r.Contains(record.Changes, "deleted_at")
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:        "Delete user with an active hold",
+			userID:      userID,
+			expectedErr: nil,
+			mockBehavior: func() {
+				held := entity.User{ID: userID, Name: "test", Balance: decimal.NewFromInt(60), HeldBalance: decimal.NewFromInt(40)}
+				hold := entity.Hold{ID: uuid.Must(uuid.NewV4()), UserID: userID, Amount: decimal.NewFromInt(40),
+					Status: entity.HoldActive, ExpiresAt: time.Now().Add(time.Hour)}
+
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, userID).Return(held, nil)
+				mockRepo.EXPECT().ListActiveHolds(ctx, userID).Return([]entity.Hold{hold}, nil)
+				mockRepo.EXPECT().GetHoldForUpdate(ctx, hold.ID).Return(hold, nil)
+				mockRepo.EXPECT().UpdateBalances(ctx, userID, amountEq(decimal.NewFromInt(100)), amountEq(decimal.Zero)).
+					Return(nil)
+				expectBalanceChange(ctx, mockRepo)
+				mockRepo.EXPECT().SettleHold(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, settled entity.Hold) (entity.Hold, error) {
+						r.Equal(entity.HoldReleased, settled.Status)
+						return settled, nil
+					},
+				)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().DeleteUser(ctx, userID).Return(nil)
+				mockRepo.EXPECT().GetUserByIDWithDeleted(ctx, userID).Return(
+					entity.User{ID: userID, Name: "test", Balance: decimal.NewFromInt(100), DeletedAt: &deletedAt}, nil,
+				)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, record entity.AuditRecord) error {
+						r.Equal(entity.AuditDeleted, record.Action)
+						r.Len(record.Changes, 1)
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil)
 			},
 		},
 		{
@@ -238,7 +385,8 @@ func TestService_DeleteUser(t *testing.T) {
 			userID:      userID,
 			expectedErr: entity.ErrNotFound,
 			mockBehavior: func() {
-				mockRepo.EXPECT().DeleteUser(ctx, userID).Return(entity.ErrNotFound)
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, userID).Return(entity.User{}, entity.ErrNotFound)
 			},
 		},
 		{
@@ -246,6 +394,9 @@ func TestService_DeleteUser(t *testing.T) {
 			userID:      userID,
 			expectedErr: repositoryErr,
 			mockBehavior: func() {
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, userID).Return(user, nil)
+				mockRepo.EXPECT().ListActiveHolds(ctx, userID).Return(nil, nil)
 				mockRepo.EXPECT().DeleteUser(ctx, userID).Return(repositoryErr)
 			},
 		},
@@ -266,6 +417,532 @@ func TestService_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestService_ListUsers(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	sort := entity.UserSort{Field: entity.SortByAge}
+	users := []entity.User{
+		{ID: uuid.Must(uuid.NewV4()), Age: 20},
+		{ID: uuid.Must(uuid.NewV4()), Age: 30},
+		{ID: uuid.Must(uuid.NewV4()), Age: 40},
+	}
+
+	mockRepo.EXPECT().ListUsers(ctx, entity.UserQuery{Sort: sort, Limit: 3}).Return(users, nil)
+
+	page, err := svc.ListUsers(ctx, entity.ListUsersParams{Sort: sort, Limit: 2})
+	r.NoError(err)
+	r.Equal(users[:2], page.Items)
+	r.NotEmpty(page.NextCursor)
+
+	mockRepo.EXPECT().ListUsers(ctx, entity.UserQuery{
+		Sort:  sort,
+		Limit: 3,
+		After: &entity.UserCursor{Sort: sort, Key: "30", ID: users[1].ID},
+	}).Return(users[2:], nil)
+
+	page, err = svc.ListUsers(ctx, entity.ListUsersParams{Sort: sort, Limit: 2, Cursor: page.NextCursor})
+	r.NoError(err)
+	r.Equal(users[2:], page.Items)
+	r.Empty(page.NextCursor)
+
+	_, err = svc.ListUsers(ctx, entity.ListUsersParams{Sort: sort, Limit: 2, Cursor: "not-a-cursor"})
+	r.ErrorIs(err, entity.ErrInvalidCursor)
+}
+
+func TestService_Transfer(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	from := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(100)}
+	to := entity.User{ID: uuid.Must(uuid.NewV4()), Balance: decimal.NewFromInt(5)}
+
+	withinTx := func() {
+		mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			},
+		)
+	}
+
+	lockUsers := func() {
+		mockRepo.EXPECT().GetUserForUpdate(ctx, from.ID).Return(from, nil)
+		mockRepo.EXPECT().GetUserForUpdate(ctx, to.ID).Return(to, nil)
+	}
+
+	tests := []struct {
+		name         string
+		from, to     uuid.UUID
+		amount       decimal.Decimal
+		expectedErr  error
+		mockBehavior func()
+	}{
+		{
+			name:   "Transfer successfully",
+			from:   from.ID,
+			to:     to.ID,
+			amount: decimal.NewFromInt(40),
+			mockBehavior: func() {
+				withinTx()
+				lockUsers()
+				mockRepo.EXPECT().UpdateBalance(ctx, from.ID, decimal.NewFromInt(60)).Return(nil)
+				expectSpend(ctx, mockRepo, from.ID, decimal.NewFromInt(40))
+				mockRepo.EXPECT().UpdateBalance(ctx, to.ID, decimal.NewFromInt(45)).Return(nil)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, record entity.AuditRecord) error {
+						r.Equal(entity.AuditBalanceChanged, record.Action)
+						r.Len(record.Changes, 1)
+						r.Contains(record.Changes, "balance")
+
+						return nil
+					},
+				).Times(2)
+				mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, event entity.Event) error {
+						r.Equal(entity.EventUserBalanceChanged, event.Type)
+						return nil
+					},
+				).Times(2)
+				mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil).Times(2)
+				mockRepo.EXPECT().CreateLedgerEntries(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, entries []entity.LedgerEntry) error {
+						r.Len(entries, 2)
+						r.Equal(entity.Debit, entries[0].Direction)
+						r.Equal(from.ID, entries[0].AccountID)
+						r.Equal(entity.Credit, entries[1].Direction)
+						r.Equal(to.ID, entries[1].AccountID)
+						r.True(entries[0].Amount.Equal(entries[1].Amount))
+
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name:         "Non-positive amount",
+			from:         from.ID,
+			to:           to.ID,
+			amount:       decimal.Zero,
+			expectedErr:  entity.ErrInvalidTransfer,
+			mockBehavior: func() {},
+		},
+		{
+			name:         "Same user",
+			from:         from.ID,
+			to:           from.ID,
+			amount:       decimal.NewFromInt(1),
+			expectedErr:  entity.ErrInvalidTransfer,
+			mockBehavior: func() {},
+		},
+		{
+			name:        "Insufficient funds",
+			from:        from.ID,
+			to:          to.ID,
+			amount:      decimal.NewFromInt(101),
+			expectedErr: entity.ErrInsufficientFunds,
+			mockBehavior: func() {
+				withinTx()
+				lockUsers()
+			},
+		},
+		{
+			name:        "Recipient not found",
+			from:        from.ID,
+			to:          to.ID,
+			amount:      decimal.NewFromInt(1),
+			expectedErr: entity.ErrNotFound,
+			mockBehavior: func() {
+				withinTx()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, from.ID).Return(from, nil).AnyTimes()
+				mockRepo.EXPECT().GetUserForUpdate(ctx, to.ID).Return(entity.User{}, entity.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			transfer, err := svc.Transfer(ctx, entity.Transfer{FromUserID: tt.from, ToUserID: tt.to, Amount: tt.amount})
+			if tt.expectedErr != nil {
+				r.Error(err)
+				r.ErrorIs(err, tt.expectedErr)
+			} else {
+				r.NoError(err)
+				r.Equal(tt.from, transfer.FromUserID)
+				r.Equal(tt.to, transfer.ToUserID)
+				r.Equal("USD", transfer.Currency)
+				r.True(tt.amount.Equal(transfer.ToAmount))
+				r.False(transfer.ID.IsNil())
+			}
+		})
+	}
+}
+
+func TestService_PatchUser(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
 
+	ctx := context.Background()
 
+	current := entity.User{
+		ID:      uuid.Must(uuid.NewV4()),
+		Name:    "old",
+		Email:   "old@example.com",
+		Age:     30,
+		Balance: decimal.NewFromInt(10),
+		Version: 2,
+	}
+
+	tests := []struct {
+		name           string
+		patch          entity.UserPatch
+		expectedUser   entity.User
+		expectedErr    error
+		expectedDetail string
+		expectedArgs   map[string]any
+	}{
+		{
+			name:  "Merge patch",
+			patch: entity.UserPatch{Format: entity.MergePatch, Document: []byte(`{"name": "new", "age": 31}`)},
+			expectedUser: entity.User{
+				ID: current.ID, Name: "new", Email: current.Email, Age: 31, Balance: current.Balance, Version: 2,
+			},
+		},
+		{
+			name: "JSON patch",
+			patch: entity.UserPatch{Format: entity.JSONPatch, Document: []byte(`[
+				{"op": "test", "path": "/name", "value": "old"},
+				{"op": "replace", "path": "/email", "value": "new@example.com"}
+			]`)},
+			expectedUser: entity.User{
+				ID: current.ID, Name: "old", Email: "new@example.com", Age: 30, Balance: current.Balance, Version: 2,
+			},
+		},
+		{
+			name:           "Immutable balance",
+			patch:          entity.UserPatch{Format: entity.MergePatch, Document: []byte(`{"balance": "1000"}`)},
+			expectedErr:    entity.ErrInvalidPatch,
+			expectedDetail: "detail.field_immutable",
+			expectedArgs:   map[string]any{"field": "balance"},
+		},
+		{
+			name:           "Immutable id",
+			patch:          entity.UserPatch{Format: entity.JSONPatch, Document: []byte(`[{"op": "remove", "path": "/id"}]`)},
+			expectedErr:    entity.ErrInvalidPatch,
+			expectedDetail: "detail.field_required",
+			expectedArgs:   map[string]any{"field": "id"},
+		},
+		{
+			name:           "Removed field",
+			patch:          entity.UserPatch{Format: entity.MergePatch, Document: []byte(`{"email": null}`)},
+			expectedErr:    entity.ErrInvalidPatch,
+			expectedDetail: "detail.field_required",
+			expectedArgs:   map[string]any{"field": "email"},
+		},
+		{
+			name:           "Wrong field type",
+			patch:          entity.UserPatch{Format: entity.MergePatch, Document: []byte(`{"age": "thirty"}`)},
+			expectedErr:    entity.ErrInvalidPatch,
+			expectedDetail: "detail.field_type_mismatch",
+			expectedArgs:   map[string]any{"field": "age", "type": "int"},
+		},
+		{
+			name:           "Unknown field",
+			patch:          entity.UserPatch{Format: entity.MergePatch, Document: []byte(`{"password": "x"}`)},
+			expectedErr:    entity.ErrInvalidPatch,
+			expectedDetail: "detail.field_unknown",
+			expectedArgs:   map[string]any{"field": "password"},
+		},
+		{
+			name:           "Failed test operation",
+			patch:          entity.UserPatch{Format: entity.JSONPatch, Document: []byte(`[{"op": "test", "path": "/age", "value": 1}]`)},
+			expectedErr:    entity.ErrInvalidPatch,
+			expectedDetail: "detail.patch_operation_failed",
+			expectedArgs:   map[string]any{"index": 0, "op": "test", "path": "/age"},
+		},
+		{
+			name:           "Stale version",
+			patch:          entity.UserPatch{Format: entity.MergePatch, Document: []byte(`{"name": "new"}`), Version: 1},
+			expectedErr:    entity.ErrConflict,
+			expectedDetail: "detail.version_mismatch",
+			expectedArgs:   map[string]any{"version": int64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, fn func(context.Context) error) error {
+					return fn(ctx)
+				},
+			)
+			mockRepo.EXPECT().GetUserForUpdate(ctx, current.ID).Return(current, nil)
+
+			if tt.expectedErr == nil {
+				mockRepo.EXPECT().UpdateUser(ctx, tt.expectedUser).Return(tt.expectedUser, nil)
+				mockRepo.EXPECT().CreateAuditRecord(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateWebhookDeliveries(ctx, gomock.Any()).Return(nil)
+			}
+
+			user, err := svc.PatchUser(ctx, current.ID, tt.patch)
+			if tt.expectedErr != nil {
+				r.Error(err)
+				r.ErrorIs(err, tt.expectedErr)
+
+				var detailErr *entity.DetailError
+				r.ErrorAs(err, &detailErr)
+				r.Equal(tt.expectedDetail, detailErr.Key)
+				r.Equal(tt.expectedArgs, detailErr.Args)
+			} else {
+				r.NoError(err)
+				r.Equal(tt.expectedUser, user)
+			}
+		})
+	}
+}
+
+func TestService_ValidateUser(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	err := svc.CreateUser(ctx, entity.User{
+		ID:      uuid.Must(uuid.NewV4()),
+		Name:    strings.Repeat("x", 256),
+		Email:   "Name <a@example.com>",
+		Age:     200,
+		Balance: decimal.NewFromInt(-1),
+	})
+
+	var validationErr *entity.ValidationError
+	r.ErrorAs(err, &validationErr)
+	r.ErrorIs(err, entity.ErrValidation)
+	r.Equal([]entity.FieldError{
+		{Field: "name", Code: service.CodeTooLong, Message: "name must be at most 255 characters",
+			Params: map[string]any{"max": 255}},
+		{Field: "email", Code: service.CodeInvalidFormat, Message: "email must be a valid address"},
+		{Field: "age", Code: service.CodeOutOfRange, Message: "age must be between 0 and 150",
+			Params: map[string]any{"min": 0, "max": 150}},
+		{Field: "balance", Code: service.CodeNegative, Message: "balance must not be negative"},
+	}, validationErr.Fields)
+
+	err = svc.CreateUser(ctx, entity.User{Name: "  ", Email: ""})
+	r.ErrorAs(err, &validationErr)
+	r.Len(validationErr.Fields, 3)
+
+	for i, field := range []string{"id", "name", "email"} {
+		r.Equal(field, validationErr.Fields[i].Field)
+		r.Equal(service.CodeRequired, validationErr.Fields[i].Code)
+	}
+}
+
+func TestService_PurgeDeletedUsers(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+	retention := 30 * 24 * time.Hour
+
+	mockRepo.EXPECT().PurgeDeletedUsers(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, deletedBefore time.Time) (int64, error) {
+			r.WithinDuration(time.Now().Add(-retention), deletedBefore, time.Minute)
+			return 2, nil
+		},
+	)
+
+	n, err := svc.PurgeDeletedUsers(ctx, retention)
+	r.NoError(err)
+	r.EqualValues(2, n)
+}
+
+func TestService_GetUserHistory(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	userID := uuid.Must(uuid.NewV4())
+	records := []entity.AuditRecord{
+		{ID: 9, UserID: userID, Action: entity.AuditUpdated},
+		{ID: 7, UserID: userID, Action: entity.AuditUpdated},
+		{ID: 3, UserID: userID, Action: entity.AuditCreated},
+	}
+
+	mockRepo.EXPECT().ListAuditRecords(ctx, userID, int64(0), 3).Return(records, nil)
+
+	page, err := svc.GetUserHistory(ctx, userID, "", 2)
+	r.NoError(err)
+	r.Equal(records[:2], page.Items)
+	r.NotEmpty(page.NextCursor)
+
+	mockRepo.EXPECT().ListAuditRecords(ctx, userID, int64(7), 3).Return(records[2:], nil)
+
+	page, err = svc.GetUserHistory(ctx, userID, page.NextCursor, 2)
+	r.NoError(err)
+	r.Equal(records[2:], page.Items)
+	r.Empty(page.NextCursor)
+
+	_, err = svc.GetUserHistory(ctx, userID, "not-a-cursor", 2)
+	r.ErrorIs(err, entity.ErrInvalidCursor)
+}
+
+func TestService_PublishEvents(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	entries := []entity.OutboxEntry{
+		{Event: entity.Event{ID: uuid.Must(uuid.NewV4()), Type: entity.EventUserCreated}, Status: entity.OutboxPending},
+		{Event: entity.Event{ID: uuid.Must(uuid.NewV4()), Type: entity.EventUserUpdated}, Status: entity.OutboxPending},
+		{Event: entity.Event{ID: uuid.Must(uuid.NewV4()), Type: entity.EventUserDeleted}, Status: entity.OutboxPending},
+	}
+	policy := entity.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	sinkErr := errors.New("sink unavailable")
+
+	claim := func(entries ...entity.OutboxEntry) {
+		mockRepo.EXPECT().ClaimOutboxEntries(ctx, 10, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ int, until time.Time) ([]entity.OutboxEntry, error) {
+				r.WithinDuration(time.Now().Add(time.Minute), until, 5*time.Second)
+				return entries, nil
+			},
+		)
+	}
+
+	tests := []struct {
+		name              string
+		failAt            int
+		expectedPublished int
+		expectedErr       error
+		mockBehavior      func()
+	}{
+		{
+			name:              "All published",
+			failAt:            -1,
+			expectedPublished: 3,
+			mockBehavior: func() {
+				claim(entries...)
+				mockRepo.EXPECT().MarkOutboxEventsPublished(ctx,
+					[]uuid.UUID{entries[0].Event.ID, entries[1].Event.ID, entries[2].Event.ID}).Return(nil)
+			},
+		},
+		{
+			name:              "Stops at first failure",
+			failAt:            1,
+			expectedPublished: 1,
+			expectedErr:       sinkErr,
+			mockBehavior: func() {
+				claim(entries...)
+				mockRepo.EXPECT().MarkOutboxEventsPublished(ctx, []uuid.UUID{entries[0].Event.ID}).Return(nil)
+				mockRepo.EXPECT().RecordOutboxFailure(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, entry entity.OutboxEntry) error {
+						r.Equal(entries[1].Event.ID, entry.Event.ID)
+						r.Equal(entity.OutboxPending, entry.Status)
+						r.Equal(1, entry.Attempts)
+						r.Contains(entry.LastError, sinkErr.Error())
+						r.WithinDuration(time.Now().Add(time.Minute), entry.NextAttemptAt, 5*time.Second)
+
+						return nil
+					},
+				)
+				mockRepo.EXPECT().ReleaseOutboxEvents(ctx, []uuid.UUID{entries[2].Event.ID}).Return(nil)
+			},
+		},
+		{
+			name:              "Dead after last attempt",
+			failAt:            0,
+			expectedPublished: 0,
+			expectedErr:       sinkErr,
+			mockBehavior: func() {
+				poison := entries[2]
+				poison.Attempts = 2
+
+				claim(poison)
+				mockRepo.EXPECT().RecordOutboxFailure(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, entry entity.OutboxEntry) error {
+						r.Equal(entity.OutboxDead, entry.Status)
+						r.Equal(3, entry.Attempts)
+
+						return nil
+					},
+				)
+			},
+		},
+		{
+			name:              "Nothing pending",
+			failAt:            -1,
+			expectedPublished: 0,
+			mockBehavior: func() {
+				claim()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			var delivered []entity.Event
+
+			n, err := svc.PublishEvents(ctx, 10, time.Minute, policy, func(_ context.Context, event entity.Event) error {
+				if len(delivered) == tt.failAt {
+					return sinkErr
+				}
+
+				delivered = append(delivered, event)
+
+				return nil
+			})
+			if tt.expectedErr != nil {
+				r.ErrorIs(err, tt.expectedErr)
+			} else {
+				r.NoError(err)
+			}
+
+			r.Equal(tt.expectedPublished, n)
+			r.Len(delivered, tt.expectedPublished)
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"users-app/internal/entity"
+	"users-app/internal/webhook"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxNameLength  = 255
+	maxEmailLength = 255
+	minAge         = 0
+	maxAge         = 150
+	maxURLLength   = 2048
+)
+
+// Field error codes returned to clients.
+const (
+	CodeRequired      = "required"
+	CodeTooLong       = "too_long"
+	CodeInvalidFormat = "invalid_format"
+	CodeOutOfRange    = "out_of_range"
+	CodeNegative      = "negative"
+	CodeNotPositive   = "not_positive"
+	CodeTooPrecise    = "too_precise"
+	CodeUnsupported   = "unsupported"
+	CodeInPast        = "in_past"
+	CodeNotPublic     = "not_public"
+)
+
+type validator struct {
+	fields []entity.FieldError
+}
+
+// add records a violation. The message is the English rendering; params are
+// exposed so that transports can render it in other languages.
+func (v *validator) add(field, code, message string, params map[string]any) {
+	v.fields = append(v.fields, entity.FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+		Params:  params,
+	})
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &entity.ValidationError{Fields: v.fields}
+}
+
+// validateUser checks every field of user and reports all violations at once.
+func validateUser(user entity.User) error {
+	var v validator
+
+	// the nil id is the external account of the ledger
+	if user.ID == uuid.Nil {
+		v.add("id", CodeRequired, "id is required", nil)
+	}
+
+	switch name := strings.TrimSpace(user.Name); {
+	case name == "":
+		v.add("name", CodeRequired, "name is required", nil)
+	case utf8.RuneCountInString(user.Name) > maxNameLength:
+		v.add("name", CodeTooLong, fmt.Sprintf("name must be at most %d characters", maxNameLength),
+			map[string]any{"max": maxNameLength})
+	}
+
+	switch {
+	case user.Email == "":
+		v.add("email", CodeRequired, "email is required", nil)
+	case utf8.RuneCountInString(user.Email) > maxEmailLength:
+		v.add("email", CodeTooLong, fmt.Sprintf("email must be at most %d characters", maxEmailLength),
+			map[string]any{"max": maxEmailLength})
+	case !validEmail(user.Email):
+		v.add("email", CodeInvalidFormat, "email must be a valid address", nil)
+	}
+
+	if user.Age < minAge || user.Age > maxAge {
+		v.add("age", CodeOutOfRange, fmt.Sprintf("age must be between %d and %d", minAge, maxAge),
+			map[string]any{"min": minAge, "max": maxAge})
+	}
+
+	if user.Balance.IsNegative() {
+		v.add("balance", CodeNegative, "balance must not be negative", nil)
+	}
+
+	return v.err()
+}
+
+// validateWebhook checks the URL and the event filter of a subscription. The
+// host of the URL must resolve to public addresses only, see webhook.Public.
+func validateWebhook(ctx context.Context, resolver webhook.Resolver, sub entity.WebhookSubscription) error {
+	var v validator
+
+	switch {
+	case sub.URL == "":
+		v.add("url", CodeRequired, "url is required", nil)
+	case utf8.RuneCountInString(sub.URL) > maxURLLength:
+		v.add("url", CodeTooLong, fmt.Sprintf("url must be at most %d characters", maxURLLength),
+			map[string]any{"max": maxURLLength})
+	case !validURL(sub.URL):
+		v.add("url", CodeInvalidFormat, "url must be a valid address", nil)
+	case webhook.CheckURL(ctx, resolver, sub.URL) != nil:
+		v.add("url", CodeNotPublic, "url must resolve to a public address", nil)
+	}
+
+	for _, t := range sub.Events {
+		if !t.Valid() {
+			v.add("events", CodeUnsupported, fmt.Sprintf("events contains unsupported value %s", t),
+				map[string]any{"value": string(t)})
+		}
+	}
+
+	return v.err()
+}
+
+// validateAPIKey checks the name, the scopes and the expiry of a new key.
+// Scopes limited to the caller's own record are refused: a key is nobody's
+// own record.
+func validateAPIKey(key entity.APIKey, now time.Time) error {
+	var v validator
+
+	switch name := strings.TrimSpace(key.Name); {
+	case name == "":
+		v.add("name", CodeRequired, "name is required", nil)
+	case utf8.RuneCountInString(key.Name) > maxNameLength:
+		v.add("name", CodeTooLong, fmt.Sprintf("name must be at most %d characters", maxNameLength),
+			map[string]any{"max": maxNameLength})
+	}
+
+	if len(key.Scopes) == 0 {
+		v.add("scopes", CodeRequired, "scopes is required", nil)
+	}
+
+	for _, s := range key.Scopes {
+		if !slices.Contains(entity.Permissions, s) || strings.HasSuffix(string(s), ":self") {
+			v.add("scopes", CodeUnsupported, fmt.Sprintf("scopes contains unsupported value %s", s),
+				map[string]any{"value": string(s)})
+		}
+	}
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		v.add("expires_at", CodeInPast, "expires_at must be in the future", nil)
+	}
+
+	return v.err()
+}
+
+// validateAmount checks an amount of money to move in currency.
+func validateAmount(field string, amount decimal.Decimal, currency entity.Currency) error {
+	var v validator
+
+	v.amount(field, amount, currency)
+
+	return v.err()
+}
+
+// amount records a non-positive amount, or one finer than the minor unit of
+// its currency.
+func (v *validator) amount(field string, amount decimal.Decimal, currency entity.Currency) {
+	switch {
+	case !amount.IsPositive():
+		v.add(field, CodeNotPositive, field+" must be positive", nil)
+	case !currency.Exact(amount):
+		v.add(field, CodeTooPrecise, fmt.Sprintf("%s must have at most %d decimal places", field, currency.MinorUnits),
+			map[string]any{"places": int(currency.MinorUnits)})
+	}
+}
+
+// currency records a code that is not a known currency and returns the
+// currency otherwise.
+func (v *validator) currency(field, code string) (entity.Currency, bool) {
+	currency, ok := entity.LookupCurrency(code)
+	if !ok {
+		v.add(field, CodeUnsupported, fmt.Sprintf("%s contains unsupported value %s", field, code),
+			map[string]any{"value": code})
+	}
+
+	return currency, ok
+}
+
+// limits records negative limits and limits finer than the minor unit of the
+// currency they are in.
+func (v *validator) limits(l entity.SpendingLimits, currency entity.Currency) {
+	fields := []struct {
+		name  string
+		limit *decimal.Decimal
+	}{
+		{"per_transaction", l.PerTransaction},
+		{"daily", l.Daily},
+		{"weekly", l.Weekly},
+		{"monthly", l.Monthly},
+	}
+
+	for _, f := range fields {
+		switch {
+		case f.limit == nil:
+		case f.limit.IsNegative():
+			v.add(f.name, CodeNegative, f.name+" must not be negative", nil)
+		case !currency.Exact(*f.limit):
+			v.add(f.name, CodeTooPrecise, fmt.Sprintf("%s must have at most %d decimal places", f.name, currency.MinorUnits),
+				map[string]any{"places": int(currency.MinorUnits)})
+		}
+	}
+}
+
+// validateHold checks the amount and the expiry of a new hold in currency.
+func validateHold(hold entity.Hold, now time.Time, currency entity.Currency) error {
+	var v validator
+
+	v.amount("amount", hold.Amount, currency)
+
+	if !hold.ExpiresAt.After(now) {
+		v.add("expires_at", CodeInPast, "expires_at must be in the future", nil)
+	}
+
+	return v.err()
+}
+
+// validateTransfer checks the currencies of a transfer and that its amount
+// fits the minor unit of the source currency. Positive amounts and distinct
+// wallets are checked by the caller.
+func validateTransfer(t entity.Transfer) error {
+	var v validator
+
+	if currency, ok := v.currency("currency", t.Currency); ok {
+		v.amount("amount", t.Amount, currency)
+	}
+
+	v.currency("to_currency", t.ToCurrency)
+
+	return v.err()
+}
+
+// validateExchangeRate checks the pair and the rate of an exchange rate.
+func validateExchangeRate(rate entity.ExchangeRate) error {
+	var v validator
+
+	v.currency("base", rate.Base)
+	v.currency("quote", rate.Quote)
+
+	if rate.Base == rate.Quote {
+		v.add("quote", CodeUnsupported, fmt.Sprintf("quote contains unsupported value %s", rate.Quote),
+			map[string]any{"value": rate.Quote})
+	}
+
+	if !rate.Rate.IsPositive() {
+		v.add("rate", CodeNotPositive, "rate must be positive", nil)
+	}
+
+	if rate.EffectiveAt.IsZero() {
+		v.add("effective_at", CodeRequired, "effective_at is required", nil)
+	}
+
+	return v.err()
+}
+
+// validURL accepts absolute http and https URLs.
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validEmail accepts a bare addr-spec: no display name, no angle brackets.
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return false
+	}
+
+	_, domain, _ := strings.Cut(addr.Address, "@")
+
+	return strings.Contains(domain, ".")
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func (s *Service) CreateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	if err := validateWebhook(ctx, s.resolver, sub); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return entity.WebhookSubscription{}, fmt.Errorf("failed to generate webhook id: %w", err)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	sub.ID = id
+	sub.Secret = secret
+
+	if sub.Events == nil {
+		sub.Events = []entity.EventType{}
+	}
+
+	created, err := s.userRepo.CreateWebhook(ctx, sub)
+	if err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	created.Secret = secret
+
+	return created, nil
+}
+
+func (s *Service) GetWebhook(ctx context.Context, id uuid.UUID) (entity.WebhookSubscription, error) {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	return s.userRepo.GetWebhook(ctx, id)
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.ListWebhooks(ctx)
+}
+
+// UpdateWebhook replaces the URL, the event filter and the active flag of a
+// subscription. The secret is kept.
+func (s *Service) UpdateWebhook(ctx context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	if err := validateWebhook(ctx, s.resolver, sub); err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	if sub.Events == nil {
+		sub.Events = []entity.EventType{}
+	}
+
+	return s.userRepo.UpdateWebhook(ctx, sub)
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return err
+	}
+
+	return s.userRepo.DeleteWebhook(ctx, id)
+}
+
+// ListWebhookDeliveries pages through the deliveries of a subscription,
+// newest first.
+func (s *Service) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, cursor string, limit int) (entity.WebhookDeliveryPage, error) {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return entity.WebhookDeliveryPage{}, err
+	}
+
+	beforeID, err := decodeIDCursor(cursor)
+	if err != nil {
+		return entity.WebhookDeliveryPage{}, err
+	}
+
+	if _, err := s.userRepo.GetWebhook(ctx, subscriptionID); err != nil {
+		return entity.WebhookDeliveryPage{}, err
+	}
+
+	deliveries, err := s.userRepo.ListWebhookDeliveries(ctx, subscriptionID, beforeID, limit+1)
+	if err != nil {
+		return entity.WebhookDeliveryPage{}, err
+	}
+
+	page := entity.WebhookDeliveryPage{Items: deliveries}
+
+	if len(deliveries) > limit {
+		page.Items = deliveries[:limit]
+		page.NextCursor = encodeIDCursor(page.Items[len(page.Items)-1].ID)
+	}
+
+	return page, nil
+}
+
+// RedeliverWebhook queues a delivery again with a fresh set of attempts,
+// whatever its current status.
+func (s *Service) RedeliverWebhook(ctx context.Context, subscriptionID uuid.UUID, deliveryID int64) (entity.WebhookDelivery, error) {
+	if err := s.authorize(ctx, entity.PermWebhooksManage); err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	var redelivered entity.WebhookDelivery
+
+	err := s.userRepo.WithinTx(ctx, func(ctx context.Context) error {
+		d, err := s.userRepo.GetWebhookDelivery(ctx, subscriptionID, deliveryID)
+		if err != nil {
+			return err
+		}
+
+		d.Status = entity.DeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = time.Now().UTC()
+		d.DeliveredAt = nil
+
+		redelivered, err = s.userRepo.UpdateWebhookDelivery(ctx, d)
+
+		return err
+	})
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	return redelivered, nil
+}
+
+// DispatchWebhooks sends up to limit due deliveries with send and records the
+// outcome of each attempt. Failed deliveries are retried after the backoff of
+// policy until they run out of attempts and become dead. It returns the
+// number of deliveries attempted.
+//
+// The deliveries are claimed for lease and sent outside any transaction, so
+// that a slow endpoint holds no locks. A delivery whose outcome is never
+// recorded, say because the process died, is due again once its lease runs
+// out; lease should outlast sending a whole batch.
+func (s *Service) DispatchWebhooks(ctx context.Context, limit int, lease time.Duration, policy entity.RetryPolicy,
+	send func(ctx context.Context, job entity.WebhookJob) (int, error)) (int, error) {
+	jobs, err := s.userRepo.ClaimWebhookJobs(ctx, limit, time.Now().UTC().Add(lease))
+	if err != nil {
+		return 0, err
+	}
+
+	var attempted int
+
+	for _, job := range jobs {
+		d := job.Delivery
+		code, sendErr := send(ctx, job)
+		now := time.Now().UTC()
+
+		d.Attempts++
+		d.LastStatusCode = code
+
+		switch {
+		case sendErr == nil:
+			d.Status = entity.DeliverySucceeded
+			d.LastError = ""
+			d.DeliveredAt = &now
+		case d.Attempts >= policy.MaxAttempts:
+			d.Status = entity.DeliveryDead
+			d.LastError = sendErr.Error()
+		default:
+			d.LastError = sendErr.Error()
+			d.NextAttemptAt = now.Add(policy.Backoff(d.Attempts))
+		}
+
+		if _, err := s.userRepo.UpdateWebhookDelivery(ctx, d); err != nil {
+			return attempted, err
+		}
+
+		attempted++
+	}
+
+	return attempted, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,231 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/mocks"
+	"users-app/internal/service"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_CreateWebhook(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	mockRepo.EXPECT().CreateWebhook(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, sub entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+			r.False(sub.ID.IsNil())
+			r.True(strings.HasPrefix(sub.Secret, "whsec_"))
+			r.NotNil(sub.Events)
+
+			sub.Secret = ""
+
+			return sub, nil
+		},
+	)
+
+	// an address literal resolves to itself, without a lookup
+	sub, err := svc.CreateWebhook(ctx, entity.WebhookSubscription{URL: "https://203.0.113.10/hook", Active: true})
+	r.NoError(err)
+	r.NotEmpty(sub.Secret)
+
+	_, err = svc.CreateWebhook(ctx, entity.WebhookSubscription{
+		URL:    "ftp://example.com",
+		Events: []entity.EventType{entity.EventUserCreated, "user.renamed"},
+	})
+
+	var validationErr *entity.ValidationError
+	r.ErrorAs(err, &validationErr)
+	r.Equal([]entity.FieldError{
+		{Field: "url", Code: service.CodeInvalidFormat, Message: "url must be a valid address"},
+		{Field: "events", Code: service.CodeUnsupported, Message: "events contains unsupported value user.renamed",
+			Params: map[string]any{"value": "user.renamed"}},
+	}, validationErr.Fields)
+
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://[::1]/hook", "http://169.254.169.254/latest"} {
+		_, err = svc.CreateWebhook(ctx, entity.WebhookSubscription{URL: url})
+
+		r.ErrorAs(err, &validationErr, url)
+		r.Equal([]entity.FieldError{
+			{Field: "url", Code: service.CodeNotPublic, Message: "url must resolve to a public address"},
+		}, validationErr.Fields, url)
+	}
+}
+
+func TestService_DispatchWebhooks(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	policy := entity.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+	sendErr := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		attempts int
+		code     int
+		err      error
+		check    func(d entity.WebhookDelivery)
+	}{
+		{
+			name: "Delivered",
+			code: 200,
+			check: func(d entity.WebhookDelivery) {
+				r.Equal(entity.DeliverySucceeded, d.Status)
+				r.Equal(1, d.Attempts)
+				r.NotNil(d.DeliveredAt)
+			},
+		},
+		{
+			name:     "Retried with backoff",
+			attempts: 1,
+			code:     503,
+			err:      sendErr,
+			check: func(d entity.WebhookDelivery) {
+				r.Equal(entity.DeliveryPending, d.Status)
+				r.Equal(2, d.Attempts)
+				r.Equal(503, d.LastStatusCode)
+				r.Equal(sendErr.Error(), d.LastError)
+				r.WithinDuration(time.Now().Add(2*time.Minute), d.NextAttemptAt, 5*time.Second)
+			},
+		},
+		{
+			name:     "Dead after last attempt",
+			attempts: 2,
+			err:      sendErr,
+			check: func(d entity.WebhookDelivery) {
+				r.Equal(entity.DeliveryDead, d.Status)
+				r.Equal(3, d.Attempts)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := entity.WebhookJob{
+				Delivery: entity.WebhookDelivery{ID: 1, Status: entity.DeliveryPending, Attempts: tt.attempts},
+				URL:      "https://example.com/hook",
+				Secret:   "secret",
+			}
+
+			mockRepo.EXPECT().ClaimWebhookJobs(ctx, 10, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ int, until time.Time) ([]entity.WebhookJob, error) {
+					r.WithinDuration(time.Now().Add(time.Minute), until, 5*time.Second)
+					return []entity.WebhookJob{job}, nil
+				},
+			)
+			mockRepo.EXPECT().UpdateWebhookDelivery(ctx, gomock.Any()).DoAndReturn(
+				func(_ context.Context, d entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+					tt.check(d)
+					return d, nil
+				},
+			)
+
+			n, err := svc.DispatchWebhooks(ctx, 10, time.Minute, policy, func(_ context.Context, got entity.WebhookJob) (int, error) {
+				r.Equal(job, got)
+				return tt.code, tt.err
+			})
+			r.NoError(err)
+			r.Equal(1, n)
+		})
+	}
+}
+
+func TestService_RedeliverWebhook(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	subID := uuid.Must(uuid.NewV4())
+	deliveredAt := time.Now()
+
+	withinTx := func() {
+		mockRepo.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			},
+		)
+	}
+
+	withinTx()
+	mockRepo.EXPECT().GetWebhookDelivery(ctx, subID, int64(7)).Return(entity.WebhookDelivery{
+		ID: 7, SubscriptionID: subID, Status: entity.DeliveryDead, Attempts: 8, DeliveredAt: &deliveredAt,
+	}, nil)
+	mockRepo.EXPECT().UpdateWebhookDelivery(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, d entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+			return d, nil
+		},
+	)
+
+	d, err := svc.RedeliverWebhook(ctx, subID, 7)
+	r.NoError(err)
+	r.Equal(entity.DeliveryPending, d.Status)
+	r.Zero(d.Attempts)
+	r.Nil(d.DeliveredAt)
+
+	withinTx()
+	mockRepo.EXPECT().GetWebhookDelivery(ctx, subID, int64(8)).Return(entity.WebhookDelivery{}, entity.ErrNotFound)
+
+	_, err = svc.RedeliverWebhook(ctx, subID, 8)
+	r.ErrorIs(err, entity.ErrNotFound)
+}
+
+func TestService_ListWebhookDeliveries(t *testing.T) {
+	r := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepository(ctrl)
+	svc := service.New(mockRepo, nil, nil, "USD")
+
+	ctx := context.Background()
+
+	subID := uuid.Must(uuid.NewV4())
+	deliveries := []entity.WebhookDelivery{{ID: 5}, {ID: 4}, {ID: 2}}
+
+	mockRepo.EXPECT().GetWebhook(ctx, subID).Return(entity.WebhookSubscription{ID: subID}, nil).Times(2)
+	mockRepo.EXPECT().ListWebhookDeliveries(ctx, subID, int64(0), 3).Return(deliveries, nil)
+	mockRepo.EXPECT().ListWebhookDeliveries(ctx, subID, int64(4), 3).Return(deliveries[2:], nil)
+
+	page, err := svc.ListWebhookDeliveries(ctx, subID, "", 2)
+	r.NoError(err)
+	r.Equal(deliveries[:2], page.Items)
+
+	page, err = svc.ListWebhookDeliveries(ctx, subID, page.NextCursor, 2)
+	r.NoError(err)
+	r.Equal(deliveries[2:], page.Items)
+	r.Empty(page.NextCursor)
+
+	missing := uuid.Must(uuid.NewV4())
+	mockRepo.EXPECT().GetWebhook(ctx, missing).Return(entity.WebhookSubscription{}, entity.ErrNotFound)
+
+	_, err = svc.ListWebhookDeliveries(ctx, missing, "", 2)
+	r.ErrorIs(err, entity.ErrNotFound)
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for a webhook URL whose host is not a
+// public address, so that subscribers cannot make the service call the host
+// it runs on or its private networks.
+var ErrForbiddenAddress = errors.New("forbidden webhook address")
+
+// nonPublicPrefixes are the special-purpose ranges that netip does not
+// classify: this network, shared address space (carrier-grade NAT),
+// benchmarking and reserved.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// Public reports whether webhooks may be sent to addr. Loopback, link-local
+// (including cloud metadata endpoints), private (RFC 1918, RFC 4193),
+// unspecified, multicast and the ranges above are refused.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Resolver looks up the addresses of a host; *net.Resolver is one.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// CheckURL resolves the host of rawURL and refuses it unless every address
+// it resolves to is public. The host may resolve elsewhere later, which the
+// client of NewClient checks again on every connection.
+func CheckURL(ctx context.Context, resolver Resolver, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook url: %w", err)
+	}
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", u.Hostname(), err)
+	}
+
+	for _, addr := range addrs {
+		if !Public(addr) {
+			return fmt.Errorf("%s resolves to %s: %w", u.Hostname(), addr, ErrForbiddenAddress)
+		}
+	}
+
+	return nil
+}
+
+// NewClient returns an HTTP client for Sender that only connects to public
+// addresses. The check runs on the address actually dialed, for redirects
+// too, so a host that resolves differently after CheckURL is still refused.
+// Proxies from the environment are ignored, since the client would dial them
+// instead.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refuseNonPublic,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+func refuseNonPublic(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse dialed address %s: %w", address, err)
+	}
+
+	if !Public(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", address, ErrForbiddenAddress)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/webhook"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{addr: "203.0.113.10", expected: true},
+		{addr: "2001:db8::1", expected: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "0.0.0.0"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "100.64.0.1"},
+		{addr: "224.0.0.1"},
+		{addr: "::ffff:127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			require.Equal(t, tt.expected, webhook.Public(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+type resolver map[string][]netip.Addr
+
+func (r resolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	return r[host], nil
+}
+
+func TestCheckURL(t *testing.T) {
+	r := require.New(t)
+
+	res := resolver{
+		"example.com":   {netip.MustParseAddr("203.0.113.10")},
+		"internal.test": {netip.MustParseAddr("203.0.113.10"), netip.MustParseAddr("10.0.0.5")},
+	}
+
+	ctx := context.Background()
+
+	r.NoError(webhook.CheckURL(ctx, res, "https://example.com/hook"))
+	r.ErrorIs(webhook.CheckURL(ctx, res, "https://internal.test/hook"), webhook.ErrForbiddenAddress)
+}
+
+func TestNewClient_RefusesLoopback(t *testing.T) {
+	r := require.New(t)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	_, err := webhook.NewSender(webhook.NewClient(time.Second)).Send(context.Background(),
+		entity.WebhookJob{URL: receiver.URL})
+	r.ErrorIs(err, webhook.ErrForbiddenAddress)
+}
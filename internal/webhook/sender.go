@@ -0,0 +1,121 @@
+// Package webhook sends signed webhook deliveries.
+//
+// Every request carries the X-Webhook-Signature header in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>", where the MAC is computed with the
+// subscription secret over "<t>.<body>". Receivers should recompute it,
+// compare in constant time and reject stale timestamps. X-Webhook-ID holds the
+// event ID, which stays the same across retries.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"users-app/internal/entity"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	IDHeader        = "X-Webhook-ID"
+	EventHeader     = "X-Webhook-Event"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(client *http.Client) *Sender {
+	return &Sender{
+		client: client,
+	}
+}
+
+// Send posts the delivery payload to the job URL and returns the response
+// status code. Any status outside 2xx is reported as an error.
+func (s *Sender) Send(ctx context.Context, job entity.WebhookJob) (int, error) {
+	body := []byte(job.Delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, job.Delivery.EventID.String())
+	req.Header.Set(EventHeader, string(job.Delivery.EventType))
+	req.Header.Set(SignatureHeader, Sign(job.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify checks a signature header against body and rejects signatures older
+// than tolerance.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed timestamp: %w", ErrInvalidSignature)
+	}
+
+	if now.Sub(time.Unix(unix, 0)).Abs() > tolerance {
+		return fmt.Errorf("timestamp outside tolerance: %w", ErrInvalidSignature)
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(secret, ts, body)) {
+		return fmt.Errorf("signature mismatch: %w", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"users-app/internal/entity"
+	"users-app/internal/webhook"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_Send(t *testing.T) {
+	r := require.New(t)
+
+	const secret = "whsec_test"
+
+	job := entity.WebhookJob{
+		Delivery: entity.WebhookDelivery{
+			ID:        1,
+			EventID:   uuid.Must(uuid.NewV4()),
+			EventType: entity.EventUserCreated,
+			Payload:   json.RawMessage(`{"type":"user.created"}`),
+		},
+		Secret: secret,
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		expectedErr bool
+	}{
+		{name: "accepted", status: http.StatusOK},
+		{name: "rejected", status: http.StatusBadGateway, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				r.NoError(err)
+
+				r.NoError(webhook.Verify(secret, req.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now()))
+				r.Equal(job.Delivery.EventID.String(), req.Header.Get(webhook.IDHeader))
+				r.Equal(string(entity.EventUserCreated), req.Header.Get(webhook.EventHeader))
+				r.JSONEq(string(job.Delivery.Payload), string(body))
+
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			job.URL = receiver.URL
+
+			code, err := webhook.NewSender(receiver.Client()).Send(context.Background(), job)
+			r.Equal(tt.status, code)
+
+			if tt.expectedErr {
+				r.Error(err)
+			} else {
+				r.NoError(err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	r := require.New(t)
+
+	body := []byte(`{}`)
+	sentAt := time.Now()
+	header := webhook.Sign("secret", sentAt, body)
+
+	r.NoError(webhook.Verify("secret", header, body, time.Minute, sentAt))
+	r.ErrorIs(webhook.Verify("other", header, body, time.Minute, sentAt), webhook.ErrInvalidSignature)
+	r.ErrorIs(webhook.Verify("secret", header, []byte(`{"x":1}`), time.Minute, sentAt), webhook.ErrInvalidSignature)
+	r.ErrorIs(webhook.Verify("secret", header, body, time.Minute, sentAt.Add(time.Hour)), webhook.ErrInvalidSignature)
+	r.ErrorIs(webhook.Verify("secret", "garbage", body, time.Minute, sentAt), webhook.ErrInvalidSignature)
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"time"
+	"users-app/pkg/logger"
+)
+
+type HoldExpirer interface {
+	ExpireHolds(ctx context.Context, limit int) (int, error)
+}
+
+// HoldExpiry periodically gives the money of overdue holds back to their
+// users.
+type HoldExpiry struct {
+	log       logger.Logger
+	expirer   HoldExpirer
+	batchSize int
+	interval  time.Duration
+}
+
+func NewHoldExpiry(log logger.Logger, expirer HoldExpirer, batchSize int, interval time.Duration) *HoldExpiry {
+	return &HoldExpiry{
+		log:       log,
+		expirer:   expirer,
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+// Run expires holds once immediately and then on every tick until ctx is
+// cancelled. A full batch is followed by the next one without waiting.
+func (e *HoldExpiry) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := e.expirer.ExpireHolds(ctx, e.batchSize)
+		if err != nil {
+			e.log.ErrorF("failed to expire holds: %s", err.Error())
+		} else if n > 0 {
+			e.log.InfoF("expired %d holds", n)
+		}
+
+		if err == nil && n == e.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
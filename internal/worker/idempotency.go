@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+	"users-app/pkg/logger"
+)
+
+type IdempotencyKeyPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// IdempotencyKeys periodically removes expired idempotency keys and the
+// responses stored with them.
+type IdempotencyKeys struct {
+	log      logger.Logger
+	purger   IdempotencyKeyPurger
+	interval time.Duration
+}
+
+func NewIdempotencyKeys(log logger.Logger, purger IdempotencyKeyPurger, interval time.Duration) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		log:      log,
+		purger:   purger,
+		interval: interval,
+	}
+}
+
+// Run purges once immediately and then on every tick until ctx is cancelled.
+func (k *IdempotencyKeys) Run(ctx context.Context) {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := k.purger.PurgeExpired(ctx)
+		if err != nil {
+			k.log.ErrorF("failed to purge expired idempotency keys: %s", err.Error())
+		} else if n > 0 {
+			k.log.InfoF("purged %d expired idempotency keys", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"users-app/internal/entity"
+	"users-app/pkg/logger"
+)
+
+// userChangesChannel is notified by the users_notify_change trigger.
+const userChangesChannel = "user_changes"
+
+type Listener interface {
+	Listen(ctx context.Context, channel string, handle func(payload string)) error
+}
+
+type EventBroker interface {
+	Publish(event entity.Event)
+}
+
+// UserChanges forwards notifications about changed users to the broker that
+// feeds the event stream.
+type UserChanges struct {
+	log        logger.Logger
+	listener   Listener
+	broker     EventBroker
+	retryDelay time.Duration
+}
+
+func NewUserChanges(log logger.Logger, listener Listener, broker EventBroker, retryDelay time.Duration) *UserChanges {
+	return &UserChanges{
+		log:        log,
+		listener:   listener,
+		broker:     broker,
+		retryDelay: retryDelay,
+	}
+}
+
+// Run listens until ctx is cancelled and reconnects after retryDelay when the
+// connection is lost.
+func (u *UserChanges) Run(ctx context.Context) {
+	for {
+		if err := u.listener.Listen(ctx, userChangesChannel, u.handle); err != nil {
+			u.log.ErrorF("failed to listen for user changes: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(u.retryDelay):
+		}
+	}
+}
+
+func (u *UserChanges) handle(payload string) {
+	var event entity.Event
+
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		u.log.ErrorF("failed to decode user change notification: %s", err.Error())
+		return
+	}
+
+	u.broker.Publish(event)
+}
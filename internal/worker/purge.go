@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"time"
+	"users-app/pkg/logger"
+)
+
+type UserPurger interface {
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// Purge periodically removes users that stayed soft-deleted longer than the
+// retention period.
+type Purge struct {
+	log       logger.Logger
+	purger    UserPurger
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurge(log logger.Logger, purger UserPurger, retention, interval time.Duration) *Purge {
+	return &Purge{
+		log:       log,
+		purger:    purger,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run purges once immediately and then on every tick until ctx is cancelled.
+func (p *Purge) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.purger.PurgeDeletedUsers(ctx, p.retention)
+		if err != nil {
+			p.log.ErrorF("failed to purge deleted users: %s", err.Error())
+		} else if n > 0 {
+			p.log.InfoF("purged %d deleted users", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
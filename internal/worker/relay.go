@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"users-app/internal/entity"
+	"users-app/pkg/logger"
+)
+
+type EventPublisher interface {
+	PublishEvents(ctx context.Context, limit int, lease time.Duration, policy entity.RetryPolicy,
+		publish func(ctx context.Context, event entity.Event) error) (int, error)
+}
+
+type Sink interface {
+	Deliver(ctx context.Context, event entity.Event) error
+}
+
+// Relay moves events from the outbox to the sinks. An event that fails in
+// any sink is delivered to all of them again on the next attempt.
+type Relay struct {
+	log       logger.Logger
+	publisher EventPublisher
+	sinks     []Sink
+	policy    entity.RetryPolicy
+	batchSize int
+	lease     time.Duration
+	interval  time.Duration
+}
+
+func NewRelay(log logger.Logger, publisher EventPublisher, sinks []Sink, policy entity.RetryPolicy,
+	batchSize int, lease, interval time.Duration) *Relay {
+	return &Relay{
+		log:       log,
+		publisher: publisher,
+		sinks:     sinks,
+		policy:    policy,
+		batchSize: batchSize,
+		lease:     lease,
+		interval:  interval,
+	}
+}
+
+// Run drains the outbox and then polls it on every tick until ctx is
+// cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.publisher.PublishEvents(ctx, r.batchSize, r.lease, r.policy, r.deliver)
+		if err != nil && ctx.Err() == nil {
+			r.log.ErrorF("failed to relay events: %s", err.Error())
+		}
+
+		if err == nil && n == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) deliver(ctx context.Context, event entity.Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Deliver(ctx, event); err != nil {
+			return fmt.Errorf("%T: %w", sink, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"time"
+	"users-app/internal/entity"
+	"users-app/pkg/logger"
+)
+
+type WebhookDispatcher interface {
+	DispatchWebhooks(ctx context.Context, limit int, lease time.Duration, policy entity.RetryPolicy,
+		send func(ctx context.Context, job entity.WebhookJob) (int, error)) (int, error)
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, job entity.WebhookJob) (int, error)
+}
+
+// Webhooks sends due webhook deliveries.
+type Webhooks struct {
+	log        logger.Logger
+	dispatcher WebhookDispatcher
+	sender     WebhookSender
+	policy     entity.RetryPolicy
+	batchSize  int
+	lease      time.Duration
+	interval   time.Duration
+}
+
+func NewWebhooks(log logger.Logger, dispatcher WebhookDispatcher, sender WebhookSender,
+	policy entity.RetryPolicy, batchSize int, lease, interval time.Duration) *Webhooks {
+	return &Webhooks{
+		log:        log,
+		dispatcher: dispatcher,
+		sender:     sender,
+		policy:     policy,
+		batchSize:  batchSize,
+		lease:      lease,
+		interval:   interval,
+	}
+}
+
+// Run sends every due delivery and then polls for more on every tick until
+// ctx is cancelled.
+func (w *Webhooks) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := w.dispatcher.DispatchWebhooks(ctx, w.batchSize, w.lease, w.policy, w.sender.Send)
+		if err != nil && ctx.Err() == nil {
+			w.log.ErrorF("failed to dispatch webhooks: %s", err.Error())
+		}
+
+		if err == nil && n == w.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
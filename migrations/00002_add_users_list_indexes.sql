@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX users_name_id_idx ON users (name, id);
+
+CREATE INDEX users_name_prefix_idx ON users (name varchar_pattern_ops);
+
+CREATE INDEX users_email_id_idx ON users (email, id);
+
+CREATE INDEX users_email_domain_idx ON users (lower(split_part(email, '@', 2)));
+
+CREATE INDEX users_age_id_idx ON users (age, id);
+
+CREATE INDEX users_balance_id_idx ON users (balance, id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_balance_id_idx;
+
+DROP INDEX users_age_id_idx;
+
+DROP INDEX users_email_domain_idx;
+
+DROP INDEX users_email_id_idx;
+
+DROP INDEX users_name_prefix_idx;
+
+DROP INDEX users_name_id_idx;
+
+-- +goose StatementEnd
@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+   ledger_entries (
+      id BIGSERIAL PRIMARY KEY,
+      operation_id uuid NOT NULL,
+      kind VARCHAR(32) NOT NULL,
+      account_id uuid NOT NULL,
+      direction VARCHAR(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+      amount DECIMAL NOT NULL CHECK (amount > 0),
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+   );
+
+CREATE INDEX ledger_entries_account_id_idx ON ledger_entries (account_id, id);
+
+CREATE INDEX ledger_entries_operation_id_idx ON ledger_entries (operation_id);
+
+-- every operation must balance once its transaction commits
+CREATE FUNCTION ledger_entries_check_balanced () RETURNS trigger AS $$
+DECLARE
+   diff DECIMAL;
+BEGIN
+   SELECT
+      COALESCE(SUM(CASE direction WHEN 'debit' THEN amount ELSE -amount END), 0) INTO diff
+   FROM
+      ledger_entries
+   WHERE
+      operation_id = NEW.operation_id;
+
+   IF diff <> 0 THEN
+      RAISE EXCEPTION 'ledger operation % is unbalanced by %', NEW.operation_id, diff;
+   END IF;
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+AFTER INSERT ON ledger_entries DEFERRABLE INITIALLY DEFERRED FOR EACH ROW
+EXECUTE FUNCTION ledger_entries_check_balanced ();
+
+-- opening balances for users created before the ledger existed
+INSERT INTO
+   ledger_entries (operation_id, kind, account_id, direction, amount)
+SELECT
+   op.id,
+   'opening',
+   side.account_id,
+   side.direction,
+   abs(u.balance)
+FROM
+   users u
+   CROSS JOIN LATERAL (
+      SELECT
+         gen_random_uuid () AS id,
+         u.id AS user_id
+   ) op
+   CROSS JOIN LATERAL (
+      VALUES
+         (
+            CASE WHEN u.balance > 0 THEN '00000000-0000-0000-0000-000000000000'::uuid ELSE u.id END,
+            'debit'
+         ),
+         (
+            CASE WHEN u.balance > 0 THEN u.id ELSE '00000000-0000-0000-0000-000000000000'::uuid END,
+            'credit'
+         )
+   ) side (account_id, direction)
+WHERE
+   u.balance <> 0;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE ledger_entries;
+
+DROP FUNCTION ledger_entries_check_balanced ();
+
+-- +goose StatementEnd
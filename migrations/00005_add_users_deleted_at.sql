@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- deleted users must not block their email from being registered again
+ALTER TABLE users
+DROP CONSTRAINT users_email_key;
+
+CREATE UNIQUE INDEX users_email_active_key ON users (email)
+WHERE
+   deleted_at IS NULL;
+
+CREATE INDEX users_deleted_at_idx ON users (deleted_at)
+WHERE
+   deleted_at IS NOT NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM users
+WHERE
+   deleted_at IS NOT NULL;
+
+DROP INDEX users_deleted_at_idx;
+
+DROP INDEX users_email_active_key;
+
+ALTER TABLE users
+ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE users
+DROP COLUMN deleted_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+   audit_log (
+      id BIGSERIAL PRIMARY KEY,
+      user_id uuid NOT NULL,
+      action VARCHAR(32) NOT NULL,
+      actor_kind VARCHAR(32) NOT NULL,
+      actor_id VARCHAR(255) NOT NULL,
+      request_id VARCHAR(255) NOT NULL DEFAULT '',
+      changes JSONB NOT NULL,
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+   );
+
+CREATE INDEX audit_log_user_id_idx ON audit_log (user_id, id DESC);
+
+CREATE FUNCTION audit_log_forbid_changes () RETURNS trigger AS $$
+BEGIN
+   RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE
+UPDATE
+OR DELETE ON audit_log FOR EACH ROW
+EXECUTE FUNCTION audit_log_forbid_changes ();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+
+DROP FUNCTION audit_log_forbid_changes ();
+
+-- +goose StatementEnd
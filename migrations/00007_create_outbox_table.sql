@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+   outbox (
+      seq BIGSERIAL PRIMARY KEY,
+      id uuid NOT NULL UNIQUE,
+      type VARCHAR(64) NOT NULL,
+      user_id uuid NOT NULL,
+      request_id VARCHAR(255) NOT NULL DEFAULT '',
+      payload JSONB NOT NULL,
+      occurred_at TIMESTAMPTZ NOT NULL,
+      published_at TIMESTAMPTZ,
+      attempts INT NOT NULL DEFAULT 0,
+      last_error TEXT NOT NULL DEFAULT ''
+   );
+
+CREATE INDEX outbox_pending_idx ON outbox (seq)
+WHERE
+   published_at IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE outbox;
+
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION users_notify_change () RETURNS trigger AS $$
+DECLARE
+   event_type TEXT;
+BEGIN
+   IF TG_OP = 'INSERT' THEN
+      event_type := 'user.created';
+   ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      event_type := 'user.deleted';
+   ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      event_type := 'user.restored';
+   ELSIF OLD.balance IS DISTINCT FROM NEW.balance
+      AND (OLD.name, OLD.email, OLD.age) IS NOT DISTINCT FROM (NEW.name, NEW.email, NEW.age) THEN
+      event_type := 'user.balance_changed';
+   ELSE
+      event_type := 'user.updated';
+   END IF;
+
+   PERFORM pg_notify(
+      'user_changes',
+      json_build_object(
+         'id', gen_random_uuid(),
+         'type', event_type,
+         'user_id', NEW.id,
+         'payload', json_build_object(
+            'user', json_build_object(
+               'id', NEW.id,
+               'name', NEW.name,
+               'email', NEW.email,
+               'age', NEW.age,
+               'balance', NEW.balance::text,
+               'deleted_at', NEW.deleted_at
+            )
+         ),
+         'occurred_at', now()
+      )::text
+   );
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_notify_change
+AFTER INSERT
+OR
+UPDATE ON users FOR EACH ROW
+EXECUTE FUNCTION users_notify_change ();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER users_notify_change ON users;
+
+DROP FUNCTION users_notify_change ();
+
+-- +goose StatementEnd
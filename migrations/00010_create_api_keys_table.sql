@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+   api_keys (
+      id uuid PRIMARY KEY,
+      name VARCHAR(255) NOT NULL,
+      prefix VARCHAR(32) NOT NULL UNIQUE,
+      hash BYTEA NOT NULL,
+      scopes TEXT[] NOT NULL DEFAULT '{}',
+      created_by_kind VARCHAR(32) NOT NULL,
+      created_by_id VARCHAR(255) NOT NULL,
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      expires_at TIMESTAMPTZ,
+      last_used_at TIMESTAMPTZ,
+      revoked_at TIMESTAMPTZ
+   );
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+
+-- +goose StatementEnd
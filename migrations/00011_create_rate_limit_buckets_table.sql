@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The buckets are cheap to lose: after a crash every client starts full.
+CREATE UNLOGGED TABLE
+   rate_limit_buckets (
+      key TEXT PRIMARY KEY,
+      tokens DOUBLE PRECISION NOT NULL,
+      updated_at TIMESTAMPTZ NOT NULL
+   );
+
+CREATE INDEX rate_limit_buckets_updated_at_idx ON rate_limit_buckets (updated_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rate_limit_buckets;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+   idempotency_keys (
+      scope VARCHAR(255) NOT NULL,
+      key VARCHAR(255) NOT NULL,
+      fingerprint BYTEA NOT NULL,
+      status INT,
+      header JSONB,
+      body BYTEA,
+      locked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      completed_at TIMESTAMPTZ,
+      expires_at TIMESTAMPTZ NOT NULL,
+      PRIMARY KEY (scope, key)
+   );
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+
+-- +goose StatementEnd
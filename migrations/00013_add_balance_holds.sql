@@ -0,0 +1,156 @@
+-- +goose Up
+-- +goose StatementBegin
+-- balances could go negative before they were checked; those are raised to
+-- zero by an adjustment from the external account, so that the ledger still
+-- matches them
+INSERT INTO
+   ledger_entries (operation_id, kind, account_id, direction, amount)
+SELECT
+   op.id,
+   'adjustment',
+   side.account_id,
+   side.direction,
+   - u.balance
+FROM
+   users u
+   CROSS JOIN LATERAL (
+      SELECT
+         gen_random_uuid () AS id,
+         u.id AS user_id
+   ) op
+   CROSS JOIN LATERAL (
+      VALUES
+         ('00000000-0000-0000-0000-000000000000'::uuid, 'debit'),
+         (u.id, 'credit')
+   ) side (account_id, direction)
+WHERE
+   u.balance < 0;
+
+UPDATE users
+SET
+   balance = 0,
+   version = version + 1
+WHERE
+   balance < 0;
+
+-- balance is what a user may spend, held_balance what active holds reserve
+ALTER TABLE users
+ADD COLUMN held_balance DECIMAL NOT NULL DEFAULT 0,
+ADD CONSTRAINT users_balance_non_negative CHECK (balance >= 0),
+ADD CONSTRAINT users_held_balance_non_negative CHECK (held_balance >= 0);
+
+CREATE TABLE
+   holds (
+      id uuid PRIMARY KEY,
+      user_id uuid NOT NULL,
+      amount DECIMAL NOT NULL CHECK (amount > 0),
+      captured_amount DECIMAL NOT NULL DEFAULT 0 CHECK (
+         captured_amount >= 0
+         AND captured_amount <= amount
+      ),
+      status VARCHAR(16) NOT NULL CHECK (status IN ('active', 'captured', 'released', 'expired')),
+      expires_at TIMESTAMPTZ NOT NULL,
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      settled_at TIMESTAMPTZ
+   );
+
+CREATE INDEX holds_user_id_idx ON holds (user_id, created_at);
+
+CREATE INDEX holds_active_expires_at_idx ON holds (expires_at)
+WHERE
+   status = 'active';
+
+-- a change of the held balance alone is a balance change too
+CREATE OR REPLACE FUNCTION users_notify_change () RETURNS trigger AS $$
+DECLARE
+   event_type TEXT;
+BEGIN
+   IF TG_OP = 'INSERT' THEN
+      event_type := 'user.created';
+   ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      event_type := 'user.deleted';
+   ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      event_type := 'user.restored';
+   ELSIF (OLD.balance, OLD.held_balance) IS DISTINCT FROM (NEW.balance, NEW.held_balance)
+      AND (OLD.name, OLD.email, OLD.age) IS NOT DISTINCT FROM (NEW.name, NEW.email, NEW.age) THEN
+      event_type := 'user.balance_changed';
+   ELSE
+      event_type := 'user.updated';
+   END IF;
+
+   PERFORM pg_notify(
+      'user_changes',
+      json_build_object(
+         'id', gen_random_uuid(),
+         'type', event_type,
+         'user_id', NEW.id,
+         'payload', json_build_object(
+            'user', json_build_object(
+               'id', NEW.id,
+               'name', NEW.name,
+               'email', NEW.email,
+               'age', NEW.age,
+               'balance', NEW.balance::text,
+               'held_balance', NEW.held_balance::text,
+               'deleted_at', NEW.deleted_at
+            )
+         ),
+         'occurred_at', now()
+      )::text
+   );
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION users_notify_change () RETURNS trigger AS $$
+DECLARE
+   event_type TEXT;
+BEGIN
+   IF TG_OP = 'INSERT' THEN
+      event_type := 'user.created';
+   ELSIF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      event_type := 'user.deleted';
+   ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      event_type := 'user.restored';
+   ELSIF OLD.balance IS DISTINCT FROM NEW.balance
+      AND (OLD.name, OLD.email, OLD.age) IS NOT DISTINCT FROM (NEW.name, NEW.email, NEW.age) THEN
+      event_type := 'user.balance_changed';
+   ELSE
+      event_type := 'user.updated';
+   END IF;
+
+   PERFORM pg_notify(
+      'user_changes',
+      json_build_object(
+         'id', gen_random_uuid(),
+         'type', event_type,
+         'user_id', NEW.id,
+         'payload', json_build_object(
+            'user', json_build_object(
+               'id', NEW.id,
+               'name', NEW.name,
+               'email', NEW.email,
+               'age', NEW.age,
+               'balance', NEW.balance::text,
+               'deleted_at', NEW.deleted_at
+            )
+         ),
+         'occurred_at', now()
+      )::text
+   );
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE holds;
+
+ALTER TABLE users
+DROP COLUMN held_balance,
+DROP CONSTRAINT users_balance_non_negative;
+
+-- +goose StatementEnd
@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users.balance is the wallet in the base currency; the others live here
+CREATE TABLE
+   wallets (
+      user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+      currency CHAR(3) NOT NULL,
+      balance DECIMAL NOT NULL DEFAULT 0 CHECK (balance >= 0),
+      updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      PRIMARY KEY (user_id, currency)
+   );
+
+-- a rate applies from effective_at until the next one of the pair
+CREATE TABLE
+   exchange_rates (
+      base CHAR(3) NOT NULL,
+      quote CHAR(3) NOT NULL CHECK (quote <> base),
+      rate DECIMAL NOT NULL CHECK (rate > 0),
+      effective_at TIMESTAMPTZ NOT NULL,
+      PRIMARY KEY (base, quote, effective_at)
+   );
+
+-- NULL is the base currency, which every entry written so far is in
+ALTER TABLE ledger_entries
+ADD COLUMN currency CHAR(3),
+ADD COLUMN rate DECIMAL CHECK (rate > 0);
+
+-- an operation between currencies balances in each of them
+CREATE OR REPLACE FUNCTION ledger_entries_check_balanced () RETURNS trigger AS $$
+DECLARE
+   diff DECIMAL;
+BEGIN
+   SELECT
+      COALESCE(SUM(CASE direction WHEN 'debit' THEN amount ELSE -amount END), 0) INTO diff
+   FROM
+      ledger_entries
+   WHERE
+      operation_id = NEW.operation_id
+      AND currency IS NOT DISTINCT FROM NEW.currency;
+
+   IF diff <> 0 THEN
+      RAISE EXCEPTION 'ledger operation % is unbalanced by % %', NEW.operation_id, diff, COALESCE(NEW.currency, '');
+   END IF;
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_entries_check_balanced () RETURNS trigger AS $$
+DECLARE
+   diff DECIMAL;
+BEGIN
+   SELECT
+      COALESCE(SUM(CASE direction WHEN 'debit' THEN amount ELSE -amount END), 0) INTO diff
+   FROM
+      ledger_entries
+   WHERE
+      operation_id = NEW.operation_id;
+
+   IF diff <> 0 THEN
+      RAISE EXCEPTION 'ledger operation % is unbalanced by %', NEW.operation_id, diff;
+   END IF;
+
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE ledger_entries
+DROP COLUMN rate,
+DROP COLUMN currency;
+
+DROP TABLE exchange_rates;
+
+DROP TABLE wallets;
+
+-- +goose StatementEnd
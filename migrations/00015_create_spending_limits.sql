@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- amounts are in the base currency; a NULL limit does not apply
+CREATE TABLE
+   limit_tiers (
+      name VARCHAR(64) PRIMARY KEY,
+      per_transaction DECIMAL CHECK (per_transaction >= 0),
+      daily DECIMAL CHECK (daily >= 0),
+      weekly DECIMAL CHECK (weekly >= 0),
+      monthly DECIMAL CHECK (monthly >= 0)
+   );
+
+INSERT INTO
+   limit_tiers (name, per_transaction, daily, weekly, monthly)
+VALUES
+   ('standard', 5000, 10000, 25000, 50000),
+   ('verified', 25000, 50000, 125000, 250000),
+   ('unlimited', NULL, NULL, NULL, NULL);
+
+-- users without a row are in the standard tier; a NULL override keeps the
+-- limit of the tier
+CREATE TABLE
+   spending_limits (
+      user_id uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+      tier VARCHAR(64) NOT NULL REFERENCES limit_tiers (name),
+      per_transaction DECIMAL CHECK (per_transaction >= 0),
+      daily DECIMAL CHECK (daily >= 0),
+      weekly DECIMAL CHECK (weekly >= 0),
+      monthly DECIMAL CHECK (monthly >= 0),
+      updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+   );
+
+-- what each withdrawal and outgoing transfer counted against the limits
+CREATE TABLE
+   spendings (
+      operation_id uuid PRIMARY KEY,
+      user_id uuid NOT NULL,
+      kind VARCHAR(16) NOT NULL,
+      amount DECIMAL NOT NULL CHECK (amount > 0),
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+   );
+
+CREATE INDEX spendings_user_id_created_at_idx ON spendings (user_id, created_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE spendings;
+
+DROP TABLE spending_limits;
+
+DROP TABLE limit_tiers;
+
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- operations held until an admin approves or rejects them; value is the
+-- amount in the base currency
+CREATE TABLE
+   risk_reviews (
+      id BIGSERIAL PRIMARY KEY,
+      kind VARCHAR(16) NOT NULL,
+      user_id uuid NOT NULL,
+      counterparty_id uuid,
+      amount DECIMAL NOT NULL CHECK (amount > 0),
+      currency VARCHAR(3) NOT NULL,
+      to_currency VARCHAR(3) NOT NULL DEFAULT '',
+      value DECIMAL NOT NULL,
+      rule VARCHAR(64) NOT NULL,
+      reason TEXT NOT NULL,
+      status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+      operation_id uuid,
+      decided_by_kind VARCHAR(32),
+      decided_by_id VARCHAR(255),
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+      decided_at TIMESTAMPTZ
+   );
+
+CREATE INDEX risk_reviews_status_idx ON risk_reviews (status, id);
+
+-- every screened operation, whatever the verdict; rules look back at them to
+-- spot bursts of transfers and new recipients
+CREATE TABLE
+   risk_decisions (
+      id BIGSERIAL PRIMARY KEY,
+      kind VARCHAR(16) NOT NULL,
+      user_id uuid NOT NULL,
+      counterparty_id uuid,
+      value DECIMAL NOT NULL,
+      verdict VARCHAR(16) NOT NULL CHECK (verdict IN ('allow', 'review', 'deny')),
+      rule VARCHAR(64) NOT NULL DEFAULT '',
+      reason TEXT NOT NULL DEFAULT '',
+      review_id BIGINT REFERENCES risk_reviews (id),
+      created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+   );
+
+CREATE INDEX risk_decisions_user_id_created_at_idx ON risk_decisions (user_id, kind, created_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE risk_decisions;
+
+DROP TABLE risk_reviews;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- holds are screened too; a hold held for review keeps the expiry it asked for
+ALTER TABLE risk_reviews
+ADD COLUMN expires_at TIMESTAMPTZ;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM risk_decisions
+WHERE
+   kind = 'hold';
+
+UPDATE risk_decisions
+SET
+   review_id = NULL
+WHERE
+   review_id IN (
+      SELECT
+         id
+      FROM
+         risk_reviews
+      WHERE
+         kind = 'hold'
+   );
+
+DELETE FROM risk_reviews
+WHERE
+   kind = 'hold';
+
+ALTER TABLE risk_reviews
+DROP COLUMN expires_at;
+
+-- +goose StatementEnd
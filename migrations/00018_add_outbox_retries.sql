@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- an event that keeps failing is retried with backoff and is dead after the
+-- last attempt, so that it no longer holds back later events; next_attempt_at
+-- also leases claimed events to the relay publishing them
+ALTER TABLE outbox
+ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'published', 'dead')),
+ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE outbox
+SET
+   status = 'published'
+WHERE
+   published_at IS NOT NULL;
+
+DROP INDEX outbox_pending_idx;
+
+CREATE INDEX outbox_pending_idx ON outbox (seq)
+WHERE
+   status = 'pending';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX outbox_pending_idx;
+
+CREATE INDEX outbox_pending_idx ON outbox (seq)
+WHERE
+   published_at IS NULL;
+
+ALTER TABLE outbox
+DROP COLUMN next_attempt_at,
+DROP COLUMN status;
+
+-- +goose StatementEnd
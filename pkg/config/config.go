@@ -1,23 +1,183 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 )
 
 type Config struct {
-	Mode     string `env:"MODE"`
-	Postgres Postgres
-	HTTP     HTTP
+	Mode        string `env:"MODE"`
+	Postgres    Postgres
+	HTTP        HTTP
+	GRPC        GRPC
+	Auth        Auth
+	RateLimit   RateLimit
+	Idempotency Idempotency
+	// ShutdownTimeout bounds how long the servers wait for requests in flight
+	// on shutdown.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"15s"`
+	I18N            I18N
+	Purge           Purge
+	Holds           Holds
+	Currency        Currency
+	Risk            Risk
+	Outbox          Outbox
+	Webhooks        Webhooks
+	Stream          Stream
 }
 
 type HTTP struct {
 	Port         int           `env:"HTTP_PORT" default:"8080"`
 	ReadTimeout  time.Duration `env:"HTTP_READ_TIMEOUT" default:"10s"`
 	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT" default:"10s"`
+	LegacyRoutes LegacyRoutes
+}
+
+// LegacyRoutes dates the deprecation of the unversioned /api/users routes and
+// the day they are removed.
+type LegacyRoutes struct {
+	DeprecatedAt time.Time `env:"LEGACY_ROUTES_DEPRECATED_AT" default:"2026-10-17T00:00:00Z"`
+	SunsetAt     time.Time `env:"LEGACY_ROUTES_SUNSET_AT" default:"2027-04-17T00:00:00Z"`
+}
+
+type GRPC struct {
+	Port int `env:"GRPC_PORT" default:"9090"`
+}
+
+// Auth configures bearer token verification. Keys come from JWKSFile, or
+// PEMFile when no JWKS is set, and are reloaded every ReloadInterval. Issuer
+// and Audience are only checked when set.
+type Auth struct {
+	Enabled        bool          `env:"AUTH_ENABLED" default:"true"`
+	JWKSFile       string        `env:"AUTH_JWKS_FILE"`
+	PEMFile        string        `env:"AUTH_PEM_FILE"`
+	PEMKeyID       string        `env:"AUTH_PEM_KEY_ID"`
+	Issuer         string        `env:"AUTH_ISSUER"`
+	Audience       string        `env:"AUTH_AUDIENCE"`
+	Leeway         time.Duration `env:"AUTH_LEEWAY" default:"30s"`
+	ReloadInterval time.Duration `env:"AUTH_RELOAD_INTERVAL" default:"5m"`
+	// PolicyFile grants permissions to the roles in tokens. The built-in
+	// policy is used when it is empty.
+	PolicyFile string `env:"AUTH_POLICY_FILE"`
+}
+
+// RateLimit configures the per-client token buckets, see ratelimit.ParseLimit
+// for the format of a limit. Routes maps "<METHOD> <route pattern>" to a limit
+// of its own; the other routes of a client share Default. Address limits every
+// client address across all routes before authentication, so that it bounds
+// failed attempts too and should allow for clients sharing an address. Store
+// is memory, where each replica counts on its own, or postgres, shared by all
+// of them.
+type RateLimit struct {
+	Enabled       bool              `env:"RATE_LIMIT_ENABLED" default:"true"`
+	Store         string            `env:"RATE_LIMIT_STORE" default:"memory"`
+	Default       string            `env:"RATE_LIMIT_DEFAULT" default:"600/m"`
+	Address       string            `env:"RATE_LIMIT_ADDRESS" default:"3000/m"`
+	Routes        map[string]string `env:"RATE_LIMIT_ROUTES" envSeparator:";" envKeyValSeparator:"="`
+	SweepInterval time.Duration     `env:"RATE_LIMIT_SWEEP_INTERVAL" default:"10m"`
+}
+
+// Idempotency configures the Idempotency-Key header. Responses are kept for
+// TTL. A duplicate of a request in progress waits up to Wait for its response
+// and then gets 409; a request in progress for longer than LockTimeout is
+// taken to be abandoned and may be retried. A retry taking over a request that
+// is still running would run it twice, so LockTimeout must exceed
+// HTTP_WRITE_TIMEOUT, and the request is cancelled once its lock lapses.
+type Idempotency struct {
+	TTL           time.Duration `env:"IDEMPOTENCY_KEY_TTL" default:"24h"`
+	Wait          time.Duration `env:"IDEMPOTENCY_WAIT" default:"5s"`
+	LockTimeout   time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" default:"1m"`
+	PurgeInterval time.Duration `env:"IDEMPOTENCY_PURGE_INTERVAL" default:"1h"`
+}
+
+type I18N struct {
+	DefaultLanguage string `env:"DEFAULT_LANGUAGE" default:"en"`
+}
+
+type Purge struct {
+	Retention time.Duration `env:"USER_PURGE_RETENTION" default:"720h"`
+	Interval  time.Duration `env:"USER_PURGE_INTERVAL" default:"1h"`
+}
+
+// Holds configures the expiry of overdue holds, checked every ExpiryInterval
+// in batches of ExpiryBatchSize.
+type Holds struct {
+	ExpiryInterval  time.Duration `env:"HOLD_EXPIRY_INTERVAL" default:"1m"`
+	ExpiryBatchSize int           `env:"HOLD_EXPIRY_BATCH_SIZE" default:"100"`
+}
+
+// Currency configures money. Balances of users are in Base, other currencies
+// are kept in wallets. RatesFile, when set, is a CSV file of exchange rates
+// loaded on startup; see package rates for its format.
+type Currency struct {
+	Base      string `env:"BASE_CURRENCY" default:"USD"`
+	RatesFile string `env:"EXCHANGE_RATES_FILE"`
+}
+
+// Risk configures the screening of deposits, withdrawals and transfers to
+// other users, in the base currency. An operation worth ReviewAmount goes to
+// review and one worth DenyAmount is denied. A transfer goes to review when
+// its sender already made VelocityMax within VelocityWindow, or when it makes
+// them pay more than FanOutMax recipients within FanOutWindow. A withdrawal
+// worth NewAccountAmount goes to review when the account is younger than
+// NewAccountAge. A zero amount, count or age turns its rule off.
+type Risk struct {
+	Enabled          bool            `env:"RISK_ENABLED" default:"true"`
+	ReviewAmount     decimal.Decimal `env:"RISK_REVIEW_AMOUNT" default:"10000"`
+	DenyAmount       decimal.Decimal `env:"RISK_DENY_AMOUNT" default:"100000"`
+	VelocityWindow   time.Duration   `env:"RISK_VELOCITY_WINDOW" default:"10m"`
+	VelocityMax      int             `env:"RISK_VELOCITY_MAX" default:"5"`
+	NewAccountAge    time.Duration   `env:"RISK_NEW_ACCOUNT_AGE" default:"72h"`
+	NewAccountAmount decimal.Decimal `env:"RISK_NEW_ACCOUNT_AMOUNT" default:"1000"`
+	FanOutWindow     time.Duration   `env:"RISK_FAN_OUT_WINDOW" default:"1h"`
+	FanOutMax        int             `env:"RISK_FAN_OUT_MAX" default:"10"`
+}
+
+// Outbox configures the relay. Sinks lists where events go: stdout, file
+// (NDJSON appended to File) and webhook (POSTed to WebhookURL). An event that
+// fails is retried after RetryBaseDelay, doubling up to RetryMaxDelay, and is
+// dead after MaxAttempts. The relay claims BatchSize events for Lease.
+type Outbox struct {
+	Sinks          []string      `env:"OUTBOX_SINKS" envSeparator:"," default:"stdout"`
+	File           string        `env:"OUTBOX_FILE"`
+	WebhookURL     string        `env:"OUTBOX_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `env:"OUTBOX_WEBHOOK_TIMEOUT" default:"5s"`
+	MaxAttempts    int           `env:"OUTBOX_MAX_ATTEMPTS" default:"10"`
+	RetryBaseDelay time.Duration `env:"OUTBOX_RETRY_BASE_DELAY" default:"1s"`
+	RetryMaxDelay  time.Duration `env:"OUTBOX_RETRY_MAX_DELAY" default:"5m"`
+	BatchSize      int           `env:"OUTBOX_BATCH_SIZE" default:"100"`
+	Lease          time.Duration `env:"OUTBOX_LEASE" default:"5m"`
+	Interval       time.Duration `env:"OUTBOX_INTERVAL" default:"1s"`
+}
+
+// Webhooks configures delivery to subscribed endpoints. A failed delivery is
+// retried after RetryBaseDelay, doubling up to RetryMaxDelay, and is dead
+// after MaxAttempts. A dispatcher claims BatchSize deliveries for Lease, which
+// should outlast sending them all, Timeout each.
+type Webhooks struct {
+	MaxAttempts    int           `env:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	RetryBaseDelay time.Duration `env:"WEBHOOK_RETRY_BASE_DELAY" default:"30s"`
+	RetryMaxDelay  time.Duration `env:"WEBHOOK_RETRY_MAX_DELAY" default:"1h"`
+	Timeout        time.Duration `env:"WEBHOOK_TIMEOUT" default:"10s"`
+	BatchSize      int           `env:"WEBHOOK_BATCH_SIZE" default:"20"`
+	Lease          time.Duration `env:"WEBHOOK_LEASE" default:"5m"`
+	Interval       time.Duration `env:"WEBHOOK_INTERVAL" default:"1s"`
+}
+
+// Stream configures GET /api/users/events. ReplaySize events are kept for
+// clients resuming with Last-Event-ID; a client more than ClientBuffer events
+// behind is disconnected.
+type Stream struct {
+	ReplaySize   int           `env:"STREAM_REPLAY_SIZE" default:"1000"`
+	ClientBuffer int           `env:"STREAM_CLIENT_BUFFER" default:"64"`
+	Heartbeat    time.Duration `env:"STREAM_HEARTBEAT" default:"15s"`
+	RetryDelay   time.Duration `env:"STREAM_LISTEN_RETRY_DELAY" default:"5s"`
 }
 
 type Postgres struct {
@@ -36,5 +196,101 @@ func New(envPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse env: %w", err)
 	}
 
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid env: %w", err)
+	}
+
 	return &c, nil
 }
+
+type duration struct {
+	name  string
+	value time.Duration
+}
+
+type count struct {
+	name  string
+	value int
+}
+
+// validate rejects durations out of range. Intervals drive tickers, which
+// panic unless they are positive, and a lease, timeout or delay of zero would
+// expire at once or retry in a busy loop. The other durations may be zero,
+// which turns a risk rule off for example, but not negative. Batch sizes and
+// attempts must be positive too: a worker claiming nothing would run again at
+// once, and an event allowed no attempts would die unsent.
+func (c *Config) validate() error {
+	positive := []duration{
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"AUTH_RELOAD_INTERVAL", c.Auth.ReloadInterval},
+		{"RATE_LIMIT_SWEEP_INTERVAL", c.RateLimit.SweepInterval},
+		{"IDEMPOTENCY_KEY_TTL", c.Idempotency.TTL},
+		{"IDEMPOTENCY_LOCK_TIMEOUT", c.Idempotency.LockTimeout},
+		{"IDEMPOTENCY_PURGE_INTERVAL", c.Idempotency.PurgeInterval},
+		{"USER_PURGE_INTERVAL", c.Purge.Interval},
+		{"HOLD_EXPIRY_INTERVAL", c.Holds.ExpiryInterval},
+		{"OUTBOX_WEBHOOK_TIMEOUT", c.Outbox.WebhookTimeout},
+		{"OUTBOX_RETRY_BASE_DELAY", c.Outbox.RetryBaseDelay},
+		{"OUTBOX_RETRY_MAX_DELAY", c.Outbox.RetryMaxDelay},
+		{"OUTBOX_LEASE", c.Outbox.Lease},
+		{"OUTBOX_INTERVAL", c.Outbox.Interval},
+		{"WEBHOOK_RETRY_BASE_DELAY", c.Webhooks.RetryBaseDelay},
+		{"WEBHOOK_RETRY_MAX_DELAY", c.Webhooks.RetryMaxDelay},
+		{"WEBHOOK_TIMEOUT", c.Webhooks.Timeout},
+		{"WEBHOOK_LEASE", c.Webhooks.Lease},
+		{"WEBHOOK_INTERVAL", c.Webhooks.Interval},
+		{"STREAM_HEARTBEAT", c.Stream.Heartbeat},
+		{"STREAM_LISTEN_RETRY_DELAY", c.Stream.RetryDelay},
+	}
+
+	nonNegative := []duration{
+		{"HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout},
+		{"AUTH_LEEWAY", c.Auth.Leeway},
+		{"IDEMPOTENCY_WAIT", c.Idempotency.Wait},
+		{"USER_PURGE_RETENTION", c.Purge.Retention},
+		{"RISK_VELOCITY_WINDOW", c.Risk.VelocityWindow},
+		{"RISK_NEW_ACCOUNT_AGE", c.Risk.NewAccountAge},
+		{"RISK_FAN_OUT_WINDOW", c.Risk.FanOutWindow},
+	}
+
+	positiveCounts := []count{
+		{"HOLD_EXPIRY_BATCH_SIZE", c.Holds.ExpiryBatchSize},
+		{"OUTBOX_MAX_ATTEMPTS", c.Outbox.MaxAttempts},
+		{"OUTBOX_BATCH_SIZE", c.Outbox.BatchSize},
+		{"WEBHOOK_MAX_ATTEMPTS", c.Webhooks.MaxAttempts},
+		{"WEBHOOK_BATCH_SIZE", c.Webhooks.BatchSize},
+	}
+
+	var errs []error
+
+	for _, d := range positive {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", d.name, d.value))
+		}
+	}
+
+	for _, d := range nonNegative {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", d.name, d.value))
+		}
+	}
+
+	for _, n := range positiveCounts {
+		if n.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", n.name, n.value))
+		}
+	}
+
+	if c.Idempotency.LockTimeout <= c.HTTP.WriteTimeout {
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_LOCK_TIMEOUT must exceed HTTP_WRITE_TIMEOUT, got %s and %s",
+			c.Idempotency.LockTimeout, c.HTTP.WriteTimeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Dev reports whether the app runs in development mode.
+func (c *Config) Dev() bool {
+	return strings.EqualFold(c.Mode, "dev")
+}
@@ -0,0 +1,91 @@
+package config_test
+
+import (
+	"testing"
+	"users-app/pkg/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		expectedErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:        "zero interval",
+			env:         map[string]string{"HOLD_EXPIRY_INTERVAL": "0s"},
+			expectedErr: "HOLD_EXPIRY_INTERVAL must be positive, got 0s",
+		},
+		{
+			name:        "negative lease",
+			env:         map[string]string{"WEBHOOK_LEASE": "-1m"},
+			expectedErr: "WEBHOOK_LEASE must be positive, got -1m0s",
+		},
+		{
+			name:        "zero retry delay",
+			env:         map[string]string{"OUTBOX_RETRY_BASE_DELAY": "0s"},
+			expectedErr: "OUTBOX_RETRY_BASE_DELAY must be positive, got 0s",
+		},
+		{
+			name: "zero risk window turns the rule off",
+			env:  map[string]string{"RISK_VELOCITY_WINDOW": "0s"},
+		},
+		{
+			name:        "negative risk window",
+			env:         map[string]string{"RISK_VELOCITY_WINDOW": "-1h"},
+			expectedErr: "RISK_VELOCITY_WINDOW must not be negative, got -1h0m0s",
+		},
+		{
+			name:        "zero hold expiry batch",
+			env:         map[string]string{"HOLD_EXPIRY_BATCH_SIZE": "0"},
+			expectedErr: "HOLD_EXPIRY_BATCH_SIZE must be positive, got 0",
+		},
+		{
+			name:        "zero outbox batch",
+			env:         map[string]string{"OUTBOX_BATCH_SIZE": "0"},
+			expectedErr: "OUTBOX_BATCH_SIZE must be positive, got 0",
+		},
+		{
+			name:        "negative webhook batch",
+			env:         map[string]string{"WEBHOOK_BATCH_SIZE": "-5"},
+			expectedErr: "WEBHOOK_BATCH_SIZE must be positive, got -5",
+		},
+		{
+			name:        "zero outbox attempts",
+			env:         map[string]string{"OUTBOX_MAX_ATTEMPTS": "0"},
+			expectedErr: "OUTBOX_MAX_ATTEMPTS must be positive, got 0",
+		},
+		{
+			name:        "zero webhook attempts",
+			env:         map[string]string{"WEBHOOK_MAX_ATTEMPTS": "0"},
+			expectedErr: "WEBHOOK_MAX_ATTEMPTS must be positive, got 0",
+		},
+		{
+			name:        "idempotency lock shorter than writes",
+			env:         map[string]string{"IDEMPOTENCY_LOCK_TIMEOUT": "5s", "HTTP_WRITE_TIMEOUT": "10s"},
+			expectedErr: "IDEMPOTENCY_LOCK_TIMEOUT must exceed HTTP_WRITE_TIMEOUT, got 5s and 10s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := config.New("../../.env")
+			if tt.expectedErr != "" {
+				r.ErrorContains(err, tt.expectedErr)
+			} else {
+				r.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+type Catalog struct {
+	defaultLang string
+	messages    map[string]map[string]string
+}
+
+// New loads the bundled locales. defaultLang is used when a client accepts
+// none of them and must be one of them itself.
+func New(defaultLang string) (*Catalog, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locales: %w", err)
+	}
+
+	c := &Catalog{
+		defaultLang: strings.ToLower(defaultLang),
+		messages:    make(map[string]map[string]string, len(files)),
+	}
+
+	for _, f := range files {
+		data, err := locales.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale %s: %w", f.Name(), err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse locale %s: %w", f.Name(), err)
+		}
+
+		c.messages[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = messages
+	}
+
+	if _, ok := c.messages[c.defaultLang]; !ok {
+		return nil, fmt.Errorf("unsupported default language: %s", defaultLang)
+	}
+
+	return c, nil
+}
+
+func (c *Catalog) Default() string {
+	return c.defaultLang
+}
+
+// Negotiate picks the supported language the client prefers most according
+// to an Accept-Language header, falling back to the default language.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []weighted
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+
+			q = parsed
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, weighted{tag: strings.ToLower(tag), q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return c.defaultLang
+		}
+
+		primary, _, _ := strings.Cut(r.tag, "-")
+		if _, ok := c.messages[primary]; ok {
+			return primary
+		}
+	}
+
+	return c.defaultLang
+}
+
+// Message renders the message stored under key, replacing {name} placeholders
+// with args. Missing translations fall back to the default language and then
+// to the key itself.
+func (c *Catalog) Message(lang, key string, args map[string]any) string {
+	msg, ok := c.messages[lang][key]
+	if !ok {
+		if msg, ok = c.messages[c.defaultLang][key]; !ok {
+			return key
+		}
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+
+	replacements := make([]string, 0, len(args)*2)
+	for k, v := range args {
+		replacements = append(replacements, "{"+k+"}", fmt.Sprint(v))
+	}
+
+	return strings.NewReplacer(replacements...).Replace(msg)
+}
@@ -0,0 +1,52 @@
+package i18n_test
+
+import (
+	"testing"
+	"users-app/pkg/i18n"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog_Negotiate(t *testing.T) {
+	r := require.New(t)
+
+	catalog, err := i18n.New("en")
+	r.NoError(err)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expectedLang   string
+	}{
+		{name: "empty", acceptLanguage: "", expectedLang: "en"},
+		{name: "exact", acceptLanguage: "ru", expectedLang: "ru"},
+		{name: "region subtag", acceptLanguage: "ru-RU", expectedLang: "ru"},
+		{name: "quality order", acceptLanguage: "en;q=0.5, ru;q=0.8", expectedLang: "ru"},
+		{name: "unsupported first", acceptLanguage: "de, fr;q=0.9, ru;q=0.1", expectedLang: "ru"},
+		{name: "excluded", acceptLanguage: "ru;q=0, de", expectedLang: "en"},
+		{name: "wildcard", acceptLanguage: "de, *;q=0.5", expectedLang: "en"},
+		{name: "malformed quality", acceptLanguage: "ru;q=abc", expectedLang: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Equal(tt.expectedLang, catalog.Negotiate(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestCatalog_Message(t *testing.T) {
+	r := require.New(t)
+
+	catalog, err := i18n.New("ru")
+	r.NoError(err)
+
+	r.Equal("name must be at most 255 characters",
+		catalog.Message("en", "validation.too_long", map[string]any{"field": "name", "max": 255}))
+	r.Equal("поле name обязательно",
+		catalog.Message("de", "validation.required", map[string]any{"field": "name"}))
+	r.Equal("unknown.key", catalog.Message("en", "unknown.key", nil))
+
+	_, err = i18n.New("de")
+	r.Error(err)
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Listener receives notifications over a dedicated connection. LISTEN is
+// bound to a session, so it cannot use a pooled connection that is handed to
+// other queries in between.
+type Listener struct {
+	dsn string
+}
+
+func NewListener(dsn string) *Listener {
+	return &Listener{
+		dsn: dsn,
+	}
+}
+
+// Listen passes the payload of every notification on channel to handle. It
+// returns nil once ctx is cancelled and an error if the connection fails;
+// notifications sent while no connection is listening are lost.
+func (l *Listener) Listen(ctx context.Context, channel string, handle func(payload string)) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err := conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification on %s: %w", channel, err)
+		}
+
+		handle(n.Payload)
+	}
+}